@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/privacy/gpp"
 )
 
 // Params defines the paramters of an AMP request.
@@ -15,6 +16,7 @@ type Params struct {
 	CanonicalURL    string
 	Consent         string
 	Debug           bool
+	GPPSID          []gpp.SectionID
 	Origin          string
 	Size            Size
 	Slot            string
@@ -40,11 +42,17 @@ func ParseParams(httpRequest *http.Request) (Params, error) {
 		return Params{}, errors.New("AMP requests require an AMP tag_id")
 	}
 
+	gppSID, err := gpp.ParseSIDs(query.Get("gpp_sid"))
+	if err != nil {
+		return Params{}, err
+	}
+
 	params := Params{
 		Account:      query.Get("account"),
 		CanonicalURL: query.Get("curl"),
 		Consent:      chooseConsent(query.Get("consent_string"), query.Get("gdpr_consent")),
 		Debug:        query.Get("debug") == "1",
+		GPPSID:       gppSID,
 		Origin:       query.Get("__amp_source_origin"),
 		Size: Size{
 			Height:         parseInt(query.Get("h")),
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,12 +26,13 @@ func TestParseParams(t *testing.T) {
 		{
 			description: "All Fields",
 			query: "tag_id=anyTagID&account=anyAccount&curl=anyCurl&consent_string=anyConsent&debug=1&__amp_source_origin=anyOrigin" +
-				"&slot=anySlot&timeout=42&h=1&w=2&oh=3&ow=4&ms=10x11,12x13",
+				"&slot=anySlot&timeout=42&h=1&w=2&oh=3&ow=4&ms=10x11,12x13&gpp_sid=2,6",
 			expectedParams: Params{
 				Account:         "anyAccount",
 				CanonicalURL:    "anyCurl",
 				Consent:         "anyConsent",
 				Debug:           true,
+				GPPSID:          []gpp.SectionID{gpp.SectionTCFEU2, gpp.SectionUSPV1},
 				Origin:          "anyOrigin",
 				Slot:            "anySlot",
 				StoredRequestID: "anyTagID",
@@ -66,6 +68,11 @@ func TestParseParams(t *testing.T) {
 			query:          "tag_id=anyTagID&gdpr_consent=consent2",
 			expectedParams: Params{StoredRequestID: "anyTagID", Consent: "consent2"},
 		},
+		{
+			description:   "Invalid gpp_sid",
+			query:         "tag_id=anyTagID&gpp_sid=2,x",
+			expectedError: "gpp_sid must be a comma separated list of section ids. Got 2,x",
+		},
 		{
 			description:    "Debug 0",
 			query:          "tag_id=anyTagID&debug=0",
@@ -5,6 +5,7 @@ import (
 
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/ivt"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/usersync"
 )
@@ -34,6 +35,8 @@ type AuctionObject struct {
 	Response  *openrtb.BidResponse
 	Account   *config.Account
 	StartTime time.Time
+	// InvalidTraffic are the invalid traffic checks the request failed.
+	InvalidTraffic []ivt.Detection
 }
 
 //Loggable object of a transaction at /openrtb2/amp endpoint
@@ -45,6 +48,8 @@ type AmpObject struct {
 	AmpTargetingValues map[string]string
 	Origin             string
 	StartTime          time.Time
+	// InvalidTraffic are the invalid traffic checks the request failed.
+	InvalidTraffic []ivt.Detection
 }
 
 //Loggable object of a transaction at /openrtb2/video endpoint
@@ -56,6 +61,8 @@ type VideoObject struct {
 	VideoRequest  *openrtb_ext.BidRequestVideo
 	VideoResponse *openrtb_ext.BidResponseVideo
 	StartTime     time.Time
+	// InvalidTraffic are the invalid traffic checks the request failed.
+	InvalidTraffic []ivt.Detection
 }
 
 //Loggable object of a transaction at /setuid
@@ -0,0 +1,196 @@
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// State is the state of a circuit.
+type State string
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = "closed"
+	// StateOpen lets no call through, until the open interval is over.
+	StateOpen State = "open"
+	// StateHalfOpen lets one probe call through at a time, to find out whether the server is back.
+	StateHalfOpen State = "half_open"
+)
+
+// Breakers keeps a circuit for each bidder, or for each host of each bidder, and decides whether
+// the bidders may be called.
+//
+// A nil Breakers lets every call through, so callers don't need to check whether it's enabled.
+// It may be used by several goroutines at once.
+type Breakers struct {
+	errorThreshold int
+	openInterval   time.Duration
+	halfOpenProbes int
+	perHost        bool
+	now            func() time.Time
+
+	mutex    sync.Mutex
+	circuits map[circuitKey]*circuit
+}
+
+type circuitKey struct {
+	bidder openrtb_ext.BidderName
+	host   string
+}
+
+type circuit struct {
+	state         State
+	failures      int
+	successes     int
+	openedAt      time.Time
+	probeInFlight bool
+	probeStarted  time.Time
+}
+
+// Status describes a circuit, as shown on the admin port.
+type Status struct {
+	Bidder   string     `json:"bidder"`
+	Host     string     `json:"host,omitempty"`
+	State    State      `json:"state"`
+	Failures int        `json:"failures"`
+	OpenedAt *time.Time `json:"openedAt,omitempty"`
+}
+
+// NewBreakers returns the circuit breakers for the config, or nil if they aren't enabled.
+func NewBreakers(cfg config.CircuitBreaker) *Breakers {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Breakers{
+		errorThreshold: cfg.ErrorThreshold,
+		openInterval:   time.Duration(cfg.OpenIntervalMillis) * time.Millisecond,
+		halfOpenProbes: cfg.HalfOpenProbes,
+		perHost:        cfg.PerHost,
+		now:            time.Now,
+		circuits:       make(map[circuitKey]*circuit),
+	}
+}
+
+// Allow tells whether the bidder may call the host. If it returns true, the caller should report how
+// the call went with Record.
+func (b *Breakers) Allow(bidder openrtb_ext.BidderName, host string) bool {
+	if b == nil {
+		return true
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[b.key(bidder, host)]
+	if !ok {
+		return true
+	}
+	switch c.state {
+	case StateOpen:
+		if b.now().Sub(c.openedAt) < b.openInterval {
+			return false
+		}
+		c.state = StateHalfOpen
+		c.successes = 0
+		b.startProbe(c)
+		return true
+	case StateHalfOpen:
+		// A probe which was never recorded, such as one cancelled with its auction, is given up on
+		// after the open interval.
+		if c.probeInFlight && b.now().Sub(c.probeStarted) < b.openInterval {
+			return false
+		}
+		b.startProbe(c)
+		return true
+	}
+	return true
+}
+
+// Record reports whether a call the bidder made to the host succeeded.
+func (b *Breakers) Record(bidder openrtb_ext.BidderName, host string, success bool) {
+	if b == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	key := b.key(bidder, host)
+	c, ok := b.circuits[key]
+	if !ok {
+		if success {
+			return
+		}
+		c = &circuit{state: StateClosed}
+		b.circuits[key] = c
+	}
+
+	switch c.state {
+	case StateClosed:
+		if success {
+			c.failures = 0
+		} else if c.failures++; c.failures >= b.errorThreshold {
+			b.open(c)
+		}
+	case StateHalfOpen:
+		c.probeInFlight = false
+		if !success {
+			c.failures++
+			b.open(c)
+		} else if c.successes++; c.successes >= b.halfOpenProbes {
+			c.state = StateClosed
+			c.failures = 0
+		}
+	}
+}
+
+// Status returns the state of every circuit which saw a failure, sorted by bidder and host.
+func (b *Breakers) Status() []Status {
+	if b == nil {
+		return nil
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	statuses := make([]Status, 0, len(b.circuits))
+	for key, c := range b.circuits {
+		status := Status{
+			Bidder:   key.bidder.String(),
+			Host:     key.host,
+			State:    c.state,
+			Failures: c.failures,
+		}
+		if c.state != StateClosed {
+			openedAt := c.openedAt
+			status.OpenedAt = &openedAt
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Bidder != statuses[j].Bidder {
+			return statuses[i].Bidder < statuses[j].Bidder
+		}
+		return statuses[i].Host < statuses[j].Host
+	})
+	return statuses
+}
+
+func (b *Breakers) key(bidder openrtb_ext.BidderName, host string) circuitKey {
+	if !b.perHost {
+		host = ""
+	}
+	return circuitKey{bidder: bidder, host: host}
+}
+
+func (b *Breakers) startProbe(c *circuit) {
+	c.probeInFlight = true
+	c.probeStarted = b.now()
+}
+
+func (b *Breakers) open(c *circuit) {
+	c.state = StateOpen
+	c.openedAt = b.now()
+	c.successes = 0
+}
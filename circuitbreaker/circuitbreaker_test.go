@@ -0,0 +1,97 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	time time.Time
+}
+
+func (clock *fakeClock) now() time.Time {
+	return clock.time
+}
+
+func newTestBreakers(perHost bool) (*Breakers, *fakeClock) {
+	clock := &fakeClock{time: time.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC)}
+	breakers := NewBreakers(config.CircuitBreaker{
+		Enabled:            true,
+		ErrorThreshold:     3,
+		OpenIntervalMillis: 1000,
+		HalfOpenProbes:     2,
+		PerHost:            perHost,
+	})
+	breakers.now = clock.now
+	return breakers, clock
+}
+
+func TestDisabled(t *testing.T) {
+	breakers := NewBreakers(config.CircuitBreaker{ErrorThreshold: 1, OpenIntervalMillis: 1000, HalfOpenProbes: 1})
+
+	breakers.Record(openrtb_ext.BidderAppnexus, "", false)
+	assert.True(t, breakers.Allow(openrtb_ext.BidderAppnexus, ""))
+	assert.Nil(t, breakers.Status())
+}
+
+func TestStateTransitions(t *testing.T) {
+	breakers, clock := newTestBreakers(false)
+	bidder := openrtb_ext.BidderAppnexus
+
+	breakers.Record(bidder, "", false)
+	breakers.Record(bidder, "", false)
+	breakers.Record(bidder, "", true)
+	breakers.Record(bidder, "", false)
+	breakers.Record(bidder, "", false)
+	assert.True(t, breakers.Allow(bidder, ""), "A success should reset the failure count")
+
+	breakers.Record(bidder, "", false)
+	assert.False(t, breakers.Allow(bidder, ""), "The circuit should open at the threshold")
+
+	clock.time = clock.time.Add(time.Second)
+	assert.True(t, breakers.Allow(bidder, ""), "A probe should be let through after the open interval")
+	assert.False(t, breakers.Allow(bidder, ""), "Only one probe should be let through at a time")
+
+	breakers.Record(bidder, "", false)
+	assert.False(t, breakers.Allow(bidder, ""), "A failed probe should open the circuit again")
+
+	clock.time = clock.time.Add(time.Second)
+	assert.True(t, breakers.Allow(bidder, ""))
+	breakers.Record(bidder, "", true)
+	assert.True(t, breakers.Allow(bidder, ""))
+	breakers.Record(bidder, "", true)
+	assert.Equal(t, []Status{{Bidder: "appnexus", State: StateClosed}}, breakers.Status(), "Enough successful probes should close the circuit")
+}
+
+func TestAbandonedProbe(t *testing.T) {
+	breakers, clock := newTestBreakers(false)
+	bidder := openrtb_ext.BidderAppnexus
+	for i := 0; i < 3; i++ {
+		breakers.Record(bidder, "", false)
+	}
+
+	clock.time = clock.time.Add(time.Second)
+	assert.True(t, breakers.Allow(bidder, ""))
+	assert.False(t, breakers.Allow(bidder, ""))
+
+	clock.time = clock.time.Add(time.Second)
+	assert.True(t, breakers.Allow(bidder, ""), "A probe which was never recorded should be given up on")
+}
+
+func TestPerHost(t *testing.T) {
+	breakers, clock := newTestBreakers(true)
+	bidder := openrtb_ext.BidderAppnexus
+	for i := 0; i < 3; i++ {
+		breakers.Record(bidder, "us.example.com", false)
+	}
+
+	assert.False(t, breakers.Allow(bidder, "us.example.com"))
+	assert.True(t, breakers.Allow(bidder, "eu.example.com"), "Other hosts should still be called")
+
+	openedAt := clock.time
+	assert.Equal(t, []Status{{Bidder: "appnexus", Host: "us.example.com", State: StateOpen, Failures: 3, OpenedAt: &openedAt}}, breakers.Status())
+}
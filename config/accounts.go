@@ -1,5 +1,11 @@
 package config
 
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
 // IntegrationType enumerates the values of integrations Prebid Server can configure for an account
 type IntegrationType string
 
@@ -13,19 +19,224 @@ const (
 
 // Account represents a publisher account configuration
 type Account struct {
-	ID            string      `mapstructure:"id" json:"id"`
-	Disabled      bool        `mapstructure:"disabled" json:"disabled"`
-	CacheTTL      DefaultTTLs `mapstructure:"cache_ttl" json:"cache_ttl"`
-	EventsEnabled bool        `mapstructure:"events_enabled" json:"events_enabled"`
-	CCPA          AccountCCPA `mapstructure:"ccpa" json:"ccpa"`
-	GDPR          AccountGDPR `mapstructure:"gdpr" json:"gdpr"`
-	DebugAllow    bool        `mapstructure:"debug_allow" json:"debug_allow"`
+	ID            string             `mapstructure:"id" json:"id"`
+	Disabled      bool               `mapstructure:"disabled" json:"disabled"`
+	CacheTTL      DefaultTTLs        `mapstructure:"cache_ttl" json:"cache_ttl"`
+	EventsEnabled bool               `mapstructure:"events_enabled" json:"events_enabled"`
+	CCPA          AccountCCPA        `mapstructure:"ccpa" json:"ccpa"`
+	GDPR          AccountGDPR        `mapstructure:"gdpr" json:"gdpr"`
+	DebugAllow    bool               `mapstructure:"debug_allow" json:"debug_allow"`
+	PriceFloors   AccountPriceFloors `mapstructure:"price_floors" json:"price_floors"`
+	AdQuality     AccountAdQuality   `mapstructure:"ad_quality" json:"ad_quality"`
+	Validations   Validations        `mapstructure:"validations" json:"validations"`
+	// BidAdjustmentFactors are the defaults of request.ext.prebid.bidadjustmentfactors. The factors of the
+	// request take precedence.
+	BidAdjustmentFactors *openrtb_ext.ExtBidAdjustmentFactors `mapstructure:"bid_adjustment_factors" json:"bid_adjustment_factors,omitempty"`
+	Auction              AccountAuction                       `mapstructure:"auction" json:"auction"`
+	Activities           AccountActivities                    `mapstructure:"activities" json:"activities"`
+	IPMasks              AccountIPMasks                       `mapstructure:"ip_masks" json:"ip_masks"`
+}
+
+// AccountIPMasks represents how much of the IP address of the device is kept when a privacy policy requires
+// it be anonymized. Hosts set them in account_defaults, and accounts may override them. If more than one
+// policy applies, the mask which keeps the fewest bits wins.
+type AccountIPMasks struct {
+	GDPR IPMask `mapstructure:"gdpr" json:"gdpr"`
+	// CCPA is used for GPP as well
+	CCPA  IPMask `mapstructure:"ccpa" json:"ccpa"`
+	LMT   IPMask `mapstructure:"lmt" json:"lmt"`
+	COPPA IPMask `mapstructure:"coppa" json:"coppa"`
+	// PreciseGeo is used when activity controls deny sending the precise geo of the device
+	PreciseGeo IPMask `mapstructure:"precise_geo" json:"precise_geo"`
+}
+
+func (cfg *AccountIPMasks) validate(errs []error) []error {
+	errs = cfg.GDPR.validate("account_defaults.ip_masks.gdpr", errs)
+	errs = cfg.CCPA.validate("account_defaults.ip_masks.ccpa", errs)
+	errs = cfg.LMT.validate("account_defaults.ip_masks.lmt", errs)
+	errs = cfg.COPPA.validate("account_defaults.ip_masks.coppa", errs)
+	return cfg.PreciseGeo.validate("account_defaults.ip_masks.precise_geo", errs)
+}
+
+func (cfg *AccountIPMasks) anyHash() bool {
+	return cfg.GDPR.Hash || cfg.CCPA.Hash || cfg.LMT.Hash || cfg.COPPA.Hash || cfg.PreciseGeo.Hash
+}
+
+// IPMask keeps the leading bits of IP addresses, and removes the rest. A prefix length of 0 uses the
+// default of the policy.
+type IPMask struct {
+	IPv4PrefixLength int `mapstructure:"ipv4_prefix_length" json:"ipv4_prefix_length,omitempty"`
+	IPv6PrefixLength int `mapstructure:"ipv6_prefix_length" json:"ipv6_prefix_length,omitempty"`
+	// Hash replaces the removed bits with bits of an HMAC of the whole address, keyed with the host's
+	// ip_hash_secret, rather than zeroes. The address stays the same for the device, and can't be worked out
+	// without the secret. Without an ip_hash_secret, the bits are zeroed.
+	Hash bool `mapstructure:"hash" json:"hash"`
+}
+
+func (cfg *IPMask) validate(name string, errs []error) []error {
+	if cfg.IPv4PrefixLength < 0 || cfg.IPv4PrefixLength > 32 {
+		errs = append(errs, fmt.Errorf("%s.ipv4_prefix_length must be in the range [0, 32]. Got %d", name, cfg.IPv4PrefixLength))
+	}
+	if cfg.IPv6PrefixLength < 0 || cfg.IPv6PrefixLength > 128 {
+		errs = append(errs, fmt.Errorf("%s.ipv6_prefix_length must be in the range [0, 128]. Got %d", name, cfg.IPv6PrefixLength))
+	}
+	return errs
+}
+
+// AccountActivities represents the activity controls, which allow or deny privacy sensitive activities per
+// bidder. Hosts set them in account_defaults, and accounts may override them. The rules of an account
+// replace the host's rules for the same activity.
+type AccountActivities struct {
+	// SyncUser controls user syncs in /cookie_sync and /setuid
+	SyncUser Activity `mapstructure:"sync_user" json:"sync_user"`
+	// TransmitUFPD controls sending the user's first party data to bidders
+	TransmitUFPD Activity `mapstructure:"transmit_ufpd" json:"transmit_ufpd"`
+	// TransmitPreciseGeo controls sending the precise geo and full IP of the device to bidders
+	TransmitPreciseGeo Activity `mapstructure:"transmit_precise_geo" json:"transmit_precise_geo"`
+	// TransmitEIDs controls sending the user's extended IDs to bidders
+	TransmitEIDs Activity `mapstructure:"transmit_eids" json:"transmit_eids"`
+}
+
+func (cfg *AccountActivities) validate(errs []error) []error {
+	errs = cfg.SyncUser.validate("account_defaults.activities.sync_user", errs)
+	errs = cfg.TransmitUFPD.validate("account_defaults.activities.transmit_ufpd", errs)
+	errs = cfg.TransmitPreciseGeo.validate("account_defaults.activities.transmit_precise_geo", errs)
+	return cfg.TransmitEIDs.validate("account_defaults.activities.transmit_eids", errs)
+}
+
+// Activity represents the rules of an activity. The first rule whose condition matches decides whether the
+// activity is allowed. If none does, Default decides, and an unset default allows it.
+type Activity struct {
+	Default *bool          `mapstructure:"default" json:"default,omitempty"`
+	Rules   []ActivityRule `mapstructure:"rules" json:"rules,omitempty"`
+}
+
+func (cfg *Activity) validate(name string, errs []error) []error {
+	for i, rule := range cfg.Rules {
+		for _, country := range rule.Condition.Countries {
+			if len(country) != 3 {
+				errs = append(errs, fmt.Errorf("%s.rules[%d].condition.countries must contain ISO 3166-1 alpha-3 codes. Got %s", name, i, country))
+			}
+		}
+	}
+	return errs
+}
+
+// ActivityRule allows or denies an activity when its condition matches.
+type ActivityRule struct {
+	Allow     bool              `mapstructure:"allow" json:"allow"`
+	Condition ActivityCondition `mapstructure:"condition" json:"condition"`
+}
+
+// ActivityCondition matches when all of its parts match. The parts which aren't set match everything.
+type ActivityCondition struct {
+	// Bidders are the bidders the rule applies to
+	Bidders []string `mapstructure:"bidders" json:"bidders,omitempty"`
+	// GDPR matches whether GDPR applies to the request
+	GDPR *bool `mapstructure:"gdpr" json:"gdpr,omitempty"`
+	// Countries are the ISO 3166-1 alpha-3 codes of the countries the request may come from
+	Countries []string `mapstructure:"countries" json:"countries,omitempty"`
+}
+
+// AuctionType enumerates the ways the price of the winning bid is set
+type AuctionType string
+
+// Possible values of auction types
+const (
+	// AuctionFirstPrice prices the winning bid at its own price.
+	AuctionFirstPrice AuctionType = "first_price"
+	// AuctionSecondPrice prices the winning bid just above the runner-up, and no lower than the floor.
+	AuctionSecondPrice AuctionType = "second_price"
+)
+
+// AccountAuction represents account-specific auction settings
+type AccountAuction struct {
+	// Type is first_price or second_price. An empty type is the same as first_price.
+	//
+	// In a second-price auction, the targeting price of each imp's winner is the price of the runner-up plus
+	// the increment, or the floor of the imp if the winner is alone. The floor is a soft floor: it's also the
+	// lowest clearing price when there is a runner-up. Winners never clear above their own price, and deals
+	// always clear at their own price.
+	Type AuctionType `mapstructure:"type" json:"type,omitempty"`
+	// PriceIncrement is added to the price of the runner-up to make the clearing price.
+	PriceIncrement float64 `mapstructure:"price_increment" json:"price_increment,omitempty"`
+}
+
+func (cfg *AccountAuction) validate(errs []error) []error {
+	switch cfg.Type {
+	case "", AuctionFirstPrice, AuctionSecondPrice:
+	default:
+		errs = append(errs, fmt.Errorf("account_defaults.auction.type must be first_price or second_price. Got %s", cfg.Type))
+	}
+	if cfg.PriceIncrement < 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.auction.price_increment must be >= 0. Got %f", cfg.PriceIncrement))
+	}
+	return errs
+}
+
+// ValidationMode tells what happens to bids which fail a validation
+type ValidationMode string
+
+// Possible values of validation modes
+const (
+	// ValidationSkip doesn't run the validation.
+	ValidationSkip ValidationMode = "skip"
+	// ValidationWarn keeps the bid, with a warning in the response.
+	ValidationWarn ValidationMode = "warn"
+	// ValidationEnforce rejects the bid.
+	ValidationEnforce ValidationMode = "enforce"
+)
+
+// Validations represents the checks run on the creatives of the bids. Hosts set them in account_defaults,
+// and accounts may override them. An empty mode is the same as skip.
+type Validations struct {
+	// BannerCreativeSize checks that the w and h of banner bids match one of the sizes of the imp
+	BannerCreativeSize ValidationMode `mapstructure:"banner_creative_size" json:"banner_creative_size,omitempty"`
+	// SecureMarkup checks that bids on secure imps don't load anything over http
+	SecureMarkup ValidationMode `mapstructure:"secure_markup" json:"secure_markup,omitempty"`
+}
+
+func (cfg *Validations) validate(errs []error) []error {
+	errs = validateValidationMode("account_defaults.validations.banner_creative_size", cfg.BannerCreativeSize, errs)
+	errs = validateValidationMode("account_defaults.validations.secure_markup", cfg.SecureMarkup, errs)
+	return errs
+}
+
+func validateValidationMode(name string, mode ValidationMode, errs []error) []error {
+	switch mode {
+	case "", ValidationSkip, ValidationWarn, ValidationEnforce:
+		return errs
+	}
+	return append(errs, fmt.Errorf("%s must be one of skip, warn or enforce. Got %s", name, mode))
+}
+
+// AccountAdQuality represents account-specific blocklists. They're added to the blocklists of every request
+// of the account, and bids which violate them are rejected.
+type AccountAdQuality struct {
+	// BlockedAdvertisers are added to request.badv
+	BlockedAdvertisers []string `mapstructure:"badv" json:"badv,omitempty"`
+	// BlockedCategories are added to request.bcat
+	BlockedCategories []string `mapstructure:"bcat" json:"bcat,omitempty"`
+	// BlockedAttributes are added to request.imp[i].banner.battr
+	BlockedAttributes []int `mapstructure:"battr" json:"battr,omitempty"`
+}
+
+// AccountPriceFloors represents account-specific price floor configuration
+type AccountPriceFloors struct {
+	// Enabled turns on price floor resolution and enforcement for the account.
+	// Requests may still opt out by setting ext.prebid.floors.enabled to false.
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// EnforceDealFloors subjects deal bids to floor enforcement even if the request doesn't ask for it.
+	EnforceDealFloors bool `mapstructure:"enforce_deal_floors" json:"enforce_deal_floors"`
 }
 
 // AccountCCPA represents account-specific CCPA configuration
 type AccountCCPA struct {
 	Enabled            *bool              `mapstructure:"enabled" json:"enabled,omitempty"`
 	IntegrationEnabled AccountIntegration `mapstructure:"integration_enabled" json:"integration_enabled"`
+	// GPC makes the Global Privacy Control signal (the Sec-GPC header or request.regs.ext.gpc) opt users out
+	// of the sale of their data, as if the US Privacy string did.
+	GPC bool `mapstructure:"gpc" json:"gpc"`
 }
 
 // EnabledForIntegrationType indicates whether CCPA is turned on at the account level for the specified integration type
@@ -41,6 +252,47 @@ func (a *AccountCCPA) EnabledForIntegrationType(integrationType IntegrationType)
 type AccountGDPR struct {
 	Enabled            *bool              `mapstructure:"enabled" json:"enabled,omitempty"`
 	IntegrationEnabled AccountIntegration `mapstructure:"integration_enabled" json:"integration_enabled"`
+	// The TCF2 purposes override the host's enforcement of them in gdpr.tcf2.
+	Purpose1            AccountGDPRPurpose             `mapstructure:"purpose1" json:"purpose1"`
+	Purpose2            AccountGDPRPurpose             `mapstructure:"purpose2" json:"purpose2"`
+	Purpose3            AccountGDPRPurpose             `mapstructure:"purpose3" json:"purpose3"`
+	Purpose4            AccountGDPRPurpose             `mapstructure:"purpose4" json:"purpose4"`
+	Purpose5            AccountGDPRPurpose             `mapstructure:"purpose5" json:"purpose5"`
+	Purpose6            AccountGDPRPurpose             `mapstructure:"purpose6" json:"purpose6"`
+	Purpose7            AccountGDPRPurpose             `mapstructure:"purpose7" json:"purpose7"`
+	Purpose8            AccountGDPRPurpose             `mapstructure:"purpose8" json:"purpose8"`
+	Purpose9            AccountGDPRPurpose             `mapstructure:"purpose9" json:"purpose9"`
+	Purpose10           AccountGDPRPurpose             `mapstructure:"purpose10" json:"purpose10"`
+	SpecialPurpose1     AccountGDPRPurpose             `mapstructure:"special_purpose1" json:"special_purpose1"`
+	PurposeOneTreatment AccountGDPRPurposeOneTreatment `mapstructure:"purpose_one_treatement" json:"purpose_one_treatement"`
+}
+
+func (a *AccountGDPR) purposes() []AccountGDPRPurpose {
+	return []AccountGDPRPurpose{
+		a.Purpose1, a.Purpose2, a.Purpose3, a.Purpose4, a.Purpose5,
+		a.Purpose6, a.Purpose7, a.Purpose8, a.Purpose9, a.Purpose10,
+	}
+}
+
+func (a *AccountGDPR) validate(errs []error) []error {
+	for i, purpose := range a.purposes() {
+		errs = validateTCF2EnforcementType(fmt.Sprintf("account_defaults.gdpr.purpose%d.enforcement_type", i+1), purpose.EnforcementType, errs)
+	}
+	return validateTCF2EnforcementType("account_defaults.gdpr.special_purpose1.enforcement_type", a.SpecialPurpose1.EnforcementType, errs)
+}
+
+// AccountGDPRPurpose overrides the host's enforcement of a TCF2 purpose for an account. The fields which
+// aren't set keep the host's setting, and an empty list of vendor exceptions removes the host's.
+type AccountGDPRPurpose struct {
+	Enabled          *bool                    `mapstructure:"enabled" json:"enabled,omitempty"`
+	EnforcementType  TCF2EnforcementType      `mapstructure:"enforcement_type" json:"enforcement_type,omitempty"`
+	VendorExceptions []openrtb_ext.BidderName `mapstructure:"vendor_exceptions" json:"vendor_exceptions,omitempty"`
+}
+
+// AccountGDPRPurposeOneTreatment overrides the host's purpose one treatment for an account.
+type AccountGDPRPurposeOneTreatment struct {
+	Enabled       *bool `mapstructure:"enabled" json:"enabled,omitempty"`
+	AccessAllowed *bool `mapstructure:"access_allowed" json:"access_allowed,omitempty"`
 }
 
 // EnabledForIntegrationType indicates whether GDPR is turned on at the account level for the specified integration type
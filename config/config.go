@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -26,20 +27,23 @@ type Configuration struct {
 	EnableGzip  bool       `mapstructure:"enable_gzip"`
 	// StatusResponse is the string which will be returned by the /status endpoint when things are OK.
 	// If empty, it will return a 204 with no content.
-	StatusResponse    string          `mapstructure:"status_response"`
-	AuctionTimeouts   AuctionTimeouts `mapstructure:"auction_timeouts_ms"`
-	CacheURL          Cache           `mapstructure:"cache"`
-	ExtCacheURL       ExternalCache   `mapstructure:"external_cache"`
-	RecaptchaSecret   string          `mapstructure:"recaptcha_secret"`
-	HostCookie        HostCookie      `mapstructure:"host_cookie"`
-	Metrics           Metrics         `mapstructure:"metrics"`
-	DataCache         DataCache       `mapstructure:"datacache"`
-	StoredRequests    StoredRequests  `mapstructure:"stored_requests"`
-	StoredRequestsAMP StoredRequests  `mapstructure:"stored_amp_req"`
-	CategoryMapping   StoredRequests  `mapstructure:"category_mapping"`
-	VTrack            VTrack          `mapstructure:"vtrack"`
-	Event             Event           `mapstructure:"event"`
-	Accounts          StoredRequests  `mapstructure:"accounts"`
+	StatusResponse  string          `mapstructure:"status_response"`
+	AuctionTimeouts AuctionTimeouts `mapstructure:"auction_timeouts_ms"`
+	CacheURL        Cache           `mapstructure:"cache"`
+	ExtCacheURL     ExternalCache   `mapstructure:"external_cache"`
+	RecaptchaSecret string          `mapstructure:"recaptcha_secret"`
+	// IPHashSecret keys the HMAC of the IP masks which hash. It must be kept secret, or the IP addresses
+	// they hash could be worked out again.
+	IPHashSecret      string         `mapstructure:"ip_hash_secret"`
+	HostCookie        HostCookie     `mapstructure:"host_cookie"`
+	Metrics           Metrics        `mapstructure:"metrics"`
+	DataCache         DataCache      `mapstructure:"datacache"`
+	StoredRequests    StoredRequests `mapstructure:"stored_requests"`
+	StoredRequestsAMP StoredRequests `mapstructure:"stored_amp_req"`
+	CategoryMapping   StoredRequests `mapstructure:"category_mapping"`
+	VTrack            VTrack         `mapstructure:"vtrack"`
+	Event             Event          `mapstructure:"event"`
+	Accounts          StoredRequests `mapstructure:"accounts"`
 	// Note that StoredVideo refers to stored video requests, and has nothing to do with caching video creatives.
 	StoredVideo StoredRequests `mapstructure:"stored_video_req"`
 
@@ -80,6 +84,18 @@ type Configuration struct {
 	RequestValidation RequestValidation `mapstructure:"request_validation"`
 	// When true, PBS will assign a randomly generated UUID to req.Source.TID if it is empty
 	AutoGenSourceTID bool `mapstructure:"auto_gen_source_tid"`
+	// Hooks configures the modules which run at the stages of an auction.
+	Hooks Hooks `mapstructure:"hooks"`
+	// BidderCircuitBreaker stops calling the bidders whose servers keep failing.
+	BidderCircuitBreaker CircuitBreaker `mapstructure:"bidder_circuit_breaker"`
+	// AdaptiveBidderTimeouts gives each bidder its own deadline, based on how long it usually takes to answer.
+	AdaptiveBidderTimeouts AdaptiveBidderTimeouts `mapstructure:"adaptive_bidder_timeouts"`
+	// GeoLocation looks up where devices are from their IP addresses.
+	GeoLocation GeoLocation `mapstructure:"geolocation"`
+	// DeviceDetection works out the device fields a request is missing from its user agent.
+	DeviceDetection DeviceDetection `mapstructure:"device_detection"`
+	// InvalidTraffic keeps crawlers, bots and other invalid traffic out of the auctions.
+	InvalidTraffic InvalidTraffic `mapstructure:"invalid_traffic"`
 }
 
 const MIN_COOKIE_SIZE_BYTES = 500
@@ -108,6 +124,20 @@ func (cfg *Configuration) validate() []error {
 	errs = validateAdapters(cfg.Adapters, errs)
 	errs = cfg.Debug.validate(errs)
 	errs = cfg.ExtCacheURL.validate(errs)
+	errs = cfg.Hooks.validate(errs)
+	errs = cfg.BidderCircuitBreaker.validate(errs)
+	errs = cfg.AdaptiveBidderTimeouts.validate(errs)
+	errs = cfg.GeoLocation.validate(errs)
+	errs = cfg.DeviceDetection.validate(errs)
+	errs = cfg.InvalidTraffic.validate(errs)
+	errs = cfg.AccountDefaults.Validations.validate(errs)
+	errs = cfg.AccountDefaults.Auction.validate(errs)
+	errs = cfg.AccountDefaults.GDPR.validate(errs)
+	errs = cfg.AccountDefaults.Activities.validate(errs)
+	errs = cfg.AccountDefaults.IPMasks.validate(errs)
+	if cfg.AccountDefaults.IPMasks.anyHash() && cfg.IPHashSecret == "" {
+		errs = append(errs, errors.New("ip_hash_secret must be set to hash IP addresses in account_defaults.ip_masks"))
+	}
 	if cfg.AccountDefaults.Disabled {
 		glog.Warning(`With account_defaults.disabled=true, host-defined accounts must exist and have "disabled":false. All other requests will be rejected.`)
 	}
@@ -186,6 +216,8 @@ type Privacy struct {
 	CCPA CCPA
 	GDPR GDPR
 	LMT  LMT
+	// IPHashSecret is the host's ip_hash_secret
+	IPHashSecret string
 }
 
 type GDPR struct {
@@ -206,6 +238,25 @@ type GDPR struct {
 	EEACountriesMap map[string]struct{}
 }
 
+// UsersyncIfAmbiguousInCountry tells whether user syncs are allowed for a request which doesn't say whether GDPR
+// applies, given the ISO-3166-1 alpha-3 code of the country the user is in. GDPR is assumed to apply in the EEA
+// countries and nowhere else. If the country isn't known, UsersyncIfAmbiguous decides.
+func (cfg *GDPR) UsersyncIfAmbiguousInCountry(country string) bool {
+	if cfg.IsEEACountry(country) {
+		return false
+	} else if len(country) == 3 {
+		// The country field is formatted properly as a three character country code
+		return true
+	}
+	return cfg.UsersyncIfAmbiguous
+}
+
+// IsEEACountry tells whether the country, given its ISO-3166-1 alpha-3 code, is one of the EEA countries.
+func (cfg *GDPR) IsEEACountry(country string) bool {
+	_, found := cfg.EEACountriesMap[strings.ToUpper(country)]
+	return found
+}
+
 func (cfg *GDPR) validate(errs []error) []error {
 	if cfg.HostVendorID < 0 || cfg.HostVendorID > 0xffff {
 		errs = append(errs, fmt.Errorf("gdpr.host_vendor_id must be in the range [0, %d]. Got %d", 0xffff, cfg.HostVendorID))
@@ -219,6 +270,7 @@ func (cfg *GDPR) validate(errs []error) []error {
 	if cfg.TCF1.FetchGVL == true {
 		errs = append(errs, fmt.Errorf("gdpr.tcf1.fetch_gvl has been discontinued and must be removed from your config. TCF1 will always use the fallback GVL going forward"))
 	}
+	errs = cfg.TCF2.validate(errs)
 	return errs
 }
 
@@ -243,17 +295,138 @@ type TCF1 struct {
 
 // TCF2 defines the TCF2 specific configurations for GDPR
 type TCF2 struct {
-	Enabled             bool                 `mapstructure:"enabled"`
-	Purpose1            PurposeDetail        `mapstructure:"purpose1"`
-	Purpose2            PurposeDetail        `mapstructure:"purpose2"`
-	Purpose7            PurposeDetail        `mapstructure:"purpose7"`
+	Enabled   bool          `mapstructure:"enabled"`
+	Purpose1  PurposeDetail `mapstructure:"purpose1"`
+	Purpose2  PurposeDetail `mapstructure:"purpose2"`
+	Purpose3  PurposeDetail `mapstructure:"purpose3"`
+	Purpose4  PurposeDetail `mapstructure:"purpose4"`
+	Purpose5  PurposeDetail `mapstructure:"purpose5"`
+	Purpose6  PurposeDetail `mapstructure:"purpose6"`
+	Purpose7  PurposeDetail `mapstructure:"purpose7"`
+	Purpose8  PurposeDetail `mapstructure:"purpose8"`
+	Purpose9  PurposeDetail `mapstructure:"purpose9"`
+	Purpose10 PurposeDetail `mapstructure:"purpose10"`
+	// SpecialPurpose1 is special feature 1, the use of precise geolocation data.
 	SpecialPurpose1     PurposeDetail        `mapstructure:"special_purpose1"`
 	PurposeOneTreatment PurposeOneTreatement `mapstructure:"purpose_one_treatement"`
+	// FallbackGVLPath is an optional vendor list to use for any version which can't be fetched or loaded from the
+	// cache dir. It should be a vendor list the IAB published. No fallback is used if it's empty, the default.
+	FallbackGVLPath string `mapstructure:"fallback_gvl_path"`
+	// VendorListCacheDir is where vendor lists are saved once they're fetched, and loaded from at startup.
+	// Vendor lists are only kept in memory if it's empty.
+	VendorListCacheDir string `mapstructure:"vendorlist_cache_dir"`
+}
+
+// Purpose returns the config of a purpose, numbered from 1 to 10. It returns nil for any other number.
+func (t *TCF2) Purpose(purpose int) *PurposeDetail {
+	purposes := t.purposes()
+	if purpose < 1 || purpose > len(purposes) {
+		return nil
+	}
+	return purposes[purpose-1]
+}
+
+func (t *TCF2) purposes() []*PurposeDetail {
+	return []*PurposeDetail{
+		&t.Purpose1, &t.Purpose2, &t.Purpose3, &t.Purpose4, &t.Purpose5,
+		&t.Purpose6, &t.Purpose7, &t.Purpose8, &t.Purpose9, &t.Purpose10,
+	}
+}
+
+// WithAccountOverrides returns a copy of the config with the settings the account overrides.
+func (t TCF2) WithAccountOverrides(account AccountGDPR) TCF2 {
+	accountPurposes := account.purposes()
+	for i, purpose := range t.purposes() {
+		*purpose = purpose.withAccountOverrides(accountPurposes[i])
+	}
+	t.SpecialPurpose1 = t.SpecialPurpose1.withAccountOverrides(account.SpecialPurpose1)
+	if account.PurposeOneTreatment.Enabled != nil {
+		t.PurposeOneTreatment.Enabled = *account.PurposeOneTreatment.Enabled
+	}
+	if account.PurposeOneTreatment.AccessAllowed != nil {
+		t.PurposeOneTreatment.AccessAllowed = *account.PurposeOneTreatment.AccessAllowed
+	}
+	return t
+}
+
+func (t *TCF2) validate(errs []error) []error {
+	for i, purpose := range t.purposes() {
+		errs = validateTCF2EnforcementType(fmt.Sprintf("gdpr.tcf2.purpose%d.enforcement_type", i+1), purpose.EnforcementType, errs)
+	}
+	return validateTCF2EnforcementType("gdpr.tcf2.special_purpose1.enforcement_type", t.SpecialPurpose1.EnforcementType, errs)
+}
+
+func (t *TCF2) buildVendorExceptionMaps() {
+	for _, purpose := range append(t.purposes(), &t.SpecialPurpose1) {
+		purpose.buildVendorExceptionMap()
+	}
+}
+
+// TCF2EnforcementType tells how strictly a TCF2 purpose is enforced
+type TCF2EnforcementType string
+
+const (
+	// TCF2FullEnforcement checks that the consent string allows the purpose, and that the vendor declares
+	// the purpose in the GVL and has the user's consent or legitimate interest for it.
+	TCF2FullEnforcement TCF2EnforcementType = "full"
+	// TCF2BasicEnforcement only checks that the consent string allows the purpose, whatever the GVL says
+	// about the vendor.
+	TCF2BasicEnforcement TCF2EnforcementType = "basic"
+	// TCF2NoEnforcement doesn't check the purpose.
+	TCF2NoEnforcement TCF2EnforcementType = "none"
+)
+
+func validateTCF2EnforcementType(name string, enforcementType TCF2EnforcementType, errs []error) []error {
+	switch enforcementType {
+	case "", TCF2FullEnforcement, TCF2BasicEnforcement, TCF2NoEnforcement:
+		return errs
+	}
+	return append(errs, fmt.Errorf("%s must be one of full, basic or none. Got %s", name, enforcementType))
 }
 
-// Making a purpose struct so purpose specific details can be added later.
+// PurposeDetail configures the enforcement of a TCF2 purpose.
 type PurposeDetail struct {
 	Enabled bool `mapstructure:"enabled"`
+	// EnforcementType is how strictly the purpose is enforced when it's enabled. An empty type is the same as full.
+	EnforcementType TCF2EnforcementType `mapstructure:"enforcement_type"`
+	// VendorExceptions are the bidders the purpose isn't enforced for.
+	VendorExceptions   []openrtb_ext.BidderName `mapstructure:"vendor_exceptions"`
+	VendorExceptionMap map[openrtb_ext.BidderName]struct{}
+}
+
+// Enforcement returns how strictly the purpose is enforced for the bidder.
+func (p *PurposeDetail) Enforcement(bidder openrtb_ext.BidderName) TCF2EnforcementType {
+	if !p.Enabled || p.EnforcementType == TCF2NoEnforcement {
+		return TCF2NoEnforcement
+	}
+	if _, isException := p.VendorExceptionMap[bidder]; isException {
+		return TCF2NoEnforcement
+	}
+	if p.EnforcementType == TCF2BasicEnforcement {
+		return TCF2BasicEnforcement
+	}
+	return TCF2FullEnforcement
+}
+
+func (p PurposeDetail) withAccountOverrides(account AccountGDPRPurpose) PurposeDetail {
+	if account.Enabled != nil {
+		p.Enabled = *account.Enabled
+	}
+	if account.EnforcementType != "" {
+		p.EnforcementType = account.EnforcementType
+	}
+	if account.VendorExceptions != nil {
+		p.VendorExceptions = account.VendorExceptions
+		p.buildVendorExceptionMap()
+	}
+	return p
+}
+
+func (p *PurposeDetail) buildVendorExceptionMap() {
+	p.VendorExceptionMap = make(map[openrtb_ext.BidderName]struct{}, len(p.VendorExceptions))
+	for _, bidder := range p.VendorExceptions {
+		p.VendorExceptionMap[bidder] = struct{}{}
+	}
 }
 
 type PurposeOneTreatement struct {
@@ -278,15 +451,229 @@ type CurrencyConverter struct {
 	FetchURL             string `mapstructure:"fetch_url"`
 	FetchIntervalSeconds int    `mapstructure:"fetch_interval_seconds"`
 	StaleRatesSeconds    int    `mapstructure:"stale_rates_seconds"`
+	// Sources are where the rates are fetched from, in order of preference. The rates of the first source
+	// which has rates that aren't stale are used. If no Sources are configured, the rates are fetched from
+	// FetchURL. Otherwise FetchURL and StaleRatesSeconds are ignored, so FetchURL must be listed as a "json"
+	// source to be used as a fallback.
+	Sources []CurrencyRateSource `mapstructure:"sources"`
 }
 
 func (cfg *CurrencyConverter) validate(errs []error) []error {
 	if cfg.FetchIntervalSeconds < 0 {
 		errs = append(errs, fmt.Errorf("currency_converter.fetch_interval_seconds must be in the range [0, %d]. Got %d", 0xffff, cfg.FetchIntervalSeconds))
 	}
+	for i := range cfg.Sources {
+		errs = cfg.Sources[i].validate(errs, i)
+	}
 	return errs
 }
 
+// CurrencyRateSourceType is the format of a source of currency rates.
+type CurrencyRateSourceType string
+
+const (
+	// CurrencyRateSourceJSON is the format of https://cdn.jsdelivr.net/gh/prebid/currency-file@1/latest.json
+	CurrencyRateSourceJSON CurrencyRateSourceType = "json"
+	// CurrencyRateSourceECB is the XML format of the daily reference rates of the European Central Bank.
+	CurrencyRateSourceECB CurrencyRateSourceType = "ecb_xml"
+	// CurrencyRateSourceFile is a local file in the same format as CurrencyRateSourceJSON. It's read again on every fetch.
+	CurrencyRateSourceFile CurrencyRateSourceType = "file"
+)
+
+type CurrencyRateSource struct {
+	Type CurrencyRateSourceType `mapstructure:"type"`
+	// URL is where the json and ecb_xml sources are fetched from.
+	URL string `mapstructure:"url"`
+	// Path is the file of the file sources.
+	Path string `mapstructure:"path"`
+	// StaleRatesSeconds is how long the rates of the source are used after it fails. 0 means forever.
+	StaleRatesSeconds int `mapstructure:"stale_rates_seconds"`
+}
+
+func (cfg *CurrencyRateSource) validate(errs []error, index int) []error {
+	switch cfg.Type {
+	case CurrencyRateSourceJSON, CurrencyRateSourceECB:
+		if cfg.URL == "" {
+			errs = append(errs, fmt.Errorf("currency_converter.sources[%d].url is required for %s sources", index, cfg.Type))
+		}
+	case CurrencyRateSourceFile:
+		if cfg.Path == "" {
+			errs = append(errs, fmt.Errorf("currency_converter.sources[%d].path is required for file sources", index))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("currency_converter.sources[%d].type must be one of [%s, %s, %s]. Got %s", index, CurrencyRateSourceJSON, CurrencyRateSourceECB, CurrencyRateSourceFile, cfg.Type))
+	}
+	if cfg.StaleRatesSeconds < 0 {
+		errs = append(errs, fmt.Errorf("currency_converter.sources[%d].stale_rates_seconds must be >= 0. Got %d", index, cfg.StaleRatesSeconds))
+	}
+	return errs
+}
+
+// CircuitBreaker configures when the calls to a bidder are stopped, and when they're tried again.
+type CircuitBreaker struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ErrorThreshold is the number of failed calls in a row which opens the circuit.
+	// Timeouts, connection errors and 5xx responses are failed calls.
+	ErrorThreshold int `mapstructure:"error_threshold"`
+	// OpenIntervalMillis is how long the circuit stays open before it lets probe calls through.
+	OpenIntervalMillis int `mapstructure:"open_interval_ms"`
+	// HalfOpenProbes is the number of probe calls in a row which must succeed to close the circuit again.
+	HalfOpenProbes int `mapstructure:"half_open_probes"`
+	// PerHost keeps a circuit for each host the bidder calls, rather than one for the whole bidder.
+	PerHost bool `mapstructure:"per_host"`
+}
+
+func (cfg *CircuitBreaker) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.ErrorThreshold <= 0 {
+		errs = append(errs, fmt.Errorf("bidder_circuit_breaker.error_threshold must be positive. Got %d", cfg.ErrorThreshold))
+	}
+	if cfg.OpenIntervalMillis <= 0 {
+		errs = append(errs, fmt.Errorf("bidder_circuit_breaker.open_interval_ms must be positive. Got %d", cfg.OpenIntervalMillis))
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		errs = append(errs, fmt.Errorf("bidder_circuit_breaker.half_open_probes must be positive. Got %d", cfg.HalfOpenProbes))
+	}
+	return errs
+}
+
+// AdaptiveBidderTimeouts configures the deadlines given to each bidder.
+//
+// A bidder's deadline is the chosen percentile of its latest response times, but never more than what's
+// left of the auction.
+type AdaptiveBidderTimeouts struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Percentile of the response times which the deadline is based on, such as 95.
+	Percentile float64 `mapstructure:"percentile"`
+	// SampleSize is the number of latest response times kept for each bidder.
+	SampleSize int `mapstructure:"sample_size"`
+	// MinSamples is the number of response times needed before a bidder gets its own deadline.
+	MinSamples int `mapstructure:"min_samples"`
+	// MinTimeoutMillis is the shortest deadline a bidder may be given.
+	MinTimeoutMillis int `mapstructure:"min_timeout_ms"`
+}
+
+func (cfg *AdaptiveBidderTimeouts) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Percentile <= 0 || cfg.Percentile > 100 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.percentile must be in the range (0, 100]. Got %g", cfg.Percentile))
+	}
+	if cfg.SampleSize <= 0 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.sample_size must be positive. Got %d", cfg.SampleSize))
+	}
+	if cfg.MinSamples <= 0 || cfg.MinSamples > cfg.SampleSize {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.min_samples must be in the range [1, sample_size]. Got %d", cfg.MinSamples))
+	}
+	if cfg.MinTimeoutMillis < 0 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.min_timeout_ms must be >= 0. Got %d", cfg.MinTimeoutMillis))
+	}
+	return errs
+}
+
+// GeoLocation configures the lookup of where a device is from its IP address. The location fills in
+// the parts of device.geo which the request is missing, and decides whether GDPR applies when the
+// request doesn't say.
+type GeoLocation struct {
+	Enabled bool `mapstructure:"enabled"`
+	// DatabasePath is a database in the MaxMind DB format, such as GeoLite2-City.mmdb.
+	DatabasePath string `mapstructure:"database_path"`
+	// RefreshIntervalSeconds is how often the database file is checked for changes, and loaded again if it has any.
+	RefreshIntervalSeconds int `mapstructure:"refresh_interval_seconds"`
+	// LatLonPrecision is the number of decimals the latitude and longitude are rounded to.
+	LatLonPrecision int `mapstructure:"lat_lon_precision"`
+}
+
+func (cfg *GeoLocation) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.DatabasePath == "" {
+		errs = append(errs, errors.New("geolocation.database_path is required when geolocation is enabled"))
+	}
+	if cfg.RefreshIntervalSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("geolocation.refresh_interval_seconds must be positive. Got %d", cfg.RefreshIntervalSeconds))
+	}
+	if cfg.LatLonPrecision < 0 || cfg.LatLonPrecision > 6 {
+		errs = append(errs, fmt.Errorf("geolocation.lat_lon_precision must be in the range [0, 6]. Got %d", cfg.LatLonPrecision))
+	}
+	return errs
+}
+
+// DeviceDetection configures how the os, osv, make, model and devicetype of a device are worked out from its
+// user agent. They only fill in the fields the request is missing.
+type DeviceDetection struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RulesFile is the rule set of the built-in detector.
+	RulesFile string `mapstructure:"rules_file"`
+}
+
+func (cfg *DeviceDetection) validate(errs []error) []error {
+	if cfg.Enabled && cfg.RulesFile == "" {
+		errs = append(errs, errors.New("device_detection.rules_file is required when device detection is enabled"))
+	}
+	return errs
+}
+
+// InvalidTrafficAction tells what happens to requests which fail an invalid traffic check
+type InvalidTrafficAction string
+
+const (
+	// InvalidTrafficSkip doesn't run the check.
+	InvalidTrafficSkip InvalidTrafficAction = "skip"
+	// InvalidTrafficFlag marks the request as invalid traffic, and doesn't call the bidders in flagged_skip_bidders.
+	InvalidTrafficFlag InvalidTrafficAction = "flag"
+	// InvalidTrafficReject rejects the request.
+	InvalidTrafficReject InvalidTrafficAction = "reject"
+)
+
+// InvalidTraffic configures the checks which the /openrtb2/auction, /openrtb2/amp and /openrtb2/video
+// endpoints run before the auction. An empty action is the same as skip.
+type InvalidTraffic struct {
+	Enabled bool `mapstructure:"enabled"`
+	// BotUserAgentAction is taken on requests whose device.ua matches one of BotUserAgentPatterns.
+	BotUserAgentAction InvalidTrafficAction `mapstructure:"bot_user_agent_action"`
+	// BotUserAgentPatterns are the case insensitive regular expressions of the user agents of known crawlers and bots.
+	BotUserAgentPatterns []string `mapstructure:"bot_user_agent_patterns"`
+	// DataCenterIPAction is taken on requests whose device IP address is in one of the networks of DataCenterCIDRFile.
+	DataCenterIPAction InvalidTrafficAction `mapstructure:"datacenter_ip_action"`
+	// DataCenterCIDRFile lists the networks of data centers, one CIDR block per line. Lines starting with # are ignored.
+	DataCenterCIDRFile string `mapstructure:"datacenter_cidr_file"`
+	// InvalidIPAction is taken on requests whose device has no IP address, or one which isn't valid.
+	InvalidIPAction InvalidTrafficAction `mapstructure:"invalid_ip_action"`
+	// FlaggedSkipBidders are the bidders which aren't called for flagged requests.
+	FlaggedSkipBidders []string `mapstructure:"flagged_skip_bidders"`
+}
+
+func (cfg *InvalidTraffic) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	errs = validateInvalidTrafficAction("invalid_traffic.bot_user_agent_action", cfg.BotUserAgentAction, errs)
+	errs = validateInvalidTrafficAction("invalid_traffic.datacenter_ip_action", cfg.DataCenterIPAction, errs)
+	errs = validateInvalidTrafficAction("invalid_traffic.invalid_ip_action", cfg.InvalidIPAction, errs)
+	for i, pattern := range cfg.BotUserAgentPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid_traffic.bot_user_agent_patterns[%d] is not a valid regular expression: %v", i, err))
+		}
+	}
+	if cfg.DataCenterIPAction != "" && cfg.DataCenterIPAction != InvalidTrafficSkip && cfg.DataCenterCIDRFile == "" {
+		errs = append(errs, errors.New("invalid_traffic.datacenter_cidr_file is required when the datacenter_ip check is enabled"))
+	}
+	return errs
+}
+
+func validateInvalidTrafficAction(name string, action InvalidTrafficAction, errs []error) []error {
+	switch action {
+	case "", InvalidTrafficSkip, InvalidTrafficFlag, InvalidTrafficReject:
+		return errs
+	}
+	return append(errs, fmt.Errorf("%s must be one of skip, flag or reject. Got %s", name, action))
+}
+
 // FileLogs Corresponding config for FileLogger as a PBS Analytics Module
 type FileLogs struct {
 	Filename string `mapstructure:"filename"`
@@ -498,10 +885,12 @@ func New(v *viper.Viper) (*Configuration, error) {
 	}
 
 	c.GDPR.EEACountriesMap = make(map[string]struct{})
-	for i := 0; i < len(c.GDPR.EEACountriesMap); i++ {
-		c.GDPR.NonStandardPublisherMap[c.GDPR.EEACountries[i]] = s
+	for i := 0; i < len(c.GDPR.EEACountries); i++ {
+		c.GDPR.EEACountriesMap[c.GDPR.EEACountries[i]] = s
 	}
 
+	c.GDPR.TCF2.buildVendorExceptionMaps()
+
 	// To look for a request's app_id in O(1) time, we fill this hash table located in the
 	// the BlacklistedApps field of the Configuration struct defined in this file
 	c.BlacklistedAppMap = make(map[string]bool)
@@ -686,6 +1075,7 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("external_cache.host", "")
 	v.SetDefault("external_cache.path", "")
 	v.SetDefault("recaptcha_secret", "")
+	v.SetDefault("ip_hash_secret", "")
 	v.SetDefault("host_cookie.domain", "")
 	v.SetDefault("host_cookie.family", "")
 	v.SetDefault("host_cookie.cookie_name", "")
@@ -724,6 +1114,7 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("category_mapping.http.endpoint", "")
 	v.SetDefault("stored_requests.filesystem.enabled", false)
 	v.SetDefault("stored_requests.filesystem.directorypath", "./stored_requests/data/by_id")
+	v.SetDefault("stored_requests.filesystem.refresh_rate_seconds", 0)
 	v.SetDefault("stored_requests.directorypath", "./stored_requests/data/by_id")
 	v.SetDefault("stored_requests.postgres.connection.dbname", "")
 	v.SetDefault("stored_requests.postgres.connection.host", "")
@@ -732,6 +1123,7 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_requests.postgres.connection.password", "")
 	v.SetDefault("stored_requests.postgres.fetcher.query", "")
 	v.SetDefault("stored_requests.postgres.fetcher.amp_query", "")
+	v.SetDefault("stored_requests.postgres.fetcher.responses_query", "")
 	v.SetDefault("stored_requests.postgres.initialize_caches.timeout_ms", 0)
 	v.SetDefault("stored_requests.postgres.initialize_caches.query", "")
 	v.SetDefault("stored_requests.postgres.initialize_caches.amp_query", "")
@@ -739,6 +1131,21 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_requests.postgres.poll_for_updates.timeout_ms", 0)
 	v.SetDefault("stored_requests.postgres.poll_for_updates.query", "")
 	v.SetDefault("stored_requests.postgres.poll_for_updates.amp_query", "")
+	v.SetDefault("stored_requests.mysql.connection.dbname", "")
+	v.SetDefault("stored_requests.mysql.connection.host", "")
+	v.SetDefault("stored_requests.mysql.connection.port", 0)
+	v.SetDefault("stored_requests.mysql.connection.user", "")
+	v.SetDefault("stored_requests.mysql.connection.password", "")
+	v.SetDefault("stored_requests.mysql.fetcher.query", "")
+	v.SetDefault("stored_requests.mysql.fetcher.amp_query", "")
+	v.SetDefault("stored_requests.mysql.fetcher.responses_query", "")
+	v.SetDefault("stored_requests.mysql.initialize_caches.timeout_ms", 0)
+	v.SetDefault("stored_requests.mysql.initialize_caches.query", "")
+	v.SetDefault("stored_requests.mysql.initialize_caches.amp_query", "")
+	v.SetDefault("stored_requests.mysql.poll_for_updates.refresh_rate_seconds", 0)
+	v.SetDefault("stored_requests.mysql.poll_for_updates.timeout_ms", 0)
+	v.SetDefault("stored_requests.mysql.poll_for_updates.query", "")
+	v.SetDefault("stored_requests.mysql.poll_for_updates.amp_query", "")
 	v.SetDefault("stored_requests.http.endpoint", "")
 	v.SetDefault("stored_requests.http.amp_endpoint", "")
 	v.SetDefault("stored_requests.in_memory_cache.type", "none")
@@ -754,6 +1161,7 @@ func SetupViper(v *viper.Viper, filename string) {
 	// PBS is not in the business of storing video content beyond the normal prebid cache system.
 	v.SetDefault("stored_video_req.filesystem.enabled", false)
 	v.SetDefault("stored_video_req.filesystem.directorypath", "")
+	v.SetDefault("stored_video_req.filesystem.refresh_rate_seconds", 0)
 	v.SetDefault("stored_video_req.postgres.connection.dbname", "")
 	v.SetDefault("stored_video_req.postgres.connection.host", "")
 	v.SetDefault("stored_video_req.postgres.connection.port", 0)
@@ -765,6 +1173,17 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.refresh_rate_seconds", 0)
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.timeout_ms", 0)
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.query", "")
+	v.SetDefault("stored_video_req.mysql.connection.dbname", "")
+	v.SetDefault("stored_video_req.mysql.connection.host", "")
+	v.SetDefault("stored_video_req.mysql.connection.port", 0)
+	v.SetDefault("stored_video_req.mysql.connection.user", "")
+	v.SetDefault("stored_video_req.mysql.connection.password", "")
+	v.SetDefault("stored_video_req.mysql.fetcher.query", "")
+	v.SetDefault("stored_video_req.mysql.initialize_caches.timeout_ms", 0)
+	v.SetDefault("stored_video_req.mysql.initialize_caches.query", "")
+	v.SetDefault("stored_video_req.mysql.poll_for_updates.refresh_rate_seconds", 0)
+	v.SetDefault("stored_video_req.mysql.poll_for_updates.timeout_ms", 0)
+	v.SetDefault("stored_video_req.mysql.poll_for_updates.query", "")
 	v.SetDefault("stored_video_req.http.endpoint", "")
 	v.SetDefault("stored_video_req.in_memory_cache.type", "none")
 	v.SetDefault("stored_video_req.in_memory_cache.ttl_seconds", 0)
@@ -784,6 +1203,7 @@ func SetupViper(v *viper.Viper, filename string) {
 
 	v.SetDefault("accounts.filesystem.enabled", false)
 	v.SetDefault("accounts.filesystem.directorypath", "./stored_requests/data/by_id")
+	v.SetDefault("accounts.filesystem.refresh_rate_seconds", 0)
 	v.SetDefault("accounts.in_memory_cache.type", "none")
 
 	for _, bidder := range openrtb_ext.CoreBidderNames() {
@@ -918,12 +1338,42 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("gdpr.tcf1.fallback_gvl_path", "./static/tcf1/fallback_gvl.json")
 	v.SetDefault("gdpr.tcf2.enabled", true)
 	v.SetDefault("gdpr.tcf2.purpose1.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose1.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose1.vendor_exceptions", []openrtb_ext.BidderName{})
 	v.SetDefault("gdpr.tcf2.purpose2.enabled", true)
-	v.SetDefault("gdpr.tcf2.purpose4.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose2.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose2.vendor_exceptions", []openrtb_ext.BidderName{})
+	v.SetDefault("gdpr.tcf2.purpose3.enabled", false)
+	v.SetDefault("gdpr.tcf2.purpose3.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose3.vendor_exceptions", []openrtb_ext.BidderName{})
+	v.SetDefault("gdpr.tcf2.purpose4.enabled", false)
+	v.SetDefault("gdpr.tcf2.purpose4.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose4.vendor_exceptions", []openrtb_ext.BidderName{})
+	v.SetDefault("gdpr.tcf2.purpose5.enabled", false)
+	v.SetDefault("gdpr.tcf2.purpose5.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose5.vendor_exceptions", []openrtb_ext.BidderName{})
+	v.SetDefault("gdpr.tcf2.purpose6.enabled", false)
+	v.SetDefault("gdpr.tcf2.purpose6.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose6.vendor_exceptions", []openrtb_ext.BidderName{})
 	v.SetDefault("gdpr.tcf2.purpose7.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose7.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose7.vendor_exceptions", []openrtb_ext.BidderName{})
+	v.SetDefault("gdpr.tcf2.purpose8.enabled", false)
+	v.SetDefault("gdpr.tcf2.purpose8.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose8.vendor_exceptions", []openrtb_ext.BidderName{})
+	v.SetDefault("gdpr.tcf2.purpose9.enabled", false)
+	v.SetDefault("gdpr.tcf2.purpose9.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose9.vendor_exceptions", []openrtb_ext.BidderName{})
+	v.SetDefault("gdpr.tcf2.purpose10.enabled", false)
+	v.SetDefault("gdpr.tcf2.purpose10.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.purpose10.vendor_exceptions", []openrtb_ext.BidderName{})
 	v.SetDefault("gdpr.tcf2.special_purpose1.enabled", true)
+	v.SetDefault("gdpr.tcf2.special_purpose1.enforcement_type", "full")
+	v.SetDefault("gdpr.tcf2.special_purpose1.vendor_exceptions", []openrtb_ext.BidderName{})
 	v.SetDefault("gdpr.tcf2.purpose_one_treatement.enabled", true)
 	v.SetDefault("gdpr.tcf2.purpose_one_treatement.access_allowed", true)
+	v.SetDefault("gdpr.tcf2.fallback_gvl_path", "")
+	v.SetDefault("gdpr.tcf2.vendorlist_cache_dir", "")
 	v.SetDefault("gdpr.amp_exception", false)
 	v.SetDefault("gdpr.eea_countries", []string{"ALA", "AUT", "BEL", "BGR", "HRV", "CYP", "CZE", "DNK", "EST",
 		"FIN", "FRA", "GUF", "DEU", "GIB", "GRC", "GLP", "GGY", "HUN", "ISL", "IRL", "IMN", "ITA", "JEY", "LVA",
@@ -942,8 +1392,66 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("account_required", false)
 	v.SetDefault("account_defaults.disabled", false)
 	v.SetDefault("account_defaults.debug_allow", true)
+	v.SetDefault("account_defaults.ccpa.gpc", false)
+	v.SetDefault("account_defaults.ip_masks.gdpr.ipv4_prefix_length", 24)
+	v.SetDefault("account_defaults.ip_masks.gdpr.ipv6_prefix_length", 112)
+	v.SetDefault("account_defaults.ip_masks.gdpr.hash", false)
+	v.SetDefault("account_defaults.ip_masks.ccpa.ipv4_prefix_length", 24)
+	v.SetDefault("account_defaults.ip_masks.ccpa.ipv6_prefix_length", 112)
+	v.SetDefault("account_defaults.ip_masks.ccpa.hash", false)
+	v.SetDefault("account_defaults.ip_masks.lmt.ipv4_prefix_length", 24)
+	v.SetDefault("account_defaults.ip_masks.lmt.ipv6_prefix_length", 112)
+	v.SetDefault("account_defaults.ip_masks.lmt.hash", false)
+	v.SetDefault("account_defaults.ip_masks.coppa.ipv4_prefix_length", 24)
+	v.SetDefault("account_defaults.ip_masks.coppa.ipv6_prefix_length", 96)
+	v.SetDefault("account_defaults.ip_masks.coppa.hash", false)
+	v.SetDefault("account_defaults.ip_masks.precise_geo.ipv4_prefix_length", 24)
+	v.SetDefault("account_defaults.ip_masks.precise_geo.ipv6_prefix_length", 112)
+	v.SetDefault("account_defaults.ip_masks.precise_geo.hash", false)
+	v.SetDefault("account_defaults.price_floors.enabled", false)
+	v.SetDefault("account_defaults.price_floors.enforce_deal_floors", false)
+	v.SetDefault("account_defaults.validations.banner_creative_size", "skip")
+	v.SetDefault("account_defaults.validations.secure_markup", "skip")
+	v.SetDefault("account_defaults.auction.type", "first_price")
+	v.SetDefault("account_defaults.auction.price_increment", 0.01)
 	v.SetDefault("certificates_file", "")
 	v.SetDefault("auto_gen_source_tid", true)
+	v.SetDefault("hooks.enabled", false)
+	v.SetDefault("bidder_circuit_breaker.enabled", false)
+	v.SetDefault("bidder_circuit_breaker.error_threshold", 10)
+	v.SetDefault("bidder_circuit_breaker.open_interval_ms", 30000)
+	v.SetDefault("bidder_circuit_breaker.half_open_probes", 1)
+	v.SetDefault("bidder_circuit_breaker.per_host", false)
+	v.SetDefault("adaptive_bidder_timeouts.enabled", false)
+	v.SetDefault("adaptive_bidder_timeouts.percentile", 95)
+	v.SetDefault("adaptive_bidder_timeouts.sample_size", 200)
+	v.SetDefault("adaptive_bidder_timeouts.min_samples", 20)
+	v.SetDefault("adaptive_bidder_timeouts.min_timeout_ms", 50)
+	v.SetDefault("geolocation.enabled", false)
+	v.SetDefault("geolocation.database_path", "")
+	v.SetDefault("geolocation.refresh_interval_seconds", 60)
+	v.SetDefault("geolocation.lat_lon_precision", 2)
+	v.SetDefault("device_detection.enabled", false)
+	v.SetDefault("device_detection.rules_file", "./static/device-detection/rules.json")
+	v.SetDefault("invalid_traffic.enabled", false)
+	v.SetDefault("invalid_traffic.bot_user_agent_action", "reject")
+	v.SetDefault("invalid_traffic.bot_user_agent_patterns", []string{
+		`bot\b`,
+		"crawl",
+		"spider",
+		"slurp",
+		"headlesschrome",
+		"phantomjs",
+		"python-requests",
+		"^curl/",
+		"^wget/",
+		"facebookexternalhit",
+		"mediapartners-google",
+	})
+	v.SetDefault("invalid_traffic.datacenter_ip_action", "skip")
+	v.SetDefault("invalid_traffic.datacenter_cidr_file", "")
+	v.SetDefault("invalid_traffic.invalid_ip_action", "flag")
+	v.SetDefault("invalid_traffic.flagged_skip_bidders", []string{})
 
 	v.SetDefault("request_timeout_headers.request_time_in_queue", "")
 	v.SetDefault("request_timeout_headers.request_timeout_in_queue", "")
@@ -3,6 +3,7 @@ package config
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net"
 	"os"
 	"strings"
@@ -139,6 +140,12 @@ func TestDefaults(t *testing.T) {
 	cmpBools(t, "stored_requests.filesystem.enabled", false, cfg.StoredRequests.Files.Enabled)
 	cmpStrings(t, "stored_requests.filesystem.directorypath", "./stored_requests/data/by_id", cfg.StoredRequests.Files.Path)
 	cmpBools(t, "auto_gen_source_tid", cfg.AutoGenSourceTID, true)
+	cmpStrings(t, "gdpr.tcf2.fallback_gvl_path", cfg.GDPR.TCF2.FallbackGVLPath, "")
+
+	// Only the purposes which were enforced before they could be configured are enabled by default
+	for i := 1; i <= 10; i++ {
+		cmpBools(t, fmt.Sprintf("gdpr.tcf2.purpose%d.enabled", i), cfg.GDPR.TCF2.Purpose(i).Enabled, i == 1 || i == 2 || i == 7)
+	}
 }
 
 var fullConfig = []byte(`
@@ -146,6 +153,11 @@ gdpr:
   host_vendor_id: 15
   usersync_if_ambiguous: true
   non_standard_publishers: ["siteID","fake-site-id","appID","agltb3B1Yi1pbmNyDAsSA0FwcBiJkfIUDA"]
+  tcf2:
+    purpose3:
+      enabled: true
+      enforcement_type: basic
+      vendor_exceptions: ["appnexus"]
 ccpa:
   enforce: true
 lmt:
@@ -185,6 +197,12 @@ http_client_cache:
 currency_converter:
   fetch_url: https://currency.prebid.org
   fetch_interval_seconds: 1800
+  sources:
+    - type: ecb_xml
+      url: https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml
+      stale_rates_seconds: 86400
+    - type: file
+      path: /etc/pbs/currency.json
 recaptcha_secret: asdfasdfasdfasdf
 metrics:
   influxdb:
@@ -363,6 +381,14 @@ func TestFullConfig(t *testing.T) {
 	_, found = cfg.GDPR.NonStandardPublisherMap["appnexus"]
 	cmpBools(t, "cfg.GDPR.NonStandardPublisherMap", found, false)
 
+	cmpBools(t, "gdpr.tcf2.purpose1.enabled", cfg.GDPR.TCF2.Purpose1.Enabled, true)
+	cmpStrings(t, "gdpr.tcf2.purpose1.enforcement_type", string(cfg.GDPR.TCF2.Purpose1.EnforcementType), "full")
+	cmpBools(t, "gdpr.tcf2.purpose3.enabled", cfg.GDPR.TCF2.Purpose3.Enabled, true)
+	cmpStrings(t, "gdpr.tcf2.purpose3.enforcement_type", string(cfg.GDPR.TCF2.Purpose3.EnforcementType), "basic")
+	assert.Equal(t, map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderAppnexus: {}}, cfg.GDPR.TCF2.Purpose3.VendorExceptionMap, "gdpr.tcf2.purpose3.vendor_exceptions")
+	assert.Equal(t, TCF2BasicEnforcement, cfg.GDPR.TCF2.Purpose(3).Enforcement(openrtb_ext.BidderRubicon))
+	assert.Equal(t, TCF2NoEnforcement, cfg.GDPR.TCF2.Purpose(3).Enforcement(openrtb_ext.BidderAppnexus))
+
 	cmpBools(t, "ccpa.enforce", cfg.CCPA.Enforce, true)
 	cmpBools(t, "lmt.enforce", cfg.LMT.Enforce, true)
 
@@ -377,6 +403,10 @@ func TestFullConfig(t *testing.T) {
 
 	cmpStrings(t, "currency_converter.fetch_url", cfg.CurrencyConverter.FetchURL, "https://currency.prebid.org")
 	cmpInts(t, "currency_converter.fetch_interval_seconds", cfg.CurrencyConverter.FetchIntervalSeconds, 1800)
+	assert.Equal(t, []CurrencyRateSource{
+		{Type: CurrencyRateSourceECB, URL: "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml", StaleRatesSeconds: 86400},
+		{Type: CurrencyRateSourceFile, Path: "/etc/pbs/currency.json"},
+	}, cfg.CurrencyConverter.Sources)
 	cmpStrings(t, "recaptcha_secret", cfg.RecaptchaSecret, "asdfasdfasdfasdf")
 	cmpStrings(t, "metrics.influxdb.host", cfg.Metrics.Influxdb.Host, "upstream:8232")
 	cmpStrings(t, "metrics.influxdb.database", cfg.Metrics.Influxdb.Database, "metricsdb")
@@ -534,6 +564,164 @@ func TestNegativePrometheusTimeout(t *testing.T) {
 	assertOneError(t, cfg.validate(), "metrics.prometheus.timeout_ms must be positive if metrics.prometheus.port is defined. Got timeout=0 and port=8001")
 }
 
+func TestInvalidHookStep(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.Hooks.Enabled = true
+	cfg.Hooks.ExecutionPlan = map[string][]HookStep{
+		"entrypoint": {{Module: "acme", Hook: "entry", TimeoutMillis: 0}},
+	}
+	assertOneError(t, cfg.validate(), "hooks.execution_plan.entrypoint[0].timeout_ms must be positive. Got 0")
+}
+
+func TestInvalidCircuitBreaker(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.BidderCircuitBreaker.Enabled = true
+	cfg.BidderCircuitBreaker.OpenIntervalMillis = 0
+	assertOneError(t, cfg.validate(), "bidder_circuit_breaker.open_interval_ms must be positive. Got 0")
+}
+
+func TestInvalidAdaptiveBidderTimeouts(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.AdaptiveBidderTimeouts.Enabled = true
+	cfg.AdaptiveBidderTimeouts.MinSamples = cfg.AdaptiveBidderTimeouts.SampleSize + 1
+	assertOneError(t, cfg.validate(), "adaptive_bidder_timeouts.min_samples must be in the range [1, sample_size]. Got 201")
+}
+
+func TestInvalidValidations(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.AccountDefaults.Validations.SecureMarkup = "reject"
+	assertOneError(t, cfg.validate(), "account_defaults.validations.secure_markup must be one of skip, warn or enforce. Got reject")
+}
+
+func TestInvalidAccountAuction(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.AccountDefaults.Auction.Type = "vickrey"
+	assertOneError(t, cfg.validate(), "account_defaults.auction.type must be first_price or second_price. Got vickrey")
+}
+
+func TestInvalidActivities(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.AccountDefaults.Activities.SyncUser.Rules = []ActivityRule{
+		{Allow: false, Condition: ActivityCondition{Countries: []string{"USA", "DE"}}},
+	}
+	assertOneError(t, cfg.validate(), "account_defaults.activities.sync_user.rules[0].condition.countries must contain ISO 3166-1 alpha-3 codes. Got DE")
+}
+
+func TestInvalidIPMasks(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.AccountDefaults.IPMasks.GDPR.IPv4PrefixLength = 33
+	assertOneError(t, cfg.validate(), "account_defaults.ip_masks.gdpr.ipv4_prefix_length must be in the range [0, 32]. Got 33")
+
+	cfg.AccountDefaults.IPMasks.GDPR.IPv4PrefixLength = 16
+	cfg.AccountDefaults.IPMasks.COPPA.IPv6PrefixLength = -1
+	assertOneError(t, cfg.validate(), "account_defaults.ip_masks.coppa.ipv6_prefix_length must be in the range [0, 128]. Got -1")
+
+	cfg.AccountDefaults.IPMasks.COPPA.IPv6PrefixLength = 96
+	cfg.AccountDefaults.IPMasks.PreciseGeo.Hash = true
+	assertOneError(t, cfg.validate(), "ip_hash_secret must be set to hash IP addresses in account_defaults.ip_masks")
+
+	cfg.IPHashSecret = "secret"
+	assert.Nil(t, cfg.validate())
+}
+
+func TestInvalidGeoLocation(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.GeoLocation.Enabled = true
+	cfg.GeoLocation.DatabasePath = "/etc/pbs/GeoLite2-City.mmdb"
+	cfg.GeoLocation.LatLonPrecision = 7
+	assertOneError(t, cfg.validate(), "geolocation.lat_lon_precision must be in the range [0, 6]. Got 7")
+
+	cfg.GeoLocation.LatLonPrecision = 2
+	cfg.GeoLocation.DatabasePath = ""
+	assertOneError(t, cfg.validate(), "geolocation.database_path is required when geolocation is enabled")
+}
+
+func TestInvalidDeviceDetection(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.DeviceDetection.Enabled = true
+	cfg.DeviceDetection.RulesFile = ""
+	assertOneError(t, cfg.validate(), "device_detection.rules_file is required when device detection is enabled")
+}
+
+func TestInvalidTrafficDefaults(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.InvalidTraffic.Enabled = true
+	assert.Empty(t, cfg.validate(), "The default invalid traffic checks should be valid")
+	assert.Equal(t, InvalidTrafficReject, cfg.InvalidTraffic.BotUserAgentAction)
+	assert.Contains(t, cfg.InvalidTraffic.BotUserAgentPatterns, "spider")
+}
+
+func TestInvalidInvalidTraffic(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.InvalidTraffic.Enabled = true
+	cfg.InvalidTraffic.InvalidIPAction = "block"
+	assertOneError(t, cfg.validate(), "invalid_traffic.invalid_ip_action must be one of skip, flag or reject. Got block")
+
+	cfg = newDefaultConfig(t)
+	cfg.InvalidTraffic.Enabled = true
+	cfg.InvalidTraffic.BotUserAgentPatterns = []string{"bot(", "crawl"}
+	assertOneError(t, cfg.validate(), "invalid_traffic.bot_user_agent_patterns[0] is not a valid regular expression: error parsing regexp: missing closing ): `bot(`")
+
+	cfg = newDefaultConfig(t)
+	cfg.InvalidTraffic.Enabled = true
+	cfg.InvalidTraffic.DataCenterIPAction = InvalidTrafficFlag
+	assertOneError(t, cfg.validate(), "invalid_traffic.datacenter_cidr_file is required when the datacenter_ip check is enabled")
+}
+
+func TestInvalidTCF2EnforcementType(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.GDPR.TCF2.Purpose4.EnforcementType = "strict"
+	assertOneError(t, cfg.validate(), "gdpr.tcf2.purpose4.enforcement_type must be one of full, basic or none. Got strict")
+
+	cfg = newDefaultConfig(t)
+	cfg.AccountDefaults.GDPR.SpecialPurpose1.EnforcementType = "strict"
+	assertOneError(t, cfg.validate(), "account_defaults.gdpr.special_purpose1.enforcement_type must be one of full, basic or none. Got strict")
+}
+
+func TestTCF2WithAccountOverrides(t *testing.T) {
+	enabled := true
+	host := TCF2{
+		Enabled:  true,
+		Purpose1: PurposeDetail{Enabled: true, EnforcementType: TCF2FullEnforcement},
+		Purpose2: PurposeDetail{
+			Enabled:          true,
+			EnforcementType:  TCF2FullEnforcement,
+			VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus},
+		},
+		Purpose3:            PurposeDetail{Enabled: false, EnforcementType: TCF2FullEnforcement},
+		PurposeOneTreatment: PurposeOneTreatement{Enabled: true, AccessAllowed: true},
+	}
+	host.buildVendorExceptionMaps()
+
+	merged := host.WithAccountOverrides(AccountGDPR{
+		Purpose1:            AccountGDPRPurpose{EnforcementType: TCF2BasicEnforcement},
+		Purpose2:            AccountGDPRPurpose{VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderRubicon}},
+		Purpose3:            AccountGDPRPurpose{Enabled: &enabled},
+		PurposeOneTreatment: AccountGDPRPurposeOneTreatment{AccessAllowed: new(bool)},
+	})
+
+	assert.Equal(t, TCF2BasicEnforcement, merged.Purpose(1).Enforcement(openrtb_ext.BidderAppnexus))
+	assert.Equal(t, TCF2FullEnforcement, merged.Purpose(2).Enforcement(openrtb_ext.BidderAppnexus))
+	assert.Equal(t, TCF2NoEnforcement, merged.Purpose(2).Enforcement(openrtb_ext.BidderRubicon))
+	assert.Equal(t, TCF2FullEnforcement, merged.Purpose(3).Enforcement(openrtb_ext.BidderAppnexus))
+	assert.True(t, merged.PurposeOneTreatment.Enabled)
+	assert.False(t, merged.PurposeOneTreatment.AccessAllowed)
+
+	assert.Equal(t, TCF2FullEnforcement, host.Purpose(1).Enforcement(openrtb_ext.BidderAppnexus), "The host config shouldn't change")
+	assert.Equal(t, TCF2NoEnforcement, host.Purpose(2).Enforcement(openrtb_ext.BidderAppnexus), "The host config shouldn't change")
+	assert.Equal(t, TCF2NoEnforcement, host.Purpose(3).Enforcement(openrtb_ext.BidderAppnexus), "The host config shouldn't change")
+	assert.Nil(t, host.Purpose(11))
+}
+
+func TestUsersyncIfAmbiguousInCountry(t *testing.T) {
+	cfg := newDefaultConfig(t)
+
+	assert.True(t, cfg.GDPR.IsEEACountry("deu"))
+	assert.False(t, cfg.GDPR.UsersyncIfAmbiguousInCountry("DEU"), "GDPR should apply in the EEA")
+	assert.True(t, cfg.GDPR.UsersyncIfAmbiguousInCountry("USA"), "GDPR shouldn't apply outside of the EEA")
+	assert.Equal(t, cfg.GDPR.UsersyncIfAmbiguous, cfg.GDPR.UsersyncIfAmbiguousInCountry(""), "The host default should be used if the country isn't known")
+}
+
 func TestInvalidHostVendorID(t *testing.T) {
 	tests := []struct {
 		description  string
@@ -594,6 +782,18 @@ func TestOverflowedCurrencyConverterFetchInterval(t *testing.T) {
 	assert.NotNil(t, err, "cfg.currency_converter.fetch_interval_seconds prevent values over %d, but it doesn't", 0xffff)
 }
 
+func TestInvalidCurrencyRateSource(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.CurrencyConverter.Sources = []CurrencyRateSource{
+		{Type: CurrencyRateSourceJSON, URL: "https://currency.prebid.org"},
+		{Type: CurrencyRateSourceFile},
+	}
+	assertOneError(t, cfg.validate(), "currency_converter.sources[1].path is required for file sources")
+
+	cfg.CurrencyConverter.Sources = []CurrencyRateSource{{Type: "csv", URL: "https://currency.prebid.org"}}
+	assertOneError(t, cfg.validate(), "currency_converter.sources[0].type must be one of [json, ecb_xml, file]. Got csv")
+}
+
 func TestLimitTimeout(t *testing.T) {
 	doTimeoutTest(t, 10, 15, 10, 0)
 	doTimeoutTest(t, 10, 0, 10, 0)
@@ -0,0 +1,41 @@
+package config
+
+import "fmt"
+
+// Hooks configures the modules which run at the stages of an auction.
+type Hooks struct {
+	// Enabled turns on the execution of hooks. Without it, no module is built or run.
+	Enabled bool `mapstructure:"enabled"`
+	// Modules holds the host configuration of each module, keyed by module name.
+	// Each module receives its own section as JSON when it's built.
+	Modules map[string]interface{} `mapstructure:"modules"`
+	// ExecutionPlan lists the hooks to run at each stage, in the order they run, keyed by stage name.
+	ExecutionPlan map[string][]HookStep `mapstructure:"execution_plan"`
+}
+
+// HookStep identifies one hook to run at a stage.
+type HookStep struct {
+	// Module is the name of the module which provides the hook.
+	Module string `mapstructure:"module"`
+	// Hook is the code the module uses for the hook.
+	Hook string `mapstructure:"hook"`
+	// TimeoutMillis is the time budget of the hook. Its outcome is ignored if it runs any longer.
+	TimeoutMillis int `mapstructure:"timeout_ms"`
+}
+
+func (cfg *Hooks) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	for stage, steps := range cfg.ExecutionPlan {
+		for i, step := range steps {
+			if step.Module == "" || step.Hook == "" {
+				errs = append(errs, fmt.Errorf("hooks.execution_plan.%s[%d] must name both a module and a hook", stage, i))
+			}
+			if step.TimeoutMillis <= 0 {
+				errs = append(errs, fmt.Errorf("hooks.execution_plan.%s[%d].timeout_ms must be positive. Got %d", stage, i, step.TimeoutMillis))
+			}
+		}
+	}
+	return errs
+}
@@ -59,6 +59,10 @@ type StoredRequests struct {
 	// Fetchers are in stored_requests/backends/db_fetcher/postgres.go
 	// EventProducers are in stored_requests/events/postgres
 	Postgres PostgresConfig `mapstructure:"postgres"`
+	// MySQL configures Fetchers and EventProducers which read from a MySQL DB.
+	// Fetchers are in stored_requests/backends/db_fetcher/fetcher.go
+	// EventProducers are in stored_requests/events/mysql
+	MySQL MySQLConfig `mapstructure:"mysql"`
 	// HTTP configures an instance of stored_requests/backends/http/http_fetcher.go.
 	// If non-nil, Stored Requests will be fetched from the endpoint described there.
 	HTTP HTTPFetcherConfig `mapstructure:"http"`
@@ -103,6 +107,9 @@ type FileFetcherConfig struct {
 	Enabled bool `mapstructure:"enabled"`
 	// Path to the directory this file fetcher gets data from.
 	Path string `mapstructure:"directorypath"`
+	// RefreshRate is how often, in seconds, the directory is reread for changes. Values <= 0 disable the reloads.
+	// If there's an in_memory_cache, changed files are sent to it through a stored_requests/backends/file_fetcher/events.go EventProducer.
+	RefreshRate int `mapstructure:"refresh_rate_seconds"`
 }
 
 // HTTPFetcherConfig configures a stored_requests/backends/http_fetcher/fetcher.go
@@ -123,6 +130,9 @@ func resolvedStoredRequestsConfig(cfg *Configuration) {
 	amp.Postgres.FetcherQueries.QueryTemplate = sr.Postgres.FetcherQueries.AmpQueryTemplate
 	amp.Postgres.CacheInitialization.Query = sr.Postgres.CacheInitialization.AmpQuery
 	amp.Postgres.PollUpdates.Query = sr.Postgres.PollUpdates.AmpQuery
+	amp.MySQL.FetcherQueries.QueryTemplate = sr.MySQL.FetcherQueries.AmpQueryTemplate
+	amp.MySQL.CacheInitialization.Query = sr.MySQL.CacheInitialization.AmpQuery
+	amp.MySQL.PollUpdates.Query = sr.MySQL.PollUpdates.AmpQuery
 	amp.HTTP.Endpoint = sr.HTTP.AmpEndpoint
 	amp.CacheEvents.Endpoint = "/storedrequests/amp"
 	amp.HTTPEvents.Endpoint = sr.HTTPEvents.AmpEndpoint
@@ -142,6 +152,11 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 	} else {
 		errs = cfg.Postgres.validate(cfg.DataType(), errs)
 	}
+	if cfg.DataType() == AccountDataType && cfg.MySQL.ConnectionInfo.Database != "" {
+		errs = append(errs, fmt.Errorf("%s.mysql: retrieving accounts via mysql not available, use accounts.files", cfg.Section()))
+	} else {
+		errs = cfg.MySQL.validate(cfg.DataType(), errs)
+	}
 
 	// Categories do not use cache so none of the following checks apply
 	if cfg.DataType() == CategoryDataType {
@@ -163,6 +178,12 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 		if cfg.Postgres.CacheInitialization.Query != "" {
 			errs = append(errs, fmt.Errorf("%s: postgres.initialize_caches.query must be empty if in_memory_cache=none", cfg.Section()))
 		}
+		if cfg.MySQL.PollUpdates.Query != "" {
+			errs = append(errs, fmt.Errorf("%s: mysql.poll_for_updates.query must be empty if in_memory_cache=none", cfg.Section()))
+		}
+		if cfg.MySQL.CacheInitialization.Query != "" {
+			errs = append(errs, fmt.Errorf("%s: mysql.initialize_caches.query must be empty if in_memory_cache=none", cfg.Section()))
+		}
 	}
 	errs = cfg.InMemoryCache.validate(cfg.DataType(), errs)
 	return errs
@@ -261,6 +282,15 @@ type PostgresFetcherQueries struct {
 
 	// AmpQueryTemplate is the same as QueryTemplate, but used in the `/openrtb2/amp` endpoint.
 	AmpQueryTemplate string `mapstructure:"amp_query"`
+
+	// ResponsesQueryTemplate is the Postgres Query which fetches Stored Responses. It should return the id
+	// and data of each response, with %ID_LIST% in place of the requested IDs. For example:
+	//   SELECT id, responseData
+	//     FROM stored_responses
+	//     WHERE id in %ID_LIST%
+	//
+	// If it's empty, Stored Responses aren't read from the database.
+	ResponsesQueryTemplate string `mapstructure:"responses_query"`
 }
 
 type PostgresCacheInitializer struct {
@@ -342,6 +372,13 @@ func (cfg *PostgresFetcherQueries) MakeQuery(numReqs int, numImps int) (query st
 	return resolve(cfg.QueryTemplate, numReqs, numImps)
 }
 
+// MakeResponsesQuery builds a query which can fetch numIDs Stored Responses, or returns an empty string
+// if no ResponsesQueryTemplate is configured.
+func (cfg *PostgresFetcherQueries) MakeResponsesQuery(numIDs int) (query string) {
+	numIDs = ensureNonNegative("Response", numIDs)
+	return strings.Replace(cfg.ResponsesQueryTemplate, "%ID_LIST%", makeIdList(0, numIDs), -1)
+}
+
 func resolve(template string, numReqs int, numImps int) (query string) {
 	numReqs = ensureNonNegative("Request", numReqs)
 	numImps = ensureNonNegative("Imp", numImps)
@@ -390,6 +427,182 @@ func makeIdList(numSoFar int, numArgs int) string {
 	return final.String()
 }
 
+// MySQLConfig configures the Stored Request ecosystem to use MySQL. This must include a Fetcher,
+// and may optionally include some EventProducers to populate and refresh the caches.
+type MySQLConfig struct {
+	ConnectionInfo      MySQLConnection       `mapstructure:"connection"`
+	FetcherQueries      MySQLFetcherQueries   `mapstructure:"fetcher"`
+	CacheInitialization MySQLCacheInitializer `mapstructure:"initialize_caches"`
+	PollUpdates         MySQLUpdatePolling    `mapstructure:"poll_for_updates"`
+}
+
+func (cfg *MySQLConfig) validate(dataType DataType, errs []error) []error {
+	if cfg.ConnectionInfo.Database == "" {
+		return errs
+	}
+
+	errs = cfg.CacheInitialization.validate(dataType, errs)
+	errs = cfg.PollUpdates.validate(dataType, errs)
+	return errs
+}
+
+// MySQLConnection has options which put types to the MySQL Data Source Name. See:
+// https://github.com/go-sql-driver/mysql#dsn-data-source-name
+type MySQLConnection struct {
+	Database string `mapstructure:"dbname"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+}
+
+func (cfg *MySQLConnection) ConnString() string {
+	buffer := bytes.NewBuffer(nil)
+
+	if cfg.Username != "" {
+		buffer.WriteString(cfg.Username)
+		if cfg.Password != "" {
+			buffer.WriteString(":")
+			buffer.WriteString(cfg.Password)
+		}
+		buffer.WriteString("@")
+	}
+
+	buffer.WriteString("tcp(")
+	buffer.WriteString(cfg.Host)
+	if cfg.Port > 0 {
+		buffer.WriteString(":")
+		buffer.WriteString(strconv.Itoa(cfg.Port))
+	}
+	buffer.WriteString(")")
+
+	buffer.WriteString("/")
+	buffer.WriteString(cfg.Database)
+
+	// parseTime lets the driver scan DATETIME and TIMESTAMP columns into time.Time
+	buffer.WriteString("?parseTime=true")
+	return buffer.String()
+}
+
+type MySQLFetcherQueries struct {
+	// QueryTemplate is the MySQL Query which can be used to fetch configs from the database.
+	// It works like PostgresFetcherQueries.QueryTemplate, except that the MakeQuery function
+	// replaces %REQUEST_ID_LIST% and %IMP_ID_LIST% with lists of "?" placeholders:
+	//   SELECT id, requestData, 'request' as type
+	//     FROM stored_requests
+	//     WHERE id in (?)
+	//     UNION ALL
+	//   SELECT id, impData, 'imp' as type
+	//     FROM stored_imps
+	//     WHERE id in (?, ?, ?, ...)
+	QueryTemplate string `mapstructure:"query"`
+
+	// AmpQueryTemplate is the same as QueryTemplate, but used in the `/openrtb2/amp` endpoint.
+	AmpQueryTemplate string `mapstructure:"amp_query"`
+
+	// ResponsesQueryTemplate works like PostgresFetcherQueries.ResponsesQueryTemplate, except that
+	// %ID_LIST% is replaced with a list of "?" placeholders.
+	ResponsesQueryTemplate string `mapstructure:"responses_query"`
+}
+
+// MakeQuery builds a query which can fetch numReqs Stored Requests and numImps Stored Imps.
+// See the docs on MySQLFetcherQueries.QueryTemplate for a description of how it works.
+func (cfg *MySQLFetcherQueries) MakeQuery(numReqs int, numImps int) (query string) {
+	numReqs = ensureNonNegative("Request", numReqs)
+	numImps = ensureNonNegative("Imp", numImps)
+
+	query = strings.Replace(cfg.QueryTemplate, "%REQUEST_ID_LIST%", makeMySQLIdList(numReqs), -1)
+	query = strings.Replace(query, "%IMP_ID_LIST%", makeMySQLIdList(numImps), -1)
+	return
+}
+
+// MakeResponsesQuery builds a query which can fetch numIDs Stored Responses, or returns an empty string
+// if no ResponsesQueryTemplate is configured.
+func (cfg *MySQLFetcherQueries) MakeResponsesQuery(numIDs int) (query string) {
+	numIDs = ensureNonNegative("Response", numIDs)
+	return strings.Replace(cfg.ResponsesQueryTemplate, "%ID_LIST%", makeMySQLIdList(numIDs), -1)
+}
+
+func makeMySQLIdList(numArgs int) string {
+	// As with Postgres, an empty list like "()" is illegal, and `id IN (NULL)` evaluates to an empty set.
+	if numArgs == 0 {
+		return "(NULL)"
+	}
+	return "(" + strings.Repeat("?, ", numArgs-1) + "?)"
+}
+
+type MySQLCacheInitializer struct {
+	Timeout int `mapstructure:"timeout_ms"`
+	// Query should be something like:
+	//
+	// SELECT id, requestData, 'request' AS type FROM stored_requests
+	// UNION ALL
+	// SELECT id, impData, 'imp' AS type FROM stored_imps
+	//
+	// This query will be run once on startup to fetch _all_ known Stored Request data from the database.
+	Query string `mapstructure:"query"`
+	// AmpQuery is just like Query, but for AMP Stored Requests
+	AmpQuery string `mapstructure:"amp_query"`
+}
+
+func (cfg *MySQLCacheInitializer) validate(dataType DataType, errs []error) []error {
+	section := dataType.Section()
+	if cfg.Query == "" {
+		return errs
+	}
+	if cfg.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s: mysql.initialize_caches.timeout_ms must be positive", section))
+	}
+	if strings.Contains(cfg.Query, "?") {
+		errs = append(errs, fmt.Errorf("%s: mysql.initialize_caches.query should not contain any wildcards (e.g. ?)", section))
+	}
+	return errs
+}
+
+type MySQLUpdatePolling struct {
+	// RefreshRate determines how frequently the Query and AmpQuery are run.
+	RefreshRate int `mapstructure:"refresh_rate_seconds"`
+
+	// Timeout is the amount of time before a call to the database is aborted.
+	Timeout int `mapstructure:"timeout_ms"`
+
+	// An example UpdateQuery is:
+	//
+	// SELECT id, requestData, 'request' AS type
+	//   FROM stored_requests
+	//   WHERE last_updated > ?
+	// UNION ALL
+	// SELECT id, requestData, 'imp' AS type
+	//   FROM stored_imps
+	//   WHERE last_updated > ?
+	//
+	// MySQL placeholders can't be reused, so every "?" is bound to the time of the last update.
+	// The code will be run periodically to fetch updates from the database.
+	Query string `mapstructure:"query"`
+	// AmpQuery is the same as Query, but used for the `/openrtb2/amp` endpoint.
+	AmpQuery string `mapstructure:"amp_query"`
+}
+
+func (cfg *MySQLUpdatePolling) validate(dataType DataType, errs []error) []error {
+	section := dataType.Section()
+	if cfg.Query == "" {
+		return errs
+	}
+
+	if cfg.RefreshRate <= 0 {
+		errs = append(errs, fmt.Errorf("%s: mysql.poll_for_updates.refresh_rate_seconds must be > 0", section))
+	}
+
+	if cfg.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s: mysql.poll_for_updates.timeout_ms must be > 0", section))
+	}
+
+	if !strings.Contains(cfg.Query, "?") {
+		errs = append(errs, fmt.Errorf("%s: mysql.poll_for_updates.query must contain at least one wildcard", section))
+	}
+	return errs
+}
+
 type InMemoryCache struct {
 	// Identify the type of memory cache. "none", "unbounded", "lru"
 	Type string `mapstructure:"type"`
@@ -41,6 +41,109 @@ func TestQueryMakerNegative(t *testing.T) {
 	assertStringsEqual(t, query, expected)
 }
 
+func TestMySQLQueryMaker(t *testing.T) {
+	tests := []struct {
+		description string
+		numReqs     int
+		numImps     int
+		wantQuery   string
+	}{
+		{
+			description: "Requests and imps",
+			numReqs:     1,
+			numImps:     3,
+			wantQuery:   "SELECT id, requestData, 'request' as type FROM stored_requests WHERE id in (?) UNION ALL SELECT id, impData, 'imp' as type FROM stored_requests WHERE id in (?, ?, ?)",
+		},
+		{
+			description: "No requests",
+			numReqs:     0,
+			numImps:     2,
+			wantQuery:   "SELECT id, requestData, 'request' as type FROM stored_requests WHERE id in (NULL) UNION ALL SELECT id, impData, 'imp' as type FROM stored_requests WHERE id in (?, ?)",
+		},
+		{
+			description: "Negative counts are treated as zero",
+			numReqs:     -1,
+			numImps:     -2,
+			wantQuery:   "SELECT id, requestData, 'request' as type FROM stored_requests WHERE id in (NULL) UNION ALL SELECT id, impData, 'imp' as type FROM stored_requests WHERE id in (NULL)",
+		},
+	}
+
+	for _, tt := range tests {
+		cfg := MySQLFetcherQueries{QueryTemplate: sampleQueryTemplate}
+		assert.Equal(t, tt.wantQuery, cfg.MakeQuery(tt.numReqs, tt.numImps), tt.description)
+	}
+}
+
+func TestResponsesQueryMaker(t *testing.T) {
+	template := "SELECT id, responseData FROM stored_responses WHERE id in %ID_LIST%"
+	tests := []struct {
+		description  string
+		template     string
+		numIDs       int
+		wantPostgres string
+		wantMySQL    string
+	}{
+		{
+			description:  "Several responses",
+			template:     template,
+			numIDs:       3,
+			wantPostgres: "SELECT id, responseData FROM stored_responses WHERE id in ($1, $2, $3)",
+			wantMySQL:    "SELECT id, responseData FROM stored_responses WHERE id in (?, ?, ?)",
+		},
+		{
+			description:  "No responses",
+			template:     template,
+			numIDs:       0,
+			wantPostgres: "SELECT id, responseData FROM stored_responses WHERE id in (NULL)",
+			wantMySQL:    "SELECT id, responseData FROM stored_responses WHERE id in (NULL)",
+		},
+		{
+			description: "No template",
+			numIDs:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		postgresCfg := PostgresFetcherQueries{ResponsesQueryTemplate: tt.template}
+		assert.Equal(t, tt.wantPostgres, postgresCfg.MakeResponsesQuery(tt.numIDs), tt.description)
+		mysqlCfg := MySQLFetcherQueries{ResponsesQueryTemplate: tt.template}
+		assert.Equal(t, tt.wantMySQL, mysqlCfg.MakeResponsesQuery(tt.numIDs), tt.description)
+	}
+}
+
+func TestMySQLConnString(t *testing.T) {
+	tests := []struct {
+		description string
+		connection  MySQLConnection
+		wantDSN     string
+	}{
+		{
+			description: "All options",
+			connection: MySQLConnection{
+				Database: "TestDB",
+				Host:     "somehost.com",
+				Port:     20,
+				Username: "someuser",
+				Password: "somepassword",
+			},
+			wantDSN: "someuser:somepassword@tcp(somehost.com:20)/TestDB?parseTime=true",
+		},
+		{
+			description: "No password or port",
+			connection: MySQLConnection{
+				Database: "TestDB",
+				Host:     "somehost.com",
+				Username: "someuser",
+			},
+			wantDSN: "someuser@tcp(somehost.com)/TestDB?parseTime=true",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantDSN, tt.connection.ConnString(), tt.description)
+	}
+}
+
 func TestPostgressConnString(t *testing.T) {
 	db := "TestDB"
 	host := "somehost.com"
@@ -259,6 +362,86 @@ func TestPostgresConfigValidation(t *testing.T) {
 	}
 }
 
+func TestMySQLConfigValidation(t *testing.T) {
+	tests := []struct {
+		description            string
+		database               string
+		cacheInitQuery         string
+		cacheInitTimeout       int
+		cacheUpdateQuery       string
+		cacheUpdateRefreshRate int
+		cacheUpdateTimeout     int
+		wantErrorCount         int
+	}{
+		{
+			description: "No database",
+		},
+		{
+			description:            "Valid queries",
+			database:               "some-database",
+			cacheInitQuery:         "SELECT * FROM table;",
+			cacheInitTimeout:       1,
+			cacheUpdateQuery:       "SELECT * FROM table WHERE last_updated > ? OR created > ?",
+			cacheUpdateRefreshRate: 1,
+			cacheUpdateTimeout:     1,
+		},
+		{
+			description:      "Invalid cache init query contains wildcard",
+			database:         "some-database",
+			cacheInitQuery:   "SELECT * FROM table WHERE last_updated > ?",
+			cacheInitTimeout: 1,
+			wantErrorCount:   1,
+		},
+		{
+			description:            "Invalid cache update query missing wildcard",
+			database:               "some-database",
+			cacheUpdateQuery:       "SELECT * FROM table",
+			cacheUpdateRefreshRate: 1,
+			cacheUpdateTimeout:     1,
+			wantErrorCount:         1,
+		},
+		{
+			description:      "Valid queries missing timeouts and refresh rates",
+			database:         "some-database",
+			cacheInitQuery:   "SELECT * FROM table;",
+			cacheUpdateQuery: "SELECT * FROM table WHERE last_updated > ?",
+			wantErrorCount:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		mysqlConfig := &MySQLConfig{
+			ConnectionInfo: MySQLConnection{
+				Database: tt.database,
+			},
+			CacheInitialization: MySQLCacheInitializer{
+				Query:   tt.cacheInitQuery,
+				Timeout: tt.cacheInitTimeout,
+			},
+			PollUpdates: MySQLUpdatePolling{
+				Query:       tt.cacheUpdateQuery,
+				RefreshRate: tt.cacheUpdateRefreshRate,
+				Timeout:     tt.cacheUpdateTimeout,
+			},
+		}
+
+		errs := mysqlConfig.validate(RequestDataType, nil)
+		assert.Equal(t, tt.wantErrorCount, len(errs), tt.description)
+	}
+}
+
+func TestMySQLAccountsValidation(t *testing.T) {
+	cfg := &StoredRequests{
+		dataType: AccountDataType,
+		MySQL: MySQLConfig{
+			ConnectionInfo: MySQLConnection{Database: "some-database"},
+		},
+	}
+
+	errs := cfg.validate(nil)
+	assertErrsExist(t, errs)
+}
+
 func assertErrsExist(t *testing.T, err []error) {
 	t.Helper()
 	if len(err) == 0 {
@@ -322,6 +505,17 @@ func TestResolveConfig(t *testing.T) {
 					AmpQuery: "amp-poll-query",
 				},
 			},
+			MySQL: MySQLConfig{
+				FetcherQueries: MySQLFetcherQueries{
+					AmpQueryTemplate: "amp-mysql-fetcher-query",
+				},
+				CacheInitialization: MySQLCacheInitializer{
+					AmpQuery: "amp-mysql-cache-init-query",
+				},
+				PollUpdates: MySQLUpdatePolling{
+					AmpQuery: "amp-mysql-poll-query",
+				},
+			},
 			HTTP: HTTPFetcherConfig{
 				AmpEndpoint: "amp-http-fetcher-endpoint",
 			},
@@ -343,6 +537,7 @@ func TestResolveConfig(t *testing.T) {
 	cfg.StoredRequests.Postgres.FetcherQueries.QueryTemplate = "auc-fetcher-query"
 	cfg.StoredRequests.Postgres.CacheInitialization.Query = "auc-cache-init-query"
 	cfg.StoredRequests.Postgres.PollUpdates.Query = "auc-poll-query"
+	cfg.StoredRequests.MySQL.FetcherQueries.QueryTemplate = "auc-mysql-fetcher-query"
 	cfg.StoredRequests.HTTP.Endpoint = "auc-http-fetcher-endpoint"
 	cfg.StoredRequests.HTTPEvents.Endpoint = "auc-http-events-endpoint"
 
@@ -357,6 +552,9 @@ func TestResolveConfig(t *testing.T) {
 	assertStringsEqual(t, amp.Postgres.FetcherQueries.QueryTemplate, cfg.StoredRequests.Postgres.FetcherQueries.AmpQueryTemplate)
 	assertStringsEqual(t, amp.Postgres.CacheInitialization.Query, cfg.StoredRequests.Postgres.CacheInitialization.AmpQuery)
 	assertStringsEqual(t, amp.Postgres.PollUpdates.Query, cfg.StoredRequests.Postgres.PollUpdates.AmpQuery)
+	assertStringsEqual(t, amp.MySQL.FetcherQueries.QueryTemplate, cfg.StoredRequests.MySQL.FetcherQueries.AmpQueryTemplate)
+	assertStringsEqual(t, amp.MySQL.CacheInitialization.Query, cfg.StoredRequests.MySQL.CacheInitialization.AmpQuery)
+	assertStringsEqual(t, amp.MySQL.PollUpdates.Query, cfg.StoredRequests.MySQL.PollUpdates.AmpQuery)
 	assertStringsEqual(t, amp.HTTP.Endpoint, cfg.StoredRequests.HTTP.AmpEndpoint)
 	assertStringsEqual(t, amp.HTTPEvents.Endpoint, cfg.StoredRequests.HTTPEvents.AmpEndpoint)
 	assertStringsEqual(t, amp.CacheEvents.Endpoint, "/storedrequests/amp")
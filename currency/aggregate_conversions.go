@@ -0,0 +1,44 @@
+package currency
+
+// AggregateConversions looks up the rates of a request first, and falls back to the rates of the server.
+type AggregateConversions struct {
+	customRates Conversions
+	serverRates Conversions
+}
+
+// NewAggregateConversions creates a new AggregateConversions object from the rates of a request and of the server
+func NewAggregateConversions(customRates, serverRates Conversions) *AggregateConversions {
+	return &AggregateConversions{
+		customRates: customRates,
+		serverRates: serverRates,
+	}
+}
+
+// GetRate returns the custom conversion rate between two currencies, or the server's if there is no custom one.
+// It returns the error of the server's rates if neither has the conversion.
+func (ac *AggregateConversions) GetRate(from string, to string) (float64, error) {
+	if rate, err := ac.customRates.GetRate(from, to); err == nil {
+		return rate, nil
+	}
+	return ac.serverRates.GetRate(from, to)
+}
+
+// GetRates returns the rates of the server, overridden by the custom ones
+func (ac *AggregateConversions) GetRates() *map[string]map[string]float64 {
+	rates := make(map[string]map[string]float64)
+	for _, source := range []Conversions{ac.serverRates, ac.customRates} {
+		sourceRates := source.GetRates()
+		if sourceRates == nil {
+			continue
+		}
+		for from, toRates := range *sourceRates {
+			if rates[from] == nil {
+				rates[from] = make(map[string]float64, len(toRates))
+			}
+			for to, rate := range toRates {
+				rates[from][to] = rate
+			}
+		}
+	}
+	return &rates
+}
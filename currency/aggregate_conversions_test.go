@@ -0,0 +1,31 @@
+package currency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateConversionsGetRate(t *testing.T) {
+	customRates := NewRates(time.Time{}, map[string]map[string]float64{
+		"USD": {"EUR": 0.9},
+	})
+	serverRates := NewRates(time.Time{}, map[string]map[string]float64{
+		"USD": {"EUR": 0.85, "GBP": 0.75},
+	})
+	conversions := NewAggregateConversions(customRates, serverRates)
+
+	rate, err := conversions.GetRate("USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.9, rate, "The custom rates should take priority")
+
+	rate, err = conversions.GetRate("GBP", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 1/0.75, rate, "The server's rates should be used when there is no custom rate")
+
+	_, err = conversions.GetRate("USD", "JPY")
+	assert.EqualError(t, err, "Currency conversion rate not found: 'USD' => 'JPY'")
+
+	assert.Equal(t, &map[string]map[string]float64{"USD": {"EUR": 0.9, "GBP": 0.75}}, conversions.GetRates())
+}
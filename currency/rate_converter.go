@@ -1,94 +1,84 @@
 package currency
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
 	"net/http"
 	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
-	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/util/timeutil"
 )
 
-// RateConverter holds the currencies conversion rates dictionary
+// RateConverter holds the currencies conversion rates dictionary.
+//
+// The rates come from the first of its sources which has rates that aren't stale. If none of them has,
+// constant rates are used.
 type RateConverter struct {
-	httpClient          httpClient
-	staleRatesThreshold time.Duration
-	syncSourceURL       string
-	rates               atomic.Value // Should only hold Rates struct
-	lastUpdated         atomic.Value // Should only hold time.Time
-	constantRates       Conversions
-	time                timeutil.Time
+	sources       []*rateSourceState
+	constantRates Conversions
+	time          timeutil.Time
 }
 
-// NewRateConverter returns a new RateConverter
+// Source is a place to fetch rates from, and how long its rates may be used once it can't be fetched anymore.
+// A threshold of 0 means the rates never go stale.
+type Source struct {
+	RateSource          RateSource
+	StaleRatesThreshold time.Duration
+}
+
+type rateSourceState struct {
+	Source
+	rates       atomic.Value // Should only hold Rates struct
+	lastUpdated atomic.Value // Should only hold time.Time
+}
+
+// NewRateConverter returns a new RateConverter which fetches rates in the Prebid currency file format
+// from syncSourceURL
 func NewRateConverter(
 	httpClient httpClient,
 	syncSourceURL string,
 	staleRatesThreshold time.Duration,
 ) *RateConverter {
-	return &RateConverter{
-		httpClient:          httpClient,
-		staleRatesThreshold: staleRatesThreshold,
-		syncSourceURL:       syncSourceURL,
-		rates:               atomic.Value{},
-		lastUpdated:         atomic.Value{},
-		constantRates:       NewConstantRates(),
-		time:                &timeutil.RealTime{},
-	}
+	return NewRateConverterFromSources([]Source{{
+		RateSource:          NewJSONRateSource(httpClient, syncSourceURL),
+		StaleRatesThreshold: staleRatesThreshold,
+	}})
 }
 
-// fetch allows to retrieve the currencies rates from the syncSourceURL provided
-func (rc *RateConverter) fetch() (*Rates, error) {
-	request, err := http.NewRequest("GET", rc.syncSourceURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := rc.httpClient.Do(request)
-	if err != nil {
-		return nil, err
+// NewRateConverterFromSources returns a new RateConverter which fails over from one source to the next, in order.
+func NewRateConverterFromSources(sources []Source) *RateConverter {
+	states := make([]*rateSourceState, len(sources))
+	for i, source := range sources {
+		states[i] = &rateSourceState{Source: source}
 	}
-
-	if response.StatusCode >= 400 {
-		message := fmt.Sprintf("The currency rates request failed with status code %d", response.StatusCode)
-		return nil, &errortypes.BadServerResponse{Message: message}
-	}
-
-	defer response.Body.Close()
-
-	bytesJSON, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	updatedRates := &Rates{}
-	err = json.Unmarshal(bytesJSON, updatedRates)
-	if err != nil {
-		return nil, err
+	return &RateConverter{
+		sources:       states,
+		constantRates: NewConstantRates(),
+		time:          &timeutil.RealTime{},
 	}
-
-	return updatedRates, err
 }
 
-// Update updates the internal currencies rates from remote sources
+// Update updates the internal currencies rates from remote sources.
+//
+// The sources are fetched in order until one of them succeeds, so that the ones after it are only called
+// while it's failing. The sources which failed drop their rates once they're stale.
 func (rc *RateConverter) update() error {
-	rates, err := rc.fetch()
-	if err == nil {
-		rc.rates.Store(rates)
-		rc.lastUpdated.Store(rc.time.Now())
-	} else {
-		if rc.checkStaleRates() {
-			rc.clearRates()
-			glog.Errorf("Error updating conversion rates, falling back to constant rates: %v", err)
+	var err error
+	for _, source := range rc.sources {
+		var rates *Rates
+		if rates, err = source.RateSource.Fetch(); err == nil {
+			source.rates.Store(rates)
+			source.lastUpdated.Store(rc.time.Now())
+			return nil
+		}
+
+		if rc.checkStaleRates(source) {
+			source.clearRates()
+			glog.Errorf("Error updating conversion rates from %s, its rates are stale and won't be used: %v", source.RateSource.Name(), err)
 		} else {
-			glog.Errorf("Error updating conversion rates: %v", err)
+			glog.Errorf("Error updating conversion rates from %s: %v", source.RateSource.Name(), err)
 		}
 	}
-
 	return err
 }
 
@@ -96,54 +86,110 @@ func (rc *RateConverter) Run() error {
 	return rc.update()
 }
 
+// activeSource returns the source whose rates are used, or nil if constant rates are used
+func (rc *RateConverter) activeSource() *rateSourceState {
+	for _, source := range rc.sources {
+		if source.currentRates() != nil {
+			return source
+		}
+	}
+	return nil
+}
+
 // LastUpdated returns time when currencies rates were updated
 func (rc *RateConverter) LastUpdated() time.Time {
-	if lastUpdated := rc.lastUpdated.Load(); lastUpdated != nil {
-		return lastUpdated.(time.Time)
+	if source := rc.activeSource(); source != nil {
+		return source.lastUpdatedTime()
 	}
-	return time.Time{}
+
+	var lastUpdated time.Time
+	for _, source := range rc.sources {
+		if updated := source.lastUpdatedTime(); updated.After(lastUpdated) {
+			lastUpdated = updated
+		}
+	}
+	return lastUpdated
 }
 
 // Rates returns current conversions rates
 func (rc *RateConverter) Rates() Conversions {
-	// atomic.Value field rates is an empty interface and will be of type *Rates the first time rates are stored
-	// or nil if the rates have never been stored
-	if rates := rc.rates.Load(); rates != (*Rates)(nil) && rates != nil {
-		return rates.(*Rates)
+	if source := rc.activeSource(); source != nil {
+		return source.currentRates()
 	}
 	return rc.constantRates
 }
 
-// clearRates sets the rates to nil
-func (rc *RateConverter) clearRates() {
-	// atomic.Value field rates must be of type *Rates so we cast nil to that type
-	rc.rates.Store((*Rates)(nil))
-}
-
 // checkStaleRates checks if loaded third party conversion rates are stale
-func (rc *RateConverter) checkStaleRates() bool {
-	if rc.staleRatesThreshold <= 0 {
+func (rc *RateConverter) checkStaleRates(source *rateSourceState) bool {
+	if source.StaleRatesThreshold <= 0 {
 		return false
 	}
 
 	currentTime := rc.time.Now().UTC()
-	if lastUpdated := rc.lastUpdated.Load(); lastUpdated != nil {
+	if lastUpdated := source.lastUpdated.Load(); lastUpdated != nil {
 		delta := currentTime.Sub(lastUpdated.(time.Time).UTC())
-		if delta.Seconds() > rc.staleRatesThreshold.Seconds() {
+		if delta.Seconds() > source.StaleRatesThreshold.Seconds() {
 			return true
 		}
 	}
 	return false
 }
 
-// GetInfo returns setup information about the converter
+// currentRates returns the rates of the source, or nil if there aren't any
+func (s *rateSourceState) currentRates() *Rates {
+	// atomic.Value field rates is an empty interface and will be of type *Rates the first time rates are stored
+	// or nil if the rates have never been stored
+	if rates := s.rates.Load(); rates != nil {
+		return rates.(*Rates)
+	}
+	return nil
+}
+
+func (s *rateSourceState) lastUpdatedTime() time.Time {
+	if lastUpdated := s.lastUpdated.Load(); lastUpdated != nil {
+		return lastUpdated.(time.Time)
+	}
+	return time.Time{}
+}
+
+// clearRates sets the rates to nil
+func (s *rateSourceState) clearRates() {
+	// atomic.Value field rates must be of type *Rates so we cast nil to that type
+	s.rates.Store((*Rates)(nil))
+}
+
+// sourceInfo describes one of the sources of a RateConverter.
+type sourceInfo struct {
+	Source      string    `json:"source"`
+	Active      bool      `json:"active"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// GetInfo returns setup information about the converter. The source is the one whose rates are used,
+// and the additional info lists all of the sources.
 func (rc *RateConverter) GetInfo() ConverterInfo {
-	var rates *map[string]map[string]float64
-	rates = rc.Rates().GetRates()
+	active := rc.activeSource()
+	sources := make([]sourceInfo, len(rc.sources))
+	for i, source := range rc.sources {
+		sources[i] = sourceInfo{
+			Source:      source.RateSource.Name(),
+			Active:      source == active,
+			LastUpdated: source.lastUpdatedTime(),
+		}
+	}
+
+	var name string
+	if active != nil {
+		name = active.RateSource.Name()
+	} else if len(rc.sources) > 0 {
+		name = rc.sources[0].RateSource.Name()
+	}
+
 	return converterInfo{
-		source:      rc.syncSourceURL,
-		lastUpdated: rc.LastUpdated(),
-		rates:       rates,
+		source:         name,
+		lastUpdated:    rc.LastUpdated(),
+		rates:          rc.Rates().GetRates(),
+		additionalInfo: map[string]interface{}{"sources": sources},
 	}
 }
 
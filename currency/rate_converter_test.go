@@ -1,6 +1,7 @@
 package currency
 
 import (
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -383,3 +384,68 @@ func (m *mockHttpClient) Do(req *http.Request) (*http.Response, error) {
 		Body:       ioutil.NopCloser(strings.NewReader(m.responseBody)),
 	}, nil
 }
+
+type fakeRateSource struct {
+	name  string
+	rates *Rates
+	err   error
+}
+
+func (s *fakeRateSource) Name() string {
+	return s.name
+}
+
+func (s *fakeRateSource) Fetch() (*Rates, error) {
+	return s.rates, s.err
+}
+
+func TestSourceFailover(t *testing.T) {
+	primaryRates := NewRates(time.Time{}, map[string]map[string]float64{"USD": {"GBP": 0.77}})
+	backupRates := NewRates(time.Time{}, map[string]map[string]float64{"USD": {"GBP": 0.8}})
+	primary := &fakeRateSource{name: "primary", rates: primaryRates}
+	backup := &fakeRateSource{name: "backup", err: errors.New("not fetched yet")}
+	initialFakeTime := time.Date(2018, time.September, 12, 30, 0, 0, 0, time.UTC)
+	fakeTime := &FakeTime{time: initialFakeTime}
+
+	currencyConverter := NewRateConverterFromSources([]Source{
+		{RateSource: primary, StaleRatesThreshold: 30 * time.Second},
+		{RateSource: backup},
+	})
+	currencyConverter.time = fakeTime
+
+	assert.Nil(t, currencyConverter.Run())
+	assert.Equal(t, primaryRates, currencyConverter.Rates())
+	assert.Equal(t, "primary", currencyConverter.GetInfo().Source())
+
+	// The primary source fails, but its rates aren't stale yet
+	primary.err = errors.New("primary is down")
+	backup.rates, backup.err = backupRates, nil
+	fakeTime.time = initialFakeTime.Add(10 * time.Second)
+	assert.Nil(t, currencyConverter.Run(), "The update should succeed as long as one source works")
+	assert.Equal(t, primaryRates, currencyConverter.Rates())
+	assert.Equal(t, initialFakeTime, currencyConverter.LastUpdated())
+
+	// The primary rates go stale, so the backup takes over
+	fakeTime.time = initialFakeTime.Add(31 * time.Second)
+	assert.Nil(t, currencyConverter.Run())
+	assert.Equal(t, backupRates, currencyConverter.Rates())
+	assert.Equal(t, fakeTime.time, currencyConverter.LastUpdated())
+	info := currencyConverter.GetInfo()
+	assert.Equal(t, "backup", info.Source())
+	assert.Equal(t, map[string]interface{}{"sources": []sourceInfo{
+		{Source: "primary", LastUpdated: initialFakeTime},
+		{Source: "backup", Active: true, LastUpdated: fakeTime.time},
+	}}, info.AdditionalInfo())
+
+	// The primary source comes back
+	primary.err = nil
+	assert.Nil(t, currencyConverter.Run())
+	assert.Equal(t, primaryRates, currencyConverter.Rates())
+
+	// All the sources fail
+	primary.err = errors.New("primary is down")
+	backup.err = errors.New("backup is down")
+	fakeTime.time = fakeTime.time.Add(time.Minute)
+	assert.NotNil(t, currencyConverter.Run())
+	assert.Equal(t, backupRates, currencyConverter.Rates(), "The backup rates should be used since they never go stale")
+}
@@ -0,0 +1,164 @@
+package currency
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-server/errortypes"
+)
+
+// RateSource is a place the currencies conversion rates can be fetched from.
+type RateSource interface {
+	// Name describes the source, such as its URL or path.
+	Name() string
+	Fetch() (*Rates, error)
+}
+
+// jsonRateSource fetches rates in the format of https://cdn.jsdelivr.net/gh/prebid/currency-file@1/latest.json
+type jsonRateSource struct {
+	httpClient httpClient
+	url        string
+}
+
+// NewJSONRateSource returns a source which fetches rates in the Prebid currency file format from the URL.
+func NewJSONRateSource(httpClient httpClient, url string) RateSource {
+	return &jsonRateSource{httpClient: httpClient, url: url}
+}
+
+func (s *jsonRateSource) Name() string {
+	return s.url
+}
+
+func (s *jsonRateSource) Fetch() (*Rates, error) {
+	body, err := httpGet(s.httpClient, s.url)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := &Rates{}
+	if err := json.Unmarshal(body, rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// ecbRateSource fetches rates in the format of the daily reference rates of the European Central Bank,
+// such as https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml
+type ecbRateSource struct {
+	httpClient httpClient
+	url        string
+}
+
+// NewECBRateSource returns a source which fetches rates in the XML format of the European Central Bank from the URL.
+func NewECBRateSource(httpClient httpClient, url string) RateSource {
+	return &ecbRateSource{httpClient: httpClient, url: url}
+}
+
+func (s *ecbRateSource) Name() string {
+	return s.url
+}
+
+func (s *ecbRateSource) Fetch() (*Rates, error) {
+	body, err := httpGet(s.httpClient, s.url)
+	if err != nil {
+		return nil, err
+	}
+	return parseECBRates(body)
+}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// parseECBRates reads the rates of the latest day in the feed. The feed only has rates from EUR, so the rates
+// between the other currencies are worked out from them.
+func parseECBRates(body []byte) (*Rates, error) {
+	envelope := ecbEnvelope{}
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	if len(envelope.Days) == 0 || len(envelope.Days[0].Rates) == 0 {
+		return nil, &errortypes.BadServerResponse{Message: "The currency rates feed has no rates"}
+	}
+	day := envelope.Days[0]
+
+	eurRates := map[string]float64{"EUR": 1}
+	for _, rate := range day.Rates {
+		if rate.Rate <= 0 {
+			return nil, &errortypes.BadServerResponse{Message: fmt.Sprintf("The currency rates feed has an invalid rate for %s", rate.Currency)}
+		}
+		eurRates[strings.ToUpper(rate.Currency)] = rate.Rate
+	}
+
+	conversions := make(map[string]map[string]float64, len(eurRates))
+	for from, fromRate := range eurRates {
+		conversions[from] = make(map[string]float64, len(eurRates)-1)
+		for to, toRate := range eurRates {
+			if from != to {
+				conversions[from][to] = toRate / fromRate
+			}
+		}
+	}
+
+	dataAsOf, _ := time.Parse("2006-01-02", day.Time)
+	return NewRates(dataAsOf, conversions), nil
+}
+
+// fileRateSource reads rates in the Prebid currency file format from a local file. The file is read again
+// on every fetch, so it can be replaced while the server runs.
+type fileRateSource struct {
+	path string
+}
+
+// NewFileRateSource returns a source which reads rates in the Prebid currency file format from the file.
+func NewFileRateSource(path string) RateSource {
+	return &fileRateSource{path: path}
+}
+
+func (s *fileRateSource) Name() string {
+	return "file://" + s.path
+}
+
+func (s *fileRateSource) Fetch() (*Rates, error) {
+	body, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := &Rates{}
+	if err := json.Unmarshal(body, rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+func httpGet(client httpClient, url string) ([]byte, error) {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		message := fmt.Sprintf("The currency rates request failed with status code %d", response.StatusCode)
+		return nil, &errortypes.BadServerResponse{Message: message}
+	}
+
+	return ioutil.ReadAll(response.Body)
+}
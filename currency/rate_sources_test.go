@@ -0,0 +1,83 @@
+package currency
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ecbMockRates = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2020-07-01">
+			<Cube currency="USD" rate="1.125"/>
+			<Cube currency="GBP" rate="0.9"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestECBRateSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(ecbMockRates))
+	}))
+	defer server.Close()
+
+	rates, err := NewECBRateSource(&http.Client{}, server.URL).Fetch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC), rates.DataAsOf)
+	assert.Equal(t, 1.125, rates.Conversions["EUR"]["USD"])
+	rate, err := rates.GetRate("USD", "GBP")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.8, rate, 0.000001, "The rates between other currencies should be worked out from the EUR rates")
+	rate, err = rates.GetRate("GBP", "EUR")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1/0.9, rate, 0.000001)
+}
+
+func TestECBRateSourceErrors(t *testing.T) {
+	testCases := []struct {
+		description string
+		body        string
+	}{
+		{description: "Invalid XML", body: `<Cube`},
+		{description: "No rates", body: `<Envelope><Cube><Cube time="2020-07-01"></Cube></Cube></Envelope>`},
+		{description: "Negative rate", body: `<Envelope><Cube><Cube time="2020-07-01"><Cube currency="USD" rate="-1"/></Cube></Cube></Envelope>`},
+	}
+
+	for _, test := range testCases {
+		_, err := parseECBRates([]byte(test.body))
+		assert.Error(t, err, test.description)
+	}
+}
+
+func TestFileRateSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "currency")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rates.json")
+	source := NewFileRateSource(path)
+
+	_, err = source.Fetch()
+	assert.Error(t, err, "A missing file should be an error")
+
+	assert.NoError(t, ioutil.WriteFile(path, getMockRates(), 0644))
+	rates, err := source.Fetch()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.77208, rates.Conversions["USD"]["GBP"])
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"dataAsOf":"2018-09-13","conversions":{"USD":{"GBP":0.8}}}`), 0644))
+	rates, err = source.Fetch()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.8, rates.Conversions["USD"]["GBP"], "The file should be read again on every fetch")
+	assert.Equal(t, "file://"+path, source.Name())
+}
@@ -0,0 +1,135 @@
+package devicedetection
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+)
+
+// Device holds what a Detector found out about a device. The fields it couldn't work out are left empty.
+type Device struct {
+	OS         string
+	OSV        string
+	Make       string
+	Model      string
+	DeviceType openrtb.DeviceType
+}
+
+// Detector works out what a device is from its user agent.
+// Implementations must be threadsafe, and will be shared across many goroutines.
+type Detector interface {
+	Detect(ua string) Device
+}
+
+// NewDetector returns the built-in detector with the rule set of the config. It returns nil if device
+// detection is disabled.
+func NewDetector(cfg config.DeviceDetection) (Detector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return LoadRuleSet(cfg.RulesFile)
+}
+
+// RuleSet is a Detector which matches the user agent against a list of rules.
+//
+// Each field of the device comes from the first rule which matches and sets it. A rule only sets osv if it
+// doesn't name another os than the one already found, and model if it doesn't name another make.
+type RuleSet struct {
+	rules []rule
+}
+
+type rule struct {
+	pattern    *regexp.Regexp
+	os         string
+	osv        string
+	make       string
+	model      string
+	deviceType openrtb.DeviceType
+}
+
+// ruleJSON is a rule as it's written in the rule set file. The os, osv, make and model may refer to
+// the submatches of the pattern, such as $1.
+type ruleJSON struct {
+	UA         string             `json:"ua"`
+	OS         string             `json:"os"`
+	OSV        string             `json:"osv"`
+	Make       string             `json:"make"`
+	Model      string             `json:"model"`
+	DeviceType openrtb.DeviceType `json:"devicetype"`
+}
+
+// LoadRuleSet reads a rule set from a JSON file, such as static/device-detection/rules.json.
+func LoadRuleSet(path string) (*RuleSet, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewRuleSet(contents)
+}
+
+// NewRuleSet parses a rule set in the JSON format of LoadRuleSet.
+func NewRuleSet(rulesJSON []byte) (*RuleSet, error) {
+	file := struct {
+		Rules []ruleJSON `json:"rules"`
+	}{}
+	if err := json.Unmarshal(rulesJSON, &file); err != nil {
+		return nil, fmt.Errorf("invalid device detection rules: %v", err)
+	}
+
+	rules := make([]rule, 0, len(file.Rules))
+	for i, r := range file.Rules {
+		pattern, err := regexp.Compile(r.UA)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d].ua is not a valid regular expression: %v", i, err)
+		}
+		rules = append(rules, rule{
+			pattern:    pattern,
+			os:         r.OS,
+			osv:        r.OSV,
+			make:       r.Make,
+			model:      r.Model,
+			deviceType: r.DeviceType,
+		})
+	}
+	return &RuleSet{rules: rules}, nil
+}
+
+// Detect implements Detector.
+func (rs *RuleSet) Detect(ua string) Device {
+	device := Device{}
+	if ua == "" {
+		return device
+	}
+	for _, r := range rs.rules {
+		submatches := r.pattern.FindStringSubmatchIndex(ua)
+		if submatches == nil {
+			continue
+		}
+		expand := func(template string) string {
+			return string(r.pattern.ExpandString(nil, template, ua, submatches))
+		}
+
+		os := expand(r.os)
+		if device.OS == "" {
+			device.OS = os
+		}
+		if device.OSV == "" && (os == "" || os == device.OS) {
+			device.OSV = expand(r.osv)
+		}
+		deviceMake := expand(r.make)
+		if device.Make == "" {
+			device.Make = deviceMake
+		}
+		if device.Model == "" && (deviceMake == "" || deviceMake == device.Make) {
+			device.Model = expand(r.model)
+		}
+		if device.DeviceType == 0 {
+			device.DeviceType = r.deviceType
+		}
+	}
+	return device
+}
@@ -0,0 +1,89 @@
+package devicedetection
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltInRules(t *testing.T) {
+	rules, err := LoadRuleSet("../static/device-detection/rules.json")
+	if err != nil {
+		t.Fatalf("The built-in rules should load: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		ua          string
+		expected    Device
+	}{
+		{
+			description: "iPhone",
+			ua:          "Mozilla/5.0 (iPhone; CPU iPhone OS 14_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.0.1 Mobile/15E148 Safari/604.1",
+			expected:    Device{OS: "iOS", OSV: "14.2", Make: "Apple", Model: "iPhone", DeviceType: openrtb.DeviceTypePhone},
+		},
+		{
+			description: "iPad",
+			ua:          "Mozilla/5.0 (iPad; CPU OS 13_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.0.4 Mobile/15E148 Safari/604.1",
+			expected:    Device{OS: "iOS", OSV: "13.3", Make: "Apple", Model: "iPad", DeviceType: openrtb.DeviceTypeTablet},
+		},
+		{
+			description: "Samsung phone",
+			ua:          "Mozilla/5.0 (Linux; Android 10; SM-G973F) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/86.0.4240.198 Mobile Safari/537.36",
+			expected:    Device{OS: "Android", OSV: "10", Make: "Samsung", Model: "SM-G973F", DeviceType: openrtb.DeviceTypePhone},
+		},
+		{
+			description: "Android tablet",
+			ua:          "Mozilla/5.0 (Linux; Android 9; SM-T510) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/86.0.4240.198 Safari/537.36",
+			expected:    Device{OS: "Android", OSV: "9", Make: "Samsung", Model: "SM-T510", DeviceType: openrtb.DeviceTypeTablet},
+		},
+		{
+			description: "Windows desktop",
+			ua:          "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/86.0.4240.198 Safari/537.36",
+			expected:    Device{OS: "Windows", OSV: "10.0", DeviceType: openrtb.DeviceTypePersonalComputer},
+		},
+		{
+			description: "Mac desktop",
+			ua:          "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.0.1 Safari/605.1.15",
+			expected:    Device{OS: "macOS", OSV: "10.15", Make: "Apple", DeviceType: openrtb.DeviceTypePersonalComputer},
+		},
+		{
+			description: "Samsung smart TV",
+			ua:          "Mozilla/5.0 (SMART-TV; Linux; Tizen 5.0) AppleWebKit/538.1 (KHTML, like Gecko) Version/5.0 NativeTVAds Safari/538.1",
+			expected:    Device{OS: "Tizen", OSV: "5.0", DeviceType: openrtb.DeviceTypeConnectedTV},
+		},
+		{
+			description: "Fire TV",
+			ua:          "Mozilla/5.0 (Linux; Android 7.1.2; AFTMM Build/NS6265; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/70.0.3538.110 Mobile Safari/537.36",
+			expected:    Device{OS: "Fire OS", Make: "Amazon", Model: "Fire TV", DeviceType: openrtb.DeviceTypeSetTopBox},
+		},
+		{
+			description: "Unknown",
+			ua:          "curl/7.64.1",
+			expected:    Device{},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, rules.Detect(test.ua), test.description)
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	_, err := NewRuleSet([]byte(`{"rules":[{"ua":"iPhone("}]}`))
+	assert.EqualError(t, err, "rules[0].ua is not a valid regular expression: error parsing regexp: missing closing ): `iPhone(`")
+
+	_, err = NewRuleSet([]byte(`{"rules":{}}`))
+	assert.Error(t, err)
+}
+
+func TestNewDetector(t *testing.T) {
+	detector, err := NewDetector(config.DeviceDetection{Enabled: false})
+	assert.NoError(t, err)
+	assert.Nil(t, detector)
+
+	_, err = NewDetector(config.DeviceDetection{Enabled: true, RulesFile: "does-not-exist.json"})
+	assert.Error(t, err)
+}
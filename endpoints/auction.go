@@ -194,10 +194,10 @@ func (a *auction) shouldUsersync(ctx context.Context, bidder openrtb_ext.BidderN
 		gdprSignal = signal
 	}
 
-	if canSync, err := a.gdprPerms.HostCookiesAllowed(ctx, gdprSignal, gdprPrivacyPolicy.Consent); err != nil || !canSync {
+	if canSync, err := a.gdprPerms.HostCookiesAllowed(ctx, gdprSignal, gdprPrivacyPolicy.Consent, config.AccountGDPR{}); err != nil || !canSync {
 		return false
 	}
-	canSync, err := a.gdprPerms.BidderSyncAllowed(ctx, bidder, gdprSignal, gdprPrivacyPolicy.Consent)
+	canSync, err := a.gdprPerms.BidderSyncAllowed(ctx, bidder, gdprSignal, gdprPrivacyPolicy.Consent, config.AccountGDPR{})
 	return canSync && err == nil
 }
 
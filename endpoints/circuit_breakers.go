@@ -0,0 +1,33 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/circuitbreaker"
+)
+
+// circuitBreakersInfo holds the state of the bidder circuit breakers.
+type circuitBreakersInfo struct {
+	Enabled  bool                    `json:"enabled"`
+	Circuits []circuitbreaker.Status `json:"circuits,omitempty"`
+}
+
+// NewCircuitBreakersEndpoint returns the state of the bidder circuit breakers.
+func NewCircuitBreakersEndpoint(breakers *circuitbreaker.Breakers) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		jsonOutput, err := json.Marshal(circuitBreakersInfo{
+			Enabled:  breakers != nil,
+			Circuits: breakers.Status(),
+		})
+		if err != nil {
+			glog.Errorf("/bidders/circuit_breakers Critical error when trying to marshal circuitBreakersInfo: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jsonOutput)
+	}
+}
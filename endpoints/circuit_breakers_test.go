@@ -0,0 +1,47 @@
+package endpoints
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prebid/prebid-server/circuitbreaker"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakersEndpoint(t *testing.T) {
+	breakers := circuitbreaker.NewBreakers(config.CircuitBreaker{
+		Enabled:            true,
+		ErrorThreshold:     5,
+		OpenIntervalMillis: 1000,
+		HalfOpenProbes:     1,
+	})
+	breakers.Record(openrtb_ext.BidderAppnexus, "", false)
+
+	testCases := []struct {
+		description  string
+		breakers     *circuitbreaker.Breakers
+		expectedBody string
+	}{
+		{
+			description:  "Disabled",
+			breakers:     nil,
+			expectedBody: `{"enabled":false}`,
+		},
+		{
+			description:  "Enabled",
+			breakers:     breakers,
+			expectedBody: `{"enabled":true,"circuits":[{"bidder":"appnexus","state":"closed","failures":1}]}`,
+		},
+	}
+
+	for _, test := range testCases {
+		w := httptest.NewRecorder()
+
+		NewCircuitBreakersEndpoint(test.breakers)(w, nil)
+
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"), test.description)
+		assert.JSONEq(t, test.expectedBody, w.Body.String(), test.description)
+	}
+}
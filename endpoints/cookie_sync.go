@@ -13,15 +13,22 @@ import (
 	"github.com/buger/jsonparser"
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
+	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/geolocation"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/coppa"
 	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	gppPrivacy "github.com/prebid/prebid-server/privacy/gpp"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
+	"github.com/prebid/prebid-server/util/httputil"
+	"github.com/prebid/prebid-server/util/iputil"
 )
 
 func NewCookieSyncEndpoint(
@@ -30,7 +37,9 @@ func NewCookieSyncEndpoint(
 	syncPermissions gdpr.Permissions,
 	metrics metrics.MetricsEngine,
 	pbsAnalytics analytics.PBSAnalyticsModule,
-	bidderMap map[string]openrtb_ext.BidderName) httprouter.Handle {
+	bidderMap map[string]openrtb_ext.BidderName,
+	geoLookup geolocation.Lookup,
+	accounts stored_requests.AccountFetcher) httprouter.Handle {
 
 	bidderLookup := make(map[string]struct{})
 	for k := range bidderMap {
@@ -39,6 +48,7 @@ func NewCookieSyncEndpoint(
 
 	deps := &cookieSyncDeps{
 		syncers:         syncers,
+		cfg:             cfg,
 		hostCookie:      &cfg.HostCookie,
 		gDPR:            &cfg.GDPR,
 		syncPermissions: syncPermissions,
@@ -46,12 +56,19 @@ func NewCookieSyncEndpoint(
 		pbsAnalytics:    pbsAnalytics,
 		enforceCCPA:     cfg.CCPA.Enforce,
 		bidderLookup:    bidderLookup,
+		geoLookup:       geoLookup,
+		accounts:        accounts,
+		ipValidator: iputil.PublicNetworkIPValidator{
+			IPv4PrivateNetworks: cfg.RequestValidation.IPv4PrivateNetworksParsed,
+			IPv6PrivateNetworks: cfg.RequestValidation.IPv6PrivateNetworksParsed,
+		},
 	}
 	return deps.Endpoint
 }
 
 type cookieSyncDeps struct {
 	syncers         map[openrtb_ext.BidderName]usersync.Usersyncer
+	cfg             *config.Configuration
 	hostCookie      *config.HostCookie
 	gDPR            *config.GDPR
 	syncPermissions gdpr.Permissions
@@ -59,6 +76,12 @@ type cookieSyncDeps struct {
 	pbsAnalytics    analytics.PBSAnalyticsModule
 	enforceCCPA     bool
 	bidderLookup    map[string]struct{}
+	// geoLookup finds the country the request comes from, to decide whether GDPR applies if the request
+	// doesn't say. It's nil if geolocation is disabled.
+	geoLookup   geolocation.Lookup
+	ipValidator iputil.IPValidator
+	// accounts are looked up for their activity controls
+	accounts stored_requests.AccountFetcher
 }
 
 func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -96,8 +119,9 @@ func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ h
 		return
 	}
 
+	country := requestCountry(r, deps.geoLookup, deps.ipValidator)
 	parsedReq := &cookieSyncRequest{}
-	if err := parseRequest(parsedReq, bodyBytes, deps.gDPR.UsersyncIfAmbiguous); err != nil {
+	if err := parseRequest(parsedReq, bodyBytes, deps.usersyncIfAmbiguous(country)); err != nil {
 		co.Status = http.StatusBadRequest
 		co.Errors = append(co.Errors, err)
 		http.Error(w, co.Errors[len(co.Errors)-1].Error(), co.Status)
@@ -127,6 +151,10 @@ func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ h
 		adapterSyncs[openrtb_ext.BidderName(b)] = true
 	}
 
+	account := syncAccount(r.Context(), deps.cfg, deps.accounts, parsedReq.Account)
+	// The Global Privacy Control signal opts the user out only if the host or account says so
+	parsedReq.gpc = r.Header.Get("Sec-GPC") == "1" && account.CCPA.GPC
+
 	privacyPolicy := privacy.Policies{
 		GDPR: gdprPrivacy.Policy{
 			Signal:  gdprToString(parsedReq.GDPR),
@@ -134,10 +162,16 @@ func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ h
 		},
 		CCPA: ccpa.Policy{
 			Consent: parsedReq.USPrivacy,
+			GPC:     parsedReq.gpc,
 		},
+		GPP:   parsedReq.gppPolicy,
+		COPPA: coppa.Policy{Enforce: parsedReq.COPPA == 1},
 	}
 
-	parsedReq.filterForGDPR(deps.syncPermissions)
+	activityControl := privacy.NewActivityControl(&account.Activities)
+	parsedReq.filterForActivities(activityControl, privacy.ActivityScope{GDPR: *parsedReq.GDPR == 1, Country: country})
+	parsedReq.filterForCOPPA(privacyPolicy.COPPA)
+	parsedReq.filterForGDPR(deps.syncPermissions, account.GDPR)
 
 	if deps.enforceCCPA {
 		parsedReq.filterForCCPA(deps.bidderLookup)
@@ -181,11 +215,54 @@ func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ h
 	enc.Encode(csResp)
 }
 
+// usersyncIfAmbiguous tells whether user syncs are allowed if the request doesn't say whether GDPR applies.
+// The country the request comes from decides, if it's known.
+func (deps *cookieSyncDeps) usersyncIfAmbiguous(country string) bool {
+	if country == "" {
+		return deps.gDPR.UsersyncIfAmbiguous
+	}
+	return deps.gDPR.UsersyncIfAmbiguousInCountry(country)
+}
+
+// requestCountry returns the ISO 3166-1 alpha-3 code of the country the request comes from, or an empty
+// string if it's unknown or geolocation is disabled.
+func requestCountry(r *http.Request, geoLookup geolocation.Lookup, ipValidator iputil.IPValidator) string {
+	if geoLookup == nil {
+		return ""
+	}
+	ip, _ := httputil.FindIP(r, ipValidator)
+	if ip == nil {
+		return ""
+	}
+	geo, err := geoLookup.Lookup(ip)
+	if err != nil || geo == nil {
+		return ""
+	}
+	return geo.Country
+}
+
+// syncAccount returns the account of a user sync request, for its privacy settings. The host's defaults are
+// used if the request doesn't name an account, or the account can't be used.
+func syncAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_requests.AccountFetcher, accountID string) *config.Account {
+	if accountID == "" {
+		accountID = metrics.PublisherUnknown
+	}
+	account, errs := accountService.GetAccount(ctx, cfg, fetcher, accountID)
+	if len(errs) > 0 || account == nil {
+		return &cfg.AccountDefaults
+	}
+	return account
+}
+
 func parseRequest(parsedReq *cookieSyncRequest, bodyBytes []byte, usersyncIfAmbiguous bool) error {
 	if err := json.Unmarshal(bodyBytes, parsedReq); err != nil {
 		return fmt.Errorf("JSON parsing failed: %s", err.Error())
 	}
 
+	if err := parsedReq.applyGPP(); err != nil {
+		return err
+	}
+
 	if parsedReq.GDPR != nil && *parsedReq.GDPR == 1 && parsedReq.Consent == "" {
 		return errors.New("gdpr_consent is required if gdpr=1")
 	}
@@ -230,7 +307,40 @@ type cookieSyncRequest struct {
 	GDPR      *int     `json:"gdpr"`
 	Consent   string   `json:"gdpr_consent"`
 	USPrivacy string   `json:"us_privacy"`
+	GPP       string   `json:"gpp"`
+	GPPSID    string   `json:"gpp_sid"`
+	COPPA     int      `json:"coppa"`
+	Account   string   `json:"account"`
 	Limit     int      `json:"limit"`
+
+	gppPolicy       gppPrivacy.Policy
+	gppParsedPolicy gppPrivacy.ParsedPolicy
+	gpc             bool
+}
+
+// applyGPP parses the GPP string, and fills in the GDPR signal, TCF consent and US Privacy string it derives
+// unless the request already has them.
+func (req *cookieSyncRequest) applyGPP() error {
+	sids, err := gppPrivacy.ParseSIDs(req.GPPSID)
+	if err != nil {
+		return err
+	}
+	req.gppPolicy = gppPrivacy.Policy{Consent: req.GPP, SIDs: sids}
+	if req.gppParsedPolicy, err = req.gppPolicy.Parse(); err != nil {
+		return errors.New("gpp must be a valid GPP string")
+	}
+
+	if signal := req.gppParsedPolicy.GDPRSignal(); signal != nil && req.GDPR == nil {
+		gdpr := int(*signal)
+		req.GDPR = &gdpr
+	}
+	if req.Consent == "" {
+		req.Consent = req.gppParsedPolicy.TCFConsent()
+	}
+	if req.USPrivacy == "" {
+		req.USPrivacy = req.gppParsedPolicy.USPrivacy()
+	}
+	return nil
 }
 
 func (req *cookieSyncRequest) filterExistingSyncs(valid map[openrtb_ext.BidderName]usersync.Usersyncer, cookie *usersync.PBSCookie, needSyncupForSameSite bool) {
@@ -243,19 +353,40 @@ func (req *cookieSyncRequest) filterExistingSyncs(valid map[openrtb_ext.BidderNa
 	}
 }
 
-func (req *cookieSyncRequest) filterForGDPR(permissions gdpr.Permissions) {
+// filterForActivities removes the bidders which the activity controls don't allow to sync.
+func (req *cookieSyncRequest) filterForActivities(control privacy.ActivityControl, scope privacy.ActivityScope) {
+	for i := 0; i < len(req.Bidders); i++ {
+		scope.Bidder = req.Bidders[i]
+		if !control.Allow(privacy.ActivitySyncUser, scope) {
+			req.Bidders = append(req.Bidders[:i], req.Bidders[i+1:]...)
+			i--
+		}
+	}
+}
+
+// filterForCOPPA removes every bidder, since no user syncs are allowed for requests which COPPA applies to.
+func (req *cookieSyncRequest) filterForCOPPA(policy coppa.Policy) {
+	for i := 0; i < len(req.Bidders); i++ {
+		if policy.ShouldEnforce(req.Bidders[i]) {
+			req.Bidders = append(req.Bidders[:i], req.Bidders[i+1:]...)
+			i--
+		}
+	}
+}
+
+func (req *cookieSyncRequest) filterForGDPR(permissions gdpr.Permissions, account config.AccountGDPR) {
 	if req.GDPR != nil && *req.GDPR == 0 {
 		return
 	}
 
 	// At this point we know the gdpr signal is Yes because the upstream call to parseRequest already denormalized the signal if it was ambiguous
-	if allowSync, err := permissions.HostCookiesAllowed(context.Background(), gdpr.SignalYes, req.Consent); err != nil || !allowSync {
+	if allowSync, err := permissions.HostCookiesAllowed(context.Background(), gdpr.SignalYes, req.Consent, account); err != nil || !allowSync {
 		req.Bidders = nil
 		return
 	}
 
 	for i := 0; i < len(req.Bidders); i++ {
-		if allowSync, err := permissions.BidderSyncAllowed(context.Background(), openrtb_ext.BidderName(req.Bidders[i]), gdpr.SignalYes, req.Consent); err != nil || !allowSync {
+		if allowSync, err := permissions.BidderSyncAllowed(context.Background(), openrtb_ext.BidderName(req.Bidders[i]), gdpr.SignalYes, req.Consent, account); err != nil || !allowSync {
 			req.Bidders = append(req.Bidders[:i], req.Bidders[i+1:]...)
 			i--
 		}
@@ -263,12 +394,12 @@ func (req *cookieSyncRequest) filterForGDPR(permissions gdpr.Permissions) {
 }
 
 func (req *cookieSyncRequest) filterForCCPA(bidderMap map[string]struct{}) {
-	ccpaPolicy := &ccpa.Policy{Consent: req.USPrivacy}
+	ccpaPolicy := &ccpa.Policy{Consent: req.USPrivacy, GPC: req.gpc}
 	ccpaParsedPolicy, err := ccpaPolicy.Parse(bidderMap)
 
 	if err == nil {
 		for i := 0; i < len(req.Bidders); i++ {
-			if ccpaParsedPolicy.ShouldEnforce(req.Bidders[i]) {
+			if ccpaParsedPolicy.ShouldEnforce(req.Bidders[i]) || req.gppParsedPolicy.ShouldEnforce(req.Bidders[i]) {
 				req.Bidders = append(req.Bidders[:i], req.Bidders[i+1:]...)
 				i--
 			}
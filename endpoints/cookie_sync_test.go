@@ -2,6 +2,8 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/buger/jsonparser"
 	"github.com/julienschmidt/httprouter"
+	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters/appnexus"
 	"github.com/prebid/prebid-server/adapters/audienceNetwork"
 	"github.com/prebid/prebid-server/adapters/lifestreet"
@@ -20,7 +23,10 @@ import (
 	"github.com/prebid/prebid-server/gdpr"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/usersync"
+	"github.com/prebid/prebid-server/util/iputil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,6 +56,25 @@ func TestGDPRPreventsBidders(t *testing.T) {
 	assert.Equal(t, "no_cookie", parseStatus(t, rr.Body.Bytes()))
 }
 
+func TestGDPRAccountOverrides(t *testing.T) {
+	cfg := &config.Configuration{}
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+	accounts := mockAccountFetcher{
+		"except_pubmatic": json.RawMessage(`{"gdpr":{"purpose1":{"vendor_exceptions":["pubmatic"]}}}`),
+	}
+	perms := mockPermissions(true, map[openrtb_ext.BidderName]usersync.Usersyncer{
+		openrtb_ext.BidderLifestreet: lifestreet.NewLifestreetSyncer(template.Must(template.New("sync").Parse("someurl.com"))),
+	})
+
+	endpoint := NewCookieSyncEndpoint(syncersForTest(), cfg, perms, &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap(), nil, accounts)
+	req, _ := http.NewRequest("POST", "/cookie_sync", strings.NewReader(`{"gdpr":1,"bidders":["appnexus", "pubmatic", "lifestreet"],"gdpr_consent":"BOONs2HOONs2HABABBENAGgAAAAPrABACGA","account":"except_pubmatic"}`))
+	rr := httptest.NewRecorder()
+	endpoint(rr, req, nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.ElementsMatch(t, []string{"lifestreet", "pubmatic"}, parseSyncs(t, rr.Body.Bytes()))
+}
+
 func TestGDPRIgnoredIfZero(t *testing.T) {
 	rr := doPost(`{"gdpr":0,"bidders":["appnexus", "pubmatic"]}`, nil, false, nil)
 	assert.Equal(t, rr.Header().Get("Content-Type"), "application/json; charset=utf-8")
@@ -65,6 +90,111 @@ func TestGDPRConsentRequired(t *testing.T) {
 	assert.Equal(t, "gdpr_consent is required if gdpr=1\n", rr.Body.String())
 }
 
+func TestUsersyncIfAmbiguousFromGeo(t *testing.T) {
+	deps := &cookieSyncDeps{
+		gDPR: &config.GDPR{UsersyncIfAmbiguous: false, EEACountriesMap: map[string]struct{}{"DEU": {}}},
+		geoLookup: mockGeoLookup{
+			"1.2.3.4": {Country: "DEU"},
+			"5.6.7.8": {Country: "USA"},
+		},
+		ipValidator: iputil.PublicNetworkIPValidator{},
+	}
+
+	testCases := []struct {
+		description string
+		ip          string
+		expected    bool
+	}{
+		{description: "EEA country", ip: "1.2.3.4", expected: false},
+		{description: "Other country", ip: "5.6.7.8", expected: true},
+		{description: "Unknown IP address", ip: "9.9.9.9", expected: false},
+	}
+
+	for _, test := range testCases {
+		req, _ := http.NewRequest("POST", "/cookie_sync", nil)
+		req.Header.Set("X-Forwarded-For", test.ip)
+		assert.Equal(t, test.expected, deps.usersyncIfAmbiguous(requestCountry(req, deps.geoLookup, deps.ipValidator)), test.description)
+	}
+
+	deps.geoLookup = nil
+	req, _ := http.NewRequest("POST", "/cookie_sync", nil)
+	req.Header.Set("X-Forwarded-For", "5.6.7.8")
+	assert.False(t, deps.usersyncIfAmbiguous(requestCountry(req, deps.geoLookup, deps.ipValidator)), "Without geolocation, the host default should be used")
+}
+
+func TestCOPPAPreventsBidders(t *testing.T) {
+	rr := doPost(`{"coppa":1,"gdpr":0,"bidders":["appnexus", "pubmatic"]}`, nil, true, nil)
+	assert.Equal(t, rr.Header().Get("Content-Type"), "application/json; charset=utf-8")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, parseSyncs(t, rr.Body.Bytes()))
+	assert.Equal(t, "no_cookie", parseStatus(t, rr.Body.Bytes()))
+}
+
+func TestActivityControlsPreventBidders(t *testing.T) {
+	testCases := []struct {
+		description   string
+		requestBody   string
+		ip            string
+		expectedSyncs []string
+	}{
+		{
+			description:   "Host Rule Denies Bidder",
+			requestBody:   `{"gdpr":0,"bidders":["appnexus", "pubmatic"]}`,
+			ip:            "5.6.7.8",
+			expectedSyncs: []string{"appnexus"},
+		},
+		{
+			description:   "Host Rule Denies Bidder In Another Country",
+			requestBody:   `{"gdpr":0,"bidders":["appnexus", "pubmatic"]}`,
+			ip:            "1.2.3.4",
+			expectedSyncs: []string{"appnexus", "pubmatic"},
+		},
+		{
+			description:   "Account Rules Replace Host Rules",
+			requestBody:   `{"gdpr":0,"bidders":["appnexus", "pubmatic"],"account":"deny_appnexus"}`,
+			ip:            "5.6.7.8",
+			expectedSyncs: []string{"pubmatic"},
+		},
+		{
+			description:   "Unknown Account Uses Host Rules",
+			requestBody:   `{"gdpr":0,"bidders":["appnexus", "pubmatic"],"account":"unknown_account"}`,
+			ip:            "5.6.7.8",
+			expectedSyncs: []string{"appnexus"},
+		},
+		{
+			description:   "Account Default Denies All",
+			requestBody:   `{"gdpr":0,"bidders":["appnexus", "pubmatic"],"account":"deny_all"}`,
+			ip:            "5.6.7.8",
+			expectedSyncs: []string{},
+		},
+	}
+
+	cfg := &config.Configuration{}
+	cfg.AccountDefaults.Activities.SyncUser.Rules = []config.ActivityRule{
+		{Allow: false, Condition: config.ActivityCondition{Bidders: []string{"pubmatic"}, Countries: []string{"USA"}}},
+	}
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+	accounts := mockAccountFetcher{
+		"deny_appnexus": json.RawMessage(`{"activities":{"sync_user":{"rules":[{"allow":false,"condition":{"bidders":["appnexus"]}}]}}}`),
+		"deny_all":      json.RawMessage(`{"activities":{"sync_user":{"default":false,"rules":[]}}}`),
+	}
+	geoLookup := mockGeoLookup{
+		"1.2.3.4": {Country: "DEU"},
+		"5.6.7.8": {Country: "USA"},
+	}
+
+	for _, test := range testCases {
+		endpoint := NewCookieSyncEndpoint(syncersForTest(), cfg, mockPermissions(true, nil), &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap(), geoLookup, accounts)
+		req, _ := http.NewRequest("POST", "/cookie_sync", strings.NewReader(test.requestBody))
+		req.Header.Set("X-Forwarded-For", test.ip)
+		rr := httptest.NewRecorder()
+		endpoint(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code, test.description+":httpResponseCode")
+		assert.ElementsMatch(t, test.expectedSyncs, parseSyncs(t, rr.Body.Bytes()), test.description+":syncs")
+	}
+}
+
 func TestCCPA(t *testing.T) {
 	testCases := []struct {
 		description   string
@@ -120,6 +250,108 @@ func TestCCPA(t *testing.T) {
 	}
 }
 
+func TestGPC(t *testing.T) {
+	testCases := []struct {
+		description   string
+		gpcHeader     string
+		accountGPC    bool
+		expectedSyncs []string
+	}{
+		{
+			description:   "GPC Enabled & Signal Sent",
+			gpcHeader:     "1",
+			accountGPC:    true,
+			expectedSyncs: []string{},
+		},
+		{
+			description:   "GPC Disabled & Signal Sent",
+			gpcHeader:     "1",
+			accountGPC:    false,
+			expectedSyncs: []string{"appnexus"},
+		},
+		{
+			description:   "GPC Enabled & Signal Not Sent",
+			gpcHeader:     "",
+			accountGPC:    true,
+			expectedSyncs: []string{"appnexus"},
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := &config.Configuration{GDPR: config.GDPR{UsersyncIfAmbiguous: true}, CCPA: config.CCPA{Enforce: true}}
+		cfg.AccountDefaults.CCPA.GPC = test.accountGPC
+		endpoint := NewCookieSyncEndpoint(syncersForTest(), cfg, mockPermissions(true, nil), &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap(), nil, empty_fetcher.EmptyFetcher{})
+		req, _ := http.NewRequest("POST", "/cookie_sync", strings.NewReader(`{"bidders":["appnexus"]}`))
+		req.Header.Set("Sec-GPC", test.gpcHeader)
+		rr := httptest.NewRecorder()
+		endpoint(rr, req, nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code, test.description+":httpResponseCode")
+		assert.ElementsMatch(t, test.expectedSyncs, parseSyncs(t, rr.Body.Bytes()), test.description+":syncs")
+	}
+}
+
+func TestGPP(t *testing.T) {
+	testCases := []struct {
+		description     string
+		requestBody     string
+		gdprHostConsent bool
+		expectedCode    int
+		expectedSyncs   []string
+	}{
+		{
+			description:     "US National Opt-Out",
+			requestBody:     `{"bidders":["appnexus"], "gpp":"DBABLA~BVQaAAAAAgA.QA", "gpp_sid":"7"}`,
+			gdprHostConsent: true,
+			expectedCode:    http.StatusOK,
+			expectedSyncs:   []string{},
+		},
+		{
+			description:     "US National No Opt-Out",
+			requestBody:     `{"bidders":["appnexus"], "gpp":"DBABLA~BVQqAAAAAgA.QA", "gpp_sid":"7"}`,
+			gdprHostConsent: true,
+			expectedCode:    http.StatusOK,
+			expectedSyncs:   []string{"appnexus"},
+		},
+		{
+			description:     "TCF EU Section Applies",
+			requestBody:     `{"bidders":["appnexus"], "gpp":"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA", "gpp_sid":"2"}`,
+			gdprHostConsent: false,
+			expectedCode:    http.StatusOK,
+			expectedSyncs:   []string{},
+		},
+		{
+			description:     "TCF EU Section Doesn't Apply",
+			requestBody:     `{"bidders":["appnexus"], "gpp":"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA", "gpp_sid":"6"}`,
+			gdprHostConsent: false,
+			expectedCode:    http.StatusOK,
+			expectedSyncs:   []string{"appnexus"},
+		},
+		{
+			description:     "Invalid GPP String",
+			requestBody:     `{"bidders":["appnexus"], "gpp":"malformed", "gpp_sid":"7"}`,
+			gdprHostConsent: true,
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			description:     "Invalid GPP SID",
+			requestBody:     `{"bidders":["appnexus"], "gpp":"DBABLA~BVQqAAAAAgA.QA", "gpp_sid":"seven"}`,
+			gdprHostConsent: true,
+			expectedCode:    http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		gdpr := config.GDPR{UsersyncIfAmbiguous: true}
+		ccpa := config.CCPA{Enforce: true}
+		rr := doConfigurablePost(test.requestBody, nil, test.gdprHostConsent, syncersForTest(), gdpr, ccpa)
+		assert.Equal(t, test.expectedCode, rr.Code, test.description+":httpResponseCode")
+		if test.expectedCode == http.StatusOK {
+			assert.ElementsMatch(t, test.expectedSyncs, parseSyncs(t, rr.Body.Bytes()), test.description+":syncs")
+		}
+	}
+}
+
 func TestCookieSyncHasCookies(t *testing.T) {
 	rr := doPost(`{"bidders":["appnexus", "audienceNetwork", "random"]}`, map[string]string{
 		"adnxs":           "1234",
@@ -196,7 +428,7 @@ func doConfigurablePost(body string, existingSyncs map[string]string, gdprHostCo
 }
 
 func testableEndpoint(perms gdpr.Permissions, cfgGDPR config.GDPR, cfgCCPA config.CCPA) httprouter.Handle {
-	return NewCookieSyncEndpoint(syncersForTest(), &config.Configuration{GDPR: cfgGDPR, CCPA: cfgCCPA}, perms, &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap())
+	return NewCookieSyncEndpoint(syncersForTest(), &config.Configuration{GDPR: cfgGDPR, CCPA: cfgCCPA}, perms, &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap(), nil, empty_fetcher.EmptyFetcher{})
 }
 
 func syncersForTest() map[openrtb_ext.BidderName]usersync.Usersyncer {
@@ -245,15 +477,36 @@ type gdprPerms struct {
 	allowedBidders map[openrtb_ext.BidderName]usersync.Usersyncer
 }
 
-func (g *gdprPerms) HostCookiesAllowed(ctx context.Context, gdprSignal gdpr.Signal, consent string) (bool, error) {
+func (g *gdprPerms) HostCookiesAllowed(ctx context.Context, gdprSignal gdpr.Signal, consent string, account config.AccountGDPR) (bool, error) {
 	return g.allowHost, nil
 }
 
-func (g *gdprPerms) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal gdpr.Signal, consent string) (bool, error) {
+func (g *gdprPerms) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal gdpr.Signal, consent string, account config.AccountGDPR) (bool, error) {
+	// The account's purpose 1 exceptions stand in for the way it may loosen the host's enforcement
+	for _, exception := range account.Purpose1.VendorExceptions {
+		if exception == bidder {
+			return true, nil
+		}
+	}
 	_, ok := g.allowedBidders[bidder]
 	return ok, nil
 }
 
-func (g *gdprPerms) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal gdpr.Signal, consent string) (bool, bool, bool, error) {
+func (g *gdprPerms) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal gdpr.Signal, consent string, account config.AccountGDPR) (bool, bool, bool, error) {
 	return true, true, true, nil
 }
+
+type mockAccountFetcher map[string]json.RawMessage
+
+func (f mockAccountFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	if account, ok := f[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
+
+type mockGeoLookup map[string]*openrtb.Geo
+
+func (l mockGeoLookup) Lookup(ip net.IP) (*openrtb.Geo, error) {
+	return l[ip.String()], nil
+}
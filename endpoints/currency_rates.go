@@ -56,10 +56,11 @@ func newCurrencyRatesInfo(rateConverter rateConverter, fetchingInterval time.Dur
 }
 
 // NewCurrencyRatesEndpoint returns current currency rates applied by the PBS server.
+// The rates and the source they come from are read on every request, since the converter may fail over
+// to another source at any time.
 func NewCurrencyRatesEndpoint(rateConverter rateConverter, fetchingInterval time.Duration) http.HandlerFunc {
-	currencyRateInfo := newCurrencyRatesInfo(rateConverter, fetchingInterval)
-
 	return func(w http.ResponseWriter, _ *http.Request) {
+		currencyRateInfo := newCurrencyRatesInfo(rateConverter, fetchingInterval)
 		jsonOutput, err := json.Marshal(currencyRateInfo)
 		if err != nil {
 			glog.Errorf("/currency/rates Critical error when trying to marshal currencyRateInfo: %v", err)
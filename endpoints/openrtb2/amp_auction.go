@@ -21,11 +21,13 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/ivt"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	"github.com/prebid/prebid-server/privacy/gdpr"
+	gppPrivacy "github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/usersync"
@@ -54,6 +56,7 @@ func NewAmpEndpoint(
 	disabledBidders map[string]string,
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
+	ivtFilter *ivt.Filter,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
@@ -82,7 +85,9 @@ func NewAmpEndpoint(
 		bidderMap,
 		nil,
 		nil,
-		ipValidator}).AmpAuction), nil
+		ipValidator,
+		nil,
+		ivtFilter}).AmpAuction), nil
 
 }
 
@@ -143,6 +148,18 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 
 	ao.Request = req
 
+	ivtResult := deps.checkInvalidTraffic(req)
+	ao.InvalidTraffic = ivtResult.Detections
+	if ivtResult.Rejected() {
+		ivtErr := ivtResult.Error()
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(fmt.Sprintf("Invalid request: %s\n", ivtErr.Error())))
+		labels.RequestStatus = metrics.RequestStatusInvalidTraffic
+		ao.Status = http.StatusForbidden
+		ao.Errors = append(ao.Errors, ivtErr)
+		return
+	}
+
 	ctx := context.Background()
 	var cancel context.CancelFunc
 	if req.TMax > 0 {
@@ -183,12 +200,13 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	}
 
 	auctionRequest := exchange.AuctionRequest{
-		BidRequest:   req,
-		Account:      *account,
-		UserSyncs:    usersyncs,
-		RequestType:  labels.RType,
-		StartTime:    start,
-		LegacyLabels: labels,
+		BidRequest:     req,
+		Account:        *account,
+		UserSyncs:      usersyncs,
+		RequestType:    labels.RType,
+		StartTime:      start,
+		LegacyLabels:   labels,
+		SkippedBidders: ivtResult.SkippedBidders,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
@@ -332,7 +350,11 @@ func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req
 	}
 
 	// The fetched config becomes the entire OpenRTB request
-	requestJSON := storedRequests[ampParams.StoredRequestID]
+	requestJSON, err := gppPrivacy.MoveToRegsExt(storedRequests[ampParams.StoredRequestID])
+	if err != nil {
+		errs = []error{err}
+		return
+	}
 	if err := json.Unmarshal(requestJSON, req); err != nil {
 		errs = []error{err}
 		return
@@ -405,7 +427,7 @@ func (deps *endpointDeps) overrideWithParams(ampParams amp.Params, req *openrtb.
 		req.Imp[0].TagID = ampParams.Slot
 	}
 
-	policyWriter, policyWriterErr := readPolicy(ampParams.Consent)
+	policyWriter, policyWriterErr := readPolicy(ampParams.Consent, ampParams.GPPSID)
 	if policyWriterErr != nil {
 		return []error{policyWriterErr}
 	}
@@ -515,7 +537,7 @@ func setAmpExt(site *openrtb.Site, value string) {
 	}
 }
 
-func readPolicy(consent string) (privacy.PolicyWriter, error) {
+func readPolicy(consent string, gppSID []gppPrivacy.SectionID) (privacy.PolicyWriter, error) {
 	if len(consent) == 0 {
 		return privacy.NilPolicyWriter{}, nil
 	}
@@ -528,8 +550,12 @@ func readPolicy(consent string) (privacy.PolicyWriter, error) {
 		return ccpa.ConsentWriter{consent}, nil
 	}
 
+	if gppPrivacy.ValidateConsent(consent) {
+		return gppPrivacy.ConsentWriter{Consent: consent, SIDs: gppSID}, nil
+	}
+
 	return privacy.NilPolicyWriter{}, &errortypes.InvalidPrivacyConsent{
-		Message: fmt.Sprintf("Consent '%s' is not recognized as either CCPA or GDPR TCF.", consent),
+		Message: fmt.Sprintf("Consent '%s' is not recognized as either CCPA, GDPR TCF or GPP.", consent),
 	}
 }
 
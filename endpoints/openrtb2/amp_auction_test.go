@@ -52,6 +52,7 @@ func TestGoodAmpRequests(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	for requestID := range goodRequests {
@@ -104,6 +105,7 @@ func TestAMPPageInfo(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 	request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&curl=%s", url.QueryEscape(page)), nil)
 	recorder := httptest.NewRecorder()
@@ -208,6 +210,7 @@ func TestGDPRConsent(t *testing.T) {
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -360,6 +363,7 @@ func TestCCPAConsent(t *testing.T) {
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -395,6 +399,56 @@ func TestCCPAConsent(t *testing.T) {
 	}
 }
 
+func TestGPPConsent(t *testing.T) {
+	gppConsent := "DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN"
+	var gdpr int8 = 1
+
+	// Build Request
+	bid, err := getTestBidRequest(true, nil, true, nil)
+	if err != nil {
+		t.Fatalf("Failed to marshal the complete openrtb.BidRequest object %v", err)
+	}
+
+	// Simulated Stored Request Backend
+	stored := map[string]json.RawMessage{"1": json.RawMessage(bid)}
+
+	// Build Exchange Endpoint
+	mockExchange := &mockAmpExchange{}
+	endpoint, _ := NewAmpEndpoint(
+		mockExchange,
+		newParamsValidator(t),
+		&mockAmpStoredReqFetcher{stored},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{MaxRequestSize: maxSize},
+		newTestMetrics(),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+	)
+
+	// Invoke Endpoint
+	request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&consent_string=%s&gpp_sid=2,6", gppConsent), nil)
+	responseRecorder := httptest.NewRecorder()
+	endpoint(responseRecorder, request, nil)
+
+	// Assert Result
+	result := mockExchange.lastRequest
+	if !assert.NotNil(t, result, "lastRequest") || !assert.NotNil(t, result.Regs, "lastRequest.Regs") {
+		return
+	}
+	var re openrtb_ext.ExtRegs
+	err = json.Unmarshal(result.Regs.Ext, &re)
+	assert.NoError(t, err)
+	assert.Equal(t, openrtb_ext.ExtRegs{GDPR: &gdpr, USPrivacy: "1YNN", GPP: gppConsent, GPPSID: []int8{2, 6}}, re)
+
+	var ue openrtb_ext.ExtUser
+	err = json.Unmarshal(result.User.Ext, &ue)
+	assert.NoError(t, err)
+	assert.Equal(t, "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA", ue.Consent)
+}
+
 func TestNoConsent(t *testing.T) {
 	// Build Request
 	bid, err := getTestBidRequest(true, nil, true, nil)
@@ -418,6 +472,7 @@ func TestNoConsent(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	// Invoke Endpoint
@@ -463,6 +518,7 @@ func TestInvalidConsent(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	// Invoke Endpoint
@@ -482,7 +538,7 @@ func TestInvalidConsent(t *testing.T) {
 		openrtb_ext.BidderNameGeneral: {
 			{
 				Code:    10001,
-				Message: "Consent '" + invalidConsent + "' is not recognized as either CCPA or GDPR TCF.",
+				Message: "Consent '" + invalidConsent + "' is not recognized as either CCPA, GDPR TCF or GPP.",
 			},
 		},
 	}
@@ -546,6 +602,7 @@ func TestNewAndLegacyConsentBothProvided(t *testing.T) {
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -597,6 +654,7 @@ func TestAMPSiteExt(t *testing.T) {
 		nil,
 		nil,
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 	request, err := http.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
 	if !assert.NoError(t, err) {
@@ -633,6 +691,7 @@ func TestAmpBadRequests(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 	for requestID := range badRequests {
 		request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=%s", requestID), nil)
@@ -663,6 +722,7 @@ func TestAmpDebug(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	for requestID := range requests {
@@ -735,6 +795,7 @@ func TestQueryParamOverrides(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	requestID := "1"
@@ -887,6 +948,7 @@ func (s formatOverrideSpec) execute(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	url := fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&debug=1&w=%d&h=%d&ow=%d&oh=%d&ms=%s&account=%s", s.width, s.height, s.overrideWidth, s.overrideHeight, s.multisize, s.account)
@@ -925,6 +987,10 @@ func (cf *mockAmpStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs
 	return cf.data, nil, nil
 }
 
+func (cf *mockAmpStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
+
 type mockAmpExchange struct {
 	lastRequest *openrtb.BidRequest
 }
@@ -1252,6 +1318,7 @@ func TestBuildAmpObject(t *testing.T) {
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			nil,
 		)
 
 		// Run test
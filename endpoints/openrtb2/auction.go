@@ -26,22 +26,28 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/ivt"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/privacy/ccpa"
+	gppPrivacy "github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/httputil"
 	"github.com/prebid/prebid-server/util/iputil"
 	"golang.org/x/net/publicsuffix"
+	"golang.org/x/text/currency"
 )
 
 const storedRequestTimeoutMillis = 50
 
 var (
 	dntKey      string = http.CanonicalHeaderKey("DNT")
+	secGPCKey   string = http.CanonicalHeaderKey("Sec-GPC")
 	dntDisabled int8   = 0
 	dntEnabled  int8   = 1
 )
@@ -57,6 +63,8 @@ func NewEndpoint(
 	disabledBidders map[string]string,
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
+	hookExecutionPlan *hooks.ExecutionPlan,
+	ivtFilter *ivt.Filter,
 ) (httprouter.Handle, error) {
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
 		return nil, errors.New("NewEndpoint requires non-nil arguments.")
@@ -84,7 +92,9 @@ func NewEndpoint(
 		bidderMap,
 		nil,
 		nil,
-		ipValidator}).Auction), nil
+		ipValidator,
+		hookExecutionPlan,
+		ivtFilter}).Auction), nil
 }
 
 type endpointDeps struct {
@@ -103,6 +113,8 @@ type endpointDeps struct {
 	cache                     prebid_cache_client.Client
 	debugLogRegexp            *regexp.Regexp
 	privateNetworkIPValidator iputil.IPValidator
+	hookExecutionPlan         *hooks.ExecutionPlan
+	ivtFilter                 *ivt.Filter
 }
 
 func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -133,12 +145,29 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		deps.analytics.LogAuctionObject(&ao)
 	}()
 
-	req, errL := deps.parseRequest(r)
+	hookExecutor := deps.hookExecutionPlan.NewExecutor()
+
+	req, errL := deps.parseRequest(r, hookExecutor)
+
+	if rejectErr := hooks.FindRejectError(errL); rejectErr != nil {
+		ao.Response = writeRejectedResponse(w, req, rejectErr)
+		return
+	}
 
 	if errortypes.ContainsFatalError(errL) && writeError(errL, w, &labels) {
 		return
 	}
 
+	ivtResult := deps.checkInvalidTraffic(req)
+	ao.InvalidTraffic = ivtResult.Detections
+	if ivtResult.Rejected() {
+		ivtErr := ivtResult.Error()
+		ao.Status = http.StatusForbidden
+		ao.Errors = append(ao.Errors, ivtErr)
+		writeError([]error{ivtErr}, w, &labels)
+		return
+	}
+
 	ctx := context.Background()
 
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(req.TMax) * time.Millisecond)
@@ -171,13 +200,32 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		return
 	}
 
+	storedAuctionResponses, storedBidResponses, errs := deps.processStoredResponses(ctx, req)
+	if len(errs) > 0 {
+		errL = append(errL, errs...)
+		writeError(errL, w, &labels)
+		return
+	}
+
+	req, err := hookExecutor.ExecuteProcessedAuctionRequestStage(req)
+	if rejectErr := hooks.FindRejectError([]error{err}); rejectErr != nil {
+		ao.Request = req
+		ao.Account = account
+		ao.Response = writeRejectedResponse(w, req, rejectErr)
+		return
+	}
+
 	auctionRequest := exchange.AuctionRequest{
-		BidRequest:   req,
-		Account:      *account,
-		UserSyncs:    usersyncs,
-		RequestType:  labels.RType,
-		StartTime:    start,
-		LegacyLabels: labels,
+		BidRequest:             req,
+		Account:                *account,
+		UserSyncs:              usersyncs,
+		RequestType:            labels.RType,
+		StartTime:              start,
+		LegacyLabels:           labels,
+		StoredAuctionResponses: storedAuctionResponses,
+		StoredBidResponses:     storedBidResponses,
+		HookExecutor:           hookExecutor,
+		SkippedBidders:         ivtResult.SkippedBidders,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
@@ -210,6 +258,15 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	}
 }
 
+// checkInvalidTraffic runs the invalid traffic checks on the request, and records the ones it failed.
+func (deps *endpointDeps) checkInvalidTraffic(req *openrtb.BidRequest) ivt.Result {
+	result := deps.ivtFilter.Check(req)
+	for _, detection := range result.Detections {
+		deps.metricsEngine.RecordInvalidTraffic(detection.Check, detection.Action == config.InvalidTrafficReject)
+	}
+	return result
+}
+
 // parseRequest turns the HTTP request into an OpenRTB request. This is guaranteed to return:
 //
 //   - A context which times out appropriately, given the request.
@@ -220,7 +277,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 // possible, it will return errors with messages that suggest improvements.
 //
 // If the errors list has at least one element, then no guarantees are made about the returned request.
-func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb.BidRequest, errs []error) {
+func (deps *endpointDeps) parseRequest(httpRequest *http.Request, hookExecutor *hooks.Executor) (req *openrtb.BidRequest, errs []error) {
 	req = &openrtb.BidRequest{}
 	errs = nil
 
@@ -242,6 +299,11 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb.
 		}
 	}
 
+	if requestJson, err = hookExecutor.ExecuteEntrypointStage(httpRequest, requestJson); err != nil {
+		errs = []error{err}
+		return
+	}
+
 	timeout := parseTimeout(requestJson, time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -251,6 +313,16 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb.
 		return
 	}
 
+	if requestJson, err = hookExecutor.ExecuteRawAuctionRequestStage(requestJson); err != nil {
+		errs = []error{err}
+		return
+	}
+
+	if requestJson, err = gppPrivacy.MoveToRegsExt(requestJson); err != nil {
+		errs = []error{err}
+		return
+	}
+
 	if err := json.Unmarshal(requestJson, req); err != nil {
 		errs = []error{err}
 		return
@@ -272,6 +344,86 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb.
 	return
 }
 
+// processStoredResponses fetches the stored auction and bid responses the imps ask for.
+//
+// The stored auction responses are keyed by imp ID. The stored bid responses are keyed by imp ID, then by bidder.
+// Only this endpoint supports them, so the AMP and video endpoints ignore the imps' stored responses.
+func (deps *endpointDeps) processStoredResponses(ctx context.Context, req *openrtb.BidRequest) (map[string][]openrtb.SeatBid, map[string]map[string]json.RawMessage, []error) {
+	auctionResponseIDs := make(map[string]string)
+	bidResponseIDs := make(map[string]map[string]string)
+	var ids []string
+	for _, imp := range req.Imp {
+		var impExt struct {
+			Prebid *openrtb_ext.ExtImpPrebid `json:"prebid"`
+		}
+		if err := json.Unmarshal(imp.Ext, &impExt); err != nil || impExt.Prebid == nil {
+			continue
+		}
+		if impExt.Prebid.StoredAuctionResponse != nil {
+			auctionResponseIDs[imp.ID] = impExt.Prebid.StoredAuctionResponse.ID
+			ids = append(ids, impExt.Prebid.StoredAuctionResponse.ID)
+		}
+		for _, storedBidResponse := range impExt.Prebid.StoredBidResponse {
+			if bidResponseIDs[imp.ID] == nil {
+				bidResponseIDs[imp.ID] = make(map[string]string)
+			}
+			bidResponseIDs[imp.ID][storedBidResponse.Bidder] = storedBidResponse.ID
+			ids = append(ids, storedBidResponse.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+
+	// The auction's deadline applies to the lookup, if the request has one
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
+		defer cancel()
+	}
+
+	storedResponses, errs := deps.storedReqFetcher.FetchResponses(ctx, ids)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	storedAuctionResponses := make(map[string][]openrtb.SeatBid, len(auctionResponseIDs))
+	for impID, id := range auctionResponseIDs {
+		var seatBids []openrtb.SeatBid
+		if err := json.Unmarshal(storedResponses[id], &seatBids); err != nil {
+			return nil, nil, []error{fmt.Errorf("Stored auction response %s is not a valid seatbid array: %v", id, err)}
+		}
+		storedAuctionResponses[impID] = seatBids
+	}
+
+	storedBidResponses := make(map[string]map[string]json.RawMessage, len(bidResponseIDs))
+	for impID, bidderIDs := range bidResponseIDs {
+		storedBidResponses[impID] = make(map[string]json.RawMessage, len(bidderIDs))
+		for bidder, id := range bidderIDs {
+			storedBidResponses[impID][bidder] = storedResponses[id]
+		}
+	}
+
+	return storedAuctionResponses, storedBidResponses, nil
+}
+
+// writeRejectedResponse answers a request a hook rejected with an empty response, which tells why there was no bid.
+func writeRejectedResponse(w http.ResponseWriter, req *openrtb.BidRequest, rejectErr *hooks.RejectError) *openrtb.BidResponse {
+	nbr := rejectErr.NBR
+	response := &openrtb.BidResponse{NBR: &nbr}
+	if req != nil {
+		response.ID = req.ID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(response); err != nil {
+		glog.Errorf("/openrtb2/auction Failed to send rejected response: %v", err)
+	}
+	return response
+}
+
 // parseTimeout returns parses tmax from the requestJson, or returns the default if it doesn't exist.
 //
 // requestJson should be the content of the POST body.
@@ -328,13 +480,25 @@ func (deps *endpointDeps) validateRequest(req *openrtb.BidRequest) []error {
 			return []error{err}
 		}
 
+		if err := validateCustomRates(bidExt.Prebid.CurrencyConversions); err != nil {
+			return []error{err}
+		}
+
 		if err := validateSChains(bidExt); err != nil {
 			return []error{err}
 		}
 
+		if err := deps.validateMultiBid(bidExt, aliases); err != nil {
+			return []error{err}
+		}
+
 		if err := deps.validateEidPermissions(bidExt, aliases); err != nil {
 			return []error{err}
 		}
+
+		if err := floors.Validate(bidExt.Prebid.Floors); err != nil {
+			return []error{err}
+		}
 	}
 
 	if (req.Site == nil && req.App == nil) || (req.Site != nil && req.App != nil) {
@@ -357,6 +521,23 @@ func (deps *endpointDeps) validateRequest(req *openrtb.BidRequest) []error {
 		return append(errL, err)
 	}
 
+	// The GDPR and CCPA policies the GPP string derives are validated and enforced like the request's own.
+	if gppPolicy, err := gppPrivacy.ReadFromRequest(req); err != nil {
+		return append(errL, err)
+	} else if gppParsedPolicy, err := gppPolicy.Parse(); err != nil {
+		if _, invalidConsent := err.(*errortypes.InvalidPrivacyConsent); invalidConsent {
+			errL = append(errL, &errortypes.InvalidPrivacyConsent{Message: fmt.Sprintf("GPP consent is invalid and will be ignored. (%v)", err)})
+			consentWriter := gppPrivacy.ConsentWriter{Consent: ""}
+			if err := consentWriter.Write(req); err != nil {
+				return append(errL, fmt.Errorf("Unable to remove invalid GPP consent from the request. (%v)", err))
+			}
+		} else {
+			return append(errL, err)
+		}
+	} else if err := gppParsedPolicy.WriteDerivedPolicies(req); err != nil {
+		return append(errL, err)
+	}
+
 	if ccpaPolicy, err := ccpa.ReadFromRequest(req); err != nil {
 		return append(errL, err)
 	} else if _, err := ccpaPolicy.Parse(exchange.GetValidBidders(aliases)); err != nil {
@@ -404,14 +585,65 @@ func validateAndFillSourceTID(req *openrtb.BidRequest) error {
 	return nil
 }
 
-func (deps *endpointDeps) validateBidAdjustmentFactors(adjustmentFactors map[string]float64, aliases map[string]string) error {
-	for bidderToAdjust, adjustmentFactor := range adjustmentFactors {
+func (deps *endpointDeps) validateBidAdjustmentFactors(adjustmentFactors *openrtb_ext.ExtBidAdjustmentFactors, aliases map[string]string) error {
+	if adjustmentFactors == nil {
+		return nil
+	}
+	for bidderToAdjust, adjustmentFactor := range adjustmentFactors.Bidders {
 		if adjustmentFactor <= 0 {
 			return fmt.Errorf("request.ext.prebid.bidadjustmentfactors.%s must be a positive number. Got %f", bidderToAdjust, adjustmentFactor)
 		}
-		if _, isBidder := deps.bidderMap[bidderToAdjust]; !isBidder {
-			if _, isAlias := aliases[bidderToAdjust]; !isAlias {
-				return fmt.Errorf("request.ext.prebid.bidadjustmentfactors.%s is not a known bidder or alias", bidderToAdjust)
+		if err := deps.validateBidderToAdjust("request.ext.prebid.bidadjustmentfactors", bidderToAdjust, aliases); err != nil {
+			return err
+		}
+	}
+	for bidType, bidderFactors := range adjustmentFactors.MediaTypes {
+		path := fmt.Sprintf("request.ext.prebid.bidadjustmentfactors.mediatypes.%s", bidType)
+		if _, err := openrtb_ext.ParseBidType(string(bidType)); err != nil {
+			return fmt.Errorf("%s is not a known media type", path)
+		}
+		for bidderToAdjust, dealFactors := range bidderFactors {
+			if err := deps.validateBidderToAdjust(path, bidderToAdjust, aliases); err != nil {
+				return err
+			}
+			for dealID, adjustmentFactor := range dealFactors {
+				if adjustmentFactor <= 0 {
+					return fmt.Errorf("%s.%s.%s must be a positive number. Got %f", path, bidderToAdjust, dealID, adjustmentFactor)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateBidderToAdjust makes sure bid adjustment factors are set for a known bidder or alias, or for all bidders.
+func (deps *endpointDeps) validateBidderToAdjust(path string, bidderToAdjust string, aliases map[string]string) error {
+	if bidderToAdjust == openrtb_ext.BidAdjustmentWildcard {
+		return nil
+	}
+	if _, isBidder := deps.bidderMap[bidderToAdjust]; !isBidder {
+		if _, isAlias := aliases[bidderToAdjust]; !isAlias {
+			return fmt.Errorf("%s.%s is not a known bidder or alias", path, bidderToAdjust)
+		}
+	}
+	return nil
+}
+
+// validateCustomRates makes sure the request's currency rates convert between known currencies, at positive rates.
+func validateCustomRates(currencyConversions *openrtb_ext.ExtRequestCurrency) error {
+	if currencyConversions == nil {
+		return nil
+	}
+	for fromCurrency, rates := range currencyConversions.ConversionRates {
+		if _, err := currency.ParseISO(fromCurrency); err != nil {
+			return fmt.Errorf("request.ext.prebid.currency.rates currency code %s is not recognized or malformed", fromCurrency)
+		}
+		for toCurrency, rate := range rates {
+			if _, err := currency.ParseISO(toCurrency); err != nil {
+				return fmt.Errorf("request.ext.prebid.currency.rates.%s currency code %s is not recognized or malformed", fromCurrency, toCurrency)
+			}
+			if rate <= 0 {
+				return fmt.Errorf("request.ext.prebid.currency.rates.%s.%s must be a positive number. Got %f", fromCurrency, toCurrency, rate)
 			}
 		}
 	}
@@ -423,6 +655,30 @@ func validateSChains(req *openrtb_ext.ExtRequest) error {
 	return err
 }
 
+func (deps *endpointDeps) validateMultiBid(req *openrtb_ext.ExtRequest, aliases map[string]string) error {
+	if _, err := exchange.BidderToMultiBid(req); err != nil {
+		return err
+	}
+
+	for i, multiBid := range req.Prebid.MultiBid {
+		if multiBid == nil {
+			continue
+		}
+		bidders := multiBid.Bidders
+		if multiBid.Bidder != "" {
+			bidders = []string{multiBid.Bidder}
+		}
+		for _, bidder := range bidders {
+			if _, isBidder := deps.bidderMap[bidder]; !isBidder {
+				if _, isAlias := aliases[bidder]; !isAlias {
+					return fmt.Errorf("request.ext.prebid.multibid[%d] contains %s which is not a known bidder or alias", i, bidder)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (deps *endpointDeps) validateEidPermissions(req *openrtb_ext.ExtRequest, aliases map[string]string) error {
 	if req == nil || req.Prebid.Data == nil {
 		return nil
@@ -832,8 +1088,8 @@ func (deps *endpointDeps) validateImpExt(imp *openrtb.Imp, aliases map[string]st
 	// to migrate from imp[...].ext.${BIDDER} to imp[...].ext.prebid.bidder.${BIDDER}
 	// at this time
 	// https://github.com/prebid/prebid-server/pull/846#issuecomment-476352224
+	var prebidExt openrtb_ext.ExtImpPrebid
 	if rawPrebidExt, ok := bidderExts[openrtb_ext.PrebidExtKey]; ok {
-		var prebidExt openrtb_ext.ExtImpPrebid
 		if err := json.Unmarshal(rawPrebidExt, &prebidExt); err == nil && prebidExt.Bidder != nil {
 			for bidder, ext := range prebidExt.Bidder {
 				if ext == nil {
@@ -845,6 +1101,10 @@ func (deps *endpointDeps) validateImpExt(imp *openrtb.Imp, aliases map[string]st
 		}
 	}
 
+	if err := validateStoredResponses(&prebidExt, bidderExts, impIndex); err != nil {
+		return []error{err}
+	}
+
 	/* Process all the bidder exts in the request */
 	disabledBidders := []string{}
 	otherExtElements := 0
@@ -890,6 +1150,26 @@ func (deps *endpointDeps) validateImpExt(imp *openrtb.Imp, aliases map[string]st
 	return errL
 }
 
+// validateStoredResponses makes sure the stored responses requested by an imp can be looked up, and that
+// every stored bid response belongs to a bidder which bids on the imp.
+func validateStoredResponses(prebidExt *openrtb_ext.ExtImpPrebid, bidderExts map[string]json.RawMessage, impIndex int) error {
+	if prebidExt.StoredAuctionResponse != nil && prebidExt.StoredAuctionResponse.ID == "" {
+		return fmt.Errorf("request.imp[%d].ext.prebid.storedauctionresponse.id is required", impIndex)
+	}
+	for i, storedBidResponse := range prebidExt.StoredBidResponse {
+		if storedBidResponse.ID == "" {
+			return fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse[%d].id is required", impIndex, i)
+		}
+		if storedBidResponse.Bidder == "" {
+			return fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse[%d].bidder is required", impIndex, i)
+		}
+		if _, ok := bidderExts[storedBidResponse.Bidder]; !ok || !isBidderToValidate(storedBidResponse.Bidder) {
+			return fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse[%d].bidder %s is not a bidder on the imp", impIndex, i, storedBidResponse.Bidder)
+		}
+	}
+	return nil
+}
+
 func isBidderToValidate(bidder string) bool {
 	// PrebidExtKey is a special case for the prebid config section and is not considered a bidder.
 
@@ -1072,7 +1352,7 @@ func setDeviceImplicitly(httpReq *http.Request, bidReq *openrtb.BidRequest, ipVa
 	setIPImplicitly(httpReq, bidReq, ipValidtor)
 	setUAImplicitly(httpReq, bidReq)
 	setDoNotTrackImplicitly(httpReq, bidReq)
-
+	setGPCImplicitly(httpReq, bidReq)
 }
 
 // setAuctionTypeImplicitly sets the auction type to 1 if it wasn't on the request,
@@ -1319,6 +1599,20 @@ func setDoNotTrackImplicitly(httpReq *http.Request, bidReq *openrtb.BidRequest)
 	}
 }
 
+// setGPCImplicitly sets request.regs.ext.gpc from the Sec-GPC header, unless the request already has it.
+func setGPCImplicitly(httpReq *http.Request, bidReq *openrtb.BidRequest) {
+	if httpReq.Header.Get(secGPCKey) != "1" {
+		return
+	}
+	if bidReq.Regs != nil && len(bidReq.Regs.Ext) > 0 {
+		if _, _, _, err := jsonparser.Get(bidReq.Regs.Ext, "gpc"); err != jsonparser.KeyPathNotFoundError {
+			return
+		}
+	}
+	// A malformed request.regs.ext is left for validation to reject
+	ccpa.GPCWriter{GPC: true}.Write(bidReq)
+}
+
 // parseUserID gets this user's ID for the host machine, if it exists.
 func parseUserID(cfg *config.Configuration, httpReq *http.Request) (string, bool) {
 	if hostCookie, err := httpReq.Cookie(cfg.HostCookie.CookieName); hostCookie != nil && err == nil {
@@ -1341,6 +1635,11 @@ func writeError(errs []error, w http.ResponseWriter, labels *metrics.Labels) boo
 				metricsStatus = metrics.RequestStatusBlacklisted
 				break
 			}
+			if erVal == errortypes.InvalidTrafficErrorCode {
+				httpStatus = http.StatusForbidden
+				metricsStatus = metrics.RequestStatusInvalidTraffic
+				break
+			}
 		}
 		w.WriteHeader(httpStatus)
 		labels.RequestStatus = metricsStatus
@@ -67,7 +67,7 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		return
 	}
 
-	adapters, adaptersErr := exchange.BuildAdapters(server.Client(), &config.Configuration{}, infos, newTestMetrics())
+	adapters, adaptersErr := exchange.BuildAdapters(server.Client(), &config.Configuration{}, infos, newTestMetrics(), nil)
 	if adaptersErr != nil {
 		b.Fatal("unable to build adapters")
 	}
@@ -81,6 +81,8 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		gdpr.AlwaysAllow{},
 		currency.NewRateConverter(&http.Client{}, "", time.Duration(0)),
 		empty_fetcher.EmptyFetcher{},
+		nil,
+		nil,
 	)
 
 	endpoint, _ := NewEndpoint(
@@ -94,6 +96,8 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		map[string]string{},
 		[]byte{},
 		nil,
+		nil,
+		nil,
 	)
 
 	b.ResetTimer()
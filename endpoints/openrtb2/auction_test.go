@@ -27,11 +27,14 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/ivt"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/util/iputil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 const maxSize = 1024 * 256
@@ -419,7 +422,7 @@ func TestExplicitUserId(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(), nil, nil)
 
 	endpoint(httptest.NewRecorder(), request, nil)
 
@@ -458,7 +461,7 @@ func doRequest(t *testing.T, test testCase) (int, string) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		disabledBidders,
 		[]byte(test.Config.AliasJSON),
-		bidderMap)
+		bidderMap, nil, nil)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(test.BidRequest))
 	recorder := httptest.NewRecorder()
@@ -521,7 +524,7 @@ func doBadAliasRequest(t *testing.T, filename string, expectMsg string) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		disabledBidders,
 		aliasJSON,
-		bidderMap)
+		bidderMap, nil, nil)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(testBidRequest))
 	recorder := httptest.NewRecorder()
@@ -570,7 +573,7 @@ func TestNilExchange(t *testing.T) {
 		newTestMetrics(),
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}), map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(), nil, nil)
 
 	if err == nil {
 		t.Errorf("NewEndpoint should return an error when given a nil Exchange.")
@@ -591,7 +594,7 @@ func TestNilValidator(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(), nil, nil)
 
 	if err == nil {
 		t.Errorf("NewEndpoint should return an error when given a nil BidderParamValidator.")
@@ -612,7 +615,7 @@ func TestExchangeError(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(), nil, nil)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -623,6 +626,158 @@ func TestExchangeError(t *testing.T) {
 	}
 }
 
+type rejectingHook struct {
+	reject bool
+}
+
+func (hook rejectingHook) HandleProcessedAuctionRequestHook(ctx context.Context, payload hooks.ProcessedAuctionRequestPayload) (hooks.ProcessedAuctionRequestPayload, hooks.HookResult, error) {
+	return payload, hooks.HookResult{Reject: hook.reject, NBR: openrtb.NoBidReasonCodeBlockedPublisherOrSite}, nil
+}
+
+// TestHookRejection makes sure a request rejected by a hook never reaches the exchange, and gets a no-bid response.
+func TestHookRejection(t *testing.T) {
+	testCases := []struct {
+		description      string
+		reject           bool
+		expectedAuction  bool
+		expectedResponse string
+	}{
+		{
+			description:     "Hook Accepts",
+			reject:          false,
+			expectedAuction: true,
+		},
+		{
+			description:      "Hook Rejects",
+			reject:           true,
+			expectedAuction:  false,
+			expectedResponse: `{"id":"some-request-id","nbr":7}`,
+		},
+	}
+
+	for _, test := range testCases {
+		hook := rejectingHook{reject: test.reject}
+		plan, err := hooks.NewExecutionPlan(config.Hooks{
+			Enabled:       true,
+			ExecutionPlan: map[string][]config.HookStep{"processed_auction_request": {{Module: "acme", Hook: "reject", TimeoutMillis: 100}}},
+		}, map[string]hooks.Builder{
+			"acme": func(cfg json.RawMessage) (hooks.Module, error) { return hooks.Module{"reject": hook}, nil },
+		})
+		if !assert.NoError(t, err, test.description) {
+			continue
+		}
+
+		ex := &nobidExchange{}
+		endpoint, _ := NewEndpoint(
+			ex,
+			newParamsValidator(t),
+			empty_fetcher.EmptyFetcher{},
+			empty_fetcher.EmptyFetcher{},
+			&config.Configuration{MaxRequestSize: maxSize},
+			newTestMetrics(),
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			map[string]string{},
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			plan, nil)
+
+		request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
+		recorder := httptest.NewRecorder()
+		endpoint(recorder, request, nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, test.description)
+		assert.Equal(t, test.expectedAuction, ex.gotRequest != nil, test.description)
+		if test.expectedResponse != "" {
+			assert.JSONEq(t, test.expectedResponse, recorder.Body.String(), test.description)
+		}
+	}
+}
+
+// TestInvalidTraffic makes sure rejected invalid traffic never reaches the exchange, and flagged traffic
+// is auctioned without the skipped bidders.
+func TestInvalidTraffic(t *testing.T) {
+	filter, err := ivt.NewFilter(config.InvalidTraffic{
+		Enabled:              true,
+		BotUserAgentAction:   config.InvalidTrafficReject,
+		BotUserAgentPatterns: []string{`bot\b`},
+		InvalidIPAction:      config.InvalidTrafficFlag,
+		FlaggedSkipBidders:   []string{"appnexus"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testCases := []struct {
+		description     string
+		userAgent       string
+		remoteAddr      string
+		expectedStatus  int
+		expectedAuction bool
+		expectedSkipped map[string]struct{}
+		expectedCheck   metrics.InvalidTrafficCheck
+		expectedReject  bool
+	}{
+		{
+			description:     "Valid",
+			userAgent:       "Mozilla/5.0",
+			remoteAddr:      "1.2.3.4:80",
+			expectedStatus:  http.StatusOK,
+			expectedAuction: true,
+		},
+		{
+			description:    "Bot rejected",
+			userAgent:      "Mozilla/5.0 (compatible; bingbot/2.0)",
+			remoteAddr:     "1.2.3.4:80",
+			expectedStatus: http.StatusForbidden,
+			expectedCheck:  metrics.InvalidTrafficBotUserAgent,
+			expectedReject: true,
+		},
+		{
+			description:     "Missing IP flagged",
+			userAgent:       "Mozilla/5.0",
+			remoteAddr:      "unknown",
+			expectedStatus:  http.StatusOK,
+			expectedAuction: true,
+			expectedSkipped: map[string]struct{}{"appnexus": {}},
+			expectedCheck:   metrics.InvalidTrafficInvalidIP,
+		},
+	}
+
+	for _, test := range testCases {
+		ex := &nobidExchange{}
+		metricsEngine := &metrics.MetricsEngineMock{}
+		metricsEngine.On("RecordRequest", mock.Anything).Return()
+		metricsEngine.On("RecordRequestTime", mock.Anything, mock.Anything).Return()
+		if test.expectedCheck != "" {
+			metricsEngine.On("RecordInvalidTraffic", test.expectedCheck, test.expectedReject).Return()
+		}
+		endpoint, _ := NewEndpoint(
+			ex,
+			newParamsValidator(t),
+			empty_fetcher.EmptyFetcher{},
+			empty_fetcher.EmptyFetcher{},
+			&config.Configuration{MaxRequestSize: maxSize},
+			metricsEngine,
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			map[string]string{},
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			nil,
+			filter)
+
+		request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
+		request.Header.Set("User-Agent", test.userAgent)
+		request.RemoteAddr = test.remoteAddr
+		recorder := httptest.NewRecorder()
+		endpoint(recorder, request, nil)
+
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.description)
+		assert.Equal(t, test.expectedAuction, ex.gotRequest != nil, test.description)
+		assert.Equal(t, test.expectedSkipped, ex.gotSkippedBidders, test.description)
+		metricsEngine.AssertExpectations(t)
+	}
+}
+
 // TestUserAgentSetting makes sure we read the User-Agent header if it wasn't defined on the request.
 func TestUserAgentSetting(t *testing.T) {
 	httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
@@ -734,7 +889,7 @@ func TestImplicitIPsEndToEnd(t *testing.T) {
 			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 			map[string]string{},
 			[]byte{},
-			openrtb_ext.BuildBidderMap())
+			openrtb_ext.BuildBidderMap(), nil, nil)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
 		httpReq.Header.Set("X-Forwarded-For", test.xForwardedForHeader)
@@ -873,6 +1028,63 @@ func TestImplicitDNT(t *testing.T) {
 	}
 }
 
+func TestGPCHeader(t *testing.T) {
+	testCases := []struct {
+		description     string
+		gpcHeader       string
+		request         openrtb.BidRequest
+		expectedRequest openrtb.BidRequest
+	}{
+		{
+			description:     "Not Set In Header",
+			gpcHeader:       "",
+			request:         openrtb.BidRequest{},
+			expectedRequest: openrtb.BidRequest{},
+		},
+		{
+			description: "Set To 1 In Header",
+			gpcHeader:   "1",
+			request:     openrtb.BidRequest{},
+			expectedRequest: openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpc":"1"}`)},
+			},
+		},
+		{
+			description: "Set To 1 In Header - Other Regs.Ext Fields Kept",
+			gpcHeader:   "1",
+			request: openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"us_privacy":"1NNN"}`)},
+			},
+			expectedRequest: openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpc":"1","us_privacy":"1NNN"}`)},
+			},
+		},
+		{
+			description: "Set To 1 In Header - Request Wins",
+			gpcHeader:   "1",
+			request: openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpc":"0"}`)},
+			},
+			expectedRequest: openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpc":"0"}`)},
+			},
+		},
+		{
+			description:     "Set To 0 In Header",
+			gpcHeader:       "0",
+			request:         openrtb.BidRequest{},
+			expectedRequest: openrtb.BidRequest{},
+		},
+	}
+
+	for _, test := range testCases {
+		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+		httpReq.Header.Set("Sec-GPC", test.gpcHeader)
+		setGPCImplicitly(httpReq, &test.request)
+		assert.Equal(t, test.expectedRequest, test.request, test.description)
+	}
+}
+
 func TestImplicitDNTEndToEnd(t *testing.T) {
 	var (
 		disabled int8 = 0
@@ -928,7 +1140,7 @@ func TestImplicitDNTEndToEnd(t *testing.T) {
 			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 			map[string]string{},
 			[]byte{},
-			openrtb_ext.BuildBidderMap())
+			openrtb_ext.BuildBidderMap(), nil, nil)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
 		httpReq.Header.Set("DNT", test.dntHeader)
@@ -995,6 +1207,8 @@ func TestStoredRequests(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	for i, requestData := range testStoredRequests {
@@ -1015,6 +1229,54 @@ func TestStoredRequests(t *testing.T) {
 	}
 }
 
+func TestProcessStoredResponses(t *testing.T) {
+	deps := &endpointDeps{storedReqFetcher: &mockStoredReqFetcher{}}
+
+	testCases := []struct {
+		description                    string
+		impExt                         string
+		expectedStoredAuctionResponses map[string][]openrtb.SeatBid
+		expectedStoredBidResponses     map[string]map[string]json.RawMessage
+		expectedErrs                   []error
+	}{
+		{
+			description: "No Stored Responses",
+			impExt:      `{"appnexus":{"placement_id":555}}`,
+		},
+		{
+			description: "Stored Auction Response",
+			impExt:      `{"appnexus":{"placement_id":555},"prebid":{"storedauctionresponse":{"id":"auction-response"}}}`,
+			expectedStoredAuctionResponses: map[string][]openrtb.SeatBid{
+				"imp1": {{Seat: "appnexus", Bid: []openrtb.Bid{{ID: "bid1", ImpID: "stored-imp", Price: 1.5}}}},
+			},
+			expectedStoredBidResponses: map[string]map[string]json.RawMessage{},
+		},
+		{
+			description:                    "Stored Bid Response",
+			impExt:                         `{"appnexus":{"placement_id":555},"prebid":{"storedbidresponse":[{"id":"bid-response","bidder":"appnexus"}]}}`,
+			expectedStoredAuctionResponses: map[string][]openrtb.SeatBid{},
+			expectedStoredBidResponses: map[string]map[string]json.RawMessage{
+				"imp1": {"appnexus": testStoredResponseData["bid-response"]},
+			},
+		},
+		{
+			description:  "Stored Auction Response Not A Seatbid Array",
+			impExt:       `{"appnexus":{"placement_id":555},"prebid":{"storedauctionresponse":{"id":"bad-auction-response"}}}`,
+			expectedErrs: []error{errors.New("Stored auction response bad-auction-response is not a valid seatbid array: json: cannot unmarshal object into Go value of type []openrtb.SeatBid")},
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "imp1", Ext: json.RawMessage(test.impExt)}}}
+
+		storedAuctionResponses, storedBidResponses, errs := deps.processStoredResponses(context.Background(), req)
+
+		assert.Equal(t, test.expectedStoredAuctionResponses, storedAuctionResponses, test.description)
+		assert.Equal(t, test.expectedStoredBidResponses, storedBidResponses, test.description)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
 // TestOversizedRequest makes sure we behave properly when the request size exceeds the configured max.
 func TestOversizedRequest(t *testing.T) {
 	reqBody := validRequest(t, "site.json")
@@ -1034,6 +1296,8 @@ func TestOversizedRequest(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -1069,6 +1333,8 @@ func TestRequestSizeEdgeCase(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -1098,6 +1364,8 @@ func TestNoEncoding(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -1173,6 +1441,8 @@ func TestContentType(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -1372,6 +1642,47 @@ func TestValidateImpExt(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Stored response tests",
+			[]testCase{
+				{
+					description:    "Valid Stored Auction Response",
+					impExt:         json.RawMessage(`{"appnexus":{"placement_id":555},"prebid":{"storedauctionresponse":{"id":"1"}}}`),
+					expectedImpExt: `{"appnexus":{"placement_id":555},"prebid":{"storedauctionresponse":{"id":"1"}}}`,
+					expectedErrs:   []error{},
+				},
+				{
+					description:    "Stored Auction Response Without ID",
+					impExt:         json.RawMessage(`{"appnexus":{"placement_id":555},"prebid":{"storedauctionresponse":{}}}`),
+					expectedImpExt: `{"appnexus":{"placement_id":555},"prebid":{"storedauctionresponse":{}}}`,
+					expectedErrs:   []error{errors.New("request.imp[0].ext.prebid.storedauctionresponse.id is required")},
+				},
+				{
+					description:    "Valid Stored Bid Response",
+					impExt:         json.RawMessage(`{"appnexus":{"placement_id":555},"prebid":{"storedbidresponse":[{"id":"1","bidder":"appnexus"}]}}`),
+					expectedImpExt: `{"appnexus":{"placement_id":555},"prebid":{"storedbidresponse":[{"id":"1","bidder":"appnexus"}]}}`,
+					expectedErrs:   []error{},
+				},
+				{
+					description:    "Stored Bid Response Without ID",
+					impExt:         json.RawMessage(`{"appnexus":{"placement_id":555},"prebid":{"storedbidresponse":[{"bidder":"appnexus"}]}}`),
+					expectedImpExt: `{"appnexus":{"placement_id":555},"prebid":{"storedbidresponse":[{"bidder":"appnexus"}]}}`,
+					expectedErrs:   []error{errors.New("request.imp[0].ext.prebid.storedbidresponse[0].id is required")},
+				},
+				{
+					description:    "Stored Bid Response Without Bidder",
+					impExt:         json.RawMessage(`{"appnexus":{"placement_id":555},"prebid":{"storedbidresponse":[{"id":"1"}]}}`),
+					expectedImpExt: `{"appnexus":{"placement_id":555},"prebid":{"storedbidresponse":[{"id":"1"}]}}`,
+					expectedErrs:   []error{errors.New("request.imp[0].ext.prebid.storedbidresponse[0].bidder is required")},
+				},
+				{
+					description:    "Stored Bid Response For Bidder Not On Imp",
+					impExt:         json.RawMessage(`{"appnexus":{"placement_id":555},"prebid":{"storedbidresponse":[{"id":"1","bidder":"rubicon"}]}}`),
+					expectedImpExt: `{"appnexus":{"placement_id":555},"prebid":{"storedbidresponse":[{"id":"1","bidder":"rubicon"}]}}`,
+					expectedErrs:   []error{errors.New("request.imp[0].ext.prebid.storedbidresponse[0].bidder rubicon is not a bidder on the imp")},
+				},
+			},
+		},
 	}
 
 	deps := &endpointDeps{
@@ -1390,6 +1701,8 @@ func TestValidateImpExt(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	for _, group := range testGroups {
@@ -1436,6 +1749,8 @@ func TestCurrencyTrunc(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	ui := uint64(1)
@@ -1480,6 +1795,8 @@ func TestCCPAInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	ui := uint64(1)
@@ -1511,6 +1828,105 @@ func TestCCPAInvalid(t *testing.T) {
 	assert.Empty(t, req.Regs.Ext, "Invalid Consent Removed From Request")
 }
 
+func TestGPPInvalid(t *testing.T) {
+	deps := &endpointDeps{
+		&nobidExchange{},
+		newParamsValidator(t),
+		&mockStoredReqFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{},
+		newTestMetrics(),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		false,
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
+		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
+	}
+
+	ui := uint64(1)
+	req := openrtb.BidRequest{
+		ID: "anyRequestID",
+		Imp: []openrtb.Imp{
+			{
+				ID: "anyImpID",
+				Banner: &openrtb.Banner{
+					W: &ui,
+					H: &ui,
+				},
+				Ext: json.RawMessage(`{"appnexus": {"placementId": 5667}}`),
+			},
+		},
+		Site: &openrtb.Site{
+			ID: "anySiteID",
+		},
+		Regs: &openrtb.Regs{
+			Ext: json.RawMessage(`{"gpp": "DBACNY~1YNN", "gpp_sid": [6]}`),
+		},
+	}
+
+	errL := deps.validateRequest(&req)
+
+	expectedWarning := errortypes.InvalidPrivacyConsent{Message: "GPP consent is invalid and will be ignored. (request.regs.ext.gpp lists 2 sections in its header, but has 1)"}
+	assert.ElementsMatch(t, errL, []error{&expectedWarning})
+
+	assert.Empty(t, req.Regs.Ext, "Invalid Consent Removed From Request")
+}
+
+func TestGPPDerivedPolicies(t *testing.T) {
+	deps := &endpointDeps{
+		&nobidExchange{},
+		newParamsValidator(t),
+		&mockStoredReqFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{},
+		newTestMetrics(),
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		false,
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
+		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
+	}
+
+	ui := uint64(1)
+	req := openrtb.BidRequest{
+		ID: "anyRequestID",
+		Imp: []openrtb.Imp{
+			{
+				ID: "anyImpID",
+				Banner: &openrtb.Banner{
+					W: &ui,
+					H: &ui,
+				},
+				Ext: json.RawMessage(`{"appnexus": {"placementId": 5667}}`),
+			},
+		},
+		Site: &openrtb.Site{
+			ID: "anySiteID",
+		},
+		Regs: &openrtb.Regs{
+			Ext: json.RawMessage(`{"gpp": "DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN", "gpp_sid": [2, 6]}`),
+		},
+	}
+
+	errL := deps.validateRequest(&req)
+
+	assert.Empty(t, errL)
+	assert.JSONEq(t, `{"gdpr":1,"gpp":"DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN","gpp_sid":[2,6],"us_privacy":"1YNN"}`, string(req.Regs.Ext))
+	assert.JSONEq(t, `{"consent":"CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"}`, string(req.User.Ext))
+}
+
 func TestNoSaleInvalid(t *testing.T) {
 	deps := &endpointDeps{
 		&nobidExchange{},
@@ -1528,6 +1944,8 @@ func TestNoSaleInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	ui := uint64(1)
@@ -1579,6 +1997,8 @@ func TestValidateSourceTID(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	ui := uint64(1)
@@ -1620,6 +2040,8 @@ func TestSChainInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	ui := uint64(1)
@@ -1839,6 +2261,8 @@ func TestEidPermissionsInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
+		nil,
 	}
 
 	ui := uint64(1)
@@ -2078,11 +2502,13 @@ func TestValidateBidders(t *testing.T) {
 
 // nobidExchange is a well-behaved exchange which always bids "no bid".
 type nobidExchange struct {
-	gotRequest *openrtb.BidRequest
+	gotRequest        *openrtb.BidRequest
+	gotSkippedBidders map[string]struct{}
 }
 
 func (e *nobidExchange) HoldAuction(ctx context.Context, r exchange.AuctionRequest, debugLog *exchange.DebugLog) (*openrtb.BidResponse, error) {
 	e.gotRequest = r.BidRequest
+	e.gotSkippedBidders = r.SkippedBidders
 	return &openrtb.BidResponse{
 		ID:    r.BidRequest.ID,
 		BidID: "test bid id",
@@ -2483,6 +2909,16 @@ var testFinalRequests = []string{
 }`,
 }
 
+// Stored Responses
+// first below is a valid stored auction response
+// second below is not a seatbid array
+// third below is a stored bid response, which is passed to the bidder as is
+var testStoredResponseData = map[string]json.RawMessage{
+	"auction-response":     json.RawMessage(`[{"seat": "appnexus", "bid": [{"id": "bid1", "impid": "stored-imp", "price": 1.5}]}]`),
+	"bad-auction-response": json.RawMessage(`{"seat": "appnexus"}`),
+	"bid-response":         json.RawMessage(`{"id": "response1", "seatbid": []}`),
+}
+
 type mockStoredReqFetcher struct {
 }
 
@@ -2490,6 +2926,10 @@ func (cf mockStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs []s
 	return testStoredRequestData, testStoredImpData, nil
 }
 
+func (cf mockStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return testStoredResponseData, nil
+}
+
 var mockAccountData = map[string]json.RawMessage{
 	"valid_acct": json.RawMessage(`{"disabled":false}`),
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/ivt"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
@@ -49,6 +50,7 @@ func NewVideoEndpoint(
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
 	cache prebid_cache_client.Client,
+	ivtFilter *ivt.Filter,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
@@ -79,7 +81,9 @@ func NewVideoEndpoint(
 		bidderMap,
 		cache,
 		videoEndpointRegexp,
-		ipValidator}).VideoAuctionEndpoint), nil
+		ipValidator,
+		nil,
+		ivtFilter}).VideoAuctionEndpoint), nil
 }
 
 /*
@@ -245,6 +249,13 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	ivtResult := deps.checkInvalidTraffic(bidReq)
+	vo.InvalidTraffic = ivtResult.Detections
+	if ivtResult.Rejected() {
+		handleError(&labels, w, []error{ivtResult.Error()}, &vo, &debugLog)
+		return
+	}
+
 	ctx := context.Background()
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(bidReq.TMax) * time.Millisecond)
 	if timeout > 0 {
@@ -275,12 +286,13 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	auctionRequest := exchange.AuctionRequest{
-		BidRequest:   bidReq,
-		Account:      *account,
-		UserSyncs:    usersyncs,
-		RequestType:  labels.RType,
-		StartTime:    start,
-		LegacyLabels: labels,
+		BidRequest:     bidReq,
+		Account:        *account,
+		UserSyncs:      usersyncs,
+		RequestType:    labels.RType,
+		StartTime:      start,
+		LegacyLabels:   labels,
+		SkippedBidders: ivtResult.SkippedBidders,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, &debugLog)
@@ -356,6 +368,11 @@ func handleError(labels *metrics.Labels, w http.ResponseWriter, errL []error, vo
 			status = http.StatusServiceUnavailable
 			labels.RequestStatus = metrics.RequestStatusBlacklisted
 			break
+		} else if erVal == errortypes.InvalidTrafficErrorCode {
+			status = http.StatusForbidden
+			labels.RequestStatus = metrics.RequestStatusInvalidTraffic
+			errors = fmt.Sprintf("%s %s", errors, er.Error())
+			break
 		} else if erVal == errortypes.AcctRequiredErrorCode {
 			status = http.StatusBadRequest
 			labels.RequestStatus = metrics.RequestStatusBadInput
@@ -13,9 +13,15 @@ import (
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/geolocation"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/privacy"
+	"github.com/prebid/prebid-server/privacy/coppa"
+	gppPrivacy "github.com/prebid/prebid-server/privacy/gpp"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
+	"github.com/prebid/prebid-server/util/iputil"
 )
 
 const (
@@ -26,8 +32,13 @@ const (
 	chromeiOSStrLen = len(chromeiOSStr)
 )
 
-func NewSetUIDEndpoint(cfg config.HostCookie, syncers map[openrtb_ext.BidderName]usersync.Usersyncer, perms gdpr.Permissions, pbsanalytics analytics.PBSAnalyticsModule, metricsEngine metrics.MetricsEngine) httprouter.Handle {
-	cookieTTL := time.Duration(cfg.TTL) * 24 * time.Hour
+func NewSetUIDEndpoint(cfg *config.Configuration, syncers map[openrtb_ext.BidderName]usersync.Usersyncer, perms gdpr.Permissions, pbsanalytics analytics.PBSAnalyticsModule, metricsEngine metrics.MetricsEngine, accounts stored_requests.AccountFetcher, geoLookup geolocation.Lookup) httprouter.Handle {
+	hostCookie := cfg.HostCookie
+	cookieTTL := time.Duration(hostCookie.TTL) * 24 * time.Hour
+	ipValidator := iputil.PublicNetworkIPValidator{
+		IPv4PrivateNetworks: cfg.RequestValidation.IPv4PrivateNetworksParsed,
+		IPv6PrivateNetworks: cfg.RequestValidation.IPv6PrivateNetworksParsed,
+	}
 
 	validFamilyNameMap := make(map[string]struct{})
 	for _, s := range syncers {
@@ -42,7 +53,7 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncers map[openrtb_ext.BidderName
 
 		defer pbsanalytics.LogSetUIDObject(&so)
 
-		pc := usersync.ParsePBSCookieFromRequest(r, &cfg)
+		pc := usersync.ParsePBSCookieFromRequest(r, &hostCookie)
 		if !pc.AllowSyncs() {
 			w.WriteHeader(http.StatusUnauthorized)
 			metricsEngine.RecordUserIDSet(metrics.UserLabels{
@@ -66,7 +77,57 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncers map[openrtb_ext.BidderName
 		}
 		so.Bidder = familyName
 
-		if shouldReturn, status, body := preventSyncsGDPR(query.Get("gdpr"), query.Get("gdpr_consent"), perms); shouldReturn {
+		gdprSignal, gdprConsent, gppPolicy, err := readPrivacyParams(query)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			metricsEngine.RecordUserIDSet(metrics.UserLabels{
+				Action: metrics.RequestActionErr,
+				Bidder: openrtb_ext.BidderName(familyName),
+			})
+			so.Status = http.StatusBadRequest
+			return
+		}
+
+		account := syncAccount(r.Context(), cfg, accounts, query.Get("account"))
+		activityControl := privacy.NewActivityControl(&account.Activities)
+		activityScope := privacy.ActivityScope{
+			Bidder:  familyName,
+			GDPR:    gdprSignal == "1",
+			Country: requestCountry(r, geoLookup, ipValidator),
+		}
+		if !activityControl.Allow(privacy.ActivitySyncUser, activityScope) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("The activity controls prevent cookies from being saved"))
+			metricsEngine.RecordUserIDSet(metrics.UserLabels{
+				Action: metrics.RequestActionActivity,
+				Bidder: openrtb_ext.BidderName(familyName),
+			})
+			return
+		}
+
+		if (coppa.Policy{Enforce: query.Get("coppa") == "1"}).ShouldEnforce(familyName) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("COPPA prevents cookies from being saved"))
+			metricsEngine.RecordUserIDSet(metrics.UserLabels{
+				Action: metrics.RequestActionCOPPA,
+				Bidder: openrtb_ext.BidderName(familyName),
+			})
+			return
+		}
+
+		// The opt outs of the US sections of the GPP string are enforced along with CCPA, as in /cookie_sync
+		if cfg.CCPA.Enforce && gppPolicy.ShouldEnforce(familyName) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("The gpp string prevents cookies from being saved"))
+			metricsEngine.RecordUserIDSet(metrics.UserLabels{
+				Action: metrics.RequestActionCCPA,
+				Bidder: openrtb_ext.BidderName(familyName),
+			})
+			return
+		}
+
+		if shouldReturn, status, body := preventSyncsGDPR(gdprSignal, gdprConsent, perms, account.GDPR); shouldReturn {
 			w.WriteHeader(status)
 			w.Write([]byte(body))
 			metricsEngine.RecordUserIDSet(metrics.UserLabels{
@@ -96,7 +157,7 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncers map[openrtb_ext.BidderName
 		}
 
 		setSiteCookie := siteCookieCheck(r.UserAgent())
-		pc.SetCookieOnResponse(w, setSiteCookie, &cfg, cookieTTL)
+		pc.SetCookieOnResponse(w, setSiteCookie, &hostCookie, cookieTTL)
 	})
 }
 
@@ -115,6 +176,33 @@ func getFamilyName(query url.Values, validFamilyNameMap map[string]struct{}) (st
 	return familyName, nil
 }
 
+// readPrivacyParams returns the gdpr and gdpr_consent query params, and the parsed gpp and gpp_sid ones. The GPP
+// string fills in the GDPR params which are missing, from its TCF EU section if it applies.
+func readPrivacyParams(query url.Values) (gdprSignal string, gdprConsent string, gppPolicy gppPrivacy.ParsedPolicy, err error) {
+	gdprSignal = query.Get("gdpr")
+	gdprConsent = query.Get("gdpr_consent")
+	if query.Get("gpp") == "" {
+		return gdprSignal, gdprConsent, gppPolicy, nil
+	}
+
+	sids, err := gppPrivacy.ParseSIDs(query.Get("gpp_sid"))
+	if err != nil {
+		return "", "", gppPolicy, err
+	}
+	gppPolicy, err = gppPrivacy.Policy{Consent: query.Get("gpp"), SIDs: sids}.Parse()
+	if err != nil {
+		return "", "", gppPolicy, errors.New("the gpp query param must be a valid GPP string")
+	}
+
+	if signal := gppPolicy.GDPRSignal(); signal != nil && gdprSignal == "" {
+		gdprSignal = strconv.Itoa(int(*signal))
+	}
+	if gdprConsent == "" {
+		gdprConsent = gppPolicy.TCFConsent()
+	}
+	return gdprSignal, gdprConsent, gppPolicy, nil
+}
+
 // siteCookieCheck scans the input User Agent string to check if browser is Chrome and browser version is greater than the minimum version for adding the SameSite cookie attribute
 func siteCookieCheck(ua string) bool {
 	result := false
@@ -143,7 +231,7 @@ func checkChromeBrowserVersion(ua string, index int, chromeStrLength int) bool {
 	return result
 }
 
-func preventSyncsGDPR(gdprEnabled string, gdprConsent string, perms gdpr.Permissions) (shouldReturn bool, status int, body string) {
+func preventSyncsGDPR(gdprEnabled string, gdprConsent string, perms gdpr.Permissions, account config.AccountGDPR) (shouldReturn bool, status int, body string) {
 
 	if gdprEnabled != "" && gdprEnabled != "0" && gdprEnabled != "1" {
 		return true, http.StatusBadRequest, "the gdpr query param must be either 0 or 1. You gave " + gdprEnabled
@@ -159,7 +247,7 @@ func preventSyncsGDPR(gdprEnabled string, gdprConsent string, perms gdpr.Permiss
 		gdprSignal = gdpr.Signal(i)
 	}
 
-	allowed, err := perms.HostCookiesAllowed(context.Background(), gdprSignal, gdprConsent)
+	allowed, err := perms.HostCookiesAllowed(context.Background(), gdprSignal, gdprConsent, account)
 	if err != nil {
 		if _, ok := err.(*gdpr.ErrorMalformedConsent); ok {
 			return true, http.StatusBadRequest, "gdpr_consent was invalid. " + err.Error()
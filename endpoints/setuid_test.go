@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +15,7 @@ import (
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/privacy"
+	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/stretchr/testify/assert"
 
@@ -118,6 +120,16 @@ func TestSetUIDEndpoint(t *testing.T) {
 			expectedRespMessage:  "The gdpr_consent string prevents cookies from being saved",
 			description:          "Return err message if the GDPR consent doesn't allow syncs for the given bidder",
 		},
+		{
+			uri:                   "/setuid?bidder=pubmatic&uid=123&coppa=1",
+			validFamilyNames:      []string{"pubmatic"},
+			existingSyncs:         nil,
+			gdprAllowsHostCookies: true,
+			expectedSyncs:         nil,
+			expectedResponseCode:  http.StatusOK,
+			expectedRespMessage:   "COPPA prevents cookies from being saved",
+			description:           "Shouldn't set uid if COPPA applies",
+		},
 		{
 			uri:                   "/setuid?uid=123",
 			validFamilyNames:      []string{"appnexus"},
@@ -180,6 +192,25 @@ func TestSetUIDEndpoint(t *testing.T) {
 			expectedResponseCode:  http.StatusOK,
 			description:           "Should set uid for a bidder that is allowed by the GDPR consent string",
 		},
+		{
+			uri:                   "/setuid?bidder=pubmatic&uid=123&gpp=malformed&gpp_sid=2",
+			validFamilyNames:      []string{"pubmatic"},
+			gdprAllowsHostCookies: true,
+			existingSyncs:         nil,
+			expectedSyncs:         nil,
+			expectedResponseCode:  http.StatusBadRequest,
+			expectedRespMessage:   "the gpp query param must be a valid GPP string",
+			description:           "Return an error if the GPP string is malformed",
+		},
+		{
+			uri:                   "/setuid?bidder=pubmatic&uid=123&gpp=DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA&gpp_sid=2",
+			validFamilyNames:      []string{"pubmatic"},
+			gdprAllowsHostCookies: true,
+			existingSyncs:         nil,
+			expectedSyncs:         map[string]string{"pubmatic": "123"},
+			expectedResponseCode:  http.StatusOK,
+			description:           "Should set uid for a bidder that is allowed by the TCF EU section of the GPP string",
+		},
 	}
 
 	metrics := &metricsConf.DummyMetricsEngine{}
@@ -259,6 +290,16 @@ func TestSetUIDEndpointMetrics(t *testing.T) {
 			expectedResponseCode:  400,
 			description:           "Prevented By GDPR",
 		},
+		{
+			uri:                   "/setuid?bidder=pubmatic&uid=123&coppa=1",
+			cookies:               []*usersync.PBSCookie{},
+			validFamilyNames:      []string{"pubmatic"},
+			gdprAllowsHostCookies: true,
+			expectedMetricAction:  metrics.RequestActionCOPPA,
+			expectedMetricBidder:  openrtb_ext.BidderName("pubmatic"),
+			expectedResponseCode:  200,
+			description:           "Prevented By COPPA",
+		},
 	}
 
 	for _, test := range testCases {
@@ -280,6 +321,202 @@ func TestSetUIDEndpointMetrics(t *testing.T) {
 	}
 }
 
+func TestSetUIDEndpointActivityControls(t *testing.T) {
+	cfg := &config.Configuration{}
+	cfg.AccountDefaults.Activities.SyncUser.Rules = []config.ActivityRule{
+		{Allow: false, Condition: config.ActivityCondition{Bidders: []string{"pubmatic"}}},
+	}
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+	accounts := mockAccountFetcher{
+		"allow_all": json.RawMessage(`{"activities":{"sync_user":{"rules":[]}}}`),
+	}
+	perms := &mockPermsSetUID{allowHost: true, allowPI: true}
+	syncers := map[openrtb_ext.BidderName]usersync.Usersyncer{"pubmatic": newFakeSyncer("pubmatic")}
+
+	testCases := []struct {
+		uri                  string
+		expectedSyncs        map[string]string
+		expectedMetricAction metrics.RequestAction
+		expectedRespMessage  string
+		description          string
+	}{
+		{
+			uri:                  "/setuid?bidder=pubmatic&uid=123",
+			expectedMetricAction: metrics.RequestActionActivity,
+			expectedRespMessage:  "The activity controls prevent cookies from being saved",
+			description:          "Host rule denies the sync",
+		},
+		{
+			uri:                  "/setuid?bidder=pubmatic&uid=123&account=allow_all",
+			expectedSyncs:        map[string]string{"pubmatic": "123"},
+			expectedMetricAction: metrics.RequestActionSet,
+			description:          "Account rules replace the host's",
+		},
+	}
+
+	for _, test := range testCases {
+		metricsEngine := &metrics.MetricsEngineMock{}
+		metricsEngine.On("RecordUserIDSet", metrics.UserLabels{Action: test.expectedMetricAction, Bidder: "pubmatic"}).Once()
+		analytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics)
+
+		endpoint := NewSetUIDEndpoint(cfg, syncers, perms, analytics, metricsEngine, accounts, nil)
+		response := httptest.NewRecorder()
+		endpoint(response, makeRequest(test.uri, nil), nil)
+
+		assert.Equal(t, http.StatusOK, response.Code, test.description)
+		assert.Equal(t, test.expectedRespMessage, response.Body.String(), test.description)
+		if test.expectedSyncs != nil {
+			assertHasSyncs(t, test.description, response, test.expectedSyncs)
+		}
+		metricsEngine.AssertExpectations(t)
+	}
+}
+
+func TestSetUIDEndpointAccountGDPR(t *testing.T) {
+	cfg := &config.Configuration{}
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+	accounts := mockAccountFetcher{
+		"basic_purpose1": json.RawMessage(`{"gdpr":{"purpose1":{"enforcement_type":"basic"}}}`),
+	}
+	perms := &mockPermsSetUID{allowHost: true, allowPI: true}
+	syncers := map[openrtb_ext.BidderName]usersync.Usersyncer{"pubmatic": newFakeSyncer("pubmatic")}
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordUserIDSet", metrics.UserLabels{Action: metrics.RequestActionSet, Bidder: "pubmatic"}).Once()
+	analytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics)
+
+	endpoint := NewSetUIDEndpoint(cfg, syncers, perms, analytics, metricsEngine, accounts, nil)
+	response := httptest.NewRecorder()
+	endpoint(response, makeRequest("/setuid?bidder=pubmatic&uid=123&gdpr=1&gdpr_consent=BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw&account=basic_purpose1", nil), nil)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, config.TCF2BasicEnforcement, perms.account.Purpose1.EnforcementType, "The account's GDPR settings should be used for the host cookie")
+	metricsEngine.AssertExpectations(t)
+}
+
+func TestSetUIDEndpointGPPOptOut(t *testing.T) {
+	perms := &mockPermsSetUID{allowHost: true, allowPI: true}
+	syncers := map[openrtb_ext.BidderName]usersync.Usersyncer{"pubmatic": newFakeSyncer("pubmatic")}
+
+	testCases := []struct {
+		uri                  string
+		enforceCCPA          bool
+		expectedSyncs        map[string]string
+		expectedMetricAction metrics.RequestAction
+		expectedRespMessage  string
+		description          string
+	}{
+		{
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gpp=DBABLA~BVQaAAAAAgA.QA&gpp_sid=7",
+			enforceCCPA:          true,
+			expectedMetricAction: metrics.RequestActionCCPA,
+			expectedRespMessage:  "The gpp string prevents cookies from being saved",
+			description:          "US national section opts out of the sale of data",
+		},
+		{
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gpp=DBABLA~BVQqAAAAAgA.QA&gpp_sid=7",
+			enforceCCPA:          true,
+			expectedSyncs:        map[string]string{"pubmatic": "123"},
+			expectedMetricAction: metrics.RequestActionSet,
+			description:          "US national section doesn't opt out",
+		},
+		{
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gpp=DBABLA~BVQaAAAAAgA.QA",
+			enforceCCPA:          true,
+			expectedSyncs:        map[string]string{"pubmatic": "123"},
+			expectedMetricAction: metrics.RequestActionSet,
+			description:          "US national section doesn't apply",
+		},
+		{
+			uri:                  "/setuid?bidder=pubmatic&uid=123&gpp=DBABLA~BVQaAAAAAgA.QA&gpp_sid=7",
+			expectedSyncs:        map[string]string{"pubmatic": "123"},
+			expectedMetricAction: metrics.RequestActionSet,
+			description:          "CCPA isn't enforced",
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := &config.Configuration{}
+		cfg.CCPA.Enforce = test.enforceCCPA
+		metricsEngine := &metrics.MetricsEngineMock{}
+		metricsEngine.On("RecordUserIDSet", metrics.UserLabels{Action: test.expectedMetricAction, Bidder: "pubmatic"}).Once()
+		analytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics)
+
+		endpoint := NewSetUIDEndpoint(cfg, syncers, perms, analytics, metricsEngine, empty_fetcher.EmptyFetcher{}, nil)
+		response := httptest.NewRecorder()
+		endpoint(response, makeRequest(test.uri, nil), nil)
+
+		assert.Equal(t, http.StatusOK, response.Code, test.description)
+		assert.Equal(t, test.expectedRespMessage, response.Body.String(), test.description)
+		if test.expectedSyncs != nil {
+			assertHasSyncs(t, test.description, response, test.expectedSyncs)
+		} else {
+			assert.Equal(t, "", response.Header().Get("Set-Cookie"), test.description)
+		}
+		metricsEngine.AssertExpectations(t)
+	}
+}
+
+func TestReadPrivacyParams(t *testing.T) {
+	tcfConsent := "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+	testCases := []struct {
+		description     string
+		query           string
+		expectedSignal  string
+		expectedConsent string
+		expectedOptOut  bool
+		expectedError   string
+	}{
+		{
+			description:     "GDPR params",
+			query:           "gdpr=1&gdpr_consent=anyConsent",
+			expectedSignal:  "1",
+			expectedConsent: "anyConsent",
+		},
+		{
+			description:     "TCF EU section applies",
+			query:           "gpp=DBABMA~" + tcfConsent + "&gpp_sid=2",
+			expectedSignal:  "1",
+			expectedConsent: tcfConsent,
+		},
+		{
+			description:    "TCF EU section doesn't apply",
+			query:          "gpp=DBABMA~" + tcfConsent + "&gpp_sid=6",
+			expectedSignal: "0",
+		},
+		{
+			description:     "GDPR params win",
+			query:           "gdpr=0&gdpr_consent=anyConsent&gpp=DBABMA~" + tcfConsent + "&gpp_sid=2",
+			expectedSignal:  "0",
+			expectedConsent: "anyConsent",
+		},
+		{
+			description:    "US national section opts out",
+			query:          "gpp=DBABLA~BVQaAAAAAgA.QA&gpp_sid=7",
+			expectedSignal: "0",
+			expectedOptOut: true,
+		},
+		{
+			description:   "Invalid gpp_sid",
+			query:         "gpp=DBABMA~" + tcfConsent + "&gpp_sid=two",
+			expectedError: "gpp_sid must be a comma separated list of section ids. Got two",
+		},
+	}
+
+	for _, test := range testCases {
+		query, _ := url.ParseQuery(test.query)
+		signal, consent, gppPolicy, err := readPrivacyParams(query)
+
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedSignal, signal, test.description+":signal")
+		assert.Equal(t, test.expectedConsent, consent, test.description+":consent")
+		assert.Equal(t, test.expectedOptOut, gppPolicy.ShouldEnforce("pubmatic"), test.description+":optOut")
+	}
+}
+
 func TestOptedOut(t *testing.T) {
 	request := httptest.NewRequest("GET", "/setuid?bidder=pubmatic&uid=123", nil)
 	cookie := usersync.NewPBSCookie()
@@ -399,7 +636,7 @@ func doRequest(req *http.Request, metrics metrics.MetricsEngine, validFamilyName
 		syncers[openrtb_ext.BidderName(name)] = newFakeSyncer(name)
 	}
 
-	endpoint := NewSetUIDEndpoint(cfg.HostCookie, syncers, perms, analytics, metrics)
+	endpoint := NewSetUIDEndpoint(&cfg, syncers, perms, analytics, metrics, empty_fetcher.EmptyFetcher{}, nil)
 	response := httptest.NewRecorder()
 	endpoint(response, req, nil)
 	return response
@@ -425,9 +662,12 @@ type mockPermsSetUID struct {
 	allowHost bool
 	errorHost bool
 	allowPI   bool
+	// account records the account settings HostCookiesAllowed was last called with
+	account config.AccountGDPR
 }
 
-func (g *mockPermsSetUID) HostCookiesAllowed(ctx context.Context, gdprSignal gdpr.Signal, consent string) (bool, error) {
+func (g *mockPermsSetUID) HostCookiesAllowed(ctx context.Context, gdprSignal gdpr.Signal, consent string, account config.AccountGDPR) (bool, error) {
+	g.account = account
 	var err error
 	if g.errorHost {
 		err = errors.New("something went wrong")
@@ -435,11 +675,11 @@ func (g *mockPermsSetUID) HostCookiesAllowed(ctx context.Context, gdprSignal gdp
 	return g.allowHost, err
 }
 
-func (g *mockPermsSetUID) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal gdpr.Signal, consent string) (bool, error) {
+func (g *mockPermsSetUID) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal gdpr.Signal, consent string, account config.AccountGDPR) (bool, error) {
 	return false, nil
 }
 
-func (g *mockPermsSetUID) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal gdpr.Signal, consent string) (bool, bool, bool, error) {
+func (g *mockPermsSetUID) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal gdpr.Signal, consent string, account config.AccountGDPR) (bool, bool, bool, error) {
 	return g.allowPI, g.allowPI, g.allowPI, nil
 }
 
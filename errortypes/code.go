@@ -11,6 +11,8 @@ const (
 	BidderTemporarilyDisabledErrorCode
 	BlacklistedAcctErrorCode
 	AcctRequiredErrorCode
+	BidderCircuitOpenErrorCode
+	InvalidTrafficErrorCode
 )
 
 // Defines numeric codes for well-known warnings.
@@ -165,6 +165,44 @@ func (err *BidderTemporarilyDisabled) Severity() Severity {
 	return SeverityWarning
 }
 
+// BidderCircuitOpen is used when a bidder isn't called because its circuit breaker is open,
+// since its server kept failing recently.
+type BidderCircuitOpen struct {
+	Message string
+}
+
+func (err *BidderCircuitOpen) Error() string {
+	return err.Message
+}
+
+func (err *BidderCircuitOpen) Code() int {
+	return BidderCircuitOpenErrorCode
+}
+
+func (err *BidderCircuitOpen) Severity() Severity {
+	return SeverityFatal
+}
+
+// InvalidTraffic should be used when a request is rejected because it failed an invalid traffic check,
+// such as coming from a known crawler.
+//
+// These errors will be written to  http.ResponseWriter before canceling execution
+type InvalidTraffic struct {
+	Message string
+}
+
+func (err *InvalidTraffic) Error() string {
+	return err.Message
+}
+
+func (err *InvalidTraffic) Code() int {
+	return InvalidTrafficErrorCode
+}
+
+func (err *InvalidTraffic) Severity() Severity {
+	return SeverityFatal
+}
+
 // Warning is a generic non-fatal error.
 type Warning struct {
 	Message string
@@ -0,0 +1,155 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// applyAccountBlocklists adds the blocklists of the account to the request, so that bidders are told about them
+// and bids which violate them are rejected.
+func applyAccountBlocklists(request *openrtb.BidRequest, adQuality config.AccountAdQuality) {
+	request.BAdv = appendMissing(request.BAdv, adQuality.BlockedAdvertisers)
+	request.BCat = appendMissing(request.BCat, adQuality.BlockedCategories)
+
+	if len(adQuality.BlockedAttributes) == 0 {
+		return
+	}
+	for i := range request.Imp {
+		banner := request.Imp[i].Banner
+		if banner == nil {
+			continue
+		}
+		bannerCopy := *banner
+		for _, attr := range adQuality.BlockedAttributes {
+			if !hasAttribute(bannerCopy.BAttr, openrtb.CreativeAttribute(attr)) {
+				bannerCopy.BAttr = append(bannerCopy.BAttr, openrtb.CreativeAttribute(attr))
+			}
+		}
+		request.Imp[i].Banner = &bannerCopy
+	}
+}
+
+func appendMissing(values []string, extraValues []string) []string {
+	for _, extraValue := range extraValues {
+		found := false
+		for _, value := range values {
+			if strings.EqualFold(value, extraValue) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, extraValue)
+		}
+	}
+	return values
+}
+
+// enforceBlocklists removes the bids whose advertiser domains, categories or creative attributes are blocked
+// by the request.
+func (e *exchange) enforceBlocklists(bidderName openrtb_ext.BidderName, request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid) []error {
+	if seatBid == nil || len(seatBid.bids) == 0 {
+		return nil
+	}
+
+	impsByID := make(map[string]*openrtb.Imp, len(request.Imp))
+	for i := range request.Imp {
+		impsByID[request.Imp[i].ID] = &request.Imp[i]
+	}
+
+	var errs []error
+	validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		blocklist, reason := checkBlocklists(request, impsByID[bid.bid.ImpID], bid)
+		if reason == "" {
+			validBids = append(validBids, bid)
+			continue
+		}
+		errs = append(errs, &errortypes.Warning{
+			Message: fmt.Sprintf("bid rejected [bid ID: %s] reason: %s", bid.bid.ID, reason),
+		})
+		seatBid.addNonBid(bid.bid, openrtb_ext.NonBidResponseRejectedGeneral)
+		e.me.RecordAdapterBlockedBid(bidderName, blocklist)
+	}
+	seatBid.bids = validBids
+	return errs
+}
+
+// checkBlocklists returns the blocklist the bid violates, and why. The reason is empty if the bid is allowed.
+func checkBlocklists(request *openrtb.BidRequest, imp *openrtb.Imp, bid *pbsOrtbBid) (metrics.Blocklist, string) {
+	for _, domain := range bid.bid.ADomain {
+		for _, blockedDomain := range request.BAdv {
+			if isDomainOrSubdomain(domain, blockedDomain) {
+				return metrics.BlocklistAdvertisers, fmt.Sprintf("advertiser domain %s is blocked by request.badv", domain)
+			}
+		}
+	}
+
+	for _, category := range bid.bid.Cat {
+		for _, blockedCategory := range request.BCat {
+			if isCategoryOrSubcategory(category, blockedCategory) {
+				return metrics.BlocklistCategories, fmt.Sprintf("category %s is blocked by request.bcat", category)
+			}
+		}
+	}
+
+	if imp != nil {
+		blockedAttrs := blockedAttributes(imp, bid.bidType)
+		for _, attr := range bid.bid.Attr {
+			if hasAttribute(blockedAttrs, attr) {
+				return metrics.BlocklistAttributes, fmt.Sprintf("creative attribute %d is blocked by the battr of imp %s", attr, imp.ID)
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// isDomainOrSubdomain tells whether domain is the blocked domain, or one of its subdomains.
+func isDomainOrSubdomain(domain, blockedDomain string) bool {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	blockedDomain = strings.ToLower(strings.TrimSuffix(blockedDomain, "."))
+	if blockedDomain == "" {
+		return false
+	}
+	return domain == blockedDomain || strings.HasSuffix(domain, "."+blockedDomain)
+}
+
+// isCategoryOrSubcategory tells whether category is the blocked IAB category, or one of its subcategories.
+// For example, IAB1 blocks IAB1-2.
+func isCategoryOrSubcategory(category, blockedCategory string) bool {
+	if blockedCategory == "" {
+		return false
+	}
+	return strings.EqualFold(category, blockedCategory) || strings.HasPrefix(strings.ToUpper(category), strings.ToUpper(blockedCategory)+"-")
+}
+
+// blockedAttributes returns the creative attributes the imp blocks for bids of the given type.
+func blockedAttributes(imp *openrtb.Imp, bidType openrtb_ext.BidType) []openrtb.CreativeAttribute {
+	switch {
+	case bidType == openrtb_ext.BidTypeBanner && imp.Banner != nil:
+		return imp.Banner.BAttr
+	case bidType == openrtb_ext.BidTypeVideo && imp.Video != nil:
+		return imp.Video.BAttr
+	case bidType == openrtb_ext.BidTypeAudio && imp.Audio != nil:
+		return imp.Audio.BAttr
+	case bidType == openrtb_ext.BidTypeNative && imp.Native != nil:
+		return imp.Native.BAttr
+	}
+	return nil
+}
+
+func hasAttribute(attrs []openrtb.CreativeAttribute, attr openrtb.CreativeAttribute) bool {
+	for _, a := range attrs {
+		if a == attr {
+			return true
+		}
+	}
+	return false
+}
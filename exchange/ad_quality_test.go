@@ -0,0 +1,104 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestApplyAccountBlocklists(t *testing.T) {
+	banner := &openrtb.Banner{BAttr: []openrtb.CreativeAttribute{1}}
+	request := &openrtb.BidRequest{
+		BAdv: []string{"blocked.com"},
+		Imp: []openrtb.Imp{
+			{ID: "imp1", Banner: banner},
+			{ID: "imp2", Video: &openrtb.Video{}},
+		},
+	}
+
+	applyAccountBlocklists(request, config.AccountAdQuality{
+		BlockedAdvertisers: []string{"BLOCKED.com", "other.com"},
+		BlockedCategories:  []string{"IAB25"},
+		BlockedAttributes:  []int{1, 3},
+	})
+
+	assert.Equal(t, []string{"blocked.com", "other.com"}, request.BAdv)
+	assert.Equal(t, []string{"IAB25"}, request.BCat)
+	assert.Equal(t, []openrtb.CreativeAttribute{1, 3}, request.Imp[0].Banner.BAttr)
+	assert.Equal(t, []openrtb.CreativeAttribute{1}, banner.BAttr, "The original banner should be left as is")
+	assert.Nil(t, request.Imp[1].Banner)
+}
+
+func TestEnforceBlocklists(t *testing.T) {
+	request := &openrtb.BidRequest{
+		BAdv: []string{"blocked.com"},
+		BCat: []string{"IAB25"},
+		Imp: []openrtb.Imp{
+			{ID: "imp1", Banner: &openrtb.Banner{BAttr: []openrtb.CreativeAttribute{1}}, Video: &openrtb.Video{}},
+		},
+	}
+
+	testCases := []struct {
+		description       string
+		bid               *pbsOrtbBid
+		expectedBlocklist metrics.Blocklist
+	}{
+		{
+			description: "Allowed",
+			bid:         &pbsOrtbBid{bid: &openrtb.Bid{ID: "bid", ImpID: "imp1", ADomain: []string{"ok.com"}, Cat: []string{"IAB1"}, Attr: []openrtb.CreativeAttribute{2}}, bidType: openrtb_ext.BidTypeBanner},
+		},
+		{
+			description:       "Blocked Domain",
+			bid:               &pbsOrtbBid{bid: &openrtb.Bid{ID: "bid", ImpID: "imp1", ADomain: []string{"blocked.com"}}, bidType: openrtb_ext.BidTypeBanner},
+			expectedBlocklist: metrics.BlocklistAdvertisers,
+		},
+		{
+			description:       "Blocked Subdomain",
+			bid:               &pbsOrtbBid{bid: &openrtb.Bid{ID: "bid", ImpID: "imp1", ADomain: []string{"www.Blocked.com"}}, bidType: openrtb_ext.BidTypeBanner},
+			expectedBlocklist: metrics.BlocklistAdvertisers,
+		},
+		{
+			description: "Similar Domain",
+			bid:         &pbsOrtbBid{bid: &openrtb.Bid{ID: "bid", ImpID: "imp1", ADomain: []string{"notblocked.com"}}, bidType: openrtb_ext.BidTypeBanner},
+		},
+		{
+			description:       "Blocked Subcategory",
+			bid:               &pbsOrtbBid{bid: &openrtb.Bid{ID: "bid", ImpID: "imp1", Cat: []string{"IAB25-3"}}, bidType: openrtb_ext.BidTypeBanner},
+			expectedBlocklist: metrics.BlocklistCategories,
+		},
+		{
+			description:       "Blocked Banner Attribute",
+			bid:               &pbsOrtbBid{bid: &openrtb.Bid{ID: "bid", ImpID: "imp1", Attr: []openrtb.CreativeAttribute{1}}, bidType: openrtb_ext.BidTypeBanner},
+			expectedBlocklist: metrics.BlocklistAttributes,
+		},
+		{
+			description: "Banner Attribute On Video Bid",
+			bid:         &pbsOrtbBid{bid: &openrtb.Bid{ID: "bid", ImpID: "imp1", Attr: []openrtb.CreativeAttribute{1}}, bidType: openrtb_ext.BidTypeVideo},
+		},
+	}
+
+	for _, test := range testCases {
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordAdapterBlockedBid", mock.Anything, mock.Anything).Return()
+		e := &exchange{me: metricsMock}
+		seatBid := &pbsOrtbSeatBid{bids: []*pbsOrtbBid{test.bid}}
+
+		errs := e.enforceBlocklists(openrtb_ext.BidderAppnexus, request, seatBid)
+
+		if test.expectedBlocklist == "" {
+			assert.Len(t, seatBid.bids, 1, test.description)
+			assert.Empty(t, errs, test.description)
+			metricsMock.AssertNotCalled(t, "RecordAdapterBlockedBid", mock.Anything, mock.Anything)
+		} else {
+			assert.Empty(t, seatBid.bids, test.description)
+			assert.Len(t, errs, 1, test.description)
+			assert.Len(t, seatBid.nonBids, 1, test.description)
+			metricsMock.AssertCalled(t, "RecordAdapterBlockedBid", openrtb_ext.BidderAppnexus, test.expectedBlocklist)
+		}
+	}
+}
@@ -8,14 +8,15 @@ import (
 
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/adapters/lifestreet"
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
-func BuildAdapters(client *http.Client, cfg *config.Configuration, infos adapters.BidderInfos, me metrics.MetricsEngine) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
+func BuildAdapters(client *http.Client, cfg *config.Configuration, infos adapters.BidderInfos, me metrics.MetricsEngine, breakers *circuitbreaker.Breakers) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
 	exchangeBidders := buildExchangeBiddersLegacy(cfg.Adapters, infos)
 
-	exchangeBiddersModern, errs := buildExchangeBidders(cfg, infos, client, me)
+	exchangeBiddersModern, errs := buildExchangeBidders(cfg, infos, client, me, breakers)
 	if len(errs) > 0 {
 		return nil, errs
 	}
@@ -25,12 +26,12 @@ func BuildAdapters(client *http.Client, cfg *config.Configuration, infos adapter
 		exchangeBidders[bidderName] = bidder
 	}
 
-	wrapWithMiddleware(exchangeBidders)
+	wrapWithMiddleware(exchangeBidders, me)
 
 	return exchangeBidders, nil
 }
 
-func buildExchangeBidders(cfg *config.Configuration, infos adapters.BidderInfos, client *http.Client, me metrics.MetricsEngine) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
+func buildExchangeBidders(cfg *config.Configuration, infos adapters.BidderInfos, client *http.Client, me metrics.MetricsEngine, breakers *circuitbreaker.Breakers) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
 	bidders, errs := buildBidders(cfg.Adapters, infos, newAdapterBuilders())
 	if len(errs) > 0 {
 		return nil, errs
@@ -43,7 +44,7 @@ func buildExchangeBidders(cfg *config.Configuration, infos adapters.BidderInfos,
 			errs = append(errs, fmt.Errorf("%v: bidder info not found", bidder))
 			continue
 		}
-		exchangeBidders[bidderName] = adaptBidder(bidder, client, cfg, me, bidderName, info.Debug)
+		exchangeBidders[bidderName] = adaptBidder(bidder, client, cfg, me, bidderName, info.Debug, breakers)
 	}
 
 	return exchangeBidders, nil
@@ -106,9 +107,9 @@ func buildExchangeBiddersLegacy(adapterConfig map[string]config.Adapter, infos a
 	return bidders
 }
 
-func wrapWithMiddleware(bidders map[openrtb_ext.BidderName]adaptedBidder) {
+func wrapWithMiddleware(bidders map[openrtb_ext.BidderName]adaptedBidder, me metrics.MetricsEngine) {
 	for name, bidder := range bidders {
-		bidders[name] = addValidatedBidderMiddleware(bidder)
+		bidders[name] = addValidatedBidderMiddleware(bidder, me)
 	}
 }
 
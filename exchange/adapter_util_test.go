@@ -2,6 +2,7 @@ package exchange
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"testing"
@@ -36,15 +37,15 @@ func TestBuildAdaptersSuccess(t *testing.T) {
 	}
 	metricEngine := &metrics.DummyMetricsEngine{}
 
-	bidders, errs := BuildAdapters(client, cfg, infos, metricEngine)
+	bidders, errs := BuildAdapters(client, cfg, infos, metricEngine, nil)
 
 	appnexusBidder, _ := appnexus.Builder(openrtb_ext.BidderAppnexus, config.Adapter{})
 	appnexusBidderWithInfo := adapters.EnforceBidderInfo(appnexusBidder, infoActive)
-	appnexusBidderAdapted := adaptBidder(appnexusBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderAppnexus, nil)
-	appnexusBidderValidated := addValidatedBidderMiddleware(appnexusBidderAdapted)
+	appnexusBidderAdapted := adaptBidder(appnexusBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderAppnexus, nil, nil)
+	appnexusBidderValidated := addValidatedBidderMiddleware(appnexusBidderAdapted, metricEngine)
 
 	idLegacyAdapted := &adaptedAdapter{lifestreet.NewLifestreetLegacyAdapter(adapters.DefaultHTTPAdapterConfig, "anyEndpoint")}
-	idLegacyValidated := addValidatedBidderMiddleware(idLegacyAdapted)
+	idLegacyValidated := addValidatedBidderMiddleware(idLegacyAdapted, metricEngine)
 
 	expectedBidders := map[openrtb_ext.BidderName]adaptedBidder{
 		openrtb_ext.BidderAppnexus:   appnexusBidderValidated,
@@ -61,7 +62,7 @@ func TestBuildAdaptersErrors(t *testing.T) {
 	infos := map[string]adapters.BidderInfo{}
 	metricEngine := &metrics.DummyMetricsEngine{}
 
-	bidders, errs := BuildAdapters(client, cfg, infos, metricEngine)
+	bidders, errs := BuildAdapters(client, cfg, infos, metricEngine, nil)
 
 	expectedErrors := []error{
 		errors.New("unknown: unknown bidder"),
@@ -77,11 +78,11 @@ func TestBuildExchangeBidders(t *testing.T) {
 
 	appnexusBidder, _ := appnexus.Builder(openrtb_ext.BidderAppnexus, config.Adapter{})
 	appnexusBidderWithInfo := adapters.EnforceBidderInfo(appnexusBidder, infoActive)
-	appnexusBidderAdapted := adaptBidder(appnexusBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderAppnexus, nil)
+	appnexusBidderAdapted := adaptBidder(appnexusBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderAppnexus, nil, nil)
 
 	rubiconBidder, _ := rubicon.Builder(openrtb_ext.BidderRubicon, config.Adapter{})
 	rubiconBidderWithInfo := adapters.EnforceBidderInfo(rubiconBidder, infoActive)
-	rubiconBidderAdapted := adaptBidder(rubiconBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderRubicon, nil)
+	rubiconBidderAdapted := adaptBidder(rubiconBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderRubicon, nil, nil)
 
 	testCases := []struct {
 		description     string
@@ -126,7 +127,7 @@ func TestBuildExchangeBidders(t *testing.T) {
 
 	for _, test := range testCases {
 		cfg := &config.Configuration{Adapters: test.adapterConfig}
-		bidders, errs := buildExchangeBidders(cfg, test.bidderInfos, client, metricEngine)
+		bidders, errs := buildExchangeBidders(cfg, test.bidderInfos, client, metricEngine, nil)
 		assert.Equal(t, test.expectedBidders, bidders, test.description+":bidders")
 		assert.ElementsMatch(t, test.expectedErrors, errs, test.description+":errors")
 	}
@@ -306,10 +307,10 @@ func TestWrapWithMiddleware(t *testing.T) {
 		openrtb_ext.BidderAppnexus: appNexusBidder,
 	}
 
-	wrapWithMiddleware(bidders)
+	wrapWithMiddleware(bidders, nil)
 
 	expected := map[openrtb_ext.BidderName]adaptedBidder{
-		openrtb_ext.BidderAppnexus: &validatedBidder{appNexusBidder},
+		openrtb_ext.BidderAppnexus: &validatedBidder{appNexusBidder, nil},
 	}
 
 	assert.Equal(t, expected, bidders)
@@ -397,7 +398,7 @@ func TestGetDisabledBiddersErrorMessages(t *testing.T) {
 
 type fakeAdaptedBidder struct{}
 
-func (fakeAdaptedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
+func (fakeAdaptedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustments *openrtb_ext.ExtBidAdjustmentFactors, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error) {
 	return nil, nil
 }
 
@@ -7,12 +7,14 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	uuid "github.com/gofrs/uuid"
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 )
@@ -93,27 +95,33 @@ func (d *DebugLog) PutDebugLogError(cache prebid_cache_client.Client, timeout in
 	return nil
 }
 
-func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int, preferDeals bool) *auction {
+func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int, preferDeals bool, multiBid map[string]openrtb_ext.ExtMultiBid) *auction {
 	winningBids := make(map[string]*pbsOrtbBid, numImps)
-	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName]*pbsOrtbBid, numImps)
+	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid, numImps)
 
 	for bidderName, seatBid := range seatBids {
 		if seatBid != nil {
 			for _, bid := range seatBid.bids {
-				cpm := bid.bid.Price
 				wbid, ok := winningBids[bid.bid.ImpID]
 				if !ok || isNewWinningBid(bid.bid, wbid.bid, preferDeals) {
 					winningBids[bid.bid.ImpID] = bid
 				}
-				if bidMap, ok := winningBidsByBidder[bid.bid.ImpID]; ok {
-					bestSoFar, ok := bidMap[bidderName]
-					if !ok || cpm > bestSoFar.bid.Price {
-						bidMap[bidderName] = bid
-					}
-				} else {
-					winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName]*pbsOrtbBid)
-					winningBidsByBidder[bid.bid.ImpID][bidderName] = bid
+				if _, ok := winningBidsByBidder[bid.bid.ImpID]; !ok {
+					winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName][]*pbsOrtbBid)
 				}
+				winningBidsByBidder[bid.bid.ImpID][bidderName] = append(winningBidsByBidder[bid.bid.ImpID][bidderName], bid)
+			}
+		}
+	}
+
+	// Keep only the highest bids of each bidder. Bids with the same price stay in the order the bidder returned them.
+	for _, topBidsPerImp := range winningBidsByBidder {
+		for bidderName, topBidsPerBidder := range topBidsPerImp {
+			sort.SliceStable(topBidsPerBidder, func(i, j int) bool {
+				return topBidsPerBidder[i].bid.Price > topBidsPerBidder[j].bid.Price
+			})
+			if maxBids := maxBidsPerBidder(multiBid, bidderName); len(topBidsPerBidder) > maxBids {
+				topBidsPerImp[bidderName] = topBidsPerBidder[:maxBids]
 			}
 		}
 	}
@@ -124,6 +132,14 @@ func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int
 	}
 }
 
+// maxBidsPerBidder returns how many bids the bidder may keep on each imp. It's 1 unless ext.prebid.multibid says otherwise.
+func maxBidsPerBidder(multiBid map[string]openrtb_ext.ExtMultiBid, bidderName openrtb_ext.BidderName) int {
+	if bidderMultiBid, ok := multiBid[string(bidderName)]; ok && bidderMultiBid.MaxBids != nil && *bidderMultiBid.MaxBids > 1 {
+		return *bidderMultiBid.MaxBids
+	}
+	return 1
+}
+
 // isNewWinningBid calculates if the new bid (nbid) will win against the current winning bid (wbid) given preferDeals.
 func isNewWinningBid(bid, wbid *openrtb.Bid, preferDeals bool) bool {
 	if preferDeals {
@@ -137,11 +153,86 @@ func isNewWinningBid(bid, wbid *openrtb.Bid, preferDeals bool) bool {
 	return bid.Price > wbid.Price
 }
 
+// setClearingPrices prices the winner of each imp according to the auction type of the account. First-price
+// auctions leave the winners at their own price. See config.AccountAuction for how second-price auctions work.
+func (a *auction) setClearingPrices(auctionCfg config.AccountAuction, request *openrtb.BidRequest, seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, conversions currency.Conversions) {
+	if auctionCfg.Type != config.AuctionSecondPrice {
+		return
+	}
+
+	impsByID := make(map[string]*openrtb.Imp, len(request.Imp))
+	for i := range request.Imp {
+		impsByID[request.Imp[i].ID] = &request.Imp[i]
+	}
+
+	a.clearingPrices = make(map[*pbsOrtbBid]float64, len(a.winningBids))
+	for impID, winner := range a.winningBids {
+		if winner.bid.DealID != "" {
+			continue
+		}
+
+		var winnerCurrency string
+		runnerUpPrice, hasRunnerUp := 0.0, false
+		for bidderName, topBidsPerBidder := range a.winningBidsByBidder[impID] {
+			for _, topBid := range topBidsPerBidder {
+				if topBid == winner {
+					if seatBid, ok := seatBids[bidderName]; ok {
+						winnerCurrency = seatBid.currency
+					}
+				} else if !hasRunnerUp || topBid.bid.Price > runnerUpPrice {
+					runnerUpPrice, hasRunnerUp = topBid.bid.Price, true
+				}
+			}
+		}
+
+		clearingPrice := winner.bid.Price
+		if hasRunnerUp {
+			clearingPrice = runnerUpPrice + auctionCfg.PriceIncrement
+		}
+		if floor, ok := impFloorPrice(impsByID[impID], winnerCurrency, conversions); ok && (!hasRunnerUp || clearingPrice < floor) {
+			clearingPrice = floor
+		}
+		if clearingPrice > winner.bid.Price {
+			clearingPrice = winner.bid.Price
+		}
+		a.clearingPrices[winner] = clearingPrice
+	}
+}
+
+// impFloorPrice returns the floor of the imp in the given currency, if it has one.
+func impFloorPrice(imp *openrtb.Imp, bidCurrency string, conversions currency.Conversions) (float64, bool) {
+	if imp == nil || imp.BidFloor <= 0 {
+		return 0, false
+	}
+	floorCurrency := imp.BidFloorCur
+	if floorCurrency == "" {
+		floorCurrency = "USD"
+	}
+	if bidCurrency == "" {
+		bidCurrency = "USD"
+	}
+	rate, err := conversions.GetRate(floorCurrency, bidCurrency)
+	if err != nil {
+		return 0, false
+	}
+	return imp.BidFloor * rate, true
+}
+
+// price returns the price the bid is targeted at, which is its clearing price if the auction set one.
+func (a *auction) price(bid *pbsOrtbBid) float64 {
+	if clearingPrice, ok := a.clearingPrices[bid]; ok {
+		return clearingPrice
+	}
+	return bid.bid.Price
+}
+
 func (a *auction) setRoundedPrices(priceGranularity openrtb_ext.PriceGranularity) {
 	roundedPrices := make(map[*pbsOrtbBid]string, 5*len(a.winningBids))
 	for _, topBidsPerImp := range a.winningBidsByBidder {
-		for _, topBidPerBidder := range topBidsPerImp {
-			roundedPrices[topBidPerBidder] = GetPriceBucket(topBidPerBidder.bid.Price, priceGranularity)
+		for _, topBidsPerBidder := range topBidsPerImp {
+			for _, topBid := range topBidsPerBidder {
+				roundedPrices[topBid] = GetPriceBucket(a.price(topBid), priceGranularity)
+			}
 		}
 	}
 	a.roundedPrices = roundedPrices
@@ -176,63 +267,65 @@ func (a *auction) doCache(ctx context.Context, cache prebid_cache_client.Client,
 		expByImp[imp.ID] = imp.Exp
 	}
 	for _, topBidsPerImp := range a.winningBidsByBidder {
-		for bidderName, topBidPerBidder := range topBidsPerImp {
-			impID := topBidPerBidder.bid.ImpID
-			isOverallWinner := a.winningBids[impID] == topBidPerBidder
-			if !includeBidderKeys && !isOverallWinner {
-				continue
-			}
-			var customCacheKey string
-			var catDur string
-			useCustomCacheKey := false
-			if competitiveExclusion && isOverallWinner || includeBidderKeys {
-				// set custom cache key for winning bid when competitive exclusion applies
-				catDur = bidCategory[topBidPerBidder.bid.ID]
-				if len(catDur) > 0 {
-					customCacheKey = fmt.Sprintf("%s_%s", catDur, hbCacheID)
-					useCustomCacheKey = true
+		for bidderName, topBidsPerBidder := range topBidsPerImp {
+			for _, topBidPerBidder := range topBidsPerBidder {
+				impID := topBidPerBidder.bid.ImpID
+				isOverallWinner := a.winningBids[impID] == topBidPerBidder
+				if !includeBidderKeys && !isOverallWinner {
+					continue
 				}
-			}
-			if bids {
-				if jsonBytes, err := json.Marshal(topBidPerBidder.bid); err == nil {
-					jsonBytes, err = evTracking.modifyBidJSON(topBidPerBidder, bidderName, jsonBytes)
-					if err != nil {
-						errs = append(errs, err)
+				var customCacheKey string
+				var catDur string
+				useCustomCacheKey := false
+				if competitiveExclusion && isOverallWinner || includeBidderKeys {
+					// set custom cache key for winning bid when competitive exclusion applies
+					catDur = bidCategory[topBidPerBidder.bid.ID]
+					if len(catDur) > 0 {
+						customCacheKey = fmt.Sprintf("%s_%s", catDur, hbCacheID)
+						useCustomCacheKey = true
 					}
-					if useCustomCacheKey {
-						// not allowed if bids is true; log error and cache normally
-						errs = append(errs, errors.New("cannot use custom cache key for non-vast bids"))
-					}
-					toCache = append(toCache, prebid_cache_client.Cacheable{
-						Type:       prebid_cache_client.TypeJSON,
-						Data:       jsonBytes,
-						TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-					})
-					bidIndices[len(toCache)-1] = topBidPerBidder.bid
-				} else {
-					errs = append(errs, err)
 				}
-			}
-			if vast && topBidPerBidder.bidType == openrtb_ext.BidTypeVideo {
-				vastXML := makeVAST(topBidPerBidder.bid)
-				if jsonBytes, err := json.Marshal(vastXML); err == nil {
-					if useCustomCacheKey {
+				if bids {
+					if jsonBytes, err := json.Marshal(topBidPerBidder.bid); err == nil {
+						jsonBytes, err = evTracking.modifyBidJSON(topBidPerBidder, bidderName, jsonBytes)
+						if err != nil {
+							errs = append(errs, err)
+						}
+						if useCustomCacheKey {
+							// not allowed if bids is true; log error and cache normally
+							errs = append(errs, errors.New("cannot use custom cache key for non-vast bids"))
+						}
 						toCache = append(toCache, prebid_cache_client.Cacheable{
-							Type:       prebid_cache_client.TypeXML,
+							Type:       prebid_cache_client.TypeJSON,
 							Data:       jsonBytes,
 							TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-							Key:        customCacheKey,
 						})
+						bidIndices[len(toCache)-1] = topBidPerBidder.bid
 					} else {
-						toCache = append(toCache, prebid_cache_client.Cacheable{
-							Type:       prebid_cache_client.TypeXML,
-							Data:       jsonBytes,
-							TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-						})
+						errs = append(errs, err)
+					}
+				}
+				if vast && topBidPerBidder.bidType == openrtb_ext.BidTypeVideo {
+					vastXML := makeVAST(topBidPerBidder.bid)
+					if jsonBytes, err := json.Marshal(vastXML); err == nil {
+						if useCustomCacheKey {
+							toCache = append(toCache, prebid_cache_client.Cacheable{
+								Type:       prebid_cache_client.TypeXML,
+								Data:       jsonBytes,
+								TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
+								Key:        customCacheKey,
+							})
+						} else {
+							toCache = append(toCache, prebid_cache_client.Cacheable{
+								Type:       prebid_cache_client.TypeXML,
+								Data:       jsonBytes,
+								TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
+							})
+						}
+						vastIndices[len(toCache)-1] = topBidPerBidder.bid
+					} else {
+						errs = append(errs, err)
 					}
-					vastIndices[len(toCache)-1] = topBidPerBidder.bid
-				} else {
-					errs = append(errs, err)
 				}
 			}
 		}
@@ -350,8 +443,11 @@ func defTTL(bidType openrtb_ext.BidType, defaultTTLs *config.DefaultTTLs) (ttl i
 type auction struct {
 	// winningBids is a map from imp.id to the highest overall CPM bid in that imp.
 	winningBids map[string]*pbsOrtbBid
-	// winningBidsByBidder stores the highest bid on each imp by each bidder.
-	winningBidsByBidder map[string]map[openrtb_ext.BidderName]*pbsOrtbBid
+	// winningBidsByBidder stores the highest bids on each imp by each bidder, ordered from the highest CPM.
+	// Each bidder keeps a single bid unless the request asked for more through ext.prebid.multibid.
+	winningBidsByBidder map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid
+	// clearingPrices stores the prices the winning bids clear at, if the auction isn't a first-price auction.
+	clearingPrices map[*pbsOrtbBid]float64
 	// roundedPrices stores the price strings rounded for each bid according to the price granularity.
 	roundedPrices map[*pbsOrtbBid]string
 	// cacheIds stores the UUIDs from Prebid Cache for fetching the full bid JSON.
@@ -10,8 +10,10 @@ import (
 	"regexp"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 
@@ -138,7 +140,7 @@ func loadCacheSpec(filename string) (*cacheSpec, error) {
 func runCacheSpec(t *testing.T, fileDisplayName string, specData *cacheSpec) {
 	var bid *pbsOrtbBid
 	winningBidsByImp := make(map[string]*pbsOrtbBid)
-	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName]*pbsOrtbBid)
+	winningBidsByBidder := make(map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid)
 	roundedPrices := make(map[*pbsOrtbBid]string)
 	bidCategory := make(map[string]string)
 
@@ -159,12 +161,12 @@ func runCacheSpec(t *testing.T, fileDisplayName string, specData *cacheSpec) {
 		// Map this bid if it's the highest we've seen from this bidder so far
 		if _, ok := winningBidsByBidder[bid.bid.ImpID]; ok {
 			bestSoFar, ok := winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder]
-			if !ok || cpm > bestSoFar.bid.Price {
-				winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = bid
+			if !ok || cpm > bestSoFar[0].bid.Price {
+				winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = []*pbsOrtbBid{bid}
 			}
 		} else {
-			winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName]*pbsOrtbBid)
-			winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = bid
+			winningBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName][]*pbsOrtbBid)
+			winningBidsByBidder[bid.bid.ImpID][pbsBid.Bidder] = []*pbsOrtbBid{bid}
 		}
 
 		if len(pbsBid.Bid.Cat) == 1 {
@@ -311,11 +313,14 @@ func TestNewAuction(t *testing.T) {
 			Price: 1.44,
 		},
 	}
+	maxBids2 := 2
+	maxBids3 := 3
 	tests := []struct {
 		description     string
 		seatBids        map[openrtb_ext.BidderName]*pbsOrtbSeatBid
 		numImps         int
 		preferDeals     bool
+		multiBid        map[string]openrtb_ext.ExtMultiBid
 		expectedAuction auction
 	}{
 		{
@@ -334,10 +339,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p230,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p123,
-						"rubicon":  &bid1p230,
+						"appnexus": {&bid1p123},
+						"rubicon":  {&bid1p230},
 					},
 				},
 			},
@@ -362,15 +367,15 @@ func TestNewAuction(t *testing.T) {
 					"imp1": &bid1p230,
 					"imp2": &bid2p144,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p230,
-						"rubicon":  &bid1p077,
-						"openx":    &bid1p123,
+						"appnexus": {&bid1p230},
+						"rubicon":  {&bid1p077},
+						"openx":    {&bid1p123},
 					},
 					"imp2": {
-						"appnexus": &bid2p123,
-						"rubicon":  &bid2p144,
+						"appnexus": {&bid2p123},
+						"rubicon":  {&bid2p144},
 					},
 				},
 			},
@@ -391,10 +396,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p123,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p123,
-						"rubicon":  &bid1p088d,
+						"appnexus": {&bid1p123},
+						"rubicon":  {&bid1p088d},
 					},
 				},
 			},
@@ -415,10 +420,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p088d,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p123,
-						"rubicon":  &bid1p088d,
+						"appnexus": {&bid1p123},
+						"rubicon":  {&bid1p088d},
 					},
 				},
 			},
@@ -439,10 +444,10 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p166d,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p166d,
-						"rubicon":  &bid1p088d,
+						"appnexus": {&bid1p166d},
+						"rubicon":  {&bid1p088d},
 					},
 				},
 			},
@@ -466,11 +471,61 @@ func TestNewAuction(t *testing.T) {
 				winningBids: map[string]*pbsOrtbBid{
 					"imp1": &bid1p166d,
 				},
-				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 					"imp1": {
-						"appnexus": &bid1p166d,
-						"rubicon":  &bid1p088d,
-						"openx":    &bid1p230,
+						"appnexus": {&bid1p166d},
+						"rubicon":  {&bid1p088d},
+						"openx":    {&bid1p230},
+					},
+				},
+			},
+		},
+		{
+			description: "Multibid keeps the highest bids of the bidder",
+			seatBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {
+					bids: []*pbsOrtbBid{&bid1p077, &bid1p230, &bid1p123},
+				},
+				"rubicon": {
+					bids: []*pbsOrtbBid{&bid1p088d, &bid1p166d},
+				},
+			},
+			numImps:     1,
+			preferDeals: false,
+			multiBid: map[string]openrtb_ext.ExtMultiBid{
+				"appnexus": {Bidder: "appnexus", MaxBids: &maxBids2},
+			},
+			expectedAuction: auction{
+				winningBids: map[string]*pbsOrtbBid{
+					"imp1": &bid1p230,
+				},
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
+					"imp1": {
+						"appnexus": {&bid1p230, &bid1p123},
+						"rubicon":  {&bid1p166d},
+					},
+				},
+			},
+		},
+		{
+			description: "Multibid with fewer bids than maxbids",
+			seatBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {
+					bids: []*pbsOrtbBid{&bid1p077, &bid1p123},
+				},
+			},
+			numImps:     1,
+			preferDeals: false,
+			multiBid: map[string]openrtb_ext.ExtMultiBid{
+				"appnexus": {Bidder: "appnexus", MaxBids: &maxBids3},
+			},
+			expectedAuction: auction{
+				winningBids: map[string]*pbsOrtbBid{
+					"imp1": &bid1p123,
+				},
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
+					"imp1": {
+						"appnexus": {&bid1p123, &bid1p077},
 					},
 				},
 			},
@@ -478,13 +533,60 @@ func TestNewAuction(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		auc := newAuction(test.seatBids, test.numImps, test.preferDeals)
+		auc := newAuction(test.seatBids, test.numImps, test.preferDeals, test.multiBid)
 
 		assert.Equal(t, test.expectedAuction, *auc, test.description)
 	}
 
 }
 
+func TestSetClearingPrices(t *testing.T) {
+	winner := &pbsOrtbBid{bid: &openrtb.Bid{ImpID: "imp1", Price: 3.00}}
+	runnerUp := &pbsOrtbBid{bid: &openrtb.Bid{ImpID: "imp1", Price: 2.00}}
+	aloneWithFloor := &pbsOrtbBid{bid: &openrtb.Bid{ImpID: "imp2", Price: 3.00}}
+	aloneWithoutFloor := &pbsOrtbBid{bid: &openrtb.Bid{ImpID: "imp3", Price: 3.00}}
+	softFloorWinner := &pbsOrtbBid{bid: &openrtb.Bid{ImpID: "imp4", Price: 3.00}}
+	belowSoftFloor := &pbsOrtbBid{bid: &openrtb.Bid{ImpID: "imp4", Price: 1.00}}
+	deal := &pbsOrtbBid{bid: &openrtb.Bid{ImpID: "imp5", Price: 3.00, DealID: "deal"}}
+	closeWinner := &pbsOrtbBid{bid: &openrtb.Bid{ImpID: "imp6", Price: 2.00}}
+	closeRunnerUp := &pbsOrtbBid{bid: &openrtb.Bid{ImpID: "imp6", Price: 1.999}}
+
+	request := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "imp1"},
+			{ID: "imp2", BidFloor: 1.00, BidFloorCur: "EUR"},
+			{ID: "imp3"},
+			{ID: "imp4", BidFloor: 1.50},
+			{ID: "imp5", BidFloor: 1.00},
+			{ID: "imp6"},
+		},
+	}
+	seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexus": {bids: []*pbsOrtbBid{winner, aloneWithFloor, aloneWithoutFloor, softFloorWinner, deal, closeWinner}, currency: "USD"},
+		"rubicon":  {bids: []*pbsOrtbBid{runnerUp, belowSoftFloor, closeRunnerUp}, currency: "USD"},
+	}
+	conversions := currency.NewRates(time.Now(), map[string]map[string]float64{"EUR": {"USD": 1.2}})
+
+	auc := newAuction(seatBids, len(request.Imp), false, nil)
+	auc.setClearingPrices(config.AccountAuction{Type: config.AuctionSecondPrice, PriceIncrement: 0.01}, request, seatBids, conversions)
+
+	assert.Equal(t, map[*pbsOrtbBid]float64{
+		winner:            2.01,
+		aloneWithFloor:    1.20,
+		aloneWithoutFloor: 3.00,
+		softFloorWinner:   1.50,
+		closeWinner:       2.00,
+	}, auc.clearingPrices)
+
+	auc.setRoundedPrices(openrtb_ext.PriceGranularityFromString("med"))
+	assert.Equal(t, "2.00", auc.roundedPrices[winner], "The targeting price should be the clearing price")
+	assert.Equal(t, "1.00", auc.roundedPrices[belowSoftFloor], "Losing bids should keep their own price")
+
+	firstPrice := newAuction(seatBids, len(request.Imp), false, nil)
+	firstPrice.setClearingPrices(config.AccountAuction{Type: config.AuctionFirstPrice, PriceIncrement: 0.01}, request, seatBids, conversions)
+	assert.Nil(t, firstPrice.clearingPrices)
+}
+
 type cacheSpec struct {
 	BidRequest                  openrtb.BidRequest              `json:"bidRequest"`
 	PbsBids                     []pbsBid                        `json:"pbsBids"`
@@ -20,6 +20,7 @@ import (
 	nativeRequests "github.com/mxmCherry/openrtb/native/request"
 	nativeResponse "github.com/mxmCherry/openrtb/native/response"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/metrics"
@@ -49,7 +50,7 @@ type adaptedBidder interface {
 	//
 	// Any errors will be user-facing in the API.
 	// Error messages should help publishers understand what might account for "bad" bids.
-	requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool) (*pbsOrtbSeatBid, []error)
+	requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustments *openrtb_ext.ExtBidAdjustmentFactors, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error)
 }
 
 // pbsOrtbBid is a Bid returned by an adaptedBidder.
@@ -87,18 +88,25 @@ type pbsOrtbSeatBid struct {
 	// if len(bids) > 0, this will become response.seatbid[i].ext.{bidder} on the final OpenRTB response.
 	// if len(bids) == 0, this will be ignored because the OpenRTB spec doesn't allow a SeatBid with 0 Bids.
 	ext json.RawMessage
+	// nonBids lists the bids of this adaptedBidder which were rejected, and why.
+	// These will become response.ext.seatnonbid on the final OpenRTB response.
+	nonBids []openrtb_ext.ExtNonBid
+	// httpRoundTrips is the number of HTTP requests which were sent to the bidder's server. Stored responses,
+	// and requests stopped by the circuit breaker, aren't counted.
+	httpRoundTrips int
 }
 
 // adaptBidder converts an adapters.Bidder into an exchange.adaptedBidder.
 //
 // The name refers to the "Adapter" architecture pattern, and should not be confused with a Prebid "Adapter"
 // (which is being phased out and replaced by Bidder for OpenRTB auctions)
-func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *adapters.DebugInfo) adaptedBidder {
+func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *adapters.DebugInfo, breakers *circuitbreaker.Breakers) adaptedBidder {
 	return &bidderAdapter{
 		Bidder:     bidder,
 		BidderName: name,
 		Client:     client,
 		me:         me,
+		breakers:   breakers,
 		config: bidderAdapterConfig{
 			Debug:              cfg.Debug,
 			DisableConnMetrics: cfg.Metrics.Disabled.AdapterConnectionMetrics,
@@ -119,6 +127,7 @@ type bidderAdapter struct {
 	BidderName openrtb_ext.BidderName
 	Client     *http.Client
 	me         metrics.MetricsEngine
+	breakers   *circuitbreaker.Breakers
 	config     bidderAdapterConfig
 }
 
@@ -128,10 +137,16 @@ type bidderAdapterConfig struct {
 	DebugInfo          adapters.DebugInfo
 }
 
-func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
-	reqData, errs := bidder.Bidder.MakeRequests(request, reqInfo)
+func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustments *openrtb_ext.ExtBidAdjustmentFactors, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error) {
+	// Imps with a stored response don't need a request. The adapter only builds requests for the rest.
+	var reqData []*adapters.RequestData
+	var errs []error
+	if requestWithoutStored := removeStoredResponseImps(request, bidderStoredResponses); len(bidderStoredResponses) == 0 || len(requestWithoutStored.Imp) > 0 {
+		reqData, errs = bidder.Bidder.MakeRequests(requestWithoutStored, reqInfo)
+	}
 
-	if len(reqData) == 0 {
+	numResponses := len(reqData) + len(bidderStoredResponses)
+	if numResponses == 0 {
 		// If the adapter failed to generate both requests and errors, this is an error.
 		if len(errs) == 0 {
 			errs = append(errs, &errortypes.FailedToRequestBids{Message: "The adapter failed to generate any bid requests, but also failed to generate an error explaining why"})
@@ -141,7 +156,10 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 
 	// Make any HTTP requests in parallel.
 	// If the bidder only needs to make one, save some cycles by just using the current one.
-	responseChannel := make(chan *httpCallInfo, len(reqData))
+	responseChannel := make(chan *httpCallInfo, numResponses)
+	for impID, storedResponse := range bidderStoredResponses {
+		responseChannel <- prepareStoredResponse(impID, storedResponse)
+	}
 	if len(reqData) == 1 {
 		responseChannel <- bidder.doRequest(ctx, reqData[0])
 	} else {
@@ -154,15 +172,18 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 
 	defaultCurrency := "USD"
 	seatBid := &pbsOrtbSeatBid{
-		bids:      make([]*pbsOrtbBid, 0, len(reqData)),
+		bids:      make([]*pbsOrtbBid, 0, numResponses),
 		currency:  defaultCurrency,
-		httpCalls: make([]*openrtb_ext.ExtHttpCall, 0, len(reqData)),
+		httpCalls: make([]*openrtb_ext.ExtHttpCall, 0, numResponses),
 	}
 
 	// If the bidder made multiple requests, we still want them to enter as many bids as possible...
 	// even if the timeout occurs sometime halfway through.
-	for i := 0; i < len(reqData); i++ {
+	for i := 0; i < numResponses; i++ {
 		httpInfo := <-responseChannel
+		if httpInfo.sent {
+			seatBid.httpRoundTrips++
+		}
 		// If this is a test bid, capture debugging info from the requests.
 		// Write debug data to ext in case if:
 		// - debugContextKey (url param) in true
@@ -222,6 +243,7 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 					// Conversion rate found, using it for conversion
 					for i := 0; i < len(bidResponse.Bids); i++ {
 						if bidResponse.Bids[i].Bid != nil {
+							bidAdjustment := bidAdjustments.Factor(string(name), bidResponse.Bids[i].BidType, bidResponse.Bids[i].Bid.DealID)
 							bidResponse.Bids[i].Bid.Price = bidResponse.Bids[i].Bid.Price * bidAdjustment * conversionRate
 						}
 						seatBid.bids = append(seatBid.bids, &pbsOrtbBid{
@@ -234,6 +256,9 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 				} else {
 					// If no conversions found, do not handle the bid
 					errs = append(errs, err)
+					for _, typedBid := range bidResponse.Bids {
+						seatBid.addNonBid(typedBid.Bid, openrtb_ext.NonBidErrorInvalidBidResponse)
+					}
 				}
 			}
 		} else {
@@ -244,6 +269,37 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 	return seatBid, errs
 }
 
+// removeStoredResponseImps returns a copy of the request without the imps which have a stored response.
+// The original request is kept as is, since the adapter needs every imp to make sense of the stored responses.
+func removeStoredResponseImps(request *openrtb.BidRequest, bidderStoredResponses map[string]json.RawMessage) *openrtb.BidRequest {
+	if len(bidderStoredResponses) == 0 {
+		return request
+	}
+
+	requestCopy := *request
+	requestCopy.Imp = make([]openrtb.Imp, 0, len(request.Imp))
+	for _, imp := range request.Imp {
+		if _, ok := bidderStoredResponses[imp.ID]; !ok {
+			requestCopy.Imp = append(requestCopy.Imp, imp)
+		}
+	}
+	return &requestCopy
+}
+
+// prepareStoredResponse makes a stored bidder response look like the answer to an HTTP call the bidder made for the imp.
+func prepareStoredResponse(impID string, storedResponse json.RawMessage) *httpCallInfo {
+	return &httpCallInfo{
+		request: &adapters.RequestData{
+			Method: "STORED_BID_RESPONSE",
+			Uri:    impID,
+		},
+		response: &adapters.ResponseData{
+			StatusCode: http.StatusOK,
+			Body:       storedResponse,
+		},
+	}
+}
+
 func addNativeTypes(bid *openrtb.Bid, request *openrtb.BidRequest) (*nativeResponse.Response, []error) {
 	var errs []error
 	var nativeMarkup *nativeResponse.Response
@@ -358,6 +414,16 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	}
 	httpReq.Header = req.Headers
 
+	host := httpReq.URL.Host
+	if !bidder.breakers.Allow(bidder.BidderName, host) {
+		return &httpCallInfo{
+			request: req,
+			err: &errortypes.BidderCircuitOpen{
+				Message: fmt.Sprintf("%s was not called, since its circuit breaker is open after too many failures", bidder.BidderName),
+			},
+		}
+	}
+
 	// If adapter connection metrics are not disabled, add the client trace
 	// to get complete connection info into our metrics
 	if !bidder.config.DisableConnMetrics {
@@ -365,6 +431,10 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	}
 	httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq)
 	if err != nil {
+		// A call cancelled because the auction is over says nothing about the bidder's server.
+		if err != context.Canceled {
+			bidder.breakers.Record(bidder.BidderName, host, false)
+		}
 		if err == context.DeadlineExceeded {
 			err = &errortypes.Timeout{Message: err.Error()}
 			var corebidder adapters.Bidder = bidder.Bidder
@@ -385,18 +455,23 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 		return &httpCallInfo{
 			request: req,
 			err:     err,
+			sent:    true,
 		}
 	}
 
 	respBody, err := ioutil.ReadAll(httpResp.Body)
 	if err != nil {
+		bidder.breakers.Record(bidder.BidderName, host, false)
 		return &httpCallInfo{
 			request: req,
 			err:     err,
+			sent:    true,
 		}
 	}
 	defer httpResp.Body.Close()
 
+	bidder.breakers.Record(bidder.BidderName, host, httpResp.StatusCode < 500)
+
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 400 {
 		err = &errortypes.BadServerResponse{
 			Message: fmt.Sprintf("Server responded with failure status: %d. Set request.test = 1 for debugging info.", httpResp.StatusCode),
@@ -410,7 +485,8 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 			Body:       respBody,
 			Headers:    httpResp.Header,
 		},
-		err: err,
+		err:  err,
+		sent: true,
 	}
 }
 
@@ -459,6 +535,8 @@ type httpCallInfo struct {
 	request  *adapters.RequestData
 	response *adapters.ResponseData
 	err      error
+	// sent is true if the request was sent to the bidder's server
+	sent bool
 }
 
 // This function adds an httptrace.ClientTrace object to the context so, if connection with the bidder
@@ -18,8 +18,10 @@ import (
 	"github.com/golang/glog"
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
@@ -92,10 +94,10 @@ func TestSingleBidder(t *testing.T) {
 		}
 		bidderImpl.bidResponse = mockBidderResponse
 
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, test.debugInfo)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, test.debugInfo, nil)
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 
-		seatBid, errs := bidder.requestBid(ctx, &openrtb.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true)
+		seatBid, errs := bidder.requestBid(ctx, &openrtb.BidRequest{}, "test", &openrtb_ext.ExtBidAdjustmentFactors{Bidders: map[string]float64{"test": bidAdjustment}}, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, nil)
 
 		// Make sure the goodSingleBidder was called with the expected arguments.
 		if bidderImpl.httpResponse == nil {
@@ -144,6 +146,43 @@ func TestSingleBidder(t *testing.T) {
 
 // TestMultiBidder makes sure all the requests get sent, and the responses processed.
 // Because this is done in parallel, it should be run under the race detector.
+func TestBidAdjustmentsByMediaTypeAndDeal(t *testing.T) {
+	server := httptest.NewServer(mockHandler(200, "getBody", "{}"))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte("{}"),
+			Headers: http.Header{},
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{Price: 2}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{Price: 2}, BidType: openrtb_ext.BidTypeVideo},
+				{Bid: &openrtb.Bid{Price: 2, DealID: "deal-1"}, BidType: openrtb_ext.BidTypeVideo},
+			},
+		},
+	}
+	bidAdjustments := &openrtb_ext.ExtBidAdjustmentFactors{
+		Bidders: map[string]float64{"test": 0.5},
+		MediaTypes: map[openrtb_ext.BidType]map[string]openrtb_ext.ExtDealAdjustmentFactors{
+			openrtb_ext.BidTypeVideo: {"test": {"*": 0.75, "deal-1": 1.5}},
+		},
+	}
+
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", bidAdjustments, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, nil)
+
+	assert.Empty(t, errs)
+	if assert.Len(t, seatBid.bids, 3) {
+		assert.Equal(t, 1.0, seatBid.bids[0].bid.Price, "The bidder's factor should apply to the media types without one")
+		assert.Equal(t, 1.5, seatBid.bids[1].bid.Price, "The media type's factor should apply to the bids without a deal")
+		assert.Equal(t, 3.0, seatBid.bids[2].bid.Price, "The deal's factor should take precedence")
+	}
+}
+
 func TestMultiBidder(t *testing.T) {
 	respStatus := 200
 	getRespBody := "{\"wasPost\":false}"
@@ -182,9 +221,9 @@ func TestMultiBidder(t *testing.T) {
 			}},
 		bidResponse: mockBidderResponse,
 	}
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", nil, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, nil)
 
 	if seatBid == nil {
 		t.Fatalf("SeatBid should exist, because bids exist.")
@@ -239,6 +278,51 @@ func TestBidderTimeout(t *testing.T) {
 }
 
 // TestInvalidRequest makes sure that bidderAdapter.doRequest returns errors on bad requests.
+func TestStoredBidResponses(t *testing.T) {
+	respBody := "{\"bid\":false}"
+	server := httptest.NewServer(mockHandler(200, "getBody", respBody))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte("{\"key\":\"val\"}"),
+			Headers: http.Header{},
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, &adapters.DebugInfo{Allow: true}, nil)
+	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
+
+	request := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "live-imp"}, {ID: "stored-imp"}}}
+	storedResponses := map[string]json.RawMessage{"stored-imp": json.RawMessage(`{"stored":true}`)}
+
+	seatBid, errs := bidder.requestBid(context.Background(), request, "test", nil, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, storedResponses)
+
+	assert.Empty(t, errs, "Unexpected errors")
+	assert.Len(t, seatBid.bids, 2, "Expected a bid for the live response and for the stored response")
+	assert.Equal(t, []openrtb.Imp{{ID: "live-imp"}}, bidderImpl.bidRequest.Imp, "The adapter should only make requests for imps without a stored response")
+	assert.Len(t, request.Imp, 2, "The original request should keep every imp")
+	assert.Equal(t, 1, seatBid.httpRoundTrips, "Only the live request should count as an HTTP call")
+
+	// With every imp covered by a stored response, the bidder shouldn't make any requests.
+	bidderImpl.bidRequest = nil
+	storedResponses["live-imp"] = json.RawMessage(`{"stored":true}`)
+
+	seatBid, errs = bidder.requestBid(context.Background(), request, "test", nil, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, storedResponses)
+
+	assert.Empty(t, errs, "Unexpected errors")
+	assert.Len(t, seatBid.bids, 2, "Expected a bid for each stored response")
+	assert.Nil(t, bidderImpl.bidRequest, "The adapter shouldn't make requests when every imp has a stored response")
+	assert.Equal(t, `{"stored":true}`, string(bidderImpl.httpResponse.Body), "The adapter should get the stored response as the response body")
+	assert.Equal(t, 0, seatBid.httpRoundTrips, "Stored responses shouldn't count as HTTP calls")
+}
+
 func TestInvalidRequest(t *testing.T) {
 	server := httptest.NewServer(mockHandler(200, "getBody", "postBody"))
 	bidder := &bidderAdapter{
@@ -278,6 +362,40 @@ func TestConnectionClose(t *testing.T) {
 	}
 }
 
+// TestCircuitBreaker makes sure that bidderAdapter.doRequest stops calling a server which keeps failing.
+func TestCircuitBreaker(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	bidder := &bidderAdapter{
+		Bidder:     &mixedMultiBidder{},
+		Client:     server.Client(),
+		BidderName: openrtb_ext.BidderAppnexus,
+		me:         &metricsConfig.DummyMetricsEngine{},
+		breakers: circuitbreaker.NewBreakers(config.CircuitBreaker{
+			Enabled:            true,
+			ErrorThreshold:     2,
+			OpenIntervalMillis: 60000,
+			HalfOpenProbes:     1,
+		}),
+	}
+	request := &adapters.RequestData{Method: "POST", Uri: server.URL}
+
+	for i := 0; i < 2; i++ {
+		callInfo := bidder.doRequest(context.Background(), request)
+		assert.IsType(t, &errortypes.BadServerResponse{}, callInfo.err, "The server should be called until the threshold is reached")
+		assert.True(t, callInfo.sent)
+	}
+	callInfo := bidder.doRequest(context.Background(), request)
+	assert.IsType(t, &errortypes.BidderCircuitOpen{}, callInfo.err, "The server shouldn't be called once the circuit is open")
+	assert.False(t, callInfo.sent)
+	assert.Equal(t, 2, calls)
+}
+
 type bid struct {
 	currency string
 	price    float64
@@ -544,7 +662,7 @@ func TestMultiCurrencies(t *testing.T) {
 		)
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 		currencyConverter := currency.NewRateConverter(
 			&http.Client{},
 			mockedHTTPServer.URL,
@@ -557,10 +675,11 @@ func TestMultiCurrencies(t *testing.T) {
 			context.Background(),
 			&openrtb.BidRequest{},
 			"test",
-			1,
+			nil,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
 			true,
+			nil,
 		)
 
 		// Verify:
@@ -696,16 +815,17 @@ func TestMultiCurrencies_RateConverterNotSet(t *testing.T) {
 		}
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 		seatBid, errs := bidder.requestBid(
 			context.Background(),
 			&openrtb.BidRequest{},
 			"test",
-			1,
+			nil,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
 			true,
+			nil,
 		)
 
 		// Verify:
@@ -863,7 +983,7 @@ func TestMultiCurrencies_RequestCurrencyPick(t *testing.T) {
 		}
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 		currencyConverter := currency.NewRateConverter(
 			&http.Client{},
 			mockedHTTPServer.URL,
@@ -875,10 +995,11 @@ func TestMultiCurrencies_RequestCurrencyPick(t *testing.T) {
 				Cur: tc.bidRequestCurrencies,
 			},
 			"test",
-			1,
+			nil,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
 			true,
+			nil,
 		)
 
 		// Verify:
@@ -1054,17 +1175,18 @@ func TestMobileNativeTypes(t *testing.T) {
 			},
 			bidResponse: tc.mockBidderResponse,
 		}
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 
 		seatBids, _ := bidder.requestBid(
 			context.Background(),
 			tc.mockBidderRequest,
 			"test",
-			1.0,
+			nil,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
 			true,
+			nil,
 		)
 
 		var actualValue string
@@ -1076,9 +1198,9 @@ func TestMobileNativeTypes(t *testing.T) {
 }
 
 func TestErrorReporting(t *testing.T) {
-	bidder := adaptBidder(&bidRejector{}, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+	bidder := adaptBidder(&bidRejector{}, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	bids, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true)
+	bids, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", nil, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, nil)
 	if bids != nil {
 		t.Errorf("There should be no seatbid if no http requests are returned.")
 	}
@@ -1259,9 +1381,9 @@ func TestCallRecordAdapterConnections(t *testing.T) {
 	metrics.On("RecordAdapterConnections", expectedAdapterName, false, mock.MatchedBy(compareConnWaitTime)).Once()
 
 	// Run requestBid using an http.Client with a mock handler
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, metrics, openrtb_ext.BidderAppnexus, nil)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, metrics, openrtb_ext.BidderAppnexus, nil, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	_, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true)
+	_, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", &openrtb_ext.ExtBidAdjustmentFactors{Bidders: map[string]float64{"test": bidAdjustment}}, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, nil)
 
 	// Assert no errors
 	assert.Equal(t, 0, len(errs), "bidder.requestBid returned errors %v \n", errs)
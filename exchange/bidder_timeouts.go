@@ -0,0 +1,123 @@
+package exchange
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// bidderLatencies keeps the latest response times of each bidder, to give each one a deadline which fits how
+// long it usually takes to answer.
+//
+// A nil bidderLatencies gives no bidder a deadline of its own. It may be used by several goroutines at once.
+type bidderLatencies struct {
+	percentile float64
+	sampleSize int
+	minSamples int
+	minTimeout time.Duration
+
+	mutex   sync.Mutex
+	samples map[openrtb_ext.BidderName]*latencySamples
+}
+
+// latencySamples is a ring buffer of response times.
+type latencySamples struct {
+	durations []time.Duration
+	next      int
+}
+
+func newBidderLatencies(cfg config.AdaptiveBidderTimeouts) *bidderLatencies {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &bidderLatencies{
+		percentile: cfg.Percentile,
+		sampleSize: cfg.SampleSize,
+		minSamples: cfg.MinSamples,
+		minTimeout: time.Duration(cfg.MinTimeoutMillis) * time.Millisecond,
+		samples:    make(map[openrtb_ext.BidderName]*latencySamples),
+	}
+}
+
+// record adds a response time of the bidder.
+func (l *bidderLatencies) record(bidder openrtb_ext.BidderName, latency time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	samples, ok := l.samples[bidder]
+	if !ok {
+		samples = &latencySamples{durations: make([]time.Duration, 0, l.sampleSize)}
+		l.samples[bidder] = samples
+	}
+	if len(samples.durations) < l.sampleSize {
+		samples.durations = append(samples.durations, latency)
+	} else {
+		samples.durations[samples.next] = latency
+	}
+	samples.next = (samples.next + 1) % l.sampleSize
+}
+
+// timeout returns the time the bidder should be given to answer. It returns false if there aren't enough
+// response times of the bidder yet.
+func (l *bidderLatencies) timeout(bidder openrtb_ext.BidderName) (time.Duration, bool) {
+	if l == nil {
+		return 0, false
+	}
+	l.mutex.Lock()
+	samples, ok := l.samples[bidder]
+	if !ok || len(samples.durations) < l.minSamples {
+		l.mutex.Unlock()
+		return 0, false
+	}
+	durations := make([]time.Duration, len(samples.durations))
+	copy(durations, samples.durations)
+	l.mutex.Unlock()
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	index := int(math.Ceil(l.percentile/100*float64(len(durations)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	if timeout := durations[index]; timeout > l.minTimeout {
+		return timeout, true
+	}
+	return l.minTimeout, true
+}
+
+// makeBidderContext gives the bidder a deadline which fits its usual response time, within the deadline of
+// the auction. It returns the tmax which goes with the deadline, or the one given if the deadline is left as is.
+func (e *exchange) makeBidderContext(ctx context.Context, bidder openrtb_ext.BidderName, tmax int64) (context.Context, context.CancelFunc, int64) {
+	timeout, ok := e.bidderLatencies.timeout(bidder)
+	if !ok {
+		return ctx, func() {}, tmax
+	}
+	deadline := time.Now().Add(timeout)
+	if auctionDeadline, hasDeadline := ctx.Deadline(); hasDeadline && auctionDeadline.Before(deadline) {
+		return ctx, func() {}, tmax
+	}
+
+	bidderCtx, cancel := context.WithDeadline(ctx, deadline)
+	return bidderCtx, cancel, int64(timeout / time.Millisecond)
+}
+
+// recordBidderLatency keeps how long the bidder took to answer. Only answers which needed HTTP calls to the bidder's
+// server are kept, since the rest don't say how fast it is. Bidders which ran out of the time they were given are
+// recorded as twice as slow, so that a bidder which slows down is given longer deadlines again. Running out of the
+// auction's time isn't the bidder's fault, so it isn't counted against it.
+func (e *exchange) recordBidderLatency(auctionCtx, bidderCtx context.Context, bidder openrtb_ext.BidderName, seatBid *pbsOrtbSeatBid, elapsed time.Duration) {
+	if seatBid == nil || seatBid.httpRoundTrips == 0 {
+		return
+	}
+	if bidderCtx.Err() == context.DeadlineExceeded && auctionCtx.Err() == nil {
+		elapsed *= 2
+	}
+	e.bidderLatencies.record(bidder, elapsed)
+}
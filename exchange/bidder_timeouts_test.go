@@ -0,0 +1,140 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBidderLatenciesTimeout(t *testing.T) {
+	latencies := newBidderLatencies(config.AdaptiveBidderTimeouts{
+		Enabled:          true,
+		Percentile:       90,
+		SampleSize:       10,
+		MinSamples:       5,
+		MinTimeoutMillis: 20,
+	})
+	bidder := openrtb_ext.BidderAppnexus
+
+	for i := 1; i <= 4; i++ {
+		latencies.record(bidder, time.Duration(i*10)*time.Millisecond)
+	}
+	_, ok := latencies.timeout(bidder)
+	assert.False(t, ok, "A bidder shouldn't get a deadline before it has enough samples")
+
+	for i := 5; i <= 10; i++ {
+		latencies.record(bidder, time.Duration(i*10)*time.Millisecond)
+	}
+	timeout, ok := latencies.timeout(bidder)
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Millisecond, timeout, "The deadline should be the percentile of the samples")
+
+	for i := 0; i < 10; i++ {
+		latencies.record(bidder, 5*time.Millisecond)
+	}
+	timeout, _ = latencies.timeout(bidder)
+	assert.Equal(t, 20*time.Millisecond, timeout, "Old samples should be dropped, and the deadline should be at least the minimum")
+
+	_, ok = latencies.timeout(openrtb_ext.BidderRubicon)
+	assert.False(t, ok, "Bidders shouldn't share samples")
+}
+
+func TestMakeBidderContext(t *testing.T) {
+	latencies := newBidderLatencies(config.AdaptiveBidderTimeouts{
+		Enabled:    true,
+		Percentile: 100,
+		SampleSize: 1,
+		MinSamples: 1,
+	})
+	latencies.record(openrtb_ext.BidderAppnexus, 100*time.Millisecond)
+	e := &exchange{bidderLatencies: latencies}
+
+	longCtx, cancelLong := context.WithTimeout(context.Background(), time.Second)
+	defer cancelLong()
+	shortCtx, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+
+	bidderCtx, cancel, tmax := e.makeBidderContext(longCtx, openrtb_ext.BidderAppnexus, 1000)
+	defer cancel()
+	deadline, _ := bidderCtx.Deadline()
+	assert.WithinDuration(t, time.Now().Add(100*time.Millisecond), deadline, 20*time.Millisecond, "The bidder should get its own deadline")
+	assert.Equal(t, int64(100), tmax, "tmax should match the bidder's deadline")
+
+	bidderCtx, _, tmax = e.makeBidderContext(shortCtx, openrtb_ext.BidderAppnexus, 50)
+	assert.Equal(t, shortCtx, bidderCtx, "The bidder's deadline shouldn't be later than the auction's")
+	assert.Equal(t, int64(50), tmax)
+
+	bidderCtx, _, tmax = e.makeBidderContext(longCtx, openrtb_ext.BidderRubicon, 1000)
+	assert.Equal(t, longCtx, bidderCtx, "Bidders without samples should keep the auction's deadline")
+	assert.Equal(t, int64(1000), tmax)
+}
+
+func TestRecordBidderLatency(t *testing.T) {
+	expiredCtx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	sentSeatBid := &pbsOrtbSeatBid{httpRoundTrips: 1}
+
+	testCases := []struct {
+		description     string
+		auctionCtx      context.Context
+		bidderCtx       context.Context
+		seatBid         *pbsOrtbSeatBid
+		expectedTimeout time.Duration
+		expectedSample  bool
+	}{
+		{
+			description:     "Answered in time",
+			auctionCtx:      context.Background(),
+			bidderCtx:       context.Background(),
+			seatBid:         sentSeatBid,
+			expectedTimeout: 100 * time.Millisecond,
+			expectedSample:  true,
+		},
+		{
+			description:     "Bidder ran out of its own time - recorded as twice as slow",
+			auctionCtx:      context.Background(),
+			bidderCtx:       expiredCtx,
+			seatBid:         sentSeatBid,
+			expectedTimeout: 200 * time.Millisecond,
+			expectedSample:  true,
+		},
+		{
+			description:     "Auction ran out of time - not counted against the bidder",
+			auctionCtx:      expiredCtx,
+			bidderCtx:       expiredCtx,
+			seatBid:         sentSeatBid,
+			expectedTimeout: 100 * time.Millisecond,
+			expectedSample:  true,
+		},
+		{
+			description: "No HTTP calls - not recorded",
+			auctionCtx:  context.Background(),
+			bidderCtx:   context.Background(),
+			seatBid:     &pbsOrtbSeatBid{},
+		},
+		{
+			description: "No seat bid - not recorded",
+			auctionCtx:  context.Background(),
+			bidderCtx:   context.Background(),
+		},
+	}
+
+	for _, test := range testCases {
+		e := &exchange{bidderLatencies: newBidderLatencies(config.AdaptiveBidderTimeouts{
+			Enabled:    true,
+			Percentile: 100,
+			SampleSize: 1,
+			MinSamples: 1,
+		})}
+
+		e.recordBidderLatency(test.auctionCtx, test.bidderCtx, openrtb_ext.BidderAppnexus, test.seatBid, 100*time.Millisecond)
+
+		timeout, ok := e.bidderLatencies.timeout(openrtb_ext.BidderAppnexus)
+		assert.Equal(t, test.expectedSample, ok, test.description)
+		assert.Equal(t, test.expectedTimeout, timeout, test.description)
+	}
+}
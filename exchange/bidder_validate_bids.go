@@ -2,13 +2,18 @@ package exchange
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	goCurrency "golang.org/x/text/currency"
 )
@@ -18,24 +23,153 @@ import (
 //
 // The goal here is to make sure that the response contains Bids which are valid given the initial Request,
 // so that Publishers can trust the Bids they get from Prebid Server.
-func addValidatedBidderMiddleware(bidder adaptedBidder) adaptedBidder {
+func addValidatedBidderMiddleware(bidder adaptedBidder, me metrics.MetricsEngine) adaptedBidder {
 	return &validatedBidder{
 		bidder: bidder,
+		me:     me,
 	}
 }
 
 type validatedBidder struct {
 	bidder adaptedBidder
+	me     metrics.MetricsEngine
 }
 
-func (v *validatedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
-	seatBid, errs := v.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo, accountDebugAllowed)
+func (v *validatedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustments *openrtb_ext.ExtBidAdjustmentFactors, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error) {
+	seatBid, errs := v.bidder.requestBid(ctx, request, name, bidAdjustments, conversions, reqInfo, accountDebugAllowed, bidderStoredResponses)
 	if validationErrors := removeInvalidBids(request, seatBid); len(validationErrors) > 0 {
 		errs = append(errs, validationErrors...)
 	}
+	validations, _ := ctx.Value(ValidationsContextKey).(config.Validations)
+	if creativeErrors := v.validateCreatives(request, name, seatBid, validations); len(creativeErrors) > 0 {
+		errs = append(errs, creativeErrors...)
+	}
 	return seatBid, errs
 }
 
+// creativeValidation is a check of the bids' creatives, along with what to do with the bids which fail it.
+type creativeValidation struct {
+	name         metrics.CreativeValidation
+	mode         config.ValidationMode
+	nonBidReason openrtb_ext.NonBidReason
+	// check returns why the bid fails the validation, or an empty string if it passes.
+	check func(imp *openrtb.Imp, bid *pbsOrtbBid) string
+}
+
+// validateCreatives runs the creative validations which aren't skipped. Bids which fail a validation in enforce mode
+// are removed, while the ones which fail it in warn mode are kept with a warning.
+func (v *validatedBidder) validateCreatives(request *openrtb.BidRequest, name openrtb_ext.BidderName, seatBid *pbsOrtbSeatBid, validations config.Validations) []error {
+	if seatBid == nil || len(seatBid.bids) == 0 {
+		return nil
+	}
+
+	var creativeValidations []creativeValidation
+	if isValidationRun(validations.BannerCreativeSize) {
+		creativeValidations = append(creativeValidations, creativeValidation{
+			name:         metrics.CreativeValidationBannerSize,
+			mode:         validations.BannerCreativeSize,
+			nonBidReason: openrtb_ext.NonBidResponseRejectedCreativeSizeNotAllowed,
+			check:        checkBannerSize,
+		})
+	}
+	if isValidationRun(validations.SecureMarkup) {
+		creativeValidations = append(creativeValidations, creativeValidation{
+			name:         metrics.CreativeValidationSecureMarkup,
+			mode:         validations.SecureMarkup,
+			nonBidReason: openrtb_ext.NonBidResponseRejectedCreativeNotSecure,
+			check:        checkSecureMarkup,
+		})
+	}
+	if len(creativeValidations) == 0 {
+		return nil
+	}
+
+	impsByID := make(map[string]*openrtb.Imp, len(request.Imp))
+	for i := range request.Imp {
+		impsByID[request.Imp[i].ID] = &request.Imp[i]
+	}
+
+	var errs []error
+	validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		imp, ok := impsByID[bid.bid.ImpID]
+		if !ok {
+			validBids = append(validBids, bid)
+			continue
+		}
+		rejected := false
+		for _, validation := range creativeValidations {
+			reason := validation.check(imp, bid)
+			if reason == "" {
+				continue
+			}
+			rejected = validation.mode == config.ValidationEnforce
+			v.me.RecordAdapterInvalidCreative(name, validation.name, rejected)
+			if rejected {
+				errs = append(errs, fmt.Errorf("Bid \"%s\" %s", bid.bid.ID, reason))
+				seatBid.addNonBid(bid.bid, validation.nonBidReason)
+				break
+			}
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("Bid \"%s\" %s", bid.bid.ID, reason),
+			})
+		}
+		if !rejected {
+			validBids = append(validBids, bid)
+		}
+	}
+	seatBid.bids = validBids
+	return errs
+}
+
+func isValidationRun(mode config.ValidationMode) bool {
+	return mode == config.ValidationWarn || mode == config.ValidationEnforce
+}
+
+// checkBannerSize makes sure the size of a banner bid is one the imp allows. Imps which list no sizes allow any.
+func checkBannerSize(imp *openrtb.Imp, bid *pbsOrtbBid) string {
+	if bid.bidType != openrtb_ext.BidTypeBanner || imp.Banner == nil {
+		return ""
+	}
+
+	allowedSizes := imp.Banner.Format
+	if imp.Banner.W != nil && imp.Banner.H != nil {
+		allowedSizes = append([]openrtb.Format{{W: *imp.Banner.W, H: *imp.Banner.H}}, allowedSizes...)
+	}
+	// Bids without a size take the size of the slot
+	if len(allowedSizes) == 0 || bid.bid.W == 0 || bid.bid.H == 0 {
+		return ""
+	}
+	for _, size := range allowedSizes {
+		if size.W == bid.bid.W && size.H == bid.bid.H {
+			return ""
+		}
+	}
+	return fmt.Sprintf("has size %dx%d, which matches none of the sizes of imp %s", bid.bid.W, bid.bid.H, imp.ID)
+}
+
+// insecureResourcePatterns match markup which loads a resource over http: src attributes, the href of link and
+// script tags, and CSS urls. Other http URLs, such as XML namespaces and click-through links, load nothing.
+var insecureResourcePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bsrc\s*=\s*["']?\s*http:`),
+	regexp.MustCompile(`(?i)<(?:link|script)\b[^>]*\bhref\s*=\s*["']?\s*http:`),
+	regexp.MustCompile(`(?i)\burl\(\s*["']?\s*http:`),
+}
+
+// checkSecureMarkup makes sure the markup of bids on secure imps loads nothing over http.
+func checkSecureMarkup(imp *openrtb.Imp, bid *pbsOrtbBid) string {
+	if imp.Secure == nil || *imp.Secure != 1 {
+		return ""
+	}
+
+	for _, pattern := range insecureResourcePatterns {
+		if pattern.MatchString(bid.bid.AdM) {
+			return fmt.Sprintf("has insecure markup, but imp %s is secure", imp.ID)
+		}
+	}
+	return ""
+}
+
 // validateBids will run some validation checks on the returned bids and excise any invalid bids
 func removeInvalidBids(request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid) []error {
 	// Exit early if there is nothing to do.
@@ -45,6 +179,9 @@ func removeInvalidBids(request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid) []e
 
 	// By design, default currency is USD.
 	if cerr := validateCurrency(request.Cur, seatBid.currency); cerr != nil {
+		for _, bid := range seatBid.bids {
+			seatBid.addNonBid(bid.bid, openrtb_ext.NonBidErrorInvalidBidResponse)
+		}
 		seatBid.bids = nil
 		return []error{cerr}
 	}
@@ -56,6 +193,7 @@ func removeInvalidBids(request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid) []e
 			validBids = append(validBids, bid)
 		} else {
 			errs = append(errs, berr)
+			seatBid.addNonBid(bid.bid, openrtb_ext.NonBidResponseRejectedGeneral)
 		}
 	}
 	seatBid.bids = validBids
@@ -2,13 +2,19 @@ package exchange
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestAllValidBids(t *testing.T) {
@@ -41,8 +47,8 @@ func TestAllValidBids(t *testing.T) {
 				},
 			},
 		},
-	})
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true)
+	}, nil)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, nil, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, nil)
 	assert.Len(t, seatBid.bids, 3)
 	assert.Len(t, errs, 0)
 }
@@ -82,8 +88,8 @@ func TestAllBadBids(t *testing.T) {
 				{},
 			},
 		},
-	})
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true)
+	}, nil)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, nil, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, nil)
 	assert.Len(t, seatBid.bids, 0)
 	assert.Len(t, errs, 5)
 }
@@ -125,10 +131,11 @@ func TestMixedBids(t *testing.T) {
 				{},
 			},
 		},
-	})
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true)
+	}, nil)
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, nil, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, nil)
 	assert.Len(t, seatBid.bids, 2)
 	assert.Len(t, errs, 3)
+	assert.Len(t, seatBid.nonBids, 2, "Only the rejected bids with an imp ID can be reported")
 }
 
 func TestCurrencyBids(t *testing.T) {
@@ -231,7 +238,7 @@ func TestCurrencyBids(t *testing.T) {
 				currency: tc.brpCur,
 				bids:     bids,
 			},
-		})
+		}, nil)
 
 		expectedValidBids := len(bids)
 		expectedErrs := 0
@@ -246,17 +253,151 @@ func TestCurrencyBids(t *testing.T) {
 			Cur: tc.brqCur,
 		}
 
-		seatBid, errs := bidder.requestBid(context.Background(), request, openrtb_ext.BidderAppnexus, 1.0, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true)
+		seatBid, errs := bidder.requestBid(context.Background(), request, openrtb_ext.BidderAppnexus, nil, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, nil)
 		assert.Len(t, seatBid.bids, expectedValidBids)
 		assert.Len(t, errs, expectedErrs)
 	}
 }
 
+func TestCreativeValidations(t *testing.T) {
+	secure := int8(1)
+	request := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "banner", Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}, {W: 728, H: 90}}}},
+			{ID: "secure", Secure: &secure, Video: &openrtb.Video{}},
+		},
+	}
+	bids := []*pbsOrtbBid{
+		{bid: &openrtb.Bid{ID: "sized", ImpID: "banner", Price: 1, CrID: "c", W: 728, H: 90}, bidType: openrtb_ext.BidTypeBanner},
+		{bid: &openrtb.Bid{ID: "missized", ImpID: "banner", Price: 1, CrID: "c", W: 300, H: 600}, bidType: openrtb_ext.BidTypeBanner},
+		{bid: &openrtb.Bid{ID: "unsized", ImpID: "banner", Price: 1, CrID: "c"}, bidType: openrtb_ext.BidTypeBanner},
+		{bid: &openrtb.Bid{ID: "secure", ImpID: "secure", Price: 1, CrID: "c", AdM: "<VAST><Ad src=\"https://example.com\"/></VAST>"}, bidType: openrtb_ext.BidTypeVideo},
+		{bid: &openrtb.Bid{ID: "insecure", ImpID: "secure", Price: 1, CrID: "c", AdM: "<VAST><Ad src=\"HTTP://example.com\"/></VAST>"}, bidType: openrtb_ext.BidTypeVideo},
+	}
+
+	testCases := []struct {
+		description     string
+		validations     config.Validations
+		expectedBids    []string
+		expectedErrs    []error
+		expectedNonBids int
+	}{
+		{
+			description:  "Skip",
+			validations:  config.Validations{BannerCreativeSize: config.ValidationSkip, SecureMarkup: config.ValidationSkip},
+			expectedBids: []string{"sized", "missized", "unsized", "secure", "insecure"},
+		},
+		{
+			description:  "Warn",
+			validations:  config.Validations{BannerCreativeSize: config.ValidationWarn, SecureMarkup: config.ValidationWarn},
+			expectedBids: []string{"sized", "missized", "unsized", "secure", "insecure"},
+			expectedErrs: []error{
+				&errortypes.Warning{Message: "Bid \"missized\" has size 300x600, which matches none of the sizes of imp banner"},
+				&errortypes.Warning{Message: "Bid \"insecure\" has insecure markup, but imp secure is secure"},
+			},
+		},
+		{
+			description:  "Enforce",
+			validations:  config.Validations{BannerCreativeSize: config.ValidationEnforce, SecureMarkup: config.ValidationEnforce},
+			expectedBids: []string{"sized", "unsized", "secure"},
+			expectedErrs: []error{
+				errors.New("Bid \"missized\" has size 300x600, which matches none of the sizes of imp banner"),
+				errors.New("Bid \"insecure\" has insecure markup, but imp secure is secure"),
+			},
+			expectedNonBids: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordAdapterInvalidCreative", mock.Anything, mock.Anything, mock.Anything).Return()
+		bidder := addValidatedBidderMiddleware(&mockAdaptedBidder{
+			bidResponse: &pbsOrtbSeatBid{bids: append([]*pbsOrtbBid(nil), bids...)},
+		}, metricsMock)
+
+		ctx := context.WithValue(context.Background(), ValidationsContextKey, test.validations)
+		seatBid, errs := bidder.requestBid(ctx, request, openrtb_ext.BidderAppnexus, nil, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, nil)
+
+		var bidIDs []string
+		for _, bid := range seatBid.bids {
+			bidIDs = append(bidIDs, bid.bid.ID)
+		}
+		assert.Equal(t, test.expectedBids, bidIDs, test.description)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+		assert.Len(t, seatBid.nonBids, test.expectedNonBids, test.description)
+		if test.expectedErrs == nil {
+			metricsMock.AssertNotCalled(t, "RecordAdapterInvalidCreative", mock.Anything, mock.Anything, mock.Anything)
+		} else {
+			rejected := test.validations.BannerCreativeSize == config.ValidationEnforce
+			metricsMock.AssertCalled(t, "RecordAdapterInvalidCreative", openrtb_ext.BidderAppnexus, metrics.CreativeValidationBannerSize, rejected)
+			metricsMock.AssertCalled(t, "RecordAdapterInvalidCreative", openrtb_ext.BidderAppnexus, metrics.CreativeValidationSecureMarkup, rejected)
+		}
+	}
+}
+
+func TestCheckSecureMarkup(t *testing.T) {
+	secure := int8(1)
+	imp := &openrtb.Imp{ID: "imp", Secure: &secure}
+
+	testCases := []struct {
+		description string
+		adm         string
+		expected    bool
+	}{
+		{
+			description: "Secure image",
+			adm:         `<img src="https://example.com/ad.png">`,
+		},
+		{
+			description: "Insecure image",
+			adm:         `<IMG SRC = 'HTTP://example.com/ad.png'>`,
+			expected:    true,
+		},
+		{
+			description: "Insecure script",
+			adm:         `<script src=http://example.com/ad.js></script>`,
+			expected:    true,
+		},
+		{
+			description: "Insecure stylesheet",
+			adm:         `<link rel="stylesheet" href="http://example.com/ad.css">`,
+			expected:    true,
+		},
+		{
+			description: "Insecure CSS url",
+			adm:         `<div style="background: url('http://example.com/ad.png')"></div>`,
+			expected:    true,
+		},
+		{
+			description: "VAST with an XML namespace",
+			adm:         `<VAST version="3.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><Ad><InLine><Creatives><Creative><Linear><MediaFiles><MediaFile><![CDATA[https://example.com/ad.mp4]]></MediaFile></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`,
+		},
+		{
+			description: "SVG with an XML namespace",
+			adm:         `<svg xmlns="http://www.w3.org/2000/svg" width="300" height="250"><image href="https://example.com/ad.png"/></svg>`,
+		},
+		{
+			description: "Insecure click-through link",
+			adm:         `<a href="http://example.com/landing"><img src="https://example.com/ad.png"></a>`,
+		},
+		{
+			description: "Encoded click URL",
+			adm:         `<a href="https://example.com/click?u=http%3A%2F%2Fexample.com%2Flanding"><img src="https://example.com/ad.png?r=http%3A%2F%2Fexample.com"></a>`,
+		},
+	}
+
+	for _, test := range testCases {
+		reason := checkSecureMarkup(imp, &pbsOrtbBid{bid: &openrtb.Bid{AdM: test.adm}})
+		assert.Equal(t, test.expected, reason != "", test.description)
+	}
+	assert.Empty(t, checkSecureMarkup(&openrtb.Imp{ID: "imp"}, &pbsOrtbBid{bid: &openrtb.Bid{AdM: `<img src="http://example.com/ad.png">`}}), "Imps which aren't secure allow any markup")
+}
+
 type mockAdaptedBidder struct {
 	bidResponse   *pbsOrtbSeatBid
 	errorResponse []error
 }
 
-func (b *mockAdaptedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
+func (b *mockAdaptedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustments *openrtb_ext.ExtBidAdjustmentFactors, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error) {
 	return b.bidResponse, b.errorResponse
 }
@@ -0,0 +1,34 @@
+package exchange
+
+import (
+	"strings"
+
+	"github.com/mxmCherry/openrtb"
+)
+
+// detectDevice fills in the os, osv, make, model and devicetype of the device from its user agent. The values
+// of the request are never overwritten.
+func (e *exchange) detectDevice(request *openrtb.BidRequest) {
+	if e.deviceDetector == nil || request.Device == nil || request.Device.UA == "" {
+		return
+	}
+	detected := e.deviceDetector.Detect(request.Device.UA)
+
+	device := *request.Device
+	if device.OSV == "" && (device.OS == "" || strings.EqualFold(device.OS, detected.OS)) {
+		device.OSV = detected.OSV
+	}
+	if device.OS == "" {
+		device.OS = detected.OS
+	}
+	if device.Model == "" && (device.Make == "" || strings.EqualFold(device.Make, detected.Make)) {
+		device.Model = detected.Model
+	}
+	if device.Make == "" {
+		device.Make = detected.Make
+	}
+	if device.DeviceType == 0 {
+		device.DeviceType = detected.DeviceType
+	}
+	request.Device = &device
+}
@@ -0,0 +1,70 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/devicedetection"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDeviceDetector struct {
+	device devicedetection.Device
+}
+
+func (d *fakeDeviceDetector) Detect(ua string) devicedetection.Device {
+	return d.device
+}
+
+func TestDetectDevice(t *testing.T) {
+	detector := &fakeDeviceDetector{device: devicedetection.Device{
+		OS:         "iOS",
+		OSV:        "14.2",
+		Make:       "Apple",
+		Model:      "iPhone",
+		DeviceType: openrtb.DeviceTypePhone,
+	}}
+
+	testCases := []struct {
+		description    string
+		device         *openrtb.Device
+		expectedDevice *openrtb.Device
+	}{
+		{
+			description:    "Empty fields are filled in",
+			device:         &openrtb.Device{UA: "ua"},
+			expectedDevice: &openrtb.Device{UA: "ua", OS: "iOS", OSV: "14.2", Make: "Apple", Model: "iPhone", DeviceType: openrtb.DeviceTypePhone},
+		},
+		{
+			description:    "The values of the request are kept",
+			device:         &openrtb.Device{UA: "ua", OS: "ios", DeviceType: openrtb.DeviceTypeTablet, Model: "iPad"},
+			expectedDevice: &openrtb.Device{UA: "ua", OS: "ios", OSV: "14.2", Make: "Apple", Model: "iPad", DeviceType: openrtb.DeviceTypeTablet},
+		},
+		{
+			description:    "The version and model of another os and make are left out",
+			device:         &openrtb.Device{UA: "ua", OS: "Android", Make: "Samsung"},
+			expectedDevice: &openrtb.Device{UA: "ua", OS: "Android", Make: "Samsung", DeviceType: openrtb.DeviceTypePhone},
+		},
+		{
+			description:    "No user agent",
+			device:         &openrtb.Device{IP: "1.2.3.4"},
+			expectedDevice: &openrtb.Device{IP: "1.2.3.4"},
+		},
+	}
+
+	for _, test := range testCases {
+		e := &exchange{deviceDetector: detector}
+		original := *test.device
+		request := &openrtb.BidRequest{Device: test.device}
+
+		e.detectDevice(request)
+
+		assert.Equal(t, test.expectedDevice, request.Device, test.description)
+		assert.Equal(t, original, *test.device, "The device of the caller shouldn't be changed: "+test.description)
+	}
+
+	e := &exchange{}
+	request := &openrtb.BidRequest{Device: &openrtb.Device{UA: "ua"}}
+	e.detectDevice(request)
+	assert.Equal(t, &openrtb.Device{UA: "ua"}, request.Device, "Nothing should be filled in without a detector")
+}
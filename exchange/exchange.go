@@ -21,8 +21,12 @@ import (
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/devicedetection"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/geolocation"
+	"github.com/prebid/prebid-server/hooks"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
@@ -32,6 +36,9 @@ type ContextKey string
 
 const DebugContextKey = ContextKey("debugInfo")
 
+// ValidationsContextKey holds the account's creative validations for the bid validation middleware.
+const ValidationsContextKey = ContextKey("validations")
+
 type extCacheInstructions struct {
 	cacheBids, cacheVAST, returnCreative bool
 }
@@ -61,6 +68,9 @@ type exchange struct {
 	UsersyncIfAmbiguous bool
 	privacyConfig       config.Privacy
 	categoriesFetcher   stored_requests.CategoryFetcher
+	bidderLatencies     *bidderLatencies
+	geoLookup           geolocation.Lookup
+	deviceDetector      devicedetection.Detector
 }
 
 // Container to pass out response ext data from the GetAllBids goroutines back into the main thread
@@ -70,6 +80,9 @@ type seatResponseExtra struct {
 	// httpCalls is the list of debugging info. It should only be populated if the request.test == 1.
 	// This will become response.ext.debug.httpcalls.{bidder} on the final Response.
 	HttpCalls []*openrtb_ext.ExtHttpCall
+	// NonBids lists the imps the bidder didn't bid on, and the bids which were rejected.
+	// This will become response.ext.seatnonbid on the final Response.
+	NonBids []openrtb_ext.ExtNonBid
 }
 
 type bidResponseWrapper struct {
@@ -78,7 +91,7 @@ type bidResponseWrapper struct {
 	bidder       openrtb_ext.BidderName
 }
 
-func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, metricsEngine metrics.MetricsEngine, infos adapters.BidderInfos, gDPR gdpr.Permissions, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher) Exchange {
+func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, metricsEngine metrics.MetricsEngine, infos adapters.BidderInfos, gDPR gdpr.Permissions, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, geoLookup geolocation.Lookup, deviceDetector devicedetection.Detector) Exchange {
 	return &exchange{
 		adapterMap:          adapters,
 		bidderInfo:          infos,
@@ -91,10 +104,14 @@ func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid
 		me:                  metricsEngine,
 		UsersyncIfAmbiguous: cfg.GDPR.UsersyncIfAmbiguous,
 		privacyConfig: config.Privacy{
-			CCPA: cfg.CCPA,
-			GDPR: cfg.GDPR,
-			LMT:  cfg.LMT,
+			CCPA:         cfg.CCPA,
+			GDPR:         cfg.GDPR,
+			LMT:          cfg.LMT,
+			IPHashSecret: cfg.IPHashSecret,
 		},
+		bidderLatencies: newBidderLatencies(cfg.AdaptiveBidderTimeouts),
+		geoLookup:       geoLookup,
+		deviceDetector:  deviceDetector,
 	}
 }
 
@@ -107,6 +124,19 @@ type AuctionRequest struct {
 	RequestType metrics.RequestType
 	StartTime   time.Time
 
+	// StoredAuctionResponses holds the seatbids of the stored auction response of each imp which has one, keyed by imp.id.
+	// The bidders aren't asked to bid on these imps.
+	StoredAuctionResponses map[string][]openrtb.SeatBid
+	// StoredBidResponses holds the stored bidder responses of each imp, keyed by imp.id and then by bidder.
+	// They're used in place of the HTTP calls to those bidders.
+	StoredBidResponses map[string]map[string]json.RawMessage
+
+	// HookExecutor runs the hooks of the bidder and response stages. It may be nil if no hooks are configured.
+	HookExecutor *hooks.Executor
+
+	// SkippedBidders are the bidders which aren't called, since the request was flagged as invalid traffic.
+	SkippedBidders map[string]struct{}
+
 	// LegacyLabels is included here for temporary compatability with cleanOpenRTBRequests
 	// in HoldAuction until we get to factoring it away. Do not use for anything new.
 	LegacyLabels metrics.Labels
@@ -119,6 +149,8 @@ type BidderRequest struct {
 	BidderName     openrtb_ext.BidderName
 	BidderCoreName openrtb_ext.BidderName
 	BidderLabels   metrics.AdapterLabels
+	// BidderStoredResponses holds the stored responses to use in place of the bidder's HTTP calls, keyed by imp.id
+	BidderStoredResponses map[string]json.RawMessage
 }
 
 func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *DebugLog) (*openrtb.BidResponse, error) {
@@ -128,10 +160,14 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		return nil, err
 	}
 
+	// Bidders which may keep more than one bid per imp. The endpoints reject requests with an invalid ext.prebid.multibid.
+	multiBid, multiBidErr := BidderToMultiBid(requestExt)
+
 	cacheInstructions := getExtCacheInstructions(requestExt)
 	targData := getExtTargetData(requestExt, &cacheInstructions)
 	if targData != nil {
 		_, targData.cacheHost, targData.cachePath = e.cache.GetExtCacheData()
+		targData.multiBid = multiBid
 	}
 
 	if debugLog == nil {
@@ -147,10 +183,23 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		ctx = e.makeDebugContext(ctx, debugInfo)
 	}
 
-	bidAdjustmentFactors := getExtBidAdjustmentFactors(requestExt)
+	// The factors of the request take precedence over the account's
+	bidAdjustmentFactors := r.Account.BidAdjustmentFactors.Merge(getExtBidAdjustmentFactors(requestExt))
 
 	recordImpMetrics(r.BidRequest, e.me)
 
+	// Get currency rates conversions for the auction
+	conversions := e.getAuctionCurrencyRates(requestExt.Prebid.CurrencyConversions)
+
+	// Resolve the price floor of each imp before the request is split, so that every bidder is told about it
+	floorRules := floors.NewRules(r.Account.PriceFloors, requestExt.Prebid.Floors)
+	impFloors := floorRules.UpdateImps(r.BidRequest, conversions)
+
+	applyAccountBlocklists(r.BidRequest, r.Account.AdQuality)
+
+	e.fillDeviceGeo(r.BidRequest)
+	e.detectDevice(r.BidRequest)
+
 	// Make our best guess if GDPR applies
 	usersyncIfAmbiguous := e.parseUsersyncIfAmbiguous(r.BidRequest)
 
@@ -159,6 +208,13 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 	e.me.RecordRequestPrivacy(privacyLabels)
 
+	bidderRequests = applyStoredResponses(bidderRequests, r.StoredAuctionResponses, r.StoredBidResponses)
+	bidderRequests = removeSkippedBidders(bidderRequests, r.SkippedBidders)
+
+	if multiBidErr != nil {
+		errs = append(errs, multiBidErr)
+	}
+
 	// List of bidders we have requests for.
 	liveAdapters := listBiddersWithRequests(bidderRequests)
 
@@ -166,11 +222,20 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	// We should reduce the amount of time the bidders have, to compensate.
 	auctionCtx, cancel := e.makeAuctionContext(ctx, cacheInstructions.cacheBids)
 	defer cancel()
+	auctionCtx = context.WithValue(auctionCtx, ValidationsContextKey, r.Account.Validations)
 
-	// Get currency rates conversions for the auction
-	conversions := e.currencyConverter.Rates()
+	adapterBids, adapterExtra, anyBidsReturned := e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions, r.Account.DebugAllow, floorRules, r.HookExecutor)
+
+	if len(r.StoredAuctionResponses) > 0 {
+		storedSeats := addStoredAuctionResponses(r.BidRequest, r.StoredAuctionResponses, liveAdapters, adapterBids, adapterExtra)
+		liveAdapters = append(liveAdapters, storedSeats...)
+		anyBidsReturned = len(adapterBids) > 0
+	}
 
-	adapterBids, adapterExtra, anyBidsReturned := e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions, r.Account.DebugAllow)
+	if r.HookExecutor.HasStage(hooks.StageAllProcessedBidResponses) {
+		adapterBids = executeAllProcessedBidResponsesStage(r.HookExecutor, adapterBids)
+		anyBidsReturned = len(adapterBids) > 0
+	}
 
 	var auc *auction
 	var cacheErrs []error
@@ -188,6 +253,9 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 			for _, message := range rejections {
 				errs = append(errs, errors.New(message))
 			}
+			for bidderName, seatBid := range adapterBids {
+				moveNonBids(seatBid, adapterExtra[bidderName])
+			}
 		}
 
 		evTracking := getEventTracking(&requestExt.Prebid, r.StartTime, &r.Account, e.bidderInfo, e.externalURL)
@@ -195,7 +263,8 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 		if targData != nil {
 			// A non-nil auction is only needed if targeting is active. (It is used below this block to extract cache keys)
-			auc = newAuction(adapterBids, len(r.BidRequest.Imp), targData.preferDeals)
+			auc = newAuction(adapterBids, len(r.BidRequest.Imp), targData.preferDeals, multiBid)
+			auc.setClearingPrices(r.Account.Auction, r.BidRequest, adapterBids, conversions)
 			auc.setRoundedPrices(targData.priceGranularity)
 
 			if requestExt.Prebid.SupportDeals {
@@ -236,8 +305,40 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		}
 	}
 
+	bidResponseExt.SeatNonBid = e.makeSeatNonBids(adapterExtra)
+
+	if floorsExt := makeFloorsResponseExt(floorRules, impFloors); floorsExt != nil {
+		if bidResponseExt.Prebid == nil {
+			bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{}
+		}
+		bidResponseExt.Prebid.Floors = floorsExt
+	}
+
 	// Build the response
-	return e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequest, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, errs)
+	bidResponse, err := e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequest, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, errs)
+	if err != nil {
+		return bidResponse, err
+	}
+
+	bidResponse = r.HookExecutor.ExecuteAuctionResponseStage(bidResponse)
+	if debugInfo {
+		bidResponse.Ext, err = addModulesOutcome(bidResponse.Ext, r.HookExecutor.ModulesOutcome())
+	}
+	return bidResponse, err
+}
+
+// getAuctionCurrencyRates returns the rates of the request, backed by the server's rates unless the request
+// says not to use them.
+func (e *exchange) getAuctionCurrencyRates(requestRates *openrtb_ext.ExtRequestCurrency) currency.Conversions {
+	if requestRates == nil {
+		return e.currencyConverter.Rates()
+	}
+
+	customRates := currency.NewRates(time.Time{}, requestRates.ConversionRates)
+	if requestRates.UsePBSRates != nil && !*requestRates.UsePBSRates {
+		return customRates
+	}
+	return currency.NewAggregateConversions(customRates, e.currencyConverter.Rates())
 }
 
 func (e *exchange) parseUsersyncIfAmbiguous(bidRequest *openrtb.BidRequest) bool {
@@ -252,12 +353,9 @@ func (e *exchange) parseUsersyncIfAmbiguous(bidRequest *openrtb.BidRequest) bool
 	if geo != nil {
 		// If we have a country set, and it is on the list, we assume GDPR applies if not set on the request.
 		// Otherwise we assume it does not apply as long as it appears "valid" (is 3 characters long).
-		if _, found := e.privacyConfig.GDPR.EEACountriesMap[strings.ToUpper(geo.Country)]; found {
-			usersyncIfAmbiguous = false
-		} else if len(geo.Country) == 3 {
-			// The country field is formatted properly as a three character country code
-			usersyncIfAmbiguous = true
-		}
+		gdprConfig := e.privacyConfig.GDPR
+		gdprConfig.UsersyncIfAmbiguous = usersyncIfAmbiguous
+		usersyncIfAmbiguous = gdprConfig.UsersyncIfAmbiguousInCountry(geo.Country)
 	}
 
 	return usersyncIfAmbiguous
@@ -282,12 +380,14 @@ func applyDealSupport(bidRequest *openrtb.BidRequest, auc *auction, bidCategory
 
 	for impID, topBidsPerImp := range auc.winningBidsByBidder {
 		impDeal := impDealMap[impID]
-		for bidder, topBidPerBidder := range topBidsPerImp {
-			if topBidPerBidder.dealPriority > 0 {
-				if validateDealTier(impDeal[bidder]) {
-					updateHbPbCatDur(topBidPerBidder, impDeal[bidder], bidCategory)
-				} else {
-					errs = append(errs, fmt.Errorf("dealTier configuration invalid for bidder '%s', imp ID '%s'", string(bidder), impID))
+		for bidder, topBidsPerBidder := range topBidsPerImp {
+			for _, topBid := range topBidsPerBidder {
+				if topBid.dealPriority > 0 {
+					if validateDealTier(impDeal[bidder]) {
+						updateHbPbCatDur(topBid, impDeal[bidder], bidCategory)
+					} else {
+						errs = append(errs, fmt.Errorf("dealTier configuration invalid for bidder '%s', imp ID '%s'", string(bidder), impID))
+					}
 				}
 			}
 		}
@@ -350,9 +450,11 @@ func (e *exchange) makeAuctionContext(ctx context.Context, needsCache bool) (auc
 func (e *exchange) getAllBids(
 	ctx context.Context,
 	bidderRequests []BidderRequest,
-	bidAdjustments map[string]float64,
+	bidAdjustments *openrtb_ext.ExtBidAdjustmentFactors,
 	conversions currency.Conversions,
-	accountDebugAllowed bool) (
+	accountDebugAllowed bool,
+	floorRules *floors.Rules,
+	hookExecutor *hooks.Executor) (
 	map[openrtb_ext.BidderName]*pbsOrtbSeatBid,
 	map[openrtb_ext.BidderName]*seatResponseExtra, bool) {
 	// Set up pointers to the bid results
@@ -377,13 +479,35 @@ func (e *exchange) getAllBids(
 			}()
 			start := time.Now()
 
-			adjustmentFactor := 1.0
-			if givenAdjustment, ok := bidAdjustments[string(bidderRequest.BidderName)]; ok {
-				adjustmentFactor = givenAdjustment
+			bidRequest, rejectErr := hookExecutor.ExecuteBidderRequestStage(bidderRequest.BidderName, bidderRequest.BidRequest)
+			if rejectErr != nil {
+				brw.adapterExtra = &seatResponseExtra{Errors: errsToBidderErrors([]error{rejectErr})}
+				chBids <- brw
+				return
 			}
+
 			var reqInfo adapters.ExtraRequestInfo
 			reqInfo.PbsEntryPoint = bidderRequest.BidderLabels.RType
-			bids, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest.BidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo, accountDebugAllowed)
+
+			bidderCtx, cancel, tmax := e.makeBidderContext(ctx, bidderRequest.BidderName, bidRequest.TMax)
+			defer cancel()
+			if tmax != bidRequest.TMax {
+				requestCopy := *bidRequest
+				requestCopy.TMax = tmax
+				bidRequest = &requestCopy
+			}
+			bidderStart := time.Now()
+			bids, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(bidderCtx, bidRequest, bidderRequest.BidderName, bidAdjustments, conversions, &reqInfo, accountDebugAllowed, bidderRequest.BidderStoredResponses)
+			e.recordBidderLatency(ctx, bidderCtx, bidderRequest.BidderName, bids, time.Since(bidderStart))
+			if hookErr := executeRawBidderResponseStage(hookExecutor, bidderRequest.BidderName, bids); hookErr != nil {
+				err = append(err, hookErr)
+			}
+			if floorErrs := enforceFloors(floorRules, bidRequest, bids, conversions); len(floorErrs) > 0 {
+				err = append(err, floorErrs...)
+			}
+			if blocklistErrs := e.enforceBlocklists(bidderRequest.BidderName, bidRequest, bids); len(blocklistErrs) > 0 {
+				err = append(err, blocklistErrs...)
+			}
 
 			// Add in time reporting
 			elapsed := time.Since(start)
@@ -394,6 +518,10 @@ func (e *exchange) getAllBids(
 			if bids != nil {
 				ae.HttpCalls = bids.httpCalls
 			}
+			if hasTimeout(err) {
+				ae.NonBids = timeoutNonBids(bidRequest, bids)
+			}
+			moveNonBids(bids, ae)
 
 			// Timing statistics
 			e.me.RecordAdapterTime(bidderRequest.BidderLabels, time.Since(start))
@@ -487,6 +615,8 @@ func errorsToMetric(errs []error) map[metrics.AdapterError]struct{} {
 			ret[metrics.AdapterErrorBadServerResponse] = s
 		case errortypes.FailedToRequestBidsErrorCode:
 			ret[metrics.AdapterErrorFailedToRequestBids] = s
+		case errortypes.BidderCircuitOpenErrorCode:
+			ret[metrics.AdapterErrorCircuitOpen] = s
 		default:
 			ret[metrics.AdapterErrorUnknown] = s
 		}
@@ -494,6 +624,15 @@ func errorsToMetric(errs []error) map[metrics.AdapterError]struct{} {
 	return ret
 }
 
+func hasTimeout(errs []error) bool {
+	for _, err := range errs {
+		if errortypes.ReadCode(err) == errortypes.TimeoutErrorCode {
+			return true
+		}
+	}
+	return false
+}
+
 func errsToBidderErrors(errs []error) []openrtb_ext.ExtBidderError {
 	serr := make([]openrtb_ext.ExtBidderError, len(errs))
 	for i := 0; i < len(errs); i++ {
@@ -606,6 +745,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 					//on receiving bids from adapters if no unique IAB category is returned  or if no ad server category is returned discard the bid
 					bidsToRemove = append(bidsToRemove, bidInd)
 					rejections = updateRejections(rejections, bidID, "Bid did not contain a category")
+					seatBid.addNonBid(bid.bid, openrtb_ext.NonBidResponseRejectedCategoryMappingInvalid)
 					continue
 				}
 				if translateCategories {
@@ -617,6 +757,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 						bidsToRemove = append(bidsToRemove, bidInd)
 						reason := fmt.Sprintf("Category mapping file for primary ad server: '%s', publisher: '%s' not found", primaryAdServer, publisher)
 						rejections = updateRejections(rejections, bidID, reason)
+						seatBid.addNonBid(bid.bid, openrtb_ext.NonBidResponseRejectedCategoryMappingInvalid)
 						continue
 					}
 				} else {
@@ -637,6 +778,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 				if duration > durationRange[len(durationRange)-1] {
 					bidsToRemove = append(bidsToRemove, bidInd)
 					rejections = updateRejections(rejections, bidID, "Bid duration exceeds maximum allowed")
+					seatBid.addNonBid(bid.bid, openrtb_ext.NonBidResponseRejectedCategoryMappingInvalid)
 					continue
 				}
 				for _, dur := range durationRange {
@@ -684,9 +826,11 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 						// An older bid from the current bidder
 						bidsToRemove = append(bidsToRemove, dupe.bidIndex)
 						rejections = updateRejections(rejections, dupe.bidID, "Bid was deduplicated")
+						seatBid.addNonBid(seatBid.bids[dupe.bidIndex].bid, openrtb_ext.NonBidResponseRejectedCategoryMappingInvalid)
 					} else {
 						// An older bid from a different seatBid we've already finished with
 						oldSeatBid := (seatBids)[dupe.bidderName]
+						oldSeatBid.addNonBid(oldSeatBid.bids[dupe.bidIndex].bid, openrtb_ext.NonBidResponseRejectedCategoryMappingInvalid)
 						if len(oldSeatBid.bids) == 1 {
 							seatBidsToRemove = append(seatBidsToRemove, dupe.bidderName)
 							rejections = updateRejections(rejections, dupe.bidID, "Bid was deduplicated")
@@ -699,6 +843,7 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 					// Remove this bid
 					bidsToRemove = append(bidsToRemove, bidInd)
 					rejections = updateRejections(rejections, bidID, "Bid was deduplicated")
+					seatBid.addNonBid(bid.bid, openrtb_ext.NonBidResponseRejectedCategoryMappingInvalid)
 					continue
 				}
 			}
@@ -834,6 +979,12 @@ func (e *exchange) makeBid(Bids []*pbsOrtbBid, auc *auction, returnCreative bool
 				DealTierSatisfied: thisBid.dealTierSatisfied,
 			},
 		}
+		if auc != nil {
+			if clearingPrice, ok := auc.clearingPrices[thisBid]; ok {
+				bidExt.Prebid.ClearingPrice = clearingPrice
+				bidExt.Prebid.OriginalPrice = thisBid.bid.Price
+			}
+		}
 		if cacheInfo, found := e.getBidCacheInfo(thisBid, auc); found {
 			bidExt.Prebid.Cache = &openrtb_ext.ExtBidPrebidCache{
 				Bids: &cacheInfo,
@@ -0,0 +1,81 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// enforceFloors removes the bids whose price is below the floor of the imp they were made on.
+// Bids are compared in the seatbid currency, so the floor is converted before the comparison.
+// Each rejected bid is reported as a warning.
+func enforceFloors(floorRules *floors.Rules, request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid, conversions currency.Conversions) []error {
+	if !floorRules.Enforced() || seatBid == nil || len(seatBid.bids) == 0 {
+		return nil
+	}
+
+	impsByID := make(map[string]*openrtb.Imp, len(request.Imp))
+	for i := range request.Imp {
+		impsByID[request.Imp[i].ID] = &request.Imp[i]
+	}
+
+	var errs []error
+	validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		imp, ok := impsByID[bid.bid.ImpID]
+		if !ok || (bid.bid.DealID != "" && !floorRules.EnforceDeals()) {
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		floor, ok := floorRules.BidFloor(request, imp, bid.bidType, bid.bid.W, bid.bid.H, conversions)
+		if !ok {
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		rate, err := conversions.GetRate(floor.Currency, seatBid.currency)
+		if err != nil {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("Unable to enforce floor for bid %s: %v", bid.bid.ID, err),
+			})
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		if floorValue := floor.Value * rate; bid.bid.Price < floorValue {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("bid rejected [bid ID: %s] reason: bid price %.4f %s is below the floor %.4f %s", bid.bid.ID, bid.bid.Price, seatBid.currency, floorValue, seatBid.currency),
+			})
+			seatBid.addNonBid(bid.bid, openrtb_ext.NonBidResponseRejectedBelowFloor)
+			continue
+		}
+		validBids = append(validBids, bid)
+	}
+	seatBid.bids = validBids
+	return errs
+}
+
+// makeFloorsResponseExt reports the floors applied to each imp for bidresponse.ext.prebid.floors
+func makeFloorsResponseExt(floorRules *floors.Rules, impFloors map[string]floors.Floor) *openrtb_ext.ExtResponsePrebidFloors {
+	if len(impFloors) == 0 {
+		return nil
+	}
+
+	floorsExt := &openrtb_ext.ExtResponsePrebidFloors{
+		Enforced: floorRules.Enforced(),
+		Imps:     make(map[string]openrtb_ext.ExtResponsePrebidFloor, len(impFloors)),
+	}
+	for impID, floor := range impFloors {
+		floorsExt.Imps[impID] = openrtb_ext.ExtResponsePrebidFloor{
+			FloorValue:    floor.Value,
+			FloorCurrency: floor.Currency,
+			FloorRule:     floor.Rule,
+		}
+	}
+	return floorsExt
+}
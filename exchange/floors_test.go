@@ -0,0 +1,145 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnforceFloors(t *testing.T) {
+	floorRules := floors.NewRules(config.AccountPriceFloors{Enabled: true}, &openrtb_ext.PriceFloorRules{
+		Data: &openrtb_ext.PriceFloorData{
+			Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType"}},
+			Values: map[string]float64{"banner": 1, "video": 5},
+		},
+	})
+	request := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "imp1", Banner: &openrtb.Banner{}, Video: &openrtb.Video{}},
+		},
+	}
+	conversions := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"USD": {"EUR": 0.5},
+	})
+
+	testCases := []struct {
+		description    string
+		floorRules     *floors.Rules
+		seatCurrency   string
+		bids           []*pbsOrtbBid
+		expectedBidIDs []string
+		expectedErrs   int
+	}{
+		{
+			description:  "Nil Rules",
+			floorRules:   nil,
+			seatCurrency: "USD",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "low", ImpID: "imp1", Price: 0.1}, bidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedBidIDs: []string{"low"},
+		},
+		{
+			description:  "Bids Below Floor Removed",
+			floorRules:   floorRules,
+			seatCurrency: "USD",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "banner-low", ImpID: "imp1", Price: 0.5}, bidType: openrtb_ext.BidTypeBanner},
+				{bid: &openrtb.Bid{ID: "banner-ok", ImpID: "imp1", Price: 1}, bidType: openrtb_ext.BidTypeBanner},
+				{bid: &openrtb.Bid{ID: "video-low", ImpID: "imp1", Price: 2}, bidType: openrtb_ext.BidTypeVideo},
+				{bid: &openrtb.Bid{ID: "video-ok", ImpID: "imp1", Price: 6}, bidType: openrtb_ext.BidTypeVideo},
+			},
+			expectedBidIDs: []string{"banner-ok", "video-ok"},
+			expectedErrs:   2,
+		},
+		{
+			description:  "Deal Bids Not Enforced",
+			floorRules:   floorRules,
+			seatCurrency: "USD",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "deal", ImpID: "imp1", Price: 0.5, DealID: "deal1"}, bidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedBidIDs: []string{"deal"},
+		},
+		{
+			description: "Deal Bids Enforced",
+			floorRules: floors.NewRules(config.AccountPriceFloors{Enabled: true, EnforceDealFloors: true}, &openrtb_ext.PriceFloorRules{
+				FloorMin: 1,
+			}),
+			seatCurrency: "USD",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "deal", ImpID: "imp1", Price: 0.5, DealID: "deal1"}, bidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedBidIDs: []string{},
+			expectedErrs:   1,
+		},
+		{
+			description:  "Floor Converted To Seat Currency",
+			floorRules:   floorRules,
+			seatCurrency: "EUR",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "banner-low", ImpID: "imp1", Price: 0.4}, bidType: openrtb_ext.BidTypeBanner},
+				{bid: &openrtb.Bid{ID: "banner-ok", ImpID: "imp1", Price: 0.6}, bidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedBidIDs: []string{"banner-ok"},
+			expectedErrs:   1,
+		},
+		{
+			description:  "Unknown Seat Currency Keeps Bid",
+			floorRules:   floorRules,
+			seatCurrency: "JPY",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "banner-low", ImpID: "imp1", Price: 0.1}, bidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedBidIDs: []string{"banner-low"},
+			expectedErrs:   1,
+		},
+		{
+			description: "Enforcement Disabled",
+			floorRules: floors.NewRules(config.AccountPriceFloors{Enabled: true}, &openrtb_ext.PriceFloorRules{
+				FloorMin:    1,
+				Enforcement: &openrtb_ext.PriceFloorEnforcement{EnforcePBS: new(bool)},
+			}),
+			seatCurrency: "USD",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "banner-low", ImpID: "imp1", Price: 0.1}, bidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedBidIDs: []string{"banner-low"},
+		},
+	}
+
+	for _, test := range testCases {
+		seatBid := &pbsOrtbSeatBid{bids: test.bids, currency: test.seatCurrency}
+		errs := enforceFloors(test.floorRules, request, seatBid, conversions)
+
+		bidIDs := make([]string, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			bidIDs = append(bidIDs, bid.bid.ID)
+		}
+		assert.Equal(t, test.expectedBidIDs, bidIDs, test.description)
+		assert.Len(t, errs, test.expectedErrs, test.description)
+	}
+}
+
+func TestMakeFloorsResponseExt(t *testing.T) {
+	floorRules := floors.NewRules(config.AccountPriceFloors{Enabled: true}, &openrtb_ext.PriceFloorRules{})
+
+	assert.Nil(t, makeFloorsResponseExt(floorRules, nil), "no floors applied")
+
+	ext := makeFloorsResponseExt(floorRules, map[string]floors.Floor{
+		"imp1": {Value: 1.5, Currency: "EUR", Rule: "banner"},
+	})
+	expected := &openrtb_ext.ExtResponsePrebidFloors{
+		Enforced: true,
+		Imps: map[string]openrtb_ext.ExtResponsePrebidFloor{
+			"imp1": {FloorValue: 1.5, FloorCurrency: "EUR", FloorRule: "banner"},
+		},
+	}
+	assert.Equal(t, expected, ext)
+}
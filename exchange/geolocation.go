@@ -0,0 +1,78 @@
+package exchange
+
+import (
+	"net"
+
+	"github.com/golang/glog"
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+// fillDeviceGeo looks up where the device is from its IP address, and fills in the parts of device.geo
+// which the request is missing.
+func (e *exchange) fillDeviceGeo(request *openrtb.BidRequest) {
+	if e.geoLookup == nil || request.Device == nil {
+		return
+	}
+	ip := net.ParseIP(request.Device.IP)
+	if ip == nil {
+		ip = net.ParseIP(request.Device.IPv6)
+	}
+	if ip == nil {
+		return
+	}
+
+	ipGeo, err := e.geoLookup.Lookup(ip)
+	if err != nil {
+		glog.Warningf("Failed to look up where %s is: %v", ip, err)
+		e.me.RecordGeoLookup(metrics.GeoLookupError)
+		return
+	}
+	if ipGeo == nil {
+		e.me.RecordGeoLookup(metrics.GeoLookupNotFound)
+		return
+	}
+	if e.privacyConfig.GDPR.IsEEACountry(ipGeo.Country) {
+		e.me.RecordGeoLookup(metrics.GeoLookupEEA)
+	} else {
+		e.me.RecordGeoLookup(metrics.GeoLookupNonEEA)
+	}
+
+	device := *request.Device
+	device.Geo = mergeGeo(device.Geo, ipGeo)
+	request.Device = &device
+}
+
+// mergeGeo fills in the empty fields of geo from the location of the IP address. The fields are left as
+// they are if the request places the device in another country, since they wouldn't agree.
+func mergeGeo(geo *openrtb.Geo, ipGeo *openrtb.Geo) *openrtb.Geo {
+	if geo == nil {
+		return ipGeo
+	}
+	if geo.Country != "" && geo.Country != ipGeo.Country {
+		return geo
+	}
+
+	merged := *geo
+	merged.Country = ipGeo.Country
+	if merged.Region == "" {
+		merged.Region = ipGeo.Region
+	}
+	if merged.Metro == "" {
+		merged.Metro = ipGeo.Metro
+	}
+	if merged.City == "" {
+		merged.City = ipGeo.City
+	}
+	if merged.ZIP == "" {
+		merged.ZIP = ipGeo.ZIP
+	}
+	if merged.Lat == 0 && merged.Lon == 0 && ipGeo.Type != 0 {
+		merged.Lat = ipGeo.Lat
+		merged.Lon = ipGeo.Lon
+		merged.Type = ipGeo.Type
+		merged.Accuracy = ipGeo.Accuracy
+		merged.IPService = ipGeo.IPService
+	}
+	return &merged
+}
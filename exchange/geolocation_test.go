@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGeoLookup struct {
+	geos map[string]*openrtb.Geo
+	err  error
+}
+
+func (l *fakeGeoLookup) Lookup(ip net.IP) (*openrtb.Geo, error) {
+	return l.geos[ip.String()], l.err
+}
+
+func TestFillDeviceGeo(t *testing.T) {
+	berlin := &openrtb.Geo{Country: "DEU", Region: "BE", City: "Berlin", Lat: 52.53, Lon: 13.38, Type: openrtb.LocationTypeIPAddress, Accuracy: 20000}
+	lookup := &fakeGeoLookup{geos: map[string]*openrtb.Geo{"1.2.3.4": berlin, "2001:db8::1": berlin}}
+
+	testCases := []struct {
+		description    string
+		device         *openrtb.Device
+		lookupErr      error
+		expectedGeo    *openrtb.Geo
+		expectedResult metrics.GeoLookupResult
+	}{
+		{
+			description:    "Device without a geo",
+			device:         &openrtb.Device{IP: "1.2.3.4"},
+			expectedGeo:    berlin,
+			expectedResult: metrics.GeoLookupEEA,
+		},
+		{
+			description:    "IPv6 address",
+			device:         &openrtb.Device{IPv6: "2001:db8::1"},
+			expectedGeo:    berlin,
+			expectedResult: metrics.GeoLookupEEA,
+		},
+		{
+			description:    "The fields of the request are kept",
+			device:         &openrtb.Device{IP: "1.2.3.4", Geo: &openrtb.Geo{City: "Potsdam", ZIP: "14467"}},
+			expectedGeo:    &openrtb.Geo{Country: "DEU", Region: "BE", City: "Potsdam", ZIP: "14467", Lat: 52.53, Lon: 13.38, Type: openrtb.LocationTypeIPAddress, Accuracy: 20000},
+			expectedResult: metrics.GeoLookupEEA,
+		},
+		{
+			description:    "The request places the device in another country",
+			device:         &openrtb.Device{IP: "1.2.3.4", Geo: &openrtb.Geo{Country: "FRA"}},
+			expectedGeo:    &openrtb.Geo{Country: "FRA"},
+			expectedResult: metrics.GeoLookupEEA,
+		},
+		{
+			description:    "Unknown IP address",
+			device:         &openrtb.Device{IP: "5.6.7.8"},
+			expectedResult: metrics.GeoLookupNotFound,
+		},
+		{
+			description:    "Lookup error",
+			device:         &openrtb.Device{IP: "1.2.3.4"},
+			lookupErr:      errors.New("corrupt database"),
+			expectedResult: metrics.GeoLookupError,
+		},
+		{
+			description: "No IP address",
+			device:      &openrtb.Device{},
+		},
+	}
+
+	for _, test := range testCases {
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordGeoLookup", test.expectedResult).Return()
+		lookup.err = test.lookupErr
+		e := &exchange{
+			me:            metricsMock,
+			geoLookup:     lookup,
+			privacyConfig: config.Privacy{GDPR: config.GDPR{EEACountriesMap: map[string]struct{}{"DEU": {}, "FRA": {}}}},
+		}
+		request := &openrtb.BidRequest{Device: test.device}
+
+		e.fillDeviceGeo(request)
+
+		assert.Equal(t, test.expectedGeo, request.Device.Geo, test.description)
+		if test.expectedResult == "" {
+			metricsMock.AssertNotCalled(t, "RecordGeoLookup", test.expectedResult)
+		} else {
+			metricsMock.AssertCalled(t, "RecordGeoLookup", test.expectedResult)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package exchange
+
+import (
+	"encoding/json"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/hooks"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// executeRawBidderResponseStage lets the raw bidder response hooks change the bids of a bidder.
+// If a hook rejects the response, all the bids of the bidder are dropped.
+func executeRawBidderResponseStage(hookExecutor *hooks.Executor, bidderName openrtb_ext.BidderName, seatBid *pbsOrtbSeatBid) error {
+	if seatBid == nil || !hookExecutor.HasStage(hooks.StageRawBidderResponse) {
+		return nil
+	}
+
+	bids, err := hookExecutor.ExecuteRawBidderResponseStage(bidderName, toTypedBids(seatBid.bids))
+	if err != nil {
+		seatBid.bids = nil
+		return err
+	}
+	seatBid.bids = fromTypedBids(bids)
+	return nil
+}
+
+// executeAllProcessedBidResponsesStage lets the all processed bid responses hooks change the bids of every seat.
+// Seats left without bids are removed.
+func executeAllProcessedBidResponsesStage(hookExecutor *hooks.Executor, adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) map[openrtb_ext.BidderName]*pbsOrtbSeatBid {
+	responses := make(map[openrtb_ext.BidderName][]*adapters.TypedBid, len(adapterBids))
+	for bidderName, seatBid := range adapterBids {
+		responses[bidderName] = toTypedBids(seatBid.bids)
+	}
+
+	responses = hookExecutor.ExecuteAllProcessedBidResponsesStage(responses)
+
+	for bidderName, seatBid := range adapterBids {
+		if bids := responses[bidderName]; len(bids) > 0 {
+			seatBid.bids = fromTypedBids(bids)
+		} else {
+			delete(adapterBids, bidderName)
+		}
+	}
+	return adapterBids
+}
+
+// toTypedBids shows the bids to the hooks the same way the adapters return them.
+func toTypedBids(bids []*pbsOrtbBid) []*adapters.TypedBid {
+	typedBids := make([]*adapters.TypedBid, 0, len(bids))
+	for _, bid := range bids {
+		typedBids = append(typedBids, &adapters.TypedBid{
+			Bid:          bid.bid,
+			BidType:      bid.bidType,
+			BidVideo:     bid.bidVideo,
+			DealPriority: bid.dealPriority,
+		})
+	}
+	return typedBids
+}
+
+// fromTypedBids takes back the bids the hooks returned. The other fields of pbsOrtbBid are only filled once
+// the hooks have run.
+func fromTypedBids(typedBids []*adapters.TypedBid) []*pbsOrtbBid {
+	bids := make([]*pbsOrtbBid, 0, len(typedBids))
+	for _, typedBid := range typedBids {
+		if typedBid == nil || typedBid.Bid == nil {
+			continue
+		}
+		bids = append(bids, &pbsOrtbBid{
+			bid:          typedBid.Bid,
+			bidType:      typedBid.BidType,
+			bidVideo:     typedBid.BidVideo,
+			dealPriority: typedBid.DealPriority,
+		})
+	}
+	return bids
+}
+
+// addModulesOutcome writes what the hooks did to bidresponse.ext.prebid.modules.
+func addModulesOutcome(responseExt json.RawMessage, modulesOutcome *openrtb_ext.ExtModules) (json.RawMessage, error) {
+	if modulesOutcome == nil {
+		return responseExt, nil
+	}
+
+	modulesJSON, err := json.Marshal(modulesOutcome)
+	if err != nil {
+		return responseExt, err
+	}
+	if len(responseExt) == 0 {
+		responseExt = json.RawMessage(`{}`)
+	}
+	return jsonparser.Set(responseExt, modulesJSON, openrtb_ext.PrebidExtKey, "modules")
+}
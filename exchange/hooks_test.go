@@ -0,0 +1,77 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedBidsRoundTrip(t *testing.T) {
+	bids := []*pbsOrtbBid{
+		{
+			bid:          &openrtb.Bid{ID: "bid1", Price: 1},
+			bidType:      openrtb_ext.BidTypeVideo,
+			bidVideo:     &openrtb_ext.ExtBidPrebidVideo{Duration: 30},
+			dealPriority: 2,
+		},
+	}
+
+	typedBids := toTypedBids(bids)
+	typedBids = append(typedBids, nil)
+
+	assert.Equal(t, bids, fromTypedBids(typedBids))
+}
+
+func TestExecuteAllProcessedBidResponsesStageNoHooks(t *testing.T) {
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: {bids: []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "bid1"}, bidType: openrtb_ext.BidTypeBanner}}},
+	}
+
+	result := executeAllProcessedBidResponsesStage(nil, adapterBids)
+
+	assert.Equal(t, adapterBids, result)
+}
+
+func TestAddModulesOutcome(t *testing.T) {
+	modulesOutcome := &openrtb_ext.ExtModules{
+		Trace: []openrtb_ext.ExtModulesStage{{
+			Stage:    "entrypoint",
+			Outcomes: []openrtb_ext.ExtModulesHookOutcome{{Module: "acme", Hook: "entry", Status: "success"}},
+		}},
+	}
+	expectedModules := `{"trace":[{"stage":"entrypoint","executiontimemillis":0,"outcomes":[{"module":"acme","hook":"entry","status":"success","executiontimemillis":0}]}]}`
+
+	testCases := []struct {
+		description string
+		ext         json.RawMessage
+		outcome     *openrtb_ext.ExtModules
+		expectedExt string
+	}{
+		{
+			description: "No Outcome",
+			ext:         json.RawMessage(`{"prebid":{"auctiontimestamp":1}}`),
+			expectedExt: `{"prebid":{"auctiontimestamp":1}}`,
+		},
+		{
+			description: "Existing Prebid Ext",
+			ext:         json.RawMessage(`{"prebid":{"auctiontimestamp":1}}`),
+			outcome:     modulesOutcome,
+			expectedExt: `{"prebid":{"auctiontimestamp":1,"modules":` + expectedModules + `}}`,
+		},
+		{
+			description: "Empty Ext",
+			outcome:     modulesOutcome,
+			expectedExt: `{"prebid":{"modules":` + expectedModules + `}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		ext, err := addModulesOutcome(test.ext, test.outcome)
+
+		assert.NoError(t, err, test.description)
+		assert.JSONEq(t, test.expectedExt, string(ext), test.description)
+	}
+}
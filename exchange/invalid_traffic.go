@@ -0,0 +1,21 @@
+package exchange
+
+// removeSkippedBidders removes the requests to the bidders which mustn't be called for flagged invalid traffic.
+// A bidder is skipped if either its own name or the name of the bidder it's an alias of is in the list.
+func removeSkippedBidders(bidderRequests []BidderRequest, skippedBidders map[string]struct{}) []BidderRequest {
+	if len(skippedBidders) == 0 {
+		return bidderRequests
+	}
+
+	remainingRequests := make([]BidderRequest, 0, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		if _, skipped := skippedBidders[string(bidderRequest.BidderName)]; skipped {
+			continue
+		}
+		if _, skipped := skippedBidders[string(bidderRequest.BidderCoreName)]; skipped {
+			continue
+		}
+		remainingRequests = append(remainingRequests, bidderRequest)
+	}
+	return remainingRequests
+}
@@ -0,0 +1,22 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveSkippedBidders(t *testing.T) {
+	bidderRequests := []BidderRequest{
+		{BidderName: "appnexus", BidderCoreName: "appnexus"},
+		{BidderName: "districtm", BidderCoreName: "appnexus"},
+		{BidderName: "rubicon", BidderCoreName: "rubicon"},
+		{BidderName: "pubmatic", BidderCoreName: "pubmatic"},
+	}
+
+	assert.Equal(t, bidderRequests, removeSkippedBidders(bidderRequests, nil), "Nothing should be removed if no bidders are skipped")
+
+	remaining := removeSkippedBidders(bidderRequests, map[string]struct{}{"appnexus": {}, "pubmatic": {}})
+	assert.Equal(t, []openrtb_ext.BidderName{"rubicon"}, listBiddersWithRequests(remaining), "Skipped bidders and their aliases should be removed")
+}
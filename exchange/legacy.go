@@ -33,7 +33,7 @@ type adaptedAdapter struct {
 //
 // This is not ideal. OpenRTB provides a superset of the legacy data structures.
 // For requests which use those features, the best we can do is respond with "no bid".
-func (bidder *adaptedAdapter) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
+func (bidder *adaptedAdapter) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustments *openrtb_ext.ExtBidAdjustmentFactors, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool, bidderStoredResponses map[string]json.RawMessage) (*pbsOrtbSeatBid, []error) {
 	legacyRequest, legacyBidder, errs := bidder.toLegacyAdapterInputs(request, name)
 	if legacyRequest == nil || legacyBidder == nil {
 		return nil, errs
@@ -45,10 +45,13 @@ func (bidder *adaptedAdapter) requestBid(ctx context.Context, request *openrtb.B
 	}
 
 	for i := 0; i < len(legacyBids); i++ {
-		legacyBids[i].Price = legacyBids[i].Price * bidAdjustment
+		bidType, _ := openrtb_ext.ParseBidType(legacyBids[i].CreativeMediaType)
+		legacyBids[i].Price = legacyBids[i].Price * bidAdjustments.Factor(string(name), bidType, legacyBids[i].DealId)
 	}
 
 	finalResponse, moreErrs := toNewResponse(legacyBids, legacyBidder, name)
+	// Legacy adapters always call their server
+	finalResponse.httpRoundTrips = 1
 	return finalResponse, append(errs, moreErrs...)
 }
 
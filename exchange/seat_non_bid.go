@@ -0,0 +1,86 @@
+package exchange
+
+import (
+	"sort"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// addNonBid records that the bid was rejected for the reason given. Bids without an object can't be traced
+// back to an imp, so they're left out.
+func (seatBid *pbsOrtbSeatBid) addNonBid(bid *openrtb.Bid, reason openrtb_ext.NonBidReason) {
+	if bid == nil || bid.ImpID == "" {
+		return
+	}
+	seatBid.nonBids = append(seatBid.nonBids, openrtb_ext.ExtNonBid{
+		ImpID:      bid.ImpID,
+		StatusCode: reason,
+		Ext: &openrtb_ext.ExtNonBidExt{
+			Prebid: openrtb_ext.ExtNonBidPrebid{
+				Bid: openrtb_ext.ExtNonBidBid{
+					ID:      bid.ID,
+					Price:   bid.Price,
+					ADomain: bid.ADomain,
+					CrID:    bid.CrID,
+					DealID:  bid.DealID,
+					W:       bid.W,
+					H:       bid.H,
+				},
+			},
+		},
+	})
+}
+
+// timeoutNonBids returns a timeout non bid for every imp of the request the bidder didn't bid on.
+func timeoutNonBids(request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid) []openrtb_ext.ExtNonBid {
+	impsWithBids := make(map[string]bool)
+	if seatBid != nil {
+		for _, bid := range seatBid.bids {
+			impsWithBids[bid.bid.ImpID] = true
+		}
+	}
+
+	var nonBids []openrtb_ext.ExtNonBid
+	for _, imp := range request.Imp {
+		if !impsWithBids[imp.ID] {
+			nonBids = append(nonBids, openrtb_ext.ExtNonBid{
+				ImpID:      imp.ID,
+				StatusCode: openrtb_ext.NonBidErrorTimeout,
+			})
+		}
+	}
+	return nonBids
+}
+
+// moveNonBids moves the non bids of the seat to the extra data of the bidder, since seats without bids are
+// dropped from the auction.
+func moveNonBids(seatBid *pbsOrtbSeatBid, responseExtra *seatResponseExtra) {
+	if seatBid == nil || responseExtra == nil || len(seatBid.nonBids) == 0 {
+		return
+	}
+	responseExtra.NonBids = append(responseExtra.NonBids, seatBid.nonBids...)
+	seatBid.nonBids = nil
+}
+
+// makeSeatNonBids builds bidresponse.ext.seatnonbid out of the non bids of every bidder, and records them in the metrics.
+// Seats are sorted by name so that the response doesn't depend on map ordering.
+func (e *exchange) makeSeatNonBids(adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra) []openrtb_ext.ExtSeatNonBid {
+	var seatNonBids []openrtb_ext.ExtSeatNonBid
+	for bidderName, responseExtra := range adapterExtra {
+		if responseExtra == nil || len(responseExtra.NonBids) == 0 {
+			continue
+		}
+		for _, nonBid := range responseExtra.NonBids {
+			e.me.RecordAdapterNonBid(bidderName, nonBid.StatusCode)
+		}
+		seatNonBids = append(seatNonBids, openrtb_ext.ExtSeatNonBid{
+			Seat:   bidderName.String(),
+			NonBid: responseExtra.NonBids,
+		})
+	}
+	sort.Slice(seatNonBids, func(i, j int) bool {
+		return seatNonBids[i].Seat < seatNonBids[j].Seat
+	})
+	return seatNonBids
+}
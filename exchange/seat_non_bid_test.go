@@ -0,0 +1,64 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutNonBids(t *testing.T) {
+	request := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "imp1"}, {ID: "imp2"}}}
+	seatBid := &pbsOrtbSeatBid{bids: []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "bid1", ImpID: "imp1"}}}}
+
+	expected := []openrtb_ext.ExtNonBid{{ImpID: "imp2", StatusCode: openrtb_ext.NonBidErrorTimeout}}
+	assert.Equal(t, expected, timeoutNonBids(request, seatBid), "Imps with a bid aren't reported")
+	assert.Len(t, timeoutNonBids(request, nil), 2, "Every imp is reported if the bidder had no bids")
+}
+
+func TestMakeSeatNonBids(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterNonBid", openrtb_ext.BidderAppnexus, openrtb_ext.NonBidResponseRejectedBelowFloor).Return()
+	metricsMock.On("RecordAdapterNonBid", openrtb_ext.BidderRubicon, openrtb_ext.NonBidErrorTimeout).Return()
+	e := &exchange{me: metricsMock}
+
+	rubiconSeat := &pbsOrtbSeatBid{bids: []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "bid2", ImpID: "imp1"}}}}
+	rubiconExtra := &seatResponseExtra{NonBids: timeoutNonBids(&openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "imp2"}}}, nil)}
+	moveNonBids(rubiconSeat, rubiconExtra)
+
+	appnexusSeat := &pbsOrtbSeatBid{}
+	appnexusSeat.addNonBid(&openrtb.Bid{ID: "bid1", ImpID: "imp1", Price: 0.5, CrID: "creative", W: 300, H: 250}, openrtb_ext.NonBidResponseRejectedBelowFloor)
+	appnexusSeat.addNonBid(nil, openrtb_ext.NonBidResponseRejectedGeneral)
+	appnexusExtra := &seatResponseExtra{}
+	moveNonBids(appnexusSeat, appnexusExtra)
+
+	adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{
+		openrtb_ext.BidderRubicon:  rubiconExtra,
+		openrtb_ext.BidderAppnexus: appnexusExtra,
+		openrtb_ext.BidderOpenx:    {},
+	}
+
+	expected := []openrtb_ext.ExtSeatNonBid{
+		{
+			Seat: "appnexus",
+			NonBid: []openrtb_ext.ExtNonBid{{
+				ImpID:      "imp1",
+				StatusCode: openrtb_ext.NonBidResponseRejectedBelowFloor,
+				Ext: &openrtb_ext.ExtNonBidExt{
+					Prebid: openrtb_ext.ExtNonBidPrebid{
+						Bid: openrtb_ext.ExtNonBidBid{ID: "bid1", Price: 0.5, CrID: "creative", W: 300, H: 250},
+					},
+				},
+			}},
+		},
+		{
+			Seat:   "rubicon",
+			NonBid: []openrtb_ext.ExtNonBid{{ImpID: "imp2", StatusCode: openrtb_ext.NonBidErrorTimeout}},
+		},
+	}
+	assert.Equal(t, expected, e.makeSeatNonBids(adapterExtra))
+	assert.Nil(t, appnexusSeat.nonBids, "The non bids should be moved off the seat")
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterNonBid", 2)
+}
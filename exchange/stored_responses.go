@@ -0,0 +1,110 @@
+package exchange
+
+import (
+	"encoding/json"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// applyStoredResponses removes the imps which have a stored auction response from every bidder request,
+// and hands each bidder the stored bid responses meant for it. Bidders left without imps are dropped,
+// since the stored auction responses already hold the result of their auction.
+func applyStoredResponses(bidderRequests []BidderRequest, storedAuctionResponses map[string][]openrtb.SeatBid, storedBidResponses map[string]map[string]json.RawMessage) []BidderRequest {
+	if len(storedAuctionResponses) == 0 && len(storedBidResponses) == 0 {
+		return bidderRequests
+	}
+
+	remainingRequests := make([]BidderRequest, 0, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		imps := make([]openrtb.Imp, 0, len(bidderRequest.BidRequest.Imp))
+		for _, imp := range bidderRequest.BidRequest.Imp {
+			if _, ok := storedAuctionResponses[imp.ID]; ok {
+				continue
+			}
+			if bidResponse, ok := storedBidResponses[imp.ID][string(bidderRequest.BidderName)]; ok {
+				if bidderRequest.BidderStoredResponses == nil {
+					bidderRequest.BidderStoredResponses = make(map[string]json.RawMessage)
+				}
+				bidderRequest.BidderStoredResponses[imp.ID] = bidResponse
+			}
+			imps = append(imps, imp)
+		}
+		if len(imps) == 0 {
+			continue
+		}
+		bidderRequest.BidRequest.Imp = imps
+		remainingRequests = append(remainingRequests, bidderRequest)
+	}
+	return remainingRequests
+}
+
+// addStoredAuctionResponses adds the bids from the stored auction responses to the seats they name, as if
+// the seats had returned them. It returns the seats which didn't already have a request in the auction.
+func addStoredAuctionResponses(request *openrtb.BidRequest, storedAuctionResponses map[string][]openrtb.SeatBid, liveAdapters []openrtb_ext.BidderName, adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra) []openrtb_ext.BidderName {
+	if len(storedAuctionResponses) == 0 {
+		return nil
+	}
+
+	seatCurrency := "USD"
+	if len(request.Cur) > 0 {
+		seatCurrency = request.Cur[0]
+	}
+
+	knownSeats := make(map[openrtb_ext.BidderName]struct{}, len(liveAdapters))
+	for _, bidderName := range liveAdapters {
+		knownSeats[bidderName] = struct{}{}
+	}
+
+	var newSeats []openrtb_ext.BidderName
+	for i := range request.Imp {
+		imp := &request.Imp[i]
+		for _, storedSeatBid := range storedAuctionResponses[imp.ID] {
+			seat := openrtb_ext.BidderName(storedSeatBid.Seat)
+			if _, ok := knownSeats[seat]; !ok {
+				knownSeats[seat] = struct{}{}
+				newSeats = append(newSeats, seat)
+			}
+			if adapterExtra[seat] == nil {
+				adapterExtra[seat] = &seatResponseExtra{}
+			}
+
+			for j := range storedSeatBid.Bid {
+				bid := storedSeatBid.Bid[j]
+				// The stored bids are answers for this imp, no matter which imp ID they were saved with.
+				bid.ImpID = imp.ID
+				if adapterBids[seat] == nil {
+					adapterBids[seat] = &pbsOrtbSeatBid{currency: seatCurrency}
+				}
+				adapterBids[seat].bids = append(adapterBids[seat].bids, &pbsOrtbBid{
+					bid:     &bid,
+					bidType: storedBidType(&bid, imp),
+				})
+			}
+		}
+	}
+	return newSeats
+}
+
+// storedBidType reads the type of a stored bid from bid.ext.prebid.type. If it's missing, the type is taken
+// from the first media type the imp asks for, checking banner, video, audio and native in that order.
+func storedBidType(bid *openrtb.Bid, imp *openrtb.Imp) openrtb_ext.BidType {
+	if value, err := jsonparser.GetString(bid.Ext, openrtb_ext.PrebidExtKey, "type"); err == nil {
+		if bidType, err := openrtb_ext.ParseBidType(value); err == nil {
+			return bidType
+		}
+	}
+
+	switch {
+	case imp.Banner != nil:
+		return openrtb_ext.BidTypeBanner
+	case imp.Video != nil:
+		return openrtb_ext.BidTypeVideo
+	case imp.Audio != nil:
+		return openrtb_ext.BidTypeAudio
+	case imp.Native != nil:
+		return openrtb_ext.BidTypeNative
+	}
+	return openrtb_ext.BidTypeBanner
+}
@@ -0,0 +1,72 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyStoredResponses(t *testing.T) {
+	bidderRequests := []BidderRequest{
+		{
+			BidderName: openrtb_ext.BidderAppnexus,
+			BidRequest: &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "imp1"}, {ID: "imp2"}}},
+		},
+		{
+			BidderName: openrtb_ext.BidderRubicon,
+			BidRequest: &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "imp1"}}},
+		},
+	}
+	storedAuctionResponses := map[string][]openrtb.SeatBid{
+		"imp1": {{Seat: "appnexus"}},
+	}
+	storedBidResponses := map[string]map[string]json.RawMessage{
+		"imp2": {"appnexus": json.RawMessage(`{"id":"stored"}`)},
+	}
+
+	result := applyStoredResponses(bidderRequests, storedAuctionResponses, storedBidResponses)
+
+	if assert.Len(t, result, 1, "rubicon only bids on an imp with a stored auction response") {
+		assert.Equal(t, openrtb_ext.BidderAppnexus, result[0].BidderName)
+		assert.Equal(t, []openrtb.Imp{{ID: "imp2"}}, result[0].BidRequest.Imp)
+		assert.Equal(t, map[string]json.RawMessage{"imp2": json.RawMessage(`{"id":"stored"}`)}, result[0].BidderStoredResponses)
+	}
+}
+
+func TestAddStoredAuctionResponses(t *testing.T) {
+	request := &openrtb.BidRequest{
+		Cur: []string{"EUR"},
+		Imp: []openrtb.Imp{
+			{ID: "imp1", Video: &openrtb.Video{}},
+			{ID: "imp2", Banner: &openrtb.Banner{}},
+		},
+	}
+	storedAuctionResponses := map[string][]openrtb.SeatBid{
+		"imp1": {{Seat: "appnexus", Bid: []openrtb.Bid{{ID: "bid1", ImpID: "saved-imp", Price: 1}}}},
+		"imp2": {{Seat: "rubicon", Bid: []openrtb.Bid{{ID: "bid2", Price: 2, Ext: json.RawMessage(`{"prebid":{"type":"native"}}`)}}}},
+	}
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: {currency: "USD", bids: []*pbsOrtbBid{{bid: &openrtb.Bid{ID: "live", ImpID: "imp3"}}}},
+	}
+	adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{
+		openrtb_ext.BidderAppnexus: {},
+	}
+
+	newSeats := addStoredAuctionResponses(request, storedAuctionResponses, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, adapterBids, adapterExtra)
+
+	assert.Equal(t, []openrtb_ext.BidderName{openrtb_ext.BidderRubicon}, newSeats)
+	assert.NotNil(t, adapterExtra[openrtb_ext.BidderRubicon])
+
+	if assert.Len(t, adapterBids[openrtb_ext.BidderAppnexus].bids, 2) {
+		storedBid := adapterBids[openrtb_ext.BidderAppnexus].bids[1]
+		assert.Equal(t, "imp1", storedBid.bid.ImpID, "stored bids take the ID of the imp they answer")
+		assert.Equal(t, openrtb_ext.BidTypeVideo, storedBid.bidType)
+	}
+	if assert.Len(t, adapterBids[openrtb_ext.BidderRubicon].bids, 1) {
+		assert.Equal(t, "EUR", adapterBids[openrtb_ext.BidderRubicon].currency)
+		assert.Equal(t, openrtb_ext.BidTypeNative, adapterBids[openrtb_ext.BidderRubicon].bids[0].bidType)
+	}
+}
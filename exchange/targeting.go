@@ -24,6 +24,8 @@ type targetData struct {
 	includeCacheVast  bool
 	includeFormat     bool
 	preferDeals       bool
+	// multiBid holds the ext.prebid.multibid entry of each bidder which may keep more than one bid per imp
+	multiBid map[string]openrtb_ext.ExtMultiBid
 	// cacheHost and cachePath exist to supply cache host and path as targeting parameters
 	cacheHost string
 	cachePath string
@@ -38,50 +40,70 @@ type targetData struct {
 func (targData *targetData) setTargeting(auc *auction, isApp bool, categoryMapping map[string]string) {
 	for impId, topBidsPerImp := range auc.winningBidsByBidder {
 		overallWinner := auc.winningBids[impId]
-		for bidderName, topBidPerBidder := range topBidsPerImp {
-			isOverallWinner := overallWinner == topBidPerBidder
+		for bidderName, topBidsPerBidder := range topBidsPerImp {
+			for rank, topBid := range topBidsPerBidder {
+				targetingBidderCode, ok := targData.targetingBidderCode(bidderName, rank)
+				if !ok {
+					continue
+				}
+				isOverallWinner := overallWinner == topBid
 
-			targets := make(map[string]string, 10)
-			if cpm, ok := auc.roundedPrices[topBidPerBidder]; ok {
-				targData.addKeys(targets, openrtb_ext.HbpbConstantKey, cpm, bidderName, isOverallWinner)
-			}
-			targData.addKeys(targets, openrtb_ext.HbBidderConstantKey, string(bidderName), bidderName, isOverallWinner)
-			if hbSize := makeHbSize(topBidPerBidder.bid); hbSize != "" {
-				targData.addKeys(targets, openrtb_ext.HbSizeConstantKey, hbSize, bidderName, isOverallWinner)
-			}
-			if cacheID, ok := auc.cacheIds[topBidPerBidder.bid]; ok {
-				targData.addKeys(targets, openrtb_ext.HbCacheKey, cacheID, bidderName, isOverallWinner)
-			}
-			if vastID, ok := auc.vastCacheIds[topBidPerBidder.bid]; ok {
-				targData.addKeys(targets, openrtb_ext.HbVastCacheKey, vastID, bidderName, isOverallWinner)
-			}
-			if targData.includeFormat {
-				targData.addKeys(targets, openrtb_ext.HbFormatKey, string(topBidPerBidder.bidType), bidderName, isOverallWinner)
-			}
+				targets := make(map[string]string, 10)
+				if cpm, ok := auc.roundedPrices[topBid]; ok {
+					targData.addKeys(targets, openrtb_ext.HbpbConstantKey, cpm, targetingBidderCode, isOverallWinner)
+				}
+				targData.addKeys(targets, openrtb_ext.HbBidderConstantKey, string(targetingBidderCode), targetingBidderCode, isOverallWinner)
+				if hbSize := makeHbSize(topBid.bid); hbSize != "" {
+					targData.addKeys(targets, openrtb_ext.HbSizeConstantKey, hbSize, targetingBidderCode, isOverallWinner)
+				}
+				if cacheID, ok := auc.cacheIds[topBid.bid]; ok {
+					targData.addKeys(targets, openrtb_ext.HbCacheKey, cacheID, targetingBidderCode, isOverallWinner)
+				}
+				if vastID, ok := auc.vastCacheIds[topBid.bid]; ok {
+					targData.addKeys(targets, openrtb_ext.HbVastCacheKey, vastID, targetingBidderCode, isOverallWinner)
+				}
+				if targData.includeFormat {
+					targData.addKeys(targets, openrtb_ext.HbFormatKey, string(topBid.bidType), targetingBidderCode, isOverallWinner)
+				}
 
-			if targData.cacheHost != "" {
-				targData.addKeys(targets, openrtb_ext.HbConstantCacheHostKey, targData.cacheHost, bidderName, isOverallWinner)
-			}
-			if targData.cachePath != "" {
-				targData.addKeys(targets, openrtb_ext.HbConstantCachePathKey, targData.cachePath, bidderName, isOverallWinner)
-			}
+				if targData.cacheHost != "" {
+					targData.addKeys(targets, openrtb_ext.HbConstantCacheHostKey, targData.cacheHost, targetingBidderCode, isOverallWinner)
+				}
+				if targData.cachePath != "" {
+					targData.addKeys(targets, openrtb_ext.HbConstantCachePathKey, targData.cachePath, targetingBidderCode, isOverallWinner)
+				}
 
-			if deal := topBidPerBidder.bid.DealID; len(deal) > 0 {
-				targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, deal, bidderName, isOverallWinner)
-			}
+				if deal := topBid.bid.DealID; len(deal) > 0 {
+					targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, deal, targetingBidderCode, isOverallWinner)
+				}
 
-			if isApp {
-				targData.addKeys(targets, openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp, bidderName, isOverallWinner)
-			}
-			if len(categoryMapping) > 0 {
-				targData.addKeys(targets, openrtb_ext.HbCategoryDurationKey, categoryMapping[topBidPerBidder.bid.ID], bidderName, isOverallWinner)
-			}
+				if isApp {
+					targData.addKeys(targets, openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp, targetingBidderCode, isOverallWinner)
+				}
+				if len(categoryMapping) > 0 {
+					targData.addKeys(targets, openrtb_ext.HbCategoryDurationKey, categoryMapping[topBid.bid.ID], targetingBidderCode, isOverallWinner)
+				}
 
-			topBidPerBidder.bidTargets = targets
+				topBid.bidTargets = targets
+			}
 		}
 	}
 }
 
+// targetingBidderCode returns the name used in place of the bidder in the targeting keys of its bid at the given rank.
+// A bidder's highest bid is keyed by the bidder name. Its other bids are only targeted if the request set a
+// targetbiddercodeprefix for the bidder in ext.prebid.multibid.
+func (targData *targetData) targetingBidderCode(bidderName openrtb_ext.BidderName, rank int) (openrtb_ext.BidderName, bool) {
+	if rank == 0 {
+		return bidderName, true
+	}
+	prefix := targData.multiBid[string(bidderName)].TargetBidderCodePrefix
+	if prefix == "" {
+		return "", false
+	}
+	return openrtb_ext.BidderName(prefix + strconv.Itoa(rank+1)), true
+}
+
 func (targData *targetData) addKeys(keys map[string]string, key openrtb_ext.TargetingKey, value string, bidderName openrtb_ext.BidderName, overallWinner bool) {
 	if targData.includeBidderKeys {
 		keys[key.BidderKey(bidderName, MaxKeyLength)] = value
@@ -142,7 +142,7 @@ func buildAdapterMap(bids map[openrtb_ext.BidderName][]*openrtb.Bid, mockServerU
 		adapterMap[bidder] = adaptBidder(&mockTargetingBidder{
 			mockServerURL: mockServerURL,
 			bids:          bids,
-		}, client, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		}, client, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 	}
 	return adapterMap
 }
@@ -257,6 +257,8 @@ type TargetingTestData struct {
 	IsApp                      bool
 	CategoryMapping            map[string]string
 	ExpectedBidTargetsByBidder map[string]map[openrtb_ext.BidderName]map[string]string
+	// ExpectedExtraBidTargetsByBidder holds the targeting expected on the second bid of each bidder
+	ExpectedExtraBidTargetsByBidder map[string]map[openrtb_ext.BidderName]map[string]string
 }
 
 var bid123 *openrtb.Bid = &openrtb.Bid{
@@ -279,16 +281,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			includeWinners:   true,
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid084,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 		},
@@ -309,16 +311,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			includeBidderKeys: true,
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid084,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 		},
@@ -344,16 +346,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			includeFormat:     true,
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid084,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 		},
@@ -384,16 +386,16 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			cachePath:         "cache",
 		},
 		Auction: auction{
-			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
 				"ImpId-1": {
-					openrtb_ext.BidderAppnexus: {
+					openrtb_ext.BidderAppnexus: {{
 						bid:     bid123,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
-					openrtb_ext.BidderRubicon: {
+					}},
+					openrtb_ext.BidderRubicon: {{
 						bid:     bid111,
 						bidType: openrtb_ext.BidTypeBanner,
-					},
+					}},
 				},
 			},
 			cacheIds: map[*openrtb.Bid]string{
@@ -421,6 +423,67 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			},
 		},
 	},
+	{
+		Description: "Multibid targeting with bidder code prefix",
+		TargetData: targetData{
+			priceGranularity:  openrtb_ext.PriceGranularityFromString("med"),
+			includeWinners:    true,
+			includeBidderKeys: true,
+			multiBid: map[string]openrtb_ext.ExtMultiBid{
+				"appnexus": {Bidder: "appnexus", TargetBidderCodePrefix: "apn"},
+			},
+		},
+		Auction: auction{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"ImpId-1": {
+					openrtb_ext.BidderAppnexus: {
+						{
+							bid:     bid123,
+							bidType: openrtb_ext.BidTypeBanner,
+						},
+						{
+							bid:     bid111,
+							bidType: openrtb_ext.BidTypeBanner,
+						},
+					},
+					openrtb_ext.BidderRubicon: {
+						{
+							bid:     bid084,
+							bidType: openrtb_ext.BidTypeBanner,
+						},
+						{
+							bid:     bid084,
+							bidType: openrtb_ext.BidTypeBanner,
+						},
+					},
+				},
+			},
+		},
+		ExpectedBidTargetsByBidder: map[string]map[openrtb_ext.BidderName]map[string]string{
+			"ImpId-1": {
+				openrtb_ext.BidderAppnexus: {
+					"hb_bidder":          "appnexus",
+					"hb_bidder_appnexus": "appnexus",
+					"hb_pb":              "1.20",
+					"hb_pb_appnexus":     "1.20",
+				},
+				openrtb_ext.BidderRubicon: {
+					"hb_bidder_rubicon": "rubicon",
+					"hb_pb_rubicon":     "0.80",
+				},
+			},
+		},
+		ExpectedExtraBidTargetsByBidder: map[string]map[openrtb_ext.BidderName]map[string]string{
+			"ImpId-1": {
+				openrtb_ext.BidderAppnexus: {
+					"hb_bidder_apn2": "apn2",
+					"hb_pb_apn2":     "1.10",
+					"hb_deal_apn2":   "mydeal",
+				},
+				openrtb_ext.BidderRubicon: nil,
+			},
+		},
+	},
 }
 
 func TestSetTargeting(t *testing.T) {
@@ -431,7 +494,8 @@ func TestSetTargeting(t *testing.T) {
 		winningBids := make(map[string]*pbsOrtbBid)
 		// Set winning bids from the auction data
 		for imp, bidsByBidder := range auc.winningBidsByBidder {
-			for _, bid := range bidsByBidder {
+			for _, bids := range bidsByBidder {
+				bid := bids[0]
 				if winningBid, ok := winningBids[imp]; ok {
 					if winningBid.bid.Price < bid.bid.Price {
 						winningBids[imp] = bid
@@ -448,13 +512,24 @@ func TestSetTargeting(t *testing.T) {
 			for bidder, expected := range targetsByBidder {
 				assert.Equal(t,
 					expected,
-					auc.winningBidsByBidder[imp][bidder].bidTargets,
+					auc.winningBidsByBidder[imp][bidder][0].bidTargets,
 					"Test: %s\nTargeting failed for bidder %s on imp %s.",
 					test.Description,
 					string(bidder),
 					imp)
 			}
 		}
+		for imp, targetsByBidder := range test.ExpectedExtraBidTargetsByBidder {
+			for bidder, expected := range targetsByBidder {
+				assert.Equal(t,
+					expected,
+					auc.winningBidsByBidder[imp][bidder][1].bidTargets,
+					"Test: %s\nTargeting failed for the second bid of bidder %s on imp %s.",
+					test.Description,
+					string(bidder),
+					imp)
+			}
+		}
 	}
 
 }
@@ -16,6 +16,8 @@ import (
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/coppa"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/privacy/lmt"
 )
 
@@ -47,6 +49,47 @@ func BidderToPrebidSChains(req *openrtb_ext.ExtRequest) (map[string]*openrtb_ext
 	return bidderToSChains, nil
 }
 
+// BidderToMultiBid maps each bidder named in request.ext.prebid.multibid to the multibid entry which applies to it.
+func BidderToMultiBid(req *openrtb_ext.ExtRequest) (map[string]openrtb_ext.ExtMultiBid, error) {
+	bidderToMultiBid := make(map[string]openrtb_ext.ExtMultiBid)
+
+	if req != nil {
+		for i, multiBid := range req.Prebid.MultiBid {
+			if multiBid == nil {
+				continue
+			}
+			if multiBid.MaxBids == nil {
+				return nil, fmt.Errorf(`request.ext.prebid.multibid[%d] missing required field: "maxbids"`, i)
+			}
+			if *multiBid.MaxBids < 1 || *multiBid.MaxBids > openrtb_ext.MaxBidsPerBidder {
+				return nil, fmt.Errorf("request.ext.prebid.multibid[%d].maxbids must be between 1 and %d. Got %d", i, openrtb_ext.MaxBidsPerBidder, *multiBid.MaxBids)
+			}
+
+			bidders := multiBid.Bidders
+			if multiBid.Bidder != "" {
+				if len(bidders) > 0 {
+					return nil, fmt.Errorf(`request.ext.prebid.multibid[%d] must contain only one of "bidder" or "bidders"`, i)
+				}
+				bidders = []string{multiBid.Bidder}
+			} else if len(bidders) == 0 {
+				return nil, fmt.Errorf(`request.ext.prebid.multibid[%d] missing required field: "bidder" or "bidders"`, i)
+			} else if multiBid.TargetBidderCodePrefix != "" {
+				return nil, fmt.Errorf(`request.ext.prebid.multibid[%d].targetbiddercodeprefix can only be used with "bidder"`, i)
+			}
+
+			for _, bidder := range bidders {
+				if _, present := bidderToMultiBid[bidder]; present {
+					return nil, fmt.Errorf("request.ext.prebid.multibid contains multiple entries for bidder %s; "+
+						"it must contain no more than one per bidder.", bidder)
+				}
+				bidderToMultiBid[bidder] = *multiBid
+			}
+		}
+	}
+
+	return bidderToMultiBid, nil
+}
+
 // cleanOpenRTBRequests splits the input request into requests which are sanitized for each bidder. Intended behavior is:
 //
 //   1. BidRequest.Imp[].Ext will only contain the "prebid" field and a "bidder" field which has the params for the intended Bidder.
@@ -90,12 +133,20 @@ func cleanOpenRTBRequests(ctx context.Context,
 		errs = append(errs, err)
 	}
 
+	gppEnforcer, err := extractGPP(req.BidRequest, privacyConfig, &req.Account, integrationTypeMap[req.LegacyLabels.RType])
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	coppaEnforcer := coppa.ReadFromRequest(req.BidRequest)
 	lmtEnforcer := extractLMT(req.BidRequest, privacyConfig)
 
 	// request level privacy policies
 	privacyEnforcement := privacy.Enforcement{
-		COPPA: req.BidRequest.Regs != nil && req.BidRequest.Regs.COPPA == 1,
-		LMT:   lmtEnforcer.ShouldEnforce(unknownBidder),
+		COPPA:     coppaEnforcer.ShouldEnforce(unknownBidder),
+		LMT:       lmtEnforcer.ShouldEnforce(unknownBidder),
+		IPMasks:   req.Account.IPMasks,
+		IPHashKey: []byte(privacyConfig.IPHashSecret),
 	}
 
 	privacyLabels.CCPAProvided = ccpaEnforcer.CanEnforce()
@@ -103,6 +154,12 @@ func cleanOpenRTBRequests(ctx context.Context,
 	privacyLabels.COPPAEnforced = privacyEnforcement.COPPA
 	privacyLabels.LMTEnforced = lmtEnforcer.ShouldEnforce(unknownBidder)
 
+	activityControl := privacy.NewActivityControl(&req.Account.Activities)
+	activityScope := privacy.ActivityScope{
+		GDPR:    gdprEnforced,
+		Country: extractCountry(req.BidRequest),
+	}
+
 	gdprEnforced = gdprEnforced && gdprEnabled(&req.Account, privacyConfig, integrationTypeMap[req.LegacyLabels.RType])
 
 	if gdprEnforced {
@@ -116,13 +173,20 @@ func cleanOpenRTBRequests(ctx context.Context,
 
 	// bidder level privacy policies
 	for _, bidderRequest := range bidderRequests {
+		// activity controls
+		activityScope.Bidder = bidderRequest.BidderName.String()
+		privacyEnforcement.EIDs = !activityControl.Allow(privacy.ActivityTransmitEIDs, activityScope)
+		privacyEnforcement.PreciseGeo = !activityControl.Allow(privacy.ActivityTransmitPreciseGeo, activityScope)
+		privacyEnforcement.UFPD = !activityControl.Allow(privacy.ActivityTransmitUFPD, activityScope)
+
 		// CCPA
 		privacyEnforcement.CCPA = ccpaEnforcer.ShouldEnforce(bidderRequest.BidderName.String())
+		privacyEnforcement.GPP = gppEnforcer.ShouldEnforce(bidderRequest.BidderName.String())
 
 		// GDPR
 		if gdprEnforced {
 			var publisherID = req.LegacyLabels.PubID
-			_, geo, id, err := gDPR.PersonalInfoAllowed(ctx, bidderRequest.BidderCoreName, publisherID, gdprSignal, consent)
+			_, geo, id, err := gDPR.PersonalInfoAllowed(ctx, bidderRequest.BidderCoreName, publisherID, gdprSignal, consent, req.Account.GDPR)
 			if err == nil {
 				privacyEnforcement.GDPRGeo = !geo
 				privacyEnforcement.GDPRID = !id
@@ -157,6 +221,8 @@ func extractCCPA(orig *openrtb.BidRequest, privacyConfig config.Privacy, account
 	if err != nil {
 		return privacy.NilPolicyEnforcer{}, err
 	}
+	// The Global Privacy Control signal opts the user out only if the host or account says so
+	ccpaPolicy.GPC = ccpaPolicy.GPC && account.CCPA.GPC
 
 	validBidders := GetValidBidders(aliases)
 	ccpaParsedPolicy, err := ccpaPolicy.Parse(validBidders)
@@ -171,6 +237,32 @@ func extractCCPA(orig *openrtb.BidRequest, privacyConfig config.Privacy, account
 	return ccpaEnforcer, nil
 }
 
+// extractGPP enforces the opt outs of the US national and state sections of the GPP string. They're enabled
+// along with CCPA, since they're the US privacy laws which followed it.
+func extractGPP(orig *openrtb.BidRequest, privacyConfig config.Privacy, account *config.Account, requestType config.IntegrationType) (privacy.PolicyEnforcer, error) {
+	gppPolicy, err := gpp.ReadFromRequest(orig)
+	if err != nil {
+		return privacy.NilPolicyEnforcer{}, err
+	}
+
+	gppParsedPolicy, err := gppPolicy.Parse()
+	if err != nil {
+		return privacy.NilPolicyEnforcer{}, err
+	}
+
+	return privacy.EnabledPolicyEnforcer{
+		Enabled:        ccpaEnabled(account, privacyConfig, requestType),
+		PolicyEnforcer: gppParsedPolicy,
+	}, nil
+}
+
+func extractCountry(orig *openrtb.BidRequest) string {
+	if orig.Device != nil && orig.Device.Geo != nil {
+		return orig.Device.Geo.Country
+	}
+	return ""
+}
+
 func extractLMT(orig *openrtb.BidRequest, privacyConfig config.Privacy) privacy.PolicyEnforcer {
 	return privacy.EnabledPolicyEnforcer{
 		Enabled:        privacyConfig.LMT.Enforce,
@@ -680,8 +772,8 @@ func getDebugInfo(bidRequest *openrtb.BidRequest, requestExt *openrtb_ext.ExtReq
 	return (bidRequest != nil && bidRequest.Test == 1) || (requestExt != nil && requestExt.Prebid.Debug)
 }
 
-func getExtBidAdjustmentFactors(requestExt *openrtb_ext.ExtRequest) map[string]float64 {
-	var bidAdjustmentFactors map[string]float64
+func getExtBidAdjustmentFactors(requestExt *openrtb_ext.ExtRequest) *openrtb_ext.ExtBidAdjustmentFactors {
+	var bidAdjustmentFactors *openrtb_ext.ExtBidAdjustmentFactors
 	if requestExt != nil {
 		bidAdjustmentFactors = requestExt.Prebid.BidAdjustmentFactors
 	}
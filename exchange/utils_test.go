@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/mxmCherry/openrtb"
@@ -12,6 +15,7 @@ import (
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
 )
@@ -24,15 +28,15 @@ type permissionsMock struct {
 	personalInfoAllowedError error
 }
 
-func (p *permissionsMock) HostCookiesAllowed(ctx context.Context, gdpr gdpr.Signal, consent string) (bool, error) {
+func (p *permissionsMock) HostCookiesAllowed(ctx context.Context, gdpr gdpr.Signal, consent string, account config.AccountGDPR) (bool, error) {
 	return true, nil
 }
 
-func (p *permissionsMock) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdpr gdpr.Signal, consent string) (bool, error) {
+func (p *permissionsMock) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdpr gdpr.Signal, consent string, account config.AccountGDPR) (bool, error) {
 	return true, nil
 }
 
-func (p *permissionsMock) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdpr gdpr.Signal, consent string) (bool, bool, bool, error) {
+func (p *permissionsMock) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdpr gdpr.Signal, consent string, account config.AccountGDPR) (bool, bool, bool, error) {
 	return p.personalInfoAllowed, p.personalInfoAllowed, p.personalInfoAllowed, p.personalInfoAllowedError
 }
 
@@ -308,6 +312,213 @@ func TestCleanOpenRTBRequestsCCPAErrors(t *testing.T) {
 	}
 }
 
+func TestCleanOpenRTBRequestsGPP(t *testing.T) {
+	testCases := []struct {
+		description     string
+		regsExt         string
+		reqExt          string
+		ccpaHostEnabled bool
+		expectDataScrub bool
+		expectError     bool
+	}{
+		{
+			description:     "US National Opt Out",
+			regsExt:         `{"gpp":"DBABLA~BVQaAAAAAgA.QA","gpp_sid":[7]}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: true,
+		},
+		{
+			description:     "US National Opt Out - CCPA Disabled",
+			regsExt:         `{"gpp":"DBABLA~BVQaAAAAAgA.QA","gpp_sid":[7]}`,
+			ccpaHostEnabled: false,
+			expectDataScrub: false,
+		},
+		{
+			description:     "US National Opt Out - Section Doesn't Apply",
+			regsExt:         `{"gpp":"DBABLA~BVQaAAAAAgA.QA","gpp_sid":[8]}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: false,
+		},
+		{
+			description:     "US National Opt Out - Bidder Is A NoSale Bidder",
+			regsExt:         `{"gpp":"DBABLA~BVQaAAAAAgA.QA","gpp_sid":[7]}`,
+			reqExt:          `{"prebid":{"nosale":["appnexus"]}}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: false,
+		},
+		{
+			description:     "US National No Opt Out",
+			regsExt:         `{"gpp":"DBABLA~BVQqAAAAAgA.QA","gpp_sid":[7]}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: false,
+		},
+		{
+			description:     "Invalid GPP String",
+			regsExt:         `{"gpp":"malformed","gpp_sid":[7]}`,
+			ccpaHostEnabled: true,
+			expectDataScrub: false,
+			expectError:     true,
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.Regs = &openrtb.Regs{Ext: json.RawMessage(test.regsExt)}
+		if test.reqExt != "" {
+			req.Ext = json.RawMessage(test.reqExt)
+		}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+		}
+
+		privacyConfig := config.Privacy{
+			CCPA: config.CCPA{
+				Enforce: test.ccpaHostEnabled,
+			},
+		}
+
+		bidderRequests, _, errs := cleanOpenRTBRequests(
+			context.Background(),
+			auctionReq,
+			nil,
+			&permissionsMock{personalInfoAllowed: true},
+			true,
+			privacyConfig)
+		result := bidderRequests[0]
+
+		if test.expectError {
+			assert.Len(t, errs, 1, test.description+":errors")
+		} else {
+			assert.Empty(t, errs, test.description+":errors")
+		}
+		if test.expectDataScrub {
+			assert.Equal(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
+			assert.Equal(t, result.BidRequest.Device.DIDMD5, "", test.description+":Device.DIDMD5")
+		} else {
+			assert.NotEqual(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
+			assert.NotEqual(t, result.BidRequest.Device.DIDMD5, "", test.description+":Device.DIDMD5")
+		}
+	}
+}
+
+func TestCleanOpenRTBRequestsGPC(t *testing.T) {
+	testCases := []struct {
+		description         string
+		regsExt             string
+		accountGPC          bool
+		expectDataScrub     bool
+		expectPrivacyLabels metrics.PrivacyLabels
+	}{
+		{
+			description:     "GPC - Enabled",
+			regsExt:         `{"gpc":"1"}`,
+			accountGPC:      true,
+			expectDataScrub: true,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+		},
+		{
+			description:         "GPC - Disabled",
+			regsExt:             `{"gpc":"1"}`,
+			accountGPC:          false,
+			expectDataScrub:     false,
+			expectPrivacyLabels: metrics.PrivacyLabels{},
+		},
+		{
+			description:     "GPC - Enabled With US Privacy Opt In",
+			regsExt:         `{"gpc":"1","us_privacy":"1-N-"}`,
+			accountGPC:      true,
+			expectDataScrub: true,
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+		},
+		{
+			description:         "No GPC",
+			regsExt:             `{}`,
+			accountGPC:          true,
+			expectDataScrub:     false,
+			expectPrivacyLabels: metrics.PrivacyLabels{},
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.Regs = &openrtb.Regs{Ext: json.RawMessage(test.regsExt)}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			Account:    config.Account{CCPA: config.AccountCCPA{GPC: test.accountGPC}},
+		}
+
+		privacyConfig := config.Privacy{
+			CCPA: config.CCPA{
+				Enforce: true,
+			},
+		}
+
+		bidderRequests, privacyLabels, errs := cleanOpenRTBRequests(context.Background(), auctionReq, nil, &permissionsMock{personalInfoAllowed: true}, true, privacyConfig)
+		result := bidderRequests[0]
+
+		assert.Empty(t, errs, test.description+":errors")
+		if test.expectDataScrub {
+			assert.Equal(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
+			assert.Equal(t, result.BidRequest.Device.DIDMD5, "", test.description+":Device.DIDMD5")
+		} else {
+			assert.NotEqual(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
+			assert.NotEqual(t, result.BidRequest.Device.DIDMD5, "", test.description+":Device.DIDMD5")
+		}
+		assert.Equal(t, test.expectPrivacyLabels, privacyLabels, test.description+":PrivacyLabels")
+	}
+}
+
+func TestCleanOpenRTBRequestsIPMasks(t *testing.T) {
+	testCases := []struct {
+		description string
+		ipMasks     config.AccountIPMasks
+		expectedIP  string
+	}{
+		{
+			description: "Default",
+			ipMasks:     config.AccountIPMasks{},
+			expectedIP:  "132.173.230.0",
+		},
+		{
+			description: "Account Mask",
+			ipMasks:     config.AccountIPMasks{CCPA: config.IPMask{IPv4PrefixLength: 16}},
+			expectedIP:  "132.173.0.0",
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.Regs = &openrtb.Regs{Ext: json.RawMessage(`{"us_privacy":"1-Y-"}`)}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			Account:    config.Account{IPMasks: test.ipMasks},
+		}
+
+		privacyConfig := config.Privacy{
+			CCPA: config.CCPA{
+				Enforce: true,
+			},
+		}
+
+		bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, nil, &permissionsMock{personalInfoAllowed: true}, true, privacyConfig)
+
+		assert.Empty(t, errs, test.description+":errors")
+		assert.Equal(t, test.expectedIP, bidderRequests[0].BidRequest.Device.IP, test.description+":Device.IP")
+	}
+}
+
 func TestCleanOpenRTBRequestsCOPPA(t *testing.T) {
 	testCases := []struct {
 		description         string
@@ -357,6 +568,78 @@ func TestCleanOpenRTBRequestsCOPPA(t *testing.T) {
 	}
 }
 
+func TestCleanOpenRTBRequestsActivities(t *testing.T) {
+	deny := false
+
+	testCases := []struct {
+		description     string
+		activities      config.AccountActivities
+		country         string
+		expectUFPDScrub bool
+		expectEIDsScrub bool
+		expectGeoScrub  bool
+	}{
+		{
+			description: "No Activity Controls",
+			activities:  config.AccountActivities{},
+			country:     "USA",
+		},
+		{
+			description: "UFPD And EIDs Denied For The Bidder",
+			activities: config.AccountActivities{
+				TransmitUFPD: config.Activity{Rules: []config.ActivityRule{
+					{Allow: false, Condition: config.ActivityCondition{Bidders: []string{"appnexus"}}},
+				}},
+				TransmitEIDs: config.Activity{Default: &deny},
+			},
+			country:         "USA",
+			expectUFPDScrub: true,
+			expectEIDsScrub: true,
+		},
+		{
+			description: "Precise Geo Denied In The Country",
+			activities: config.AccountActivities{
+				TransmitPreciseGeo: config.Activity{Rules: []config.ActivityRule{
+					{Allow: false, Condition: config.ActivityCondition{Countries: []string{"USA"}}},
+				}},
+			},
+			country:        "USA",
+			expectGeoScrub: true,
+		},
+		{
+			description: "Precise Geo Denied In Another Country",
+			activities: config.AccountActivities{
+				TransmitPreciseGeo: config.Activity{Rules: []config.ActivityRule{
+					{Allow: false, Condition: config.ActivityCondition{Countries: []string{"CAN"}}},
+				}},
+			},
+			country: "USA",
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.Device.Geo = &openrtb.Geo{Country: test.country, Lat: 123.456, Lon: 678.89}
+		req.User.Ext = json.RawMessage(`{"eids":[{"source":"anySource"}]}`)
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			Account:    config.Account{Activities: test.activities},
+		}
+
+		bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, nil, &permissionsMock{personalInfoAllowed: true}, true, config.Privacy{})
+		result := bidderRequests[0]
+
+		assert.Empty(t, errs, test.description+":errors")
+		assert.Equal(t, test.expectUFPDScrub, result.BidRequest.User.Yob == 0, test.description+":User.Yob")
+		assert.Equal(t, test.expectEIDsScrub, result.BidRequest.User.Ext == nil || string(result.BidRequest.User.Ext) == "{}", test.description+":User.Ext")
+		assert.Equal(t, test.expectGeoScrub, result.BidRequest.Device.Geo.Lat == 123.46, test.description+":Device.Geo.Lat")
+		assert.Equal(t, test.expectGeoScrub, result.BidRequest.Device.IP == "132.173.230.0", test.description+":Device.IP")
+		assert.NotEqual(t, "", result.BidRequest.User.BuyerUID, test.description+":User.BuyerUID")
+	}
+}
+
 func TestCleanOpenRTBRequestsSChain(t *testing.T) {
 	testCases := []struct {
 		description   string
@@ -940,7 +1223,7 @@ func TestGetExtBidAdjustmentFactors(t *testing.T) {
 	testCases := []struct {
 		desc                    string
 		inRequestExt            *openrtb_ext.ExtRequest
-		outBidAdjustmentFactors map[string]float64
+		outBidAdjustmentFactors *openrtb_ext.ExtBidAdjustmentFactors
 	}{
 		{
 			desc:                    "Nil request ext",
@@ -954,8 +1237,8 @@ func TestGetExtBidAdjustmentFactors(t *testing.T) {
 		},
 		{
 			desc:                    "Non-nil request ext, valid BidAdjustmentFactors field",
-			inRequestExt:            &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{BidAdjustmentFactors: map[string]float64{"bid-factor": 1.0}}},
-			outBidAdjustmentFactors: map[string]float64{"bid-factor": 1.0},
+			inRequestExt:            &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{BidAdjustmentFactors: &openrtb_ext.ExtBidAdjustmentFactors{Bidders: map[string]float64{"bid-factor": 1.0}}}},
+			outBidAdjustmentFactors: &openrtb_ext.ExtBidAdjustmentFactors{Bidders: map[string]float64{"bid-factor": 1.0}},
 		},
 	}
 	for _, test := range testCases {
@@ -1267,6 +1550,86 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 	}
 }
 
+func TestCleanOpenRTBRequestsGDPREnforcement(t *testing.T) {
+	// Appnexus only claims purpose 1, so it has no legal basis for the purposes which allow IDs
+	vendorList := `{"vendorListVersion":34,"vendors":{"2":{"id":2,"purposes":[1]}}}`
+	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consents to purposes and vendors 2, 6, 8
+	tcf2Consent := "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA"
+	fullPurpose := config.PurposeDetail{Enabled: true, EnforcementType: config.TCF2FullEnforcement}
+
+	testCases := []struct {
+		description string
+		purpose2    config.PurposeDetail
+		account     config.AccountGDPR
+		expectIDs   bool
+	}{
+		{
+			description: "Full enforcement",
+			purpose2:    fullPurpose,
+		},
+		{
+			description: "Host doesn't enforce purpose 2",
+			purpose2:    config.PurposeDetail{Enabled: true, EnforcementType: config.TCF2NoEnforcement},
+			expectIDs:   true,
+		},
+		{
+			description: "Account makes the bidder a purpose 2 vendor exception",
+			purpose2:    fullPurpose,
+			account: config.AccountGDPR{
+				Purpose2: config.AccountGDPRPurpose{VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}},
+			},
+			expectIDs: true,
+		},
+	}
+
+	for _, test := range testCases {
+		gdprConfig := config.GDPR{
+			Enabled:      true,
+			HostVendorID: 2,
+			TCF2: config.TCF2{
+				Enabled:  true,
+				Purpose1: fullPurpose,
+				Purpose2: test.purpose2,
+				Purpose7: fullPurpose,
+			},
+		}
+		client := &http.Client{Transport: vendorListTransport(vendorList)}
+		perms := gdpr.NewPermissions(context.Background(), gdprConfig, map[openrtb_ext.BidderName]uint16{openrtb_ext.BidderAppnexus: 2}, client, &metricsConfig.DummyMetricsEngine{})
+
+		req := newBidRequest(t)
+		req.User.Ext = json.RawMessage(`{"consent":"` + tcf2Consent + `"}`)
+		req.Regs = &openrtb.Regs{Ext: json.RawMessage(`{"gdpr":1}`)}
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			Account:    config.Account{GDPR: test.account},
+		}
+
+		results, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, nil, perms, false, config.Privacy{GDPR: gdprConfig})
+		assert.Empty(t, errs, test.description)
+
+		result := results[0]
+		if test.expectIDs {
+			assert.Equal(t, "their-id", result.BidRequest.User.BuyerUID, test.description+":User.BuyerUID")
+			assert.Equal(t, "some device ID hash", result.BidRequest.Device.DIDMD5, test.description+":Device.DIDMD5")
+		} else {
+			assert.Empty(t, result.BidRequest.User.BuyerUID, test.description+":User.BuyerUID")
+			assert.Empty(t, result.BidRequest.Device.DIDMD5, test.description+":Device.DIDMD5")
+		}
+	}
+}
+
+// vendorListTransport serves the same vendor list for every request.
+type vendorListTransport string
+
+func (v vendorListTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(string(v))),
+		Request:    req,
+	}, nil
+}
+
 // newAdapterAliasBidRequest builds a BidRequest with aliases
 func newAdapterAliasBidRequest(t *testing.T) *openrtb.BidRequest {
 	dnt := int8(1)
@@ -1720,3 +2083,97 @@ func TestRemoveUnpermissionedEidsEmptyValidations(t *testing.T) {
 		assert.Equal(t, &requestExpected, test.request, test.description+":request")
 	}
 }
+
+func TestBidderToMultiBid(t *testing.T) {
+	maxBids0 := 0
+	maxBids2 := 2
+	maxBids10 := 10
+
+	testCases := []struct {
+		description    string
+		multiBid       []*openrtb_ext.ExtMultiBid
+		expectedOutput map[string]openrtb_ext.ExtMultiBid
+		expectedError  string
+	}{
+		{
+			description:    "None",
+			multiBid:       nil,
+			expectedOutput: map[string]openrtb_ext.ExtMultiBid{},
+		},
+		{
+			description: "Single Bidder And Bidder List",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "bidder1", MaxBids: &maxBids2, TargetBidderCodePrefix: "b1"},
+				{Bidders: []string{"bidder2", "bidder3"}, MaxBids: &maxBids2},
+			},
+			expectedOutput: map[string]openrtb_ext.ExtMultiBid{
+				"bidder1": {Bidder: "bidder1", MaxBids: &maxBids2, TargetBidderCodePrefix: "b1"},
+				"bidder2": {Bidders: []string{"bidder2", "bidder3"}, MaxBids: &maxBids2},
+				"bidder3": {Bidders: []string{"bidder2", "bidder3"}, MaxBids: &maxBids2},
+			},
+		},
+		{
+			description: "Missing MaxBids",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "bidder1"},
+			},
+			expectedError: `request.ext.prebid.multibid[0] missing required field: "maxbids"`,
+		},
+		{
+			description: "MaxBids Too Low",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "bidder1", MaxBids: &maxBids0},
+			},
+			expectedError: "request.ext.prebid.multibid[0].maxbids must be between 1 and 9. Got 0",
+		},
+		{
+			description: "MaxBids Too High",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "bidder1", MaxBids: &maxBids10},
+			},
+			expectedError: "request.ext.prebid.multibid[0].maxbids must be between 1 and 9. Got 10",
+		},
+		{
+			description: "Bidder And Bidders",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "bidder1", Bidders: []string{"bidder2"}, MaxBids: &maxBids2},
+			},
+			expectedError: `request.ext.prebid.multibid[0] must contain only one of "bidder" or "bidders"`,
+		},
+		{
+			description: "No Bidders",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{MaxBids: &maxBids2},
+			},
+			expectedError: `request.ext.prebid.multibid[0] missing required field: "bidder" or "bidders"`,
+		},
+		{
+			description: "Prefix With Bidder List",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidders: []string{"bidder1", "bidder2"}, MaxBids: &maxBids2, TargetBidderCodePrefix: "b"},
+			},
+			expectedError: `request.ext.prebid.multibid[0].targetbiddercodeprefix can only be used with "bidder"`,
+		},
+		{
+			description: "Duplicate Bidder",
+			multiBid: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "bidder1", MaxBids: &maxBids2},
+				{Bidders: []string{"bidder2", "bidder1"}, MaxBids: &maxBids2},
+			},
+			expectedError: "request.ext.prebid.multibid contains multiple entries for bidder bidder1; it must contain no more than one per bidder.",
+		},
+	}
+
+	for _, test := range testCases {
+		input := openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{MultiBid: test.multiBid}}
+
+		output, err := BidderToMultiBid(&input)
+
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+		assert.Equal(t, test.expectedOutput, output, test.description)
+	}
+}
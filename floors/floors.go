@@ -0,0 +1,351 @@
+package floors
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	goCurrency "golang.org/x/text/currency"
+)
+
+const (
+	defaultCurrency  = "USD"
+	defaultDelimiter = "|"
+)
+
+// Floor is the price floor resolved for a single impression or bid.
+type Floor struct {
+	Value    float64
+	Currency string
+	// Rule is the key of the rule in ext.prebid.floors.data.values which produced the floor.
+	// It is empty if the floor came from the data default or from floormin.
+	Rule string
+}
+
+// Rules resolves the price floors which apply to a single auction.
+//
+// A nil *Rules is valid and means that floors don't apply; all of its methods behave accordingly.
+type Rules struct {
+	data        *openrtb_ext.PriceFloorData
+	currency    string
+	delimiter   string
+	floorMin    float64
+	floorMinCur string
+	enforcePBS  bool
+	floorDeals  bool
+}
+
+// NewRules builds the floor rules for an auction from the account config and the request's ext.prebid.floors.
+// It returns nil if floors are turned off for the account, or if the request didn't ask for them.
+func NewRules(account config.AccountPriceFloors, requestFloors *openrtb_ext.PriceFloorRules) *Rules {
+	if !account.Enabled || requestFloors == nil || !requestFloors.GetEnabled() {
+		return nil
+	}
+
+	rules := &Rules{
+		data:        requestFloors.Data,
+		currency:    defaultCurrency,
+		delimiter:   defaultDelimiter,
+		floorMin:    requestFloors.FloorMin,
+		floorMinCur: requestFloors.FloorMinCur,
+		enforcePBS:  requestFloors.GetEnforcePBS(),
+		floorDeals:  requestFloors.GetFloorDeals() || account.EnforceDealFloors,
+	}
+	if rules.data != nil {
+		if rules.data.Currency != "" {
+			rules.currency = strings.ToUpper(rules.data.Currency)
+		}
+		if rules.data.Schema.Delimiter != "" {
+			rules.delimiter = rules.data.Schema.Delimiter
+		}
+	}
+	if rules.floorMinCur == "" {
+		rules.floorMinCur = rules.currency
+	}
+	return rules
+}
+
+// Enforced returns true if bids below the floor should be removed from the auction.
+func (r *Rules) Enforced() bool {
+	return r != nil && r.enforcePBS
+}
+
+// EnforceDeals returns true if deal bids are subject to floor enforcement.
+func (r *Rules) EnforceDeals() bool {
+	return r != nil && r.floorDeals
+}
+
+// ImpFloor resolves the floor for an imp before the auction, using the media types and sizes requested by the imp.
+func (r *Rules) ImpFloor(req *openrtb.BidRequest, imp *openrtb.Imp, conversions currency.Conversions) (Floor, bool) {
+	return r.resolve(req, imp, impMediaType(imp), impSize(imp), conversions)
+}
+
+// BidFloor resolves the floor for a bid made on an imp, using the bid's own media type and size.
+func (r *Rules) BidFloor(req *openrtb.BidRequest, imp *openrtb.Imp, bidType openrtb_ext.BidType, w uint64, h uint64, conversions currency.Conversions) (Floor, bool) {
+	size := formatSize(w, h)
+	if size == "" {
+		size = impSize(imp)
+	}
+	return r.resolve(req, imp, string(bidType), size, conversions)
+}
+
+// UpdateImps resolves the floor for every imp in the request and writes it to imp.bidfloor and imp.bidfloorcur,
+// so that bidders are told about the floor they need to meet. It returns the applied floors keyed by imp.id.
+func (r *Rules) UpdateImps(req *openrtb.BidRequest, conversions currency.Conversions) map[string]Floor {
+	if r == nil {
+		return nil
+	}
+
+	floors := make(map[string]Floor, len(req.Imp))
+	for i := range req.Imp {
+		imp := &req.Imp[i]
+		if floor, ok := r.ImpFloor(req, imp, conversions); ok {
+			imp.BidFloor = floor.Value
+			imp.BidFloorCur = floor.Currency
+			floors[imp.ID] = floor
+		}
+	}
+	return floors
+}
+
+func (r *Rules) resolve(req *openrtb.BidRequest, imp *openrtb.Imp, mediaType string, size string, conversions currency.Conversions) (Floor, bool) {
+	if r == nil {
+		return Floor{}, false
+	}
+
+	floor := Floor{Currency: r.currency}
+	found := false
+
+	if r.data != nil {
+		if value, rule, ok := r.lookup(req, imp, mediaType, size); ok {
+			floor.Value = value
+			floor.Rule = rule
+			found = true
+		} else if r.data.Default > 0 {
+			floor.Value = r.data.Default
+			found = true
+		}
+	}
+
+	if r.floorMin > 0 {
+		floorMin := r.floorMin
+		if rate, err := conversions.GetRate(r.floorMinCur, r.currency); err == nil {
+			floorMin = floorMin * rate
+			if floorMin > floor.Value {
+				floor.Value = floorMin
+				floor.Rule = ""
+				found = true
+			}
+		}
+	}
+
+	return floor, found
+}
+
+// lookup finds the most specific rule matching the imp. Rules with fewer wildcards win. Between rules with the
+// same number of wildcards, the one with its wildcards in the later schema fields wins.
+func (r *Rules) lookup(req *openrtb.BidRequest, imp *openrtb.Imp, mediaType string, size string) (float64, string, bool) {
+	fields := r.data.Schema.Fields
+	if len(fields) == 0 || len(r.data.Values) == 0 {
+		return 0, "", false
+	}
+
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = strings.ToLower(fieldValue(field, req, imp, mediaType, size))
+	}
+
+	// Sort the rule keys so that ties are broken the same way on every request.
+	keys := make([]string, 0, len(r.data.Values))
+	for key := range r.data.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bestScore := -1
+	bestKey := ""
+	for _, key := range keys {
+		parts := strings.Split(strings.ToLower(key), r.delimiter)
+		if len(parts) != len(values) {
+			continue
+		}
+		score, matched := matchScore(parts, values)
+		if matched && (bestScore < 0 || score < bestScore) {
+			bestScore = score
+			bestKey = key
+		}
+	}
+
+	if bestScore < 0 {
+		return 0, "", false
+	}
+	return r.data.Values[bestKey], bestKey, true
+}
+
+func matchScore(parts []string, values []string) (int, bool) {
+	numFields := uint(len(parts))
+	wildcards := 0
+	positions := 0
+	for i, part := range parts {
+		if part == openrtb_ext.FloorWildcard {
+			wildcards++
+			positions += 1 << (numFields - 1 - uint(i))
+		} else if part != values[i] {
+			return 0, false
+		}
+	}
+	return wildcards<<numFields + positions, true
+}
+
+func fieldValue(field string, req *openrtb.BidRequest, imp *openrtb.Imp, mediaType string, size string) string {
+	switch field {
+	case openrtb_ext.FloorFieldMediaType:
+		return mediaType
+	case openrtb_ext.FloorFieldSize:
+		return size
+	case openrtb_ext.FloorFieldDomain:
+		return requestDomain(req)
+	case openrtb_ext.FloorFieldBundle:
+		if req.App != nil {
+			return req.App.Bundle
+		}
+	case openrtb_ext.FloorFieldAdUnitCode:
+		return adUnitCode(imp)
+	}
+	return ""
+}
+
+func impMediaType(imp *openrtb.Imp) string {
+	var mediaTypes []openrtb_ext.BidType
+	if imp.Banner != nil {
+		mediaTypes = append(mediaTypes, openrtb_ext.BidTypeBanner)
+	}
+	if imp.Video != nil {
+		mediaTypes = append(mediaTypes, openrtb_ext.BidTypeVideo)
+	}
+	if imp.Audio != nil {
+		mediaTypes = append(mediaTypes, openrtb_ext.BidTypeAudio)
+	}
+	if imp.Native != nil {
+		mediaTypes = append(mediaTypes, openrtb_ext.BidTypeNative)
+	}
+	if len(mediaTypes) == 1 {
+		return string(mediaTypes[0])
+	}
+	return openrtb_ext.FloorWildcard
+}
+
+func impSize(imp *openrtb.Imp) string {
+	if imp.Banner != nil && imp.Video == nil {
+		if len(imp.Banner.Format) == 1 {
+			return formatSize(imp.Banner.Format[0].W, imp.Banner.Format[0].H)
+		}
+		if len(imp.Banner.Format) == 0 && imp.Banner.W != nil && imp.Banner.H != nil {
+			return formatSize(*imp.Banner.W, *imp.Banner.H)
+		}
+	}
+	if imp.Video != nil && imp.Banner == nil {
+		return formatSize(imp.Video.W, imp.Video.H)
+	}
+	return openrtb_ext.FloorWildcard
+}
+
+func formatSize(w uint64, h uint64) string {
+	if w == 0 || h == 0 {
+		return ""
+	}
+	return strconv.FormatUint(w, 10) + "x" + strconv.FormatUint(h, 10)
+}
+
+func requestDomain(req *openrtb.BidRequest) string {
+	if req.Site != nil {
+		if req.Site.Domain != "" {
+			return req.Site.Domain
+		}
+		if req.Site.Publisher != nil {
+			return req.Site.Publisher.Domain
+		}
+	}
+	if req.App != nil {
+		if req.App.Domain != "" {
+			return req.App.Domain
+		}
+		if req.App.Publisher != nil {
+			return req.App.Publisher.Domain
+		}
+	}
+	return ""
+}
+
+// adUnitCode identifies the ad unit of an imp by its tagid, falling back to the stored imp ID.
+func adUnitCode(imp *openrtb.Imp) string {
+	if imp.TagID != "" {
+		return imp.TagID
+	}
+	if storedID, err := jsonparser.GetString(imp.Ext, openrtb_ext.PrebidExtKey, "storedrequest", "id"); err == nil {
+		return storedID
+	}
+	return ""
+}
+
+// Validate checks bidrequest.ext.prebid.floors for values which can never produce a meaningful floor.
+func Validate(floors *openrtb_ext.PriceFloorRules) error {
+	if floors == nil {
+		return nil
+	}
+	if floors.FloorMin < 0 {
+		return fmt.Errorf("request.ext.prebid.floors.floormin must be a non-negative number. Got %f", floors.FloorMin)
+	}
+	if floors.FloorMinCur != "" {
+		if _, err := goCurrency.ParseISO(floors.FloorMinCur); err != nil {
+			return fmt.Errorf("request.ext.prebid.floors.floormincur must be a valid ISO-4217 currency code. Got %s", floors.FloorMinCur)
+		}
+	}
+	if floors.Data == nil {
+		return nil
+	}
+
+	data := floors.Data
+	if data.Currency != "" {
+		if _, err := goCurrency.ParseISO(data.Currency); err != nil {
+			return fmt.Errorf("request.ext.prebid.floors.data.currency must be a valid ISO-4217 currency code. Got %s", data.Currency)
+		}
+	}
+	if data.Default < 0 {
+		return fmt.Errorf("request.ext.prebid.floors.data.default must be a non-negative number. Got %f", data.Default)
+	}
+	if len(data.Values) > 0 && len(data.Schema.Fields) == 0 {
+		return errors.New("request.ext.prebid.floors.data.schema.fields must not be empty if values are defined")
+	}
+
+	knownFields := make(map[string]struct{}, len(openrtb_ext.FloorFields()))
+	for _, field := range openrtb_ext.FloorFields() {
+		knownFields[field] = struct{}{}
+	}
+	for i, field := range data.Schema.Fields {
+		if _, ok := knownFields[field]; !ok {
+			return fmt.Errorf("request.ext.prebid.floors.data.schema.fields[%d] contains unsupported field %s", i, field)
+		}
+	}
+
+	delimiter := data.Schema.Delimiter
+	if delimiter == "" {
+		delimiter = defaultDelimiter
+	}
+	for rule, value := range data.Values {
+		if value < 0 {
+			return fmt.Errorf("request.ext.prebid.floors.data.values.%s must be a non-negative number. Got %f", rule, value)
+		}
+		if len(strings.Split(rule, delimiter)) != len(data.Schema.Fields) {
+			return fmt.Errorf("request.ext.prebid.floors.data.values.%s must have one value for each of the %d schema fields", rule, len(data.Schema.Fields))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+package floors
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRules(t *testing.T) {
+	enabled := true
+	disabled := false
+
+	testCases := []struct {
+		description    string
+		account        config.AccountPriceFloors
+		requestFloors  *openrtb_ext.PriceFloorRules
+		expectNil      bool
+		expectEnforced bool
+		expectDeals    bool
+	}{
+		{
+			description:   "Account Disabled",
+			account:       config.AccountPriceFloors{Enabled: false},
+			requestFloors: &openrtb_ext.PriceFloorRules{},
+			expectNil:     true,
+		},
+		{
+			description:   "Request Floors Missing",
+			account:       config.AccountPriceFloors{Enabled: true},
+			requestFloors: nil,
+			expectNil:     true,
+		},
+		{
+			description:   "Request Floors Disabled",
+			account:       config.AccountPriceFloors{Enabled: true},
+			requestFloors: &openrtb_ext.PriceFloorRules{Enabled: &disabled},
+			expectNil:     true,
+		},
+		{
+			description:    "Enabled - Default Enforcement",
+			account:        config.AccountPriceFloors{Enabled: true},
+			requestFloors:  &openrtb_ext.PriceFloorRules{Enabled: &enabled},
+			expectEnforced: true,
+			expectDeals:    false,
+		},
+		{
+			description: "Enabled - Enforcement Off",
+			account:     config.AccountPriceFloors{Enabled: true},
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				Enforcement: &openrtb_ext.PriceFloorEnforcement{EnforcePBS: &disabled},
+			},
+			expectEnforced: false,
+		},
+		{
+			description:    "Enabled - Account Enforces Deals",
+			account:        config.AccountPriceFloors{Enabled: true, EnforceDealFloors: true},
+			requestFloors:  &openrtb_ext.PriceFloorRules{},
+			expectEnforced: true,
+			expectDeals:    true,
+		},
+		{
+			description: "Enabled - Request Enforces Deals",
+			account:     config.AccountPriceFloors{Enabled: true},
+			requestFloors: &openrtb_ext.PriceFloorRules{
+				Enforcement: &openrtb_ext.PriceFloorEnforcement{FloorDeals: true},
+			},
+			expectEnforced: true,
+			expectDeals:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		rules := NewRules(test.account, test.requestFloors)
+		if test.expectNil {
+			assert.Nil(t, rules, test.description)
+		} else {
+			assert.NotNil(t, rules, test.description)
+		}
+		assert.Equal(t, test.expectEnforced, rules.Enforced(), test.description+":enforced")
+		assert.Equal(t, test.expectDeals, rules.EnforceDeals(), test.description+":deals")
+	}
+}
+
+func TestImpFloor(t *testing.T) {
+	floorData := &openrtb_ext.PriceFloorData{
+		Currency: "EUR",
+		Schema: openrtb_ext.PriceFloorSchema{
+			Fields: []string{"mediaType", "size", "domain"},
+		},
+		Values: map[string]float64{
+			"banner|300x250|www.website.com": 1.5,
+			"banner|300x250|*":               1.2,
+			"banner|*|www.website.com":       1.1,
+			"*|300x250|*":                    0.9,
+			"video|*|*":                      3,
+			"*|*|*":                          0.5,
+		},
+		Default: 0.1,
+	}
+
+	testCases := []struct {
+		description string
+		request     *openrtb.BidRequest
+		imp         openrtb.Imp
+		data        *openrtb_ext.PriceFloorData
+		expectFound bool
+		expectFloor Floor
+	}{
+		{
+			description: "Exact Match",
+			request:     &openrtb.BidRequest{Site: &openrtb.Site{Domain: "www.website.com"}},
+			imp:         openrtb.Imp{ID: "imp1", Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}}}},
+			data:        floorData,
+			expectFound: true,
+			expectFloor: Floor{Value: 1.5, Currency: "EUR", Rule: "banner|300x250|www.website.com"},
+		},
+		{
+			description: "Wildcard In Last Field Preferred",
+			request:     &openrtb.BidRequest{Site: &openrtb.Site{Domain: "www.other.com"}},
+			imp:         openrtb.Imp{ID: "imp1", Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}}}},
+			data:        floorData,
+			expectFound: true,
+			expectFloor: Floor{Value: 1.2, Currency: "EUR", Rule: "banner|300x250|*"},
+		},
+		{
+			description: "Multiple Banner Sizes Match Size Wildcard",
+			request:     &openrtb.BidRequest{Site: &openrtb.Site{Domain: "www.website.com"}},
+			imp:         openrtb.Imp{ID: "imp1", Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}, {W: 728, H: 90}}}},
+			data:        floorData,
+			expectFound: true,
+			expectFloor: Floor{Value: 1.1, Currency: "EUR", Rule: "banner|*|www.website.com"},
+		},
+		{
+			description: "Video Imp",
+			request:     &openrtb.BidRequest{App: &openrtb.App{Bundle: "com.app"}},
+			imp:         openrtb.Imp{ID: "imp1", Video: &openrtb.Video{W: 640, H: 480}},
+			data:        floorData,
+			expectFound: true,
+			expectFloor: Floor{Value: 3, Currency: "EUR", Rule: "video|*|*"},
+		},
+		{
+			description: "Catch All Rule",
+			request:     &openrtb.BidRequest{Site: &openrtb.Site{Domain: "www.website.com"}},
+			imp:         openrtb.Imp{ID: "imp1", Native: &openrtb.Native{}},
+			data:        floorData,
+			expectFound: true,
+			expectFloor: Floor{Value: 0.5, Currency: "EUR", Rule: "*|*|*"},
+		},
+		{
+			description: "Default Used When No Rule Matches",
+			request:     &openrtb.BidRequest{Site: &openrtb.Site{Domain: "www.website.com"}},
+			imp:         openrtb.Imp{ID: "imp1", Native: &openrtb.Native{}},
+			data: &openrtb_ext.PriceFloorData{
+				Schema:  openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType"}},
+				Values:  map[string]float64{"banner": 1},
+				Default: 0.25,
+			},
+			expectFound: true,
+			expectFloor: Floor{Value: 0.25, Currency: "USD"},
+		},
+		{
+			description: "Bundle And Ad Unit Code - Custom Delimiter",
+			request:     &openrtb.BidRequest{App: &openrtb.App{Bundle: "com.app"}},
+			imp:         openrtb.Imp{ID: "imp1", TagID: "Slot-1", Banner: &openrtb.Banner{}},
+			data: &openrtb_ext.PriceFloorData{
+				Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"bundle", "adUnitCode"}, Delimiter: ":"},
+				Values: map[string]float64{"com.app:slot-1": 2},
+			},
+			expectFound: true,
+			expectFloor: Floor{Value: 2, Currency: "USD", Rule: "com.app:slot-1"},
+		},
+		{
+			description: "Ad Unit Code From Stored Request",
+			request:     &openrtb.BidRequest{Site: &openrtb.Site{}},
+			imp:         openrtb.Imp{ID: "imp1", Banner: &openrtb.Banner{}, Ext: json.RawMessage(`{"prebid":{"storedrequest":{"id":"stored-imp"}}}`)},
+			data: &openrtb_ext.PriceFloorData{
+				Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"adUnitCode"}},
+				Values: map[string]float64{"stored-imp": 4},
+			},
+			expectFound: true,
+			expectFloor: Floor{Value: 4, Currency: "USD", Rule: "stored-imp"},
+		},
+		{
+			description: "No Match And No Default",
+			request:     &openrtb.BidRequest{Site: &openrtb.Site{}},
+			imp:         openrtb.Imp{ID: "imp1", Banner: &openrtb.Banner{}},
+			data: &openrtb_ext.PriceFloorData{
+				Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType"}},
+				Values: map[string]float64{"video": 1},
+			},
+			expectFound: false,
+			expectFloor: Floor{Currency: "USD"},
+		},
+	}
+
+	for _, test := range testCases {
+		rules := NewRules(config.AccountPriceFloors{Enabled: true}, &openrtb_ext.PriceFloorRules{Data: test.data})
+		floor, found := rules.ImpFloor(test.request, &test.imp, currency.NewConstantRates())
+		assert.Equal(t, test.expectFound, found, test.description)
+		assert.Equal(t, test.expectFloor, floor, test.description)
+	}
+}
+
+func TestBidFloorUsesBidTypeAndSize(t *testing.T) {
+	rules := NewRules(config.AccountPriceFloors{Enabled: true}, &openrtb_ext.PriceFloorRules{
+		Data: &openrtb_ext.PriceFloorData{
+			Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size"}},
+			Values: map[string]float64{
+				"banner|728x90": 2,
+				"video|*":       5,
+				"*|*":           1,
+			},
+		},
+	})
+	request := &openrtb.BidRequest{Site: &openrtb.Site{}}
+	imp := &openrtb.Imp{
+		ID:     "imp1",
+		Banner: &openrtb.Banner{Format: []openrtb.Format{{W: 300, H: 250}, {W: 728, H: 90}}},
+		Video:  &openrtb.Video{},
+	}
+
+	floor, found := rules.ImpFloor(request, imp, currency.NewConstantRates())
+	assert.True(t, found)
+	assert.Equal(t, 1.0, floor.Value, "imp floor")
+
+	floor, found = rules.BidFloor(request, imp, openrtb_ext.BidTypeBanner, 728, 90, currency.NewConstantRates())
+	assert.True(t, found)
+	assert.Equal(t, 2.0, floor.Value, "banner bid floor")
+
+	floor, found = rules.BidFloor(request, imp, openrtb_ext.BidTypeVideo, 0, 0, currency.NewConstantRates())
+	assert.True(t, found)
+	assert.Equal(t, 5.0, floor.Value, "video bid floor")
+}
+
+func TestFloorMin(t *testing.T) {
+	conversions := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"USD": {"EUR": 0.5},
+	})
+
+	testCases := []struct {
+		description string
+		floors      *openrtb_ext.PriceFloorRules
+		expectFloor Floor
+	}{
+		{
+			description: "Floor Min Above Rule",
+			floors: &openrtb_ext.PriceFloorRules{
+				FloorMin: 3,
+				Data: &openrtb_ext.PriceFloorData{
+					Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType"}},
+					Values: map[string]float64{"banner": 2},
+				},
+			},
+			expectFloor: Floor{Value: 3, Currency: "USD"},
+		},
+		{
+			description: "Floor Min Below Rule",
+			floors: &openrtb_ext.PriceFloorRules{
+				FloorMin: 1,
+				Data: &openrtb_ext.PriceFloorData{
+					Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType"}},
+					Values: map[string]float64{"banner": 2},
+				},
+			},
+			expectFloor: Floor{Value: 2, Currency: "USD", Rule: "banner"},
+		},
+		{
+			description: "Floor Min Converted To Data Currency",
+			floors: &openrtb_ext.PriceFloorRules{
+				FloorMin:    8,
+				FloorMinCur: "USD",
+				Data: &openrtb_ext.PriceFloorData{
+					Currency: "EUR",
+					Schema:   openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType"}},
+					Values:   map[string]float64{"banner": 2},
+				},
+			},
+			expectFloor: Floor{Value: 4, Currency: "EUR"},
+		},
+		{
+			description: "Floor Min Without Data",
+			floors: &openrtb_ext.PriceFloorRules{
+				FloorMin: 0.75,
+			},
+			expectFloor: Floor{Value: 0.75, Currency: "USD"},
+		},
+	}
+
+	for _, test := range testCases {
+		rules := NewRules(config.AccountPriceFloors{Enabled: true}, test.floors)
+		imp := &openrtb.Imp{ID: "imp1", Banner: &openrtb.Banner{}}
+		floor, found := rules.ImpFloor(&openrtb.BidRequest{}, imp, conversions)
+		assert.True(t, found, test.description)
+		assert.Equal(t, test.expectFloor, floor, test.description)
+	}
+}
+
+func TestUpdateImps(t *testing.T) {
+	rules := NewRules(config.AccountPriceFloors{Enabled: true}, &openrtb_ext.PriceFloorRules{
+		Data: &openrtb_ext.PriceFloorData{
+			Currency: "EUR",
+			Schema:   openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType"}},
+			Values:   map[string]float64{"banner": 1.25},
+		},
+	})
+	request := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "banner-imp", Banner: &openrtb.Banner{}},
+			{ID: "video-imp", Video: &openrtb.Video{}, BidFloor: 0.5, BidFloorCur: "USD"},
+		},
+	}
+
+	impFloors := rules.UpdateImps(request, currency.NewConstantRates())
+
+	assert.Equal(t, map[string]Floor{"banner-imp": {Value: 1.25, Currency: "EUR", Rule: "banner"}}, impFloors)
+	assert.Equal(t, 1.25, request.Imp[0].BidFloor)
+	assert.Equal(t, "EUR", request.Imp[0].BidFloorCur)
+	assert.Equal(t, 0.5, request.Imp[1].BidFloor, "imps without a floor keep their bidfloor")
+	assert.Equal(t, "USD", request.Imp[1].BidFloorCur)
+
+	var nilRules *Rules
+	assert.Nil(t, nilRules.UpdateImps(request, currency.NewConstantRates()))
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		description string
+		floors      *openrtb_ext.PriceFloorRules
+		expectedErr string
+	}{
+		{
+			description: "Nil",
+			floors:      nil,
+		},
+		{
+			description: "Valid",
+			floors: &openrtb_ext.PriceFloorRules{
+				FloorMin:    1,
+				FloorMinCur: "EUR",
+				Data: &openrtb_ext.PriceFloorData{
+					Currency: "USD",
+					Schema:   openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size"}},
+					Values:   map[string]float64{"banner|300x250": 1, "*|*": 0.5},
+					Default:  0.1,
+				},
+			},
+		},
+		{
+			description: "Negative Floor Min",
+			floors:      &openrtb_ext.PriceFloorRules{FloorMin: -1},
+			expectedErr: "request.ext.prebid.floors.floormin must be a non-negative number. Got -1.000000",
+		},
+		{
+			description: "Invalid Floor Min Currency",
+			floors:      &openrtb_ext.PriceFloorRules{FloorMinCur: "FOO"},
+			expectedErr: "request.ext.prebid.floors.floormincur must be a valid ISO-4217 currency code. Got FOO",
+		},
+		{
+			description: "Invalid Data Currency",
+			floors:      &openrtb_ext.PriceFloorRules{Data: &openrtb_ext.PriceFloorData{Currency: "FOO"}},
+			expectedErr: "request.ext.prebid.floors.data.currency must be a valid ISO-4217 currency code. Got FOO",
+		},
+		{
+			description: "Negative Default",
+			floors:      &openrtb_ext.PriceFloorRules{Data: &openrtb_ext.PriceFloorData{Default: -2}},
+			expectedErr: "request.ext.prebid.floors.data.default must be a non-negative number. Got -2.000000",
+		},
+		{
+			description: "Values Without Fields",
+			floors: &openrtb_ext.PriceFloorRules{Data: &openrtb_ext.PriceFloorData{
+				Values: map[string]float64{"banner": 1},
+			}},
+			expectedErr: "request.ext.prebid.floors.data.schema.fields must not be empty if values are defined",
+		},
+		{
+			description: "Unsupported Field",
+			floors: &openrtb_ext.PriceFloorRules{Data: &openrtb_ext.PriceFloorData{
+				Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"gptSlot"}},
+			}},
+			expectedErr: "request.ext.prebid.floors.data.schema.fields[0] contains unsupported field gptSlot",
+		},
+		{
+			description: "Rule With Wrong Number Of Values",
+			floors: &openrtb_ext.PriceFloorRules{Data: &openrtb_ext.PriceFloorData{
+				Schema: openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size"}},
+				Values: map[string]float64{"banner": 1},
+			}},
+			expectedErr: "request.ext.prebid.floors.data.values.banner must have one value for each of the 2 schema fields",
+		},
+	}
+
+	for _, test := range testCases {
+		err := Validate(test.floors)
+		if test.expectedErr == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+		}
+	}
+}
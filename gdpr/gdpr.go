@@ -8,24 +8,28 @@ import (
 	"github.com/prebid/go-gdpr/vendorlist"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
 type Permissions interface {
-	// Determines whether or not the host company is allowed to read/write cookies.
+	// Determines whether or not the host company is allowed to read/write cookies. The account may override
+	// how the host enforces the TCF2 purposes.
 	//
 	// If the consent string was nonsensical, the returned error will be an ErrorMalformedConsent.
-	HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string) (bool, error)
+	HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, error)
 
-	// Determines whether or not the given bidder is allowed to user personal info for ad targeting.
+	// Determines whether or not the given bidder is allowed to user personal info for ad targeting. The account
+	// may override how the host enforces the TCF2 purposes.
 	//
 	// If the consent string was nonsensical, the returned error will be an ErrorMalformedConsent.
-	BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal Signal, consent string) (bool, error)
+	BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, error)
 
-	// Determines whether or not to send PI information to a bidder, or mask it out.
+	// Determines whether or not to send PI information to a bidder, or mask it out. The account may override
+	// how the host enforces the TCF2 purposes.
 	//
 	// If the consent string was nonsensical, the returned error will be an ErrorMalformedConsent.
-	PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal Signal, consent string) (bool, bool, bool, error)
+	PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, bool, bool, error)
 }
 
 // Versions of the GDPR TCF technical specification.
@@ -35,7 +39,7 @@ const (
 )
 
 // NewPermissions gets an instance of the Permissions for use elsewhere in the project.
-func NewPermissions(ctx context.Context, cfg config.GDPR, vendorIDs map[openrtb_ext.BidderName]uint16, client *http.Client) Permissions {
+func NewPermissions(ctx context.Context, cfg config.GDPR, vendorIDs map[openrtb_ext.BidderName]uint16, client *http.Client, metricsEngine metrics.MetricsEngine) Permissions {
 	if !cfg.Enabled {
 		return &AlwaysAllow{}
 	}
@@ -45,7 +49,7 @@ func NewPermissions(ctx context.Context, cfg config.GDPR, vendorIDs map[openrtb_
 		vendorIDs: vendorIDs,
 		fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
 			tcf1SpecVersion: newVendorListFetcherTCF1(cfg),
-			tcf2SpecVersion: newVendorListFetcherTCF2(ctx, cfg, client, vendorListURLMaker)},
+			tcf2SpecVersion: newVendorListFetcherTCF2(ctx, cfg, client, vendorListURLMaker, metricsEngine)},
 	}
 
 	if cfg.HostVendorID == 0 {
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/prebid/prebid-server/config"
+	metricsConf "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 
 	"github.com/stretchr/testify/assert"
@@ -43,7 +44,7 @@ func TestNewPermissions(t *testing.T) {
 		}
 		vendorIDs := map[openrtb_ext.BidderName]uint16{}
 
-		perms := NewPermissions(context.Background(), config, vendorIDs, &http.Client{})
+		perms := NewPermissions(context.Background(), config, vendorIDs, &http.Client{}, &metricsConf.DummyMetricsEngine{})
 
 		assert.IsType(t, tt.wantType, perms, tt.description)
 	}
@@ -33,17 +33,17 @@ type permissionsImpl struct {
 	fetchVendorList map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error)
 }
 
-func (p *permissionsImpl) HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string) (bool, error) {
+func (p *permissionsImpl) HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, error) {
 	gdprSignal = p.normalizeGDPR(gdprSignal)
 
 	if gdprSignal == SignalNo {
 		return true, nil
 	}
 
-	return p.allowSync(ctx, uint16(p.cfg.HostVendorID), consent)
+	return p.allowSync(ctx, "", uint16(p.cfg.HostVendorID), consent, p.cfg.TCF2.WithAccountOverrides(account))
 }
 
-func (p *permissionsImpl) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal Signal, consent string) (bool, error) {
+func (p *permissionsImpl) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, error) {
 	gdprSignal = p.normalizeGDPR(gdprSignal)
 
 	if gdprSignal == SignalNo {
@@ -52,13 +52,13 @@ func (p *permissionsImpl) BidderSyncAllowed(ctx context.Context, bidder openrtb_
 
 	id, ok := p.vendorIDs[bidder]
 	if ok {
-		return p.allowSync(ctx, id, consent)
+		return p.allowSync(ctx, bidder, id, consent, p.cfg.TCF2.WithAccountOverrides(account))
 	}
 
 	return false, nil
 }
 
-func (p *permissionsImpl) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal Signal, consent string) (allowPI bool, allowGeo bool, allowID bool, err error) {
+func (p *permissionsImpl) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal Signal, consent string, account config.AccountGDPR) (allowPI bool, allowGeo bool, allowID bool, err error) {
 	if _, ok := p.cfg.NonStandardPublisherMap[PublisherID]; ok {
 		return true, true, true, nil
 	}
@@ -74,7 +74,7 @@ func (p *permissionsImpl) PersonalInfoAllowed(ctx context.Context, bidder openrt
 	}
 
 	if id, ok := p.vendorIDs[bidder]; ok {
-		return p.allowPI(ctx, id, consent)
+		return p.allowPI(ctx, bidder, id, consent, p.cfg.TCF2.WithAccountOverrides(account))
 	}
 
 	return p.defaultVendorPermissions()
@@ -96,7 +96,7 @@ func (p *permissionsImpl) normalizeGDPR(gdprSignal Signal) Signal {
 	return SignalYes
 }
 
-func (p *permissionsImpl) allowSync(ctx context.Context, vendorID uint16, consent string) (bool, error) {
+func (p *permissionsImpl) allowSync(ctx context.Context, bidder openrtb_ext.BidderName, vendorID uint16, consent string, tcf2Config config.TCF2) (bool, error) {
 
 	if consent == "" {
 		return false, nil
@@ -107,22 +107,17 @@ func (p *permissionsImpl) allowSync(ctx context.Context, vendorID uint16, consen
 		return false, err
 	}
 
-	if vendor == nil {
-		return false, nil
-	}
-
 	// InfoStorageAccess is the same across TCF 1 and TCF 2
 	if parsedConsent.Version() == 2 {
-		if !p.cfg.TCF2.Purpose1.Enabled {
-			// We are not enforcing purpose 1
-			return true, nil
-		}
 		consent, ok := parsedConsent.(tcf2.ConsentMetadata)
 		if !ok {
 			err := fmt.Errorf("Unable to access TCF2 parsed consent")
 			return false, err
 		}
-		return p.checkPurpose(consent, vendor, vendorID, consentconstants.InfoStorageAccess), nil
+		return p.purposeAllowed(consent, vendor, bidder, vendorID, consentconstants.InfoStorageAccess, tcf2Config), nil
+	}
+	if vendor == nil {
+		return false, nil
 	}
 	if vendor.Purpose(consentconstants.InfoStorageAccess) && parsedConsent.PurposeAllowed(consentconstants.InfoStorageAccess) && parsedConsent.VendorConsent(vendorID) {
 		return true, nil
@@ -130,20 +125,21 @@ func (p *permissionsImpl) allowSync(ctx context.Context, vendorID uint16, consen
 	return false, nil
 }
 
-func (p *permissionsImpl) allowPI(ctx context.Context, vendorID uint16, consent string) (bool, bool, bool, error) {
+func (p *permissionsImpl) allowPI(ctx context.Context, bidder openrtb_ext.BidderName, vendorID uint16, consent string, tcf2Config config.TCF2) (bool, bool, bool, error) {
 	parsedConsent, vendor, err := p.parseVendor(ctx, vendorID, consent)
 	if err != nil {
 		return false, false, false, err
 	}
 
+	if parsedConsent.Version() == 2 && tcf2Config.Enabled {
+		return p.allowPITCF2(parsedConsent, vendor, bidder, vendorID, tcf2Config)
+	}
+
 	if vendor == nil {
 		return false, false, false, nil
 	}
 
 	if parsedConsent.Version() == 2 {
-		if p.cfg.TCF2.Enabled {
-			return p.allowPITCF2(parsedConsent, vendor, vendorID)
-		}
 		if (vendor.Purpose(consentconstants.InfoStorageAccess) || vendor.LegitimateInterest(consentconstants.InfoStorageAccess)) && parsedConsent.PurposeAllowed(consentconstants.InfoStorageAccess) && (vendor.Purpose(consentconstants.PersonalizationProfile) || vendor.LegitimateInterest(consentconstants.PersonalizationProfile)) && parsedConsent.PurposeAllowed(consentconstants.PersonalizationProfile) && parsedConsent.VendorConsent(vendorID) {
 			return true, true, true, nil
 		}
@@ -155,7 +151,13 @@ func (p *permissionsImpl) allowPI(ctx context.Context, vendorID uint16, consent
 	return false, false, false, nil
 }
 
-func (p *permissionsImpl) allowPITCF2(parsedConsent api.VendorConsents, vendor api.Vendor, vendorID uint16) (allowPI bool, allowGeo bool, allowID bool, err error) {
+// allowPITCF2 works out the permissions of a vendor from a TCF2 consent string. The vendor may be nil if it
+// isn't in the GVL, in which case only the purposes which aren't fully enforced can be allowed.
+//
+// PI is allowed if the vendor passes the enforcement of each of the ten purposes, geo if it passes the one
+// of special feature 1, and IDs if it passes the enforcement of any of purposes 2 to 10. The purposes which
+// aren't enabled still need a legal basis to allow IDs.
+func (p *permissionsImpl) allowPITCF2(parsedConsent api.VendorConsents, vendor api.Vendor, bidder openrtb_ext.BidderName, vendorID uint16, tcf2Config config.TCF2) (allowPI bool, allowGeo bool, allowID bool, err error) {
 	consent, ok := parsedConsent.(tcf2.ConsentMetadata)
 	err = nil
 	allowPI = false
@@ -165,53 +167,74 @@ func (p *permissionsImpl) allowPITCF2(parsedConsent api.VendorConsents, vendor a
 		err = fmt.Errorf("Unable to access TCF2 parsed consent")
 		return
 	}
-	if p.cfg.TCF2.SpecialPurpose1.Enabled {
-		allowGeo = consent.SpecialFeatureOptIn(1) && vendor.SpecialPurpose(1)
-	} else {
+	switch tcf2Config.SpecialPurpose1.Enforcement(bidder) {
+	case config.TCF2NoEnforcement:
 		allowGeo = true
+	case config.TCF2BasicEnforcement:
+		allowGeo = consent.SpecialFeatureOptIn(1)
+	default:
+		allowGeo = consent.SpecialFeatureOptIn(1) && vendor != nil && vendor.SpecialPurpose(1)
 	}
-	for i := 2; i <= 10; i++ {
-		if p.checkPurpose(consent, vendor, vendorID, tcf1constants.Purpose(i)) {
-			allowID = true
-			break
+	for i := 2; i <= 10 && !allowID; i++ {
+		if tcf2Config.Purpose(i).Enabled {
+			allowID = p.purposeAllowed(consent, vendor, bidder, vendorID, tcf1constants.Purpose(i), tcf2Config)
+		} else {
+			allowID = p.checkPurpose(consent, vendor, vendorID, tcf1constants.Purpose(i))
 		}
 	}
 	// Set to true so any purpose check can flip it to false
 	allowPI = true
-	if p.cfg.TCF2.Purpose1.Enabled {
-		allowPI = allowPI && p.checkPurpose(consent, vendor, vendorID, consentconstants.InfoStorageAccess)
+	for i := 1; i <= 10 && allowPI; i++ {
+		allowPI = p.purposeAllowed(consent, vendor, bidder, vendorID, tcf1constants.Purpose(i), tcf2Config)
+	}
+	return
+}
+
+// purposeAllowed tells whether the vendor may process data for the purpose, as strictly as the config enforces it.
+func (p *permissionsImpl) purposeAllowed(consent tcf2.ConsentMetadata, vendor api.Vendor, bidder openrtb_ext.BidderName, vendorID uint16, purpose tcf1constants.Purpose, tcf2Config config.TCF2) bool {
+	enforcement := tcf2Config.Purpose(int(purpose)).Enforcement(bidder)
+	if enforcement == config.TCF2NoEnforcement {
+		return true
 	}
-	if p.cfg.TCF2.Purpose2.Enabled {
-		allowPI = allowPI && p.checkPurpose(consent, vendor, vendorID, consentconstants.BasicAdserving)
+	if purpose == consentconstants.InfoStorageAccess && tcf2Config.PurposeOneTreatment.Enabled && consent.PurposeOneTreatment() {
+		return tcf2Config.PurposeOneTreatment.AccessAllowed
 	}
-	if p.cfg.TCF2.Purpose7.Enabled {
-		allowPI = allowPI && p.checkPurpose(consent, vendor, vendorID, consentconstants.AdPerformance)
+	if enforcement == config.TCF2BasicEnforcement {
+		return consent.PurposeAllowed(purpose) || (purpose != consentconstants.InfoStorageAccess && consent.PurposeLITransparency(purpose))
 	}
-	return
+	return p.checkPurpose(consent, vendor, vendorID, purpose)
 }
 
 const pubRestrictNotAllowed = 0
 const pubRestrictRequireConsent = 1
 const pubRestrictRequireLegitInterest = 2
 
+// checkPurpose tells whether the vendor has a legal basis for the purpose, which it declares in the GVL and the
+// consent string grants it. Publisher restrictions may require one legal basis or the other, which only vendors
+// that declare the purpose as flexible, or already use that legal basis for it, can comply with. Purpose 1 can't
+// be based on legitimate interest.
 func (p *permissionsImpl) checkPurpose(consent tcf2.ConsentMetadata, vendor api.Vendor, vendorID uint16, purpose tcf1constants.Purpose) bool {
-	if purpose == consentconstants.InfoStorageAccess && p.cfg.TCF2.PurposeOneTreatment.Enabled && consent.PurposeOneTreatment() {
-		return p.cfg.TCF2.PurposeOneTreatment.AccessAllowed
+	if vendor == nil {
+		return false
 	}
 	if consent.CheckPubRestriction(uint8(purpose), pubRestrictNotAllowed, vendorID) {
 		return false
 	}
+
+	// Purpose and LegitimateInterest include the flexible purposes, while the strict versions don't.
+	hasConsent := func(declared bool) bool {
+		return declared && consent.PurposeAllowed(purpose) && consent.VendorConsent(vendorID)
+	}
+	hasLegitInterest := func(declared bool) bool {
+		return declared && purpose != consentconstants.InfoStorageAccess && consent.PurposeLITransparency(purpose) && consent.VendorLegitInterest(vendorID)
+	}
 	if consent.CheckPubRestriction(uint8(purpose), pubRestrictRequireConsent, vendorID) {
-		return vendor.PurposeStrict(purpose) && consent.PurposeAllowed(purpose) && consent.VendorConsent(vendorID)
+		return hasConsent(vendor.Purpose(purpose))
 	}
 	if consent.CheckPubRestriction(uint8(purpose), pubRestrictRequireLegitInterest, vendorID) {
-		// Need LITransparency here
-		return vendor.LegitimateInterestStrict(purpose) && consent.PurposeLITransparency(purpose) && consent.VendorLegitInterest(vendorID)
+		return hasLegitInterest(vendor.LegitimateInterest(purpose))
 	}
-	purposeAllowed := vendor.Purpose(purpose) && consent.PurposeAllowed(purpose) && consent.VendorConsent(vendorID)
-	legitInterest := vendor.LegitimateInterest(purpose) && consent.PurposeLITransparency(purpose) && consent.VendorLegitInterest(vendorID)
-
-	return purposeAllowed || legitInterest
+	return hasConsent(vendor.Purpose(purpose)) || hasLegitInterest(vendor.LegitimateInterest(purpose))
 }
 
 func (p *permissionsImpl) parseVendor(ctx context.Context, vendorID uint16, consent string) (parsedConsent api.VendorConsents, vendor api.Vendor, err error) {
@@ -243,36 +266,36 @@ type AllowHostCookies struct {
 }
 
 // HostCookiesAllowed always returns true
-func (p *AllowHostCookies) HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string) (bool, error) {
+func (p *AllowHostCookies) HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, error) {
 	return true, nil
 }
 
 // Exporting to allow for easy test setups
 type AlwaysAllow struct{}
 
-func (a AlwaysAllow) HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string) (bool, error) {
+func (a AlwaysAllow) HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, error) {
 	return true, nil
 }
 
-func (a AlwaysAllow) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal Signal, consent string) (bool, error) {
+func (a AlwaysAllow) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, error) {
 	return true, nil
 }
 
-func (a AlwaysAllow) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal Signal, consent string) (bool, bool, bool, error) {
+func (a AlwaysAllow) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, bool, bool, error) {
 	return true, true, true, nil
 }
 
 // Exporting to allow for easy test setups
 type AlwaysFail struct{}
 
-func (a AlwaysFail) HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string) (bool, error) {
+func (a AlwaysFail) HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, error) {
 	return false, nil
 }
 
-func (a AlwaysFail) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal Signal, consent string) (bool, error) {
+func (a AlwaysFail) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, error) {
 	return false, nil
 }
 
-func (a AlwaysFail) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal Signal, consent string) (bool, bool, bool, error) {
+func (a AlwaysFail) PersonalInfoAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal Signal, consent string, account config.AccountGDPR) (bool, bool, bool, error) {
 	return false, false, false, nil
 }
@@ -27,10 +27,10 @@ func TestDisallowOnEmptyConsent(t *testing.T) {
 			tcf2SpecVersion: failedListFetcher,
 		},
 	}
-	allowSync, err := perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderAppnexus, SignalYes, "")
+	allowSync, err := perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderAppnexus, SignalYes, "", config.AccountGDPR{})
 	assertBoolsEqual(t, false, allowSync)
 	assertNilErr(t, err)
-	allowSync, err = perms.HostCookiesAllowed(context.Background(), SignalYes, "")
+	allowSync, err = perms.HostCookiesAllowed(context.Background(), SignalYes, "", config.AccountGDPR{})
 	assertBoolsEqual(t, false, allowSync)
 	assertNilErr(t, err)
 }
@@ -39,11 +39,11 @@ func TestAllowOnSignalNo(t *testing.T) {
 	perms := permissionsImpl{}
 	emptyConsent := ""
 
-	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalNo, emptyConsent)
+	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalNo, emptyConsent, config.AccountGDPR{})
 	assert.Equal(t, true, allowSync)
 	assert.Nil(t, err)
 
-	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderAppnexus, SignalNo, emptyConsent)
+	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderAppnexus, SignalNo, emptyConsent, config.AccountGDPR{})
 	assert.Equal(t, true, allowSync)
 	assert.Nil(t, err)
 }
@@ -74,11 +74,11 @@ func TestAllowedSyncs(t *testing.T) {
 		},
 	}
 
-	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "BON3PCUON3PCUABABBAAABoAAAAAMw")
+	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "BON3PCUON3PCUABABBAAABoAAAAAMw", config.AccountGDPR{})
 	assertNilErr(t, err)
 	assertBoolsEqual(t, true, allowSync)
 
-	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderPubmatic, SignalYes, "BON3PCUON3PCUABABBAAABoAAAAAMw")
+	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderPubmatic, SignalYes, "BON3PCUON3PCUABABBAAABoAAAAAMw", config.AccountGDPR{})
 	assertNilErr(t, err)
 	assertBoolsEqual(t, true, allowSync)
 }
@@ -109,11 +109,11 @@ func TestProhibitedPurposes(t *testing.T) {
 		},
 	}
 
-	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "BON3PCUON3PCUABABBAAABAAAAAAMw")
+	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "BON3PCUON3PCUABABBAAABAAAAAAMw", config.AccountGDPR{})
 	assertNilErr(t, err)
 	assertBoolsEqual(t, false, allowSync)
 
-	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderPubmatic, SignalYes, "BON3PCUON3PCUABABBAAABAAAAAAMw")
+	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderPubmatic, SignalYes, "BON3PCUON3PCUABABBAAABAAAAAAMw", config.AccountGDPR{})
 	assertNilErr(t, err)
 	assertBoolsEqual(t, false, allowSync)
 }
@@ -144,11 +144,11 @@ func TestProhibitedVendors(t *testing.T) {
 		},
 	}
 
-	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "BOS2bx5OS2bx5ABABBAAABoAAAAAFA")
+	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "BOS2bx5OS2bx5ABABBAAABoAAAAAFA", config.AccountGDPR{})
 	assertNilErr(t, err)
 	assertBoolsEqual(t, false, allowSync)
 
-	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderPubmatic, SignalYes, "BOS2bx5OS2bx5ABABBAAABoAAAAAFA")
+	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderPubmatic, SignalYes, "BOS2bx5OS2bx5ABABBAAABoAAAAAFA", config.AccountGDPR{})
 	assertNilErr(t, err)
 	assertBoolsEqual(t, false, allowSync)
 }
@@ -164,7 +164,7 @@ func TestMalformedConsent(t *testing.T) {
 		},
 	}
 
-	sync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "BON")
+	sync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "BON", config.AccountGDPR{})
 	assertErr(t, err, true)
 	assertBoolsEqual(t, false, sync)
 }
@@ -285,7 +285,7 @@ func TestAllowPersonalInfo(t *testing.T) {
 	for _, tt := range tests {
 		perms.cfg.UsersyncIfAmbiguous = tt.userSyncIfAmbiguous
 
-		allowPI, _, _, err := perms.PersonalInfoAllowed(context.Background(), tt.bidderName, tt.publisherID, tt.gdpr, tt.consent)
+		allowPI, _, _, err := perms.PersonalInfoAllowed(context.Background(), tt.bidderName, tt.publisherID, tt.gdpr, tt.consent, config.AccountGDPR{})
 
 		assert.Nil(t, err, tt.description)
 		assert.Equal(t, tt.allowPI, allowPI, tt.description)
@@ -392,7 +392,7 @@ func TestAllowPersonalInfoTCF2(t *testing.T) {
 	}
 
 	for _, td := range testDefs {
-		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", SignalYes, td.consent)
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", SignalYes, td.consent, config.AccountGDPR{})
 		assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed for %s", td.description)
 		assert.EqualValuesf(t, td.allowPI, allowPI, "AllowPI failure on %s", td.description)
 		assert.EqualValuesf(t, td.allowGeo, allowGeo, "AllowGeo failure on %s", td.description)
@@ -418,7 +418,7 @@ func TestAllowPersonalInfoWhitelistTCF2(t *testing.T) {
 	}
 	// Assert that an item that otherwise would not be allowed PI access, gets approved because it is found in the GDPR.NonStandardPublishers array
 	perms.cfg.NonStandardPublisherMap = map[string]struct{}{"appNexusAppID": {}}
-	allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderAppnexus, "appNexusAppID", SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+	allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), openrtb_ext.BidderAppnexus, "appNexusAppID", SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", config.AccountGDPR{})
 	assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed")
 	assert.EqualValuesf(t, true, allowPI, "AllowPI failure")
 	assert.EqualValuesf(t, true, allowGeo, "AllowGeo failure")
@@ -472,7 +472,7 @@ func TestAllowPersonalInfoTCF2PubRestrict(t *testing.T) {
 	}
 
 	for _, td := range testDefs {
-		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", SignalYes, td.consent)
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", SignalYes, td.consent, config.AccountGDPR{})
 		assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed for %s", td.description)
 		assert.EqualValuesf(t, td.allowPI, allowPI, "AllowPI failure on %s", td.description)
 		assert.EqualValuesf(t, td.allowGeo, allowGeo, "AllowGeo failure on %s", td.description)
@@ -528,7 +528,7 @@ func TestAllowPersonalInfoTCF2PurposeOneTrue(t *testing.T) {
 	}
 
 	for _, td := range testDefs {
-		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", SignalYes, td.consent)
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", SignalYes, td.consent, config.AccountGDPR{})
 		assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed for %s", td.description)
 		assert.EqualValuesf(t, td.allowPI, allowPI, "AllowPI failure on %s", td.description)
 		assert.EqualValuesf(t, td.allowGeo, allowGeo, "AllowGeo failure on %s", td.description)
@@ -585,7 +585,7 @@ func TestAllowPersonalInfoTCF2PurposeOneFalse(t *testing.T) {
 	}
 
 	for _, td := range testDefs {
-		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", SignalYes, td.consent)
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", SignalYes, td.consent, config.AccountGDPR{})
 		assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed for %s", td.description)
 		assert.EqualValuesf(t, td.allowPI, allowPI, "AllowPI failure on %s", td.description)
 		assert.EqualValuesf(t, td.allowGeo, allowGeo, "AllowGeo failure on %s", td.description)
@@ -593,6 +593,187 @@ func TestAllowPersonalInfoTCF2PurposeOneFalse(t *testing.T) {
 	}
 }
 
+func TestAllowPersonalInfoTCF2Enforcement(t *testing.T) {
+	vendorListData := tcf2MarshalVendorList(buildTCF2VendorList34())
+	basic := config.PurposeDetail{Enabled: true, EnforcementType: config.TCF2BasicEnforcement}
+	appnexusException := config.PurposeDetail{
+		Enabled:            true,
+		EnforcementType:    config.TCF2FullEnforcement,
+		VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus},
+		VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderAppnexus: {}},
+	}
+	disabled := false
+
+	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consents to purposes and vendors 2, 6, 8
+	// Appnexus only claims purpose 1, and Openx isn't in the vendor list.
+	testDefs := []struct {
+		tcf2TestDef
+		setup   func(tcf2 *config.TCF2)
+		account config.AccountGDPR
+	}{
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Full enforcement, insufficient purposes claimed",
+				bidder:      openrtb_ext.BidderAppnexus,
+			},
+		},
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Basic enforcement doesn't check the purposes claimed",
+				bidder:      openrtb_ext.BidderAppnexus,
+				allowPI:     true,
+				allowID:     true,
+			},
+			setup: func(tcf2 *config.TCF2) {
+				tcf2.Purpose2 = basic
+				tcf2.Purpose7 = basic
+			},
+		},
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Basic enforcement allows vendors which aren't in the vendor list",
+				bidder:      openrtb_ext.BidderOpenx,
+				allowPI:     true,
+				allowID:     true,
+			},
+			setup: func(tcf2 *config.TCF2) {
+				tcf2.Purpose1 = basic
+				tcf2.Purpose2 = basic
+				tcf2.Purpose7 = basic
+			},
+		},
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Vendor exceptions aren't enforced",
+				bidder:      openrtb_ext.BidderAppnexus,
+				allowPI:     true,
+				allowID:     true,
+			},
+			setup: func(tcf2 *config.TCF2) {
+				tcf2.Purpose2 = appnexusException
+				tcf2.Purpose7 = appnexusException
+			},
+		},
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Vendor exceptions don't apply to other bidders",
+				bidder:      openrtb_ext.BidderOpenx,
+			},
+			setup: func(tcf2 *config.TCF2) {
+				tcf2.Purpose1 = appnexusException
+				tcf2.Purpose2 = appnexusException
+				tcf2.Purpose7 = appnexusException
+			},
+		},
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Special purpose 1 not enforced",
+				bidder:      openrtb_ext.BidderAppnexus,
+				allowGeo:    true,
+			},
+			setup: func(tcf2 *config.TCF2) {
+				tcf2.SpecialPurpose1.EnforcementType = config.TCF2NoEnforcement
+			},
+		},
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Account overrides the enforcement type",
+				bidder:      openrtb_ext.BidderAppnexus,
+				allowPI:     true,
+				allowID:     true,
+			},
+			account: config.AccountGDPR{
+				Purpose2: config.AccountGDPRPurpose{EnforcementType: config.TCF2BasicEnforcement},
+				Purpose7: config.AccountGDPRPurpose{EnforcementType: config.TCF2BasicEnforcement},
+			},
+		},
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Account disables purposes",
+				bidder:      openrtb_ext.BidderAppnexus,
+				allowPI:     true,
+			},
+			account: config.AccountGDPR{
+				Purpose2: config.AccountGDPRPurpose{Enabled: &disabled},
+				Purpose7: config.AccountGDPRPurpose{Enabled: &disabled},
+			},
+		},
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Account removes the host's vendor exceptions",
+				bidder:      openrtb_ext.BidderAppnexus,
+				allowID:     true,
+			},
+			setup: func(tcf2 *config.TCF2) {
+				tcf2.Purpose2 = appnexusException
+				tcf2.Purpose7 = appnexusException
+			},
+			account: config.AccountGDPR{
+				Purpose2: config.AccountGDPRPurpose{VendorExceptions: []openrtb_ext.BidderName{}},
+			},
+		},
+		{
+			tcf2TestDef: tcf2TestDef{
+				description: "Account overrides special purpose 1",
+				bidder:      openrtb_ext.BidderAppnexus,
+				allowGeo:    true,
+			},
+			account: config.AccountGDPR{
+				SpecialPurpose1: config.AccountGDPRPurpose{EnforcementType: config.TCF2NoEnforcement},
+			},
+		},
+	}
+
+	for _, td := range testDefs {
+		perms := permissionsImpl{
+			cfg: tcf2Config,
+			vendorIDs: map[openrtb_ext.BidderName]uint16{
+				openrtb_ext.BidderAppnexus: 2,
+				openrtb_ext.BidderOpenx:    3,
+			},
+			fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
+				tcf1SpecVersion: nil,
+				tcf2SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+					34: parseVendorListDataV2(t, vendorListData),
+				}),
+			},
+		}
+		if td.setup != nil {
+			td.setup(&perms.cfg.TCF2)
+		}
+
+		allowPI, allowGeo, allowID, err := perms.PersonalInfoAllowed(context.Background(), td.bidder, "", SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", td.account)
+		assert.NoErrorf(t, err, "Error processing PersonalInfoAllowed for %s", td.description)
+		assert.EqualValuesf(t, td.allowPI, allowPI, "AllowPI failure on %s", td.description)
+		assert.EqualValuesf(t, td.allowGeo, allowGeo, "AllowGeo failure on %s", td.description)
+		assert.EqualValuesf(t, td.allowID, allowID, "AllowID failure on %s", td.description)
+	}
+}
+
+func TestAllowSyncTCF2VendorException(t *testing.T) {
+	tcf2VendorList34 := buildTCF2VendorList34()
+	tcf2VendorList34.Vendors["8"].Purposes = []int{7}
+	vendorListData := tcf2MarshalVendorList(tcf2VendorList34)
+	perms := permissionsImpl{
+		cfg: tcf2Config,
+		vendorIDs: map[openrtb_ext.BidderName]uint16{
+			openrtb_ext.BidderRubicon: 8,
+		},
+		fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
+			tcf1SpecVersion: nil,
+			tcf2SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+				34: parseVendorListDataV2(t, vendorListData),
+			}),
+		},
+	}
+	perms.cfg.TCF2.Purpose1.VendorExceptionMap = map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderRubicon: {}}
+
+	// Rubicon doesn't claim purpose 1, but is an exception to it
+	allowSync, err := perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderRubicon, SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", config.AccountGDPR{})
+	assert.NoErrorf(t, err, "Error processing BidderSyncAllowed")
+	assert.EqualValuesf(t, true, allowSync, "BidderSyncAllowed failure")
+}
+
 func TestAllowSyncTCF2(t *testing.T) {
 	vendorListData := tcf2MarshalVendorList(buildTCF2VendorList34())
 	perms := permissionsImpl{
@@ -611,11 +792,11 @@ func TestAllowSyncTCF2(t *testing.T) {
 	}
 
 	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consensts to purposes and vendors 2, 6, 8
-	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", config.AccountGDPR{})
 	assert.NoErrorf(t, err, "Error processing HostCookiesAllowed")
 	assert.EqualValuesf(t, true, allowSync, "HostCookiesAllowed failure")
 
-	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderRubicon, SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderRubicon, SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", config.AccountGDPR{})
 	assert.NoErrorf(t, err, "Error processing BidderSyncAllowed")
 	assert.EqualValuesf(t, true, allowSync, "BidderSyncAllowed failure")
 }
@@ -641,15 +822,68 @@ func TestProhibitedPurposeSyncTCF2(t *testing.T) {
 	perms.cfg.HostVendorID = 8
 
 	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consents to purposes for vendors 2, 6, 8
-	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", config.AccountGDPR{})
 	assert.NoErrorf(t, err, "Error processing HostCookiesAllowed")
 	assert.EqualValuesf(t, false, allowSync, "HostCookiesAllowed failure")
 
-	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderRubicon, SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderRubicon, SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", config.AccountGDPR{})
 	assert.NoErrorf(t, err, "Error processing BidderSyncAllowed")
 	assert.EqualValuesf(t, false, allowSync, "BidderSyncAllowed failure")
 }
 
+func TestAllowSyncTCF2AccountOverrides(t *testing.T) {
+	tcf2VendorList34 := buildTCF2VendorList34()
+	tcf2VendorList34.Vendors["8"].Purposes = []int{7}
+	vendorListData := tcf2MarshalVendorList(tcf2VendorList34)
+	perms := permissionsImpl{
+		cfg: tcf2Config,
+		vendorIDs: map[openrtb_ext.BidderName]uint16{
+			openrtb_ext.BidderRubicon: 8,
+		},
+		fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
+			tcf1SpecVersion: nil,
+			tcf2SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+				34: parseVendorListDataV2(t, vendorListData),
+			}),
+		},
+	}
+	perms.cfg.HostVendorID = 8
+
+	// Vendor 8 doesn't claim purpose 1, so it may sync only if the account loosens the enforcement of it
+	testDefs := []struct {
+		description     string
+		account         config.AccountGDPR
+		allowHostCookie bool
+		allowBidderSync bool
+	}{
+		{
+			description: "Host enforcement",
+		},
+		{
+			description:     "Account basic enforcement",
+			account:         config.AccountGDPR{Purpose1: config.AccountGDPRPurpose{EnforcementType: config.TCF2BasicEnforcement}},
+			allowHostCookie: true,
+			allowBidderSync: true,
+		},
+		{
+			description:     "Account vendor exception",
+			account:         config.AccountGDPR{Purpose1: config.AccountGDPRPurpose{VendorExceptions: []openrtb_ext.BidderName{openrtb_ext.BidderRubicon}}},
+			allowBidderSync: true,
+		},
+	}
+
+	for _, td := range testDefs {
+		// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consents to purposes for vendors 2, 6, 8
+		allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", td.account)
+		assert.NoErrorf(t, err, "Error processing HostCookiesAllowed for %s", td.description)
+		assert.EqualValuesf(t, td.allowHostCookie, allowSync, "HostCookiesAllowed failure on %s", td.description)
+
+		allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderRubicon, SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", td.account)
+		assert.NoErrorf(t, err, "Error processing BidderSyncAllowed for %s", td.description)
+		assert.EqualValuesf(t, td.allowBidderSync, allowSync, "BidderSyncAllowed failure on %s", td.description)
+	}
+}
+
 func TestProhibitedVendorSyncTCF2(t *testing.T) {
 	vendorListData := tcf2MarshalVendorList(buildTCF2VendorList34())
 	perms := permissionsImpl{
@@ -670,12 +904,12 @@ func TestProhibitedVendorSyncTCF2(t *testing.T) {
 	perms.cfg.HostVendorID = 10
 
 	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : TCF2 with full consents to purposes for vendors 2, 6, 8
-	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+	allowSync, err := perms.HostCookiesAllowed(context.Background(), SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", config.AccountGDPR{})
 	assert.NoErrorf(t, err, "Error processing HostCookiesAllowed")
 	assert.EqualValuesf(t, false, allowSync, "HostCookiesAllowed failure")
 
 	// Permission disallowed due to consent string not including vendor 10.
-	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderOpenx, SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA")
+	allowSync, err = perms.BidderSyncAllowed(context.Background(), openrtb_ext.BidderOpenx, SignalYes, "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA", config.AccountGDPR{})
 	assert.NoErrorf(t, err, "Error processing BidderSyncAllowed")
 	assert.EqualValuesf(t, false, allowSync, "BidderSyncAllowed failure")
 }
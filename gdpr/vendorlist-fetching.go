@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -15,10 +17,11 @@ import (
 	"github.com/prebid/go-gdpr/vendorlist"
 	"github.com/prebid/go-gdpr/vendorlist2"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
 	"golang.org/x/net/context/ctxhttp"
 )
 
-type saveVendors func(uint16, api.VendorList)
+type saveVendors func(vendorListVersion uint16, contents []byte, list api.VendorList)
 
 // This file provides the vendorlist-fetching function for Prebid Server.
 //
@@ -52,14 +55,24 @@ func loadFallbackGVLForTCF1(fallbackGVLPath string) vendorlist.VendorList {
 	return fallback
 }
 
-func newVendorListFetcherTCF2(initCtx context.Context, cfg config.GDPR, client *http.Client, urlMaker func(uint16) string) func(ctx context.Context, id uint16) (vendorlist.VendorList, error) {
-	cacheSave, cacheLoad := newVendorListCache()
+func newVendorListFetcherTCF2(initCtx context.Context, cfg config.GDPR, client *http.Client, urlMaker func(uint16) string, metricsEngine metrics.MetricsEngine) func(ctx context.Context, id uint16) (vendorlist.VendorList, error) {
+	cacheSave, cacheLoad := newVendorListCache(metricsEngine)
+	diskSave := loadVendorListDiskCache(cfg.TCF2.VendorListCacheDir, cacheSave)
+	saver := func(vendorListVersion uint16, contents []byte, list api.VendorList) {
+		cacheSave(vendorListVersion, list)
+		diskSave(vendorListVersion, contents)
+	}
+
+	var fallback api.VendorList
+	if len(cfg.TCF2.FallbackGVLPath) > 0 {
+		fallback = loadFallbackGVLForTCF2(cfg.TCF2.FallbackGVLPath)
+	}
 
 	preloadContext, cancel := context.WithTimeout(initCtx, cfg.Timeouts.InitTimeout())
 	defer cancel()
-	preloadCache(preloadContext, client, urlMaker, cacheSave)
+	preloadCache(preloadContext, client, urlMaker, saver, cacheLoad, metricsEngine)
 
-	saveOneRateLimited := newOccasionalSaver(cfg.Timeouts.ActiveTimeout())
+	saveOneRateLimited := newOccasionalSaver(cfg.Timeouts.ActiveTimeout(), metricsEngine)
 	return func(ctx context.Context, vendorListVersion uint16) (vendorlist.VendorList, error) {
 		// Attempt To Load From Cache
 		if list := cacheLoad(vendorListVersion); list != nil {
@@ -68,7 +81,7 @@ func newVendorListFetcherTCF2(initCtx context.Context, cfg config.GDPR, client *
 
 		// Attempt To Download
 		// - May not add to cache immediately.
-		saveOneRateLimited(ctx, client, urlMaker(vendorListVersion), cacheSave)
+		saveOneRateLimited(ctx, client, urlMaker(vendorListVersion), saver)
 
 		// Attempt To Load From Cache Again
 		// - May have been added by the call to saveOneRateLimited.
@@ -76,24 +89,45 @@ func newVendorListFetcherTCF2(initCtx context.Context, cfg config.GDPR, client *
 			return list, nil
 		}
 
+		// Attempt To Use The Fallback
+		if fallback != nil {
+			return fallback, nil
+		}
+
 		// Give Up
 		return nil, makeVendorListNotFoundError(vendorListVersion)
 	}
 }
 
+func loadFallbackGVLForTCF2(fallbackGVLPath string) api.VendorList {
+	fallbackContents, err := ioutil.ReadFile(fallbackGVLPath)
+	if err != nil {
+		glog.Fatalf("Error reading from file %s: %v", fallbackGVLPath, err)
+	}
+
+	fallback, err := vendorlist2.ParseEagerly(fallbackContents)
+	if err != nil {
+		glog.Fatalf("Error processing default GVL from %s: %v", fallbackGVLPath, err)
+	}
+	return fallback
+}
+
 func makeVendorListNotFoundError(vendorListVersion uint16) error {
 	return fmt.Errorf("gdpr vendor list version %d does not exist, or has not been loaded yet. Try again in a few minutes", vendorListVersion)
 }
 
-// preloadCache saves all the known versions of the vendor list for future use.
-func preloadCache(ctx context.Context, client *http.Client, urlMaker func(uint16) string, saver saveVendors) {
-	latestVersion := saveOne(ctx, client, urlMaker(0), saver)
+// preloadCache saves all the known versions of the vendor list for future use. Versions which are
+// already cached, because they were loaded from disk, aren't fetched again.
+func preloadCache(ctx context.Context, client *http.Client, urlMaker func(uint16) string, saver saveVendors, cacheLoad func(uint16) api.VendorList, metricsEngine metrics.MetricsEngine) {
+	latestVersion := saveOne(ctx, client, urlMaker(0), saver, metricsEngine)
 
 	// The GVL for TCF2 has no vendors defined in its first version. It's very unlikely to be used, so don't preload it.
 	firstVersionToLoad := uint16(2)
 
 	for i := firstVersionToLoad; i < latestVersion; i++ {
-		saveOne(ctx, client, urlMaker(i), saver)
+		if cacheLoad(i) == nil {
+			saveOne(ctx, client, urlMaker(i), saver, metricsEngine)
+		}
 	}
 }
 
@@ -111,7 +145,7 @@ func vendorListURLMaker(vendorListVersion uint16) string {
 // The goal here is to update quickly when new versions of the VendorList are released, but not wreck
 // server performance if a bad CMP starts sending us malformed consent strings that advertize a version
 // that doesn't exist yet.
-func newOccasionalSaver(timeout time.Duration) func(ctx context.Context, client *http.Client, url string, saver saveVendors) {
+func newOccasionalSaver(timeout time.Duration, metricsEngine metrics.MetricsEngine) func(ctx context.Context, client *http.Client, url string, saver saveVendors) {
 	lastSaved := &atomic.Value{}
 	lastSaved.Store(time.Time{})
 
@@ -122,13 +156,20 @@ func newOccasionalSaver(timeout time.Duration) func(ctx context.Context, client
 		if timeSinceLastSave.Minutes() > 10 {
 			withTimeout, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
-			saveOne(withTimeout, client, url, saver)
+			saveOne(withTimeout, client, url, saver, metricsEngine)
 			lastSaved.Store(now)
 		}
 	}
 }
 
-func saveOne(ctx context.Context, client *http.Client, url string, saver saveVendors) uint16 {
+// saveOne fetches a vendor list and saves it. It returns the version which was saved, or 0 if the fetch failed.
+func saveOne(ctx context.Context, client *http.Client, url string, saver saveVendors, metricsEngine metrics.MetricsEngine) uint16 {
+	version := fetchOne(ctx, client, url, saver)
+	metricsEngine.RecordVendorListFetch(version != 0)
+	return version
+}
+
+func fetchOne(ctx context.Context, client *http.Client, url string, saver saveVendors) uint16 {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		glog.Errorf("Failed to build GET %s request. Cookie syncs may be affected: %v", url, err)
@@ -158,15 +199,19 @@ func saveOne(ctx context.Context, client *http.Client, url string, saver saveVen
 		return 0
 	}
 
-	saver(newList.Version(), newList)
+	saver(newList.Version(), respBody, newList)
 	return newList.Version()
 }
 
-func newVendorListCache() (save func(vendorListVersion uint16, list api.VendorList), load func(vendorListVersion uint16) api.VendorList) {
+func newVendorListCache(metricsEngine metrics.MetricsEngine) (save func(vendorListVersion uint16, list api.VendorList), load func(vendorListVersion uint16) api.VendorList) {
 	cache := &sync.Map{}
+	var size int32
 
 	save = func(vendorListVersion uint16, list api.VendorList) {
-		cache.Store(vendorListVersion, list)
+		// Vendor list versions never change once they're published, so the first one saved can be kept.
+		if _, loaded := cache.LoadOrStore(vendorListVersion, list); !loaded {
+			metricsEngine.RecordVendorListCacheSize(int(atomic.AddInt32(&size, 1)))
+		}
 	}
 
 	load = func(vendorListVersion uint16) api.VendorList {
@@ -178,3 +223,63 @@ func newVendorListCache() (save func(vendorListVersion uint16, list api.VendorLi
 	}
 	return
 }
+
+// loadVendorListDiskCache saves the vendor lists found in dir to the in-memory cache, and returns a function
+// which writes newly fetched vendor lists to dir. If dir is empty, nothing is read and nothing will be written.
+func loadVendorListDiskCache(dir string, cacheSave func(vendorListVersion uint16, list api.VendorList)) (save func(vendorListVersion uint16, contents []byte)) {
+	if len(dir) == 0 {
+		return func(uint16, []byte) {}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		glog.Errorf("Failed to create the vendor list cache dir %s. Vendor lists will only be cached in memory: %v", dir, err)
+		return func(uint16, []byte) {}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "vendor-list-v*.json"))
+	if err != nil {
+		glog.Errorf("Failed to list the vendor list cache dir %s: %v", dir, err)
+	}
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			glog.Errorf("Error reading cached vendor list %s: %v", file, err)
+			continue
+		}
+		list, err := vendorlist2.ParseEagerly(contents)
+		if err != nil {
+			glog.Errorf("Cached vendor list %s is malformed and will be fetched again: %v", file, err)
+			continue
+		}
+		cacheSave(list.Version(), list)
+	}
+
+	return func(vendorListVersion uint16, contents []byte) {
+		path := vendorListDiskCachePath(dir, vendorListVersion)
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+
+		// Write to a temp file first, so that a crash can't leave a partial vendor list behind.
+		tmp, err := ioutil.TempFile(dir, "vendor-list-*.tmp")
+		if err != nil {
+			glog.Errorf("Failed to cache vendor list version %d on disk: %v", vendorListVersion, err)
+			return
+		}
+		_, err = tmp.Write(contents)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), path)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			glog.Errorf("Failed to cache vendor list version %d on disk: %v", vendorListVersion, err)
+		}
+	}
+}
+
+func vendorListDiskCachePath(dir string, vendorListVersion uint16) string {
+	return filepath.Join(dir, "vendor-list-v"+strconv.Itoa(int(vendorListVersion))+".json")
+}
@@ -3,8 +3,11 @@ package gdpr
 import (
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 
@@ -12,6 +15,8 @@ import (
 
 	"github.com/prebid/go-gdpr/consentconstants"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	metricsConf "github.com/prebid/prebid-server/metrics/config"
 )
 
 func TestTCF1FetcherInitialLoad(t *testing.T) {
@@ -139,7 +144,7 @@ func TestTCF2FetcherThrottling(t *testing.T) {
 	})))
 	defer server.Close()
 
-	fetcher := newVendorListFetcherTCF2(context.Background(), testConfig(), server.Client(), testURLMaker(server))
+	fetcher := newVendorListFetcherTCF2(context.Background(), testConfig(), server.Client(), testURLMaker(server), &metricsConf.DummyMetricsEngine{})
 
 	// Dynamically Load List 2 Successfully
 	_, errList1 := fetcher(context.Background(), 2)
@@ -160,7 +165,7 @@ func TestTCF2MalformedVendorlist(t *testing.T) {
 	})))
 	defer server.Close()
 
-	fetcher := newVendorListFetcherTCF2(context.Background(), testConfig(), server.Client(), testURLMaker(server))
+	fetcher := newVendorListFetcherTCF2(context.Background(), testConfig(), server.Client(), testURLMaker(server), &metricsConf.DummyMetricsEngine{})
 	_, err := fetcher(context.Background(), 1)
 
 	// Fetching should fail since vendor list could not be unmarshalled.
@@ -173,7 +178,7 @@ func TestTCF2ServerUrlInvalid(t *testing.T) {
 
 	invalidURLGenerator := func(uint16) string { return " http://invalid-url-has-leading-whitespace" }
 
-	fetcher := newVendorListFetcherTCF2(context.Background(), testConfig(), server.Client(), invalidURLGenerator)
+	fetcher := newVendorListFetcherTCF2(context.Background(), testConfig(), server.Client(), invalidURLGenerator, &metricsConf.DummyMetricsEngine{})
 	_, err := fetcher(context.Background(), 1)
 
 	assert.EqualError(t, err, "gdpr vendor list version 1 does not exist, or has not been loaded yet. Try again in a few minutes")
@@ -183,12 +188,119 @@ func TestTCF2ServerUnavailable(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	server.Close()
 
-	fetcher := newVendorListFetcherTCF2(context.Background(), testConfig(), server.Client(), testURLMaker(server))
+	fetcher := newVendorListFetcherTCF2(context.Background(), testConfig(), server.Client(), testURLMaker(server), &metricsConf.DummyMetricsEngine{})
 	_, err := fetcher(context.Background(), 1)
 
 	assert.EqualError(t, err, "gdpr vendor list version 1 does not exist, or has not been loaded yet. Try again in a few minutes")
 }
 
+func TestTCF2FetcherDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vendorlists")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := testConfig()
+	config.TCF2.VendorListCacheDir = dir
+
+	// Loads and saves vendor lists 2 and 3 during initialization.
+	server := httptest.NewServer(http.HandlerFunc(mockServer(serverSettings{
+		vendorListLatestVersion: 3,
+		vendorLists: map[int]string{
+			2: tcf2VendorList2,
+			3: tcf2VendorList3,
+		},
+	})))
+	newVendorListFetcherTCF2(context.Background(), config, server.Client(), testURLMaker(server), &metricsConf.DummyMetricsEngine{})
+	server.Close()
+
+	assert.FileExists(t, filepath.Join(dir, "vendor-list-v2.json"))
+	assert.FileExists(t, filepath.Join(dir, "vendor-list-v3.json"))
+
+	// Restarts while the vendor list server is down.
+	fetcher := newVendorListFetcherTCF2(context.Background(), config, server.Client(), testURLMaker(server), &metricsConf.DummyMetricsEngine{})
+
+	vendorList, err := fetcher(context.Background(), 2)
+	assert.NoError(t, err, "Vendor List 2")
+	assert.Equal(t, uint16(2), vendorList.Version(), "Vendor List 2")
+
+	vendorList, err = fetcher(context.Background(), 3)
+	assert.NoError(t, err, "Vendor List 3")
+	assert.Equal(t, uint16(3), vendorList.Version(), "Vendor List 3")
+}
+
+func TestTCF2FetcherDiskCacheMalformed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vendorlists")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "vendor-list-v2.json"), []byte("malformed"), 0644)
+
+	config := testConfig()
+	config.TCF2.VendorListCacheDir = dir
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	fetcher := newVendorListFetcherTCF2(context.Background(), config, server.Client(), testURLMaker(server), &metricsConf.DummyMetricsEngine{})
+	_, err = fetcher(context.Background(), 2)
+
+	assert.EqualError(t, err, "gdpr vendor list version 2 does not exist, or has not been loaded yet. Try again in a few minutes")
+}
+
+func TestTCF2FetcherFallback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vendorlists")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fallbackPath := filepath.Join(dir, "fallback_gvl.json")
+	ioutil.WriteFile(fallbackPath, []byte(tcf2VendorList2), 0644)
+
+	server := httptest.NewServer(http.HandlerFunc(mockServer(serverSettings{
+		vendorListLatestVersion: 1,
+		vendorLists: map[int]string{
+			1: tcf2VendorList1,
+		},
+	})))
+	defer server.Close()
+
+	config := testConfig()
+	config.TCF2.FallbackGVLPath = fallbackPath
+	fetcher := newVendorListFetcherTCF2(context.Background(), config, server.Client(), testURLMaker(server), &metricsConf.DummyMetricsEngine{})
+
+	vendorList, err := fetcher(context.Background(), 1)
+	assert.NoError(t, err, "Fetched")
+	assert.Equal(t, uint16(1), vendorList.Version(), "Fetched")
+
+	vendorList, err = fetcher(context.Background(), 5)
+	assert.NoError(t, err, "Fallback")
+	assert.Equal(t, uint16(2), vendorList.Version(), "Fallback")
+}
+
+func TestTCF2FetcherMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(mockServer(serverSettings{
+		vendorListLatestVersion: 2,
+		vendorLists: map[int]string{
+			2: tcf2VendorList2,
+		},
+	})))
+	defer server.Close()
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordVendorListFetch", true).Once()
+	metricsMock.On("RecordVendorListFetch", false).Once()
+	metricsMock.On("RecordVendorListCacheSize", 1).Once()
+
+	fetcher := newVendorListFetcherTCF2(context.Background(), testConfig(), server.Client(), testURLMaker(server), metricsMock)
+	_, err := fetcher(context.Background(), 3)
+
+	assert.Error(t, err)
+	metricsMock.AssertExpectations(t)
+}
+
 func TestVendorListURLMaker(t *testing.T) {
 	testCases := []struct {
 		description       string
@@ -233,6 +345,11 @@ var tcf2VendorList2 = tcf2MarshalVendorList(tcf2VendorList{
 	Vendors:           map[string]*tcf2Vendor{"12": {ID: 12, Purposes: []int{2, 3}}},
 })
 
+var tcf2VendorList3 = tcf2MarshalVendorList(tcf2VendorList{
+	VendorListVersion: 3,
+	Vendors:           map[string]*tcf2Vendor{"12": {ID: 12, Purposes: []int{2, 3, 4}}},
+})
+
 var vendorList2Expected = testExpected{
 	vendorListVersion: 2,
 	vendorID:          12,
@@ -358,7 +475,7 @@ func runTestTCF1(t *testing.T, test test, server *httptest.Server) {
 
 func runTestTCF2(t *testing.T, test test, server *httptest.Server) {
 	config := testConfig()
-	fetcher := newVendorListFetcherTCF2(context.Background(), config, server.Client(), testURLMaker(server))
+	fetcher := newVendorListFetcherTCF2(context.Background(), config, server.Client(), testURLMaker(server), &metricsConf.DummyMetricsEngine{})
 	vendorList, err := fetcher(context.Background(), test.setup.vendorListVersion)
 
 	if test.expected.errorMessage != "" {
@@ -0,0 +1,162 @@
+package geolocation
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/mxmCherry/openrtb"
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/prebid/prebid-server/config"
+	"golang.org/x/text/language"
+)
+
+// Lookup finds where IP addresses are.
+type Lookup interface {
+	// Lookup returns the location of the IP address, or nil if it isn't known.
+	Lookup(ip net.IP) (*openrtb.Geo, error)
+}
+
+// Database looks up IP addresses in a local database in the MaxMind DB format, such as GeoLite2-City.
+// The database is loaded again whenever Run finds that the file has changed.
+//
+// A nil Database doesn't know where any IP address is. It may be used by several goroutines at once.
+type Database struct {
+	path            string
+	latLonPrecision int
+
+	reader  atomic.Value // Should only hold *maxminddb.Reader
+	mutex   sync.Mutex
+	modTime time.Time
+}
+
+// mmdbRecord is the part of a GeoIP2 or GeoLite2 City record which makes up an OpenRTB geo object.
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Postal struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"postal"`
+	Location struct {
+		Latitude       *float64 `maxminddb:"latitude"`
+		Longitude      *float64 `maxminddb:"longitude"`
+		AccuracyRadius uint16   `maxminddb:"accuracy_radius"`
+		MetroCode      uint     `maxminddb:"metro_code"`
+	} `maxminddb:"location"`
+}
+
+// NewDatabase loads the database the config points to. It returns nil if geolocation is disabled.
+func NewDatabase(cfg config.GeoLocation) (*Database, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	db := &Database{
+		path:            cfg.DatabasePath,
+		latLonPrecision: cfg.LatLonPrecision,
+	}
+	if err := db.Run(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Run loads the database again if the file has changed since it was last loaded. If the file can't be loaded,
+// the database which was loaded before is kept.
+func (db *Database) Run() error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return fmt.Errorf("geolocation database %s can't be read: %v", db.path, err)
+	}
+	if info.ModTime().Equal(db.modTime) {
+		return nil
+	}
+
+	// The file is read rather than memory mapped, so that the old database can be dropped while it's still in use.
+	contents, err := ioutil.ReadFile(db.path)
+	if err != nil {
+		return fmt.Errorf("geolocation database %s can't be read: %v", db.path, err)
+	}
+	reader, err := maxminddb.FromBytes(contents)
+	if err != nil {
+		return fmt.Errorf("geolocation database %s is invalid: %v", db.path, err)
+	}
+
+	db.reader.Store(reader)
+	db.modTime = info.ModTime()
+	glog.Infof("Loaded the geolocation database %s, built at %s", db.path, time.Unix(int64(reader.Metadata.BuildEpoch), 0).UTC())
+	return nil
+}
+
+// Lookup returns the location of the IP address, or nil if it isn't in the database.
+func (db *Database) Lookup(ip net.IP) (*openrtb.Geo, error) {
+	if db == nil || ip == nil {
+		return nil, nil
+	}
+	reader, ok := db.reader.Load().(*maxminddb.Reader)
+	if !ok {
+		return nil, nil
+	}
+
+	record := mmdbRecord{}
+	if _, found, err := reader.LookupNetwork(ip, &record); err != nil || !found {
+		return nil, err
+	}
+	return db.toGeo(record), nil
+}
+
+func (db *Database) toGeo(record mmdbRecord) *openrtb.Geo {
+	geo := &openrtb.Geo{
+		Country:   countryCode(record.Country.ISOCode),
+		City:      record.City.Names["en"],
+		ZIP:       record.Postal.Code,
+		IPService: openrtb.IPLocationServiceMaxMind,
+	}
+	if len(record.Subdivisions) > 0 {
+		geo.Region = record.Subdivisions[0].ISOCode
+	}
+	if record.Location.MetroCode > 0 {
+		geo.Metro = strconv.FormatUint(uint64(record.Location.MetroCode), 10)
+	}
+	if record.Location.Latitude != nil && record.Location.Longitude != nil {
+		geo.Type = openrtb.LocationTypeIPAddress
+		geo.Lat = roundCoordinate(*record.Location.Latitude, db.latLonPrecision)
+		geo.Lon = roundCoordinate(*record.Location.Longitude, db.latLonPrecision)
+		// The radius is in kilometers, while OpenRTB wants the accuracy in meters.
+		geo.Accuracy = uint64(record.Location.AccuracyRadius) * 1000
+	}
+	return geo
+}
+
+// countryCode turns the ISO-3166-1 alpha-2 code of the database into the alpha-3 code OpenRTB uses.
+func countryCode(alpha2 string) string {
+	if alpha2 == "" {
+		return ""
+	}
+	region, err := language.ParseRegion(alpha2)
+	if err != nil {
+		return ""
+	}
+	return region.ISO3()
+}
+
+func roundCoordinate(coordinate float64, precision int) float64 {
+	scale := math.Pow(10, float64(precision))
+	return math.Round(coordinate*scale) / scale
+}
@@ -0,0 +1,238 @@
+package geolocation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var berlin = map[string]interface{}{
+	"country":      map[string]interface{}{"iso_code": "DE"},
+	"subdivisions": []interface{}{map[string]interface{}{"iso_code": "BE"}},
+	"city":         map[string]interface{}{"names": map[string]interface{}{"en": "Berlin"}},
+	"postal":       map[string]interface{}{"code": "10115"},
+	"location": map[string]interface{}{
+		"latitude":        52.5321,
+		"longitude":       13.3849,
+		"accuracy_radius": uint16(20),
+	},
+}
+
+var newYork = map[string]interface{}{
+	"country":      map[string]interface{}{"iso_code": "US"},
+	"subdivisions": []interface{}{map[string]interface{}{"iso_code": "NY"}},
+	"location": map[string]interface{}{
+		"latitude":        40.7128,
+		"longitude":       -74.006,
+		"accuracy_radius": uint16(5),
+		"metro_code":      uint16(501),
+	},
+}
+
+func TestLookup(t *testing.T) {
+	path := writeTestDatabase(t, map[string]map[string]interface{}{
+		"1.2.3.0/24": berlin,
+		"5.6.0.0/16": newYork,
+	})
+	defer os.RemoveAll(filepath.Dir(path))
+
+	db, err := NewDatabase(config.GeoLocation{Enabled: true, DatabasePath: path, LatLonPrecision: 2})
+	assert.NoError(t, err)
+
+	geo, err := db.Lookup(net.ParseIP("1.2.3.4"))
+	assert.NoError(t, err)
+	assert.Equal(t, &openrtb.Geo{
+		Country:   "DEU",
+		Region:    "BE",
+		City:      "Berlin",
+		ZIP:       "10115",
+		Lat:       52.53,
+		Lon:       13.38,
+		Accuracy:  20000,
+		Type:      openrtb.LocationTypeIPAddress,
+		IPService: openrtb.IPLocationServiceMaxMind,
+	}, geo)
+
+	geo, err = db.Lookup(net.ParseIP("5.6.7.8"))
+	assert.NoError(t, err)
+	assert.Equal(t, "USA", geo.Country)
+	assert.Equal(t, "NY", geo.Region)
+	assert.Equal(t, "501", geo.Metro)
+	assert.Equal(t, -74.01, geo.Lon)
+
+	geo, err = db.Lookup(net.ParseIP("9.9.9.9"))
+	assert.NoError(t, err)
+	assert.Nil(t, geo, "IP addresses which aren't in the database should have no location")
+}
+
+func TestDisabled(t *testing.T) {
+	db, err := NewDatabase(config.GeoLocation{Enabled: false, DatabasePath: "/does/not/exist.mmdb"})
+	assert.NoError(t, err)
+	assert.Nil(t, db)
+
+	geo, err := db.Lookup(net.ParseIP("1.2.3.4"))
+	assert.NoError(t, err)
+	assert.Nil(t, geo)
+}
+
+func TestMissingDatabase(t *testing.T) {
+	_, err := NewDatabase(config.GeoLocation{Enabled: true, DatabasePath: "/does/not/exist.mmdb"})
+	assert.Error(t, err)
+}
+
+func TestReload(t *testing.T) {
+	path := writeTestDatabase(t, map[string]map[string]interface{}{"1.2.3.0/24": berlin})
+	defer os.RemoveAll(filepath.Dir(path))
+	db, err := NewDatabase(config.GeoLocation{Enabled: true, DatabasePath: path})
+	assert.NoError(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("not a database"), 0644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	assert.Error(t, db.Run())
+	geo, _ := db.Lookup(net.ParseIP("1.2.3.4"))
+	assert.Equal(t, "DEU", geo.Country, "The old database should be kept if the new one is invalid")
+
+	assert.NoError(t, ioutil.WriteFile(path, buildTestDatabase(map[string]map[string]interface{}{"1.2.3.0/24": newYork}), 0644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	assert.NoError(t, db.Run())
+	geo, _ = db.Lookup(net.ParseIP("1.2.3.4"))
+	assert.Equal(t, "USA", geo.Country, "The database should be loaded again when the file changes")
+}
+
+func writeTestDatabase(t *testing.T, records map[string]map[string]interface{}) string {
+	dir, err := ioutil.TempDir("", "geolocation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "test.mmdb")
+	if err := ioutil.WriteFile(path, buildTestDatabase(records), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// buildTestDatabase builds an IPv4 database in the MaxMind DB format, with 24 bit records, which maps the
+// networks to their records. See https://maxmind.github.io/MaxMind-DB/
+func buildTestDatabase(records map[string]map[string]interface{}) []byte {
+	type node struct{ children [2]int }
+	const empty, dataFlag = -1, 1 << 30
+	nodes := []node{{children: [2]int{empty, empty}}}
+	data := &bytes.Buffer{}
+
+	networks := make([]string, 0, len(records))
+	for network := range records {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+	for _, network := range networks {
+		_, ipNet, _ := net.ParseCIDR(network)
+		ones, _ := ipNet.Mask.Size()
+		offset := data.Len()
+		encodeMMDB(data, records[network])
+
+		current := 0
+		for i := 0; i < ones; i++ {
+			bit := (ipNet.IP.To4()[i/8] >> uint(7-i%8)) & 1
+			if i == ones-1 {
+				nodes[current].children[bit] = dataFlag | offset
+				break
+			}
+			if nodes[current].children[bit] == empty {
+				nodes = append(nodes, node{children: [2]int{empty, empty}})
+				nodes[current].children[bit] = len(nodes) - 1
+			}
+			current = nodes[current].children[bit]
+		}
+	}
+
+	db := &bytes.Buffer{}
+	nodeCount := len(nodes)
+	for _, n := range nodes {
+		for _, child := range n.children {
+			value := nodeCount
+			if child != empty && child&dataFlag != 0 {
+				value = nodeCount + 16 + child&^dataFlag
+			} else if child != empty {
+				value = child
+			}
+			db.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+	db.Write(make([]byte, 16))
+	db.Write(data.Bytes())
+	db.WriteString("\xAB\xCD\xEFMaxMind.com")
+	encodeMMDB(db, map[string]interface{}{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(4),
+		"database_type":               "Test-City",
+		"languages":                   []interface{}{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1593561600),
+		"description":                 map[string]interface{}{"en": "Test database"},
+	})
+	return db.Bytes()
+}
+
+func encodeMMDB(buffer *bytes.Buffer, value interface{}) {
+	writeControl := func(dataType int, size int) {
+		if dataType <= 7 {
+			buffer.WriteByte(byte(dataType<<5 | size))
+		} else {
+			buffer.Write([]byte{byte(size), byte(dataType - 7)})
+		}
+	}
+	writeUint := func(dataType int, value uint64, maxBytes int) {
+		bytes := make([]byte, 8)
+		binary.BigEndian.PutUint64(bytes, value)
+		bytes = bytes[8-maxBytes:]
+		for len(bytes) > 0 && bytes[0] == 0 {
+			bytes = bytes[1:]
+		}
+		writeControl(dataType, len(bytes))
+		buffer.Write(bytes)
+	}
+
+	switch v := value.(type) {
+	case string:
+		writeControl(2, len(v))
+		buffer.WriteString(v)
+	case float64:
+		writeControl(3, 8)
+		binary.Write(buffer, binary.BigEndian, math.Float64bits(v))
+	case uint16:
+		writeUint(5, uint64(v), 2)
+	case uint32:
+		writeUint(6, uint64(v), 4)
+	case uint64:
+		writeUint(9, v, 8)
+	case map[string]interface{}:
+		writeControl(7, len(v))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			encodeMMDB(buffer, key)
+			encodeMMDB(buffer, v[key])
+		}
+	case []interface{}:
+		writeControl(11, len(v))
+		for _, item := range v {
+			encodeMMDB(buffer, item)
+		}
+	}
+}
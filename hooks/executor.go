@@ -0,0 +1,230 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// The statuses a hook can end with.
+const (
+	StatusSuccess          = "success"
+	StatusRejected         = "rejected"
+	StatusTimeout          = "timeout"
+	StatusExecutionFailure = "execution_failure"
+)
+
+// RejectError tells that a hook rejected what it was given.
+type RejectError struct {
+	Stage  Stage
+	Module string
+	Hook   string
+	NBR    openrtb.NoBidReasonCode
+}
+
+func (err *RejectError) Error() string {
+	return fmt.Sprintf("Module %s rejected the %s stage with hook %s", err.Module, err.Stage, err.Hook)
+}
+
+// FindRejectError returns the first RejectError of the list, if any.
+func FindRejectError(errs []error) *RejectError {
+	for _, err := range errs {
+		var rejectErr *RejectError
+		if errors.As(err, &rejectErr) {
+			return rejectErr
+		}
+	}
+	return nil
+}
+
+// Executor runs the hooks of an ExecutionPlan for one auction, and keeps track of what they did.
+//
+// A nil Executor runs nothing, so callers don't need to check whether hooks are enabled.
+// It may be used by several goroutines at once.
+type Executor struct {
+	plan *ExecutionPlan
+
+	mutex sync.Mutex
+	trace []openrtb_ext.ExtModulesStage
+}
+
+// ExecuteEntrypointStage runs the entrypoint hooks, and returns the request body to use from then on.
+func (e *Executor) ExecuteEntrypointStage(request *http.Request, body []byte) ([]byte, error) {
+	payload, err := e.executeStage(StageEntrypoint, "", EntrypointPayload{Request: request, Body: body},
+		func(ctx context.Context, hook interface{}, payload interface{}) (interface{}, HookResult, error) {
+			return hook.(EntrypointHook).HandleEntrypointHook(ctx, payload.(EntrypointPayload))
+		})
+	return payload.(EntrypointPayload).Body, err
+}
+
+// ExecuteRawAuctionRequestStage runs the raw auction request hooks, and returns the request body to use from then on.
+func (e *Executor) ExecuteRawAuctionRequestStage(body []byte) ([]byte, error) {
+	payload, err := e.executeStage(StageRawAuctionRequest, "", RawAuctionRequestPayload{Body: body},
+		func(ctx context.Context, hook interface{}, payload interface{}) (interface{}, HookResult, error) {
+			return hook.(RawAuctionRequestHook).HandleRawAuctionRequestHook(ctx, payload.(RawAuctionRequestPayload))
+		})
+	return payload.(RawAuctionRequestPayload).Body, err
+}
+
+// ExecuteProcessedAuctionRequestStage runs the processed auction request hooks, and returns the request to auction.
+func (e *Executor) ExecuteProcessedAuctionRequestStage(request *openrtb.BidRequest) (*openrtb.BidRequest, error) {
+	payload, err := e.executeStage(StageProcessedAuctionRequest, "", ProcessedAuctionRequestPayload{BidRequest: request},
+		func(ctx context.Context, hook interface{}, payload interface{}) (interface{}, HookResult, error) {
+			return hook.(ProcessedAuctionRequestHook).HandleProcessedAuctionRequestHook(ctx, payload.(ProcessedAuctionRequestPayload))
+		})
+	return payload.(ProcessedAuctionRequestPayload).BidRequest, err
+}
+
+// ExecuteBidderRequestStage runs the bidder request hooks, and returns the request to send to the bidder.
+func (e *Executor) ExecuteBidderRequestStage(bidder openrtb_ext.BidderName, request *openrtb.BidRequest) (*openrtb.BidRequest, error) {
+	payload, err := e.executeStage(StageBidderRequest, string(bidder), BidderRequestPayload{Bidder: bidder, BidRequest: request},
+		func(ctx context.Context, hook interface{}, payload interface{}) (interface{}, HookResult, error) {
+			return hook.(BidderRequestHook).HandleBidderRequestHook(ctx, payload.(BidderRequestPayload))
+		})
+	return payload.(BidderRequestPayload).BidRequest, err
+}
+
+// ExecuteRawBidderResponseStage runs the raw bidder response hooks, and returns the bids to keep from the bidder.
+func (e *Executor) ExecuteRawBidderResponseStage(bidder openrtb_ext.BidderName, bids []*adapters.TypedBid) ([]*adapters.TypedBid, error) {
+	payload, err := e.executeStage(StageRawBidderResponse, string(bidder), RawBidderResponsePayload{Bidder: bidder, Bids: bids},
+		func(ctx context.Context, hook interface{}, payload interface{}) (interface{}, HookResult, error) {
+			return hook.(RawBidderResponseHook).HandleRawBidderResponseHook(ctx, payload.(RawBidderResponsePayload))
+		})
+	return payload.(RawBidderResponsePayload).Bids, err
+}
+
+// ExecuteAllProcessedBidResponsesStage runs the all processed bid responses hooks, and returns the bids to auction.
+func (e *Executor) ExecuteAllProcessedBidResponsesStage(responses map[openrtb_ext.BidderName][]*adapters.TypedBid) map[openrtb_ext.BidderName][]*adapters.TypedBid {
+	payload, _ := e.executeStage(StageAllProcessedBidResponses, "", AllProcessedBidResponsesPayload{Responses: responses},
+		func(ctx context.Context, hook interface{}, payload interface{}) (interface{}, HookResult, error) {
+			return hook.(AllProcessedBidResponsesHook).HandleAllProcessedBidResponsesHook(ctx, payload.(AllProcessedBidResponsesPayload))
+		})
+	return payload.(AllProcessedBidResponsesPayload).Responses
+}
+
+// ExecuteAuctionResponseStage runs the auction response hooks, and returns the response to send.
+func (e *Executor) ExecuteAuctionResponseStage(response *openrtb.BidResponse) *openrtb.BidResponse {
+	payload, _ := e.executeStage(StageAuctionResponse, "", AuctionResponsePayload{BidResponse: response},
+		func(ctx context.Context, hook interface{}, payload interface{}) (interface{}, HookResult, error) {
+			return hook.(AuctionResponseHook).HandleAuctionResponseHook(ctx, payload.(AuctionResponsePayload))
+		})
+	return payload.(AuctionResponsePayload).BidResponse
+}
+
+// HasStage tells whether any hook runs at the stage.
+func (e *Executor) HasStage(stage Stage) bool {
+	return e != nil && len(e.plan.stages[stage]) > 0
+}
+
+// ModulesOutcome returns what the hooks did so far, in the format of bidresponse.ext.prebid.modules.
+// It returns nil if no hook ran.
+func (e *Executor) ModulesOutcome() *openrtb_ext.ExtModules {
+	if e == nil {
+		return nil
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.trace) == 0 {
+		return nil
+	}
+	trace := make([]openrtb_ext.ExtModulesStage, len(e.trace))
+	copy(trace, e.trace)
+	return &openrtb_ext.ExtModules{Trace: trace}
+}
+
+type hookCall func(ctx context.Context, hook interface{}, payload interface{}) (interface{}, HookResult, error)
+
+type hookResponse struct {
+	payload interface{}
+	result  HookResult
+	err     error
+}
+
+// executeStage runs the hooks of the stage one after the other. Each hook is given the payload returned by the
+// one before it. If a hook rejects the payload, the hooks after it don't run.
+func (e *Executor) executeStage(stage Stage, entity string, payload interface{}, call hookCall) (interface{}, error) {
+	if !e.HasStage(stage) {
+		return payload, nil
+	}
+
+	stageStart := time.Now()
+	stageOutcome := openrtb_ext.ExtModulesStage{Stage: string(stage), Entity: entity}
+	var rejectErr *RejectError
+	for _, hookStep := range e.plan.stages[stage] {
+		hookStart := time.Now()
+		response, timedOut := runHook(hookStep, payload, call)
+
+		outcome := openrtb_ext.ExtModulesHookOutcome{
+			Module:        hookStep.module,
+			Hook:          hookStep.code,
+			Errors:        response.result.Errors,
+			Warnings:      response.result.Warnings,
+			DebugMessages: response.result.DebugMessages,
+		}
+		switch {
+		case timedOut:
+			outcome.Status = StatusTimeout
+		case response.err != nil:
+			outcome.Status = StatusExecutionFailure
+			outcome.Errors = append(outcome.Errors, response.err.Error())
+		case response.result.Reject && !stage.canReject():
+			outcome.Status = StatusExecutionFailure
+			outcome.Errors = append(outcome.Errors, fmt.Sprintf("Hooks can't reject the %s stage", stage))
+		case response.result.Reject:
+			outcome.Status = StatusRejected
+			rejectErr = &RejectError{Stage: stage, Module: hookStep.module, Hook: hookStep.code, NBR: response.result.NBR}
+		default:
+			outcome.Status = StatusSuccess
+			payload = response.payload
+		}
+		outcome.ExecutionTimeMillis = int(time.Since(hookStart) / time.Millisecond)
+		stageOutcome.Outcomes = append(stageOutcome.Outcomes, outcome)
+
+		if rejectErr != nil {
+			break
+		}
+	}
+	stageOutcome.ExecutionTimeMillis = int(time.Since(stageStart) / time.Millisecond)
+
+	e.mutex.Lock()
+	e.trace = append(e.trace, stageOutcome)
+	e.mutex.Unlock()
+
+	if rejectErr != nil {
+		return payload, rejectErr
+	}
+	return payload, nil
+}
+
+// runHook runs a hook within its time budget. A hook which panics is reported as failed.
+func runHook(hookStep step, payload interface{}, call hookCall) (response hookResponse, timedOut bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), hookStep.timeout)
+	defer cancel()
+
+	// The channel is buffered so that a hook which runs out of time doesn't leak its goroutine.
+	responseChannel := make(chan hookResponse, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				responseChannel <- hookResponse{err: fmt.Errorf("hook panicked: %v", r)}
+			}
+		}()
+		newPayload, result, err := call(ctx, hookStep.hook, payload)
+		responseChannel <- hookResponse{payload: newPayload, result: result, err: err}
+	}()
+
+	select {
+	case response = <-responseChannel:
+		return response, false
+	case <-ctx.Done():
+		return hookResponse{}, true
+	}
+}
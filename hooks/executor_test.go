@@ -0,0 +1,157 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockRawAuctionRequestHook appends its suffix to the request body, unless told to behave otherwise.
+type mockRawAuctionRequestHook struct {
+	suffix string
+	result HookResult
+	err    error
+	delay  time.Duration
+	panics bool
+}
+
+func (hook mockRawAuctionRequestHook) HandleRawAuctionRequestHook(ctx context.Context, payload RawAuctionRequestPayload) (RawAuctionRequestPayload, HookResult, error) {
+	if hook.panics {
+		panic("hook failure")
+	}
+	if hook.delay > 0 {
+		time.Sleep(hook.delay)
+	}
+	return RawAuctionRequestPayload{Body: append(append([]byte{}, payload.Body...), hook.suffix...)}, hook.result, hook.err
+}
+
+type mockAuctionResponseHook struct{}
+
+func (hook mockAuctionResponseHook) HandleAuctionResponseHook(ctx context.Context, payload AuctionResponsePayload) (AuctionResponsePayload, HookResult, error) {
+	return payload, HookResult{Reject: true}, nil
+}
+
+func newTestPlan(stage Stage, hooks ...interface{}) *ExecutionPlan {
+	plan := &ExecutionPlan{stages: make(map[Stage][]step)}
+	for i, hook := range hooks {
+		plan.stages[stage] = append(plan.stages[stage], step{
+			module:  "module",
+			code:    string(rune('a' + i)),
+			hook:    hook,
+			timeout: 50 * time.Millisecond,
+		})
+	}
+	return plan
+}
+
+func TestExecuteRawAuctionRequestStage(t *testing.T) {
+	testCases := []struct {
+		description      string
+		hooks            []interface{}
+		expectedBody     string
+		expectedReject   bool
+		expectedStatuses []string
+		expectedErrors   [][]string
+	}{
+		{
+			description:      "Hooks Run In Order",
+			hooks:            []interface{}{mockRawAuctionRequestHook{suffix: "1"}, mockRawAuctionRequestHook{suffix: "2"}},
+			expectedBody:     "body12",
+			expectedStatuses: []string{StatusSuccess, StatusSuccess},
+			expectedErrors:   [][]string{nil, nil},
+		},
+		{
+			description:      "Rejection Stops The Stage",
+			hooks:            []interface{}{mockRawAuctionRequestHook{suffix: "1", result: HookResult{Reject: true, NBR: openrtb.NoBidReasonCodeInvalidRequest}}, mockRawAuctionRequestHook{suffix: "2"}},
+			expectedBody:     "body",
+			expectedReject:   true,
+			expectedStatuses: []string{StatusRejected},
+			expectedErrors:   [][]string{nil},
+		},
+		{
+			description:      "Failed Hook Is Skipped",
+			hooks:            []interface{}{mockRawAuctionRequestHook{suffix: "1", err: errors.New("failed")}, mockRawAuctionRequestHook{suffix: "2"}},
+			expectedBody:     "body2",
+			expectedStatuses: []string{StatusExecutionFailure, StatusSuccess},
+			expectedErrors:   [][]string{{"failed"}, nil},
+		},
+		{
+			description:      "Panicking Hook Is Skipped",
+			hooks:            []interface{}{mockRawAuctionRequestHook{panics: true}, mockRawAuctionRequestHook{suffix: "2"}},
+			expectedBody:     "body2",
+			expectedStatuses: []string{StatusExecutionFailure, StatusSuccess},
+			expectedErrors:   [][]string{{"hook panicked: hook failure"}, nil},
+		},
+		{
+			description:      "Slow Hook Is Skipped",
+			hooks:            []interface{}{mockRawAuctionRequestHook{suffix: "1", delay: 200 * time.Millisecond}, mockRawAuctionRequestHook{suffix: "2"}},
+			expectedBody:     "body2",
+			expectedStatuses: []string{StatusTimeout, StatusSuccess},
+			expectedErrors:   [][]string{nil, nil},
+		},
+	}
+
+	for _, test := range testCases {
+		executor := newTestPlan(StageRawAuctionRequest, test.hooks...).NewExecutor()
+
+		body, err := executor.ExecuteRawAuctionRequestStage([]byte("body"))
+
+		assert.Equal(t, test.expectedBody, string(body), test.description)
+		if test.expectedReject {
+			assert.Equal(t, &RejectError{Stage: StageRawAuctionRequest, Module: "module", Hook: "a", NBR: openrtb.NoBidReasonCodeInvalidRequest}, err, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+
+		outcome := executor.ModulesOutcome()
+		if assert.NotNil(t, outcome, test.description) && assert.Len(t, outcome.Trace, 1, test.description) {
+			var statuses []string
+			var errs [][]string
+			for _, hookOutcome := range outcome.Trace[0].Outcomes {
+				statuses = append(statuses, hookOutcome.Status)
+				errs = append(errs, hookOutcome.Errors)
+			}
+			assert.Equal(t, test.expectedStatuses, statuses, test.description)
+			assert.Equal(t, test.expectedErrors, errs, test.description)
+		}
+	}
+}
+
+func TestRejectionNotAllowed(t *testing.T) {
+	executor := newTestPlan(StageAuctionResponse, mockAuctionResponseHook{}).NewExecutor()
+	response := &openrtb.BidResponse{ID: "some-id"}
+
+	assert.Equal(t, response, executor.ExecuteAuctionResponseStage(response))
+
+	outcome := executor.ModulesOutcome()
+	expected := []openrtb_ext.ExtModulesHookOutcome{{
+		Module: "module",
+		Hook:   "a",
+		Status: StatusExecutionFailure,
+		Errors: []string{"Hooks can't reject the auction_response stage"},
+	}}
+	assert.Equal(t, expected, outcome.Trace[0].Outcomes)
+}
+
+func TestNilExecutor(t *testing.T) {
+	var executor *Executor
+
+	body, err := executor.ExecuteEntrypointStage(nil, []byte("body"))
+	assert.Equal(t, "body", string(body))
+	assert.NoError(t, err)
+	assert.False(t, executor.HasStage(StageEntrypoint))
+	assert.Nil(t, executor.ModulesOutcome())
+}
+
+func TestFindRejectError(t *testing.T) {
+	rejectErr := &RejectError{Stage: StageEntrypoint}
+
+	assert.Nil(t, FindRejectError(nil))
+	assert.Nil(t, FindRejectError([]error{errors.New("other")}))
+	assert.Equal(t, rejectErr, FindRejectError([]error{errors.New("other"), rejectErr}))
+}
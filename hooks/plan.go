@@ -0,0 +1,113 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// Module is the set of hooks a module provides, keyed by hook code.
+// Each hook implements the interface of the stages it can run at, such as EntrypointHook.
+type Module map[string]interface{}
+
+// Builder builds a module from its host configuration, which is null if the host didn't configure it.
+type Builder func(cfg json.RawMessage) (Module, error)
+
+// BuiltinModules returns the builders of the modules compiled into Prebid Server, keyed by module name.
+func BuiltinModules() map[string]Builder {
+	return map[string]Builder{}
+}
+
+// ExecutionPlan holds the hooks to run at each stage, in the order they run.
+type ExecutionPlan struct {
+	stages map[Stage][]step
+}
+
+type step struct {
+	module  string
+	code    string
+	hook    interface{}
+	timeout time.Duration
+}
+
+// NewExecutionPlan builds the modules used by the host configuration and lines up their hooks.
+//
+// If hooks aren't enabled, it returns a nil plan, which runs nothing.
+func NewExecutionPlan(cfg config.Hooks, builders map[string]Builder) (*ExecutionPlan, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	for stage := range cfg.ExecutionPlan {
+		if !isKnownStage(Stage(stage)) {
+			return nil, fmt.Errorf("hooks.execution_plan.%s is not a known stage", stage)
+		}
+	}
+
+	modules := make(map[string]Module)
+	plan := &ExecutionPlan{stages: make(map[Stage][]step, len(cfg.ExecutionPlan))}
+	for _, stage := range Stages {
+		for i, hookStep := range cfg.ExecutionPlan[string(stage)] {
+			module, ok := modules[hookStep.Module]
+			if !ok {
+				var err error
+				if module, err = buildModule(hookStep.Module, cfg.Modules[hookStep.Module], builders); err != nil {
+					return nil, err
+				}
+				modules[hookStep.Module] = module
+			}
+
+			hook, ok := module[hookStep.Hook]
+			if !ok {
+				return nil, fmt.Errorf("hooks.execution_plan.%s[%d]: module %s has no hook %s", stage, i, hookStep.Module, hookStep.Hook)
+			}
+			if !implementsStage(hook, stage) {
+				return nil, fmt.Errorf("hooks.execution_plan.%s[%d]: hook %s of module %s can't run at the %s stage", stage, i, hookStep.Hook, hookStep.Module, stage)
+			}
+
+			plan.stages[stage] = append(plan.stages[stage], step{
+				module:  hookStep.Module,
+				code:    hookStep.Hook,
+				hook:    hook,
+				timeout: time.Duration(hookStep.TimeoutMillis) * time.Millisecond,
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+func isKnownStage(stage Stage) bool {
+	for _, knownStage := range Stages {
+		if stage == knownStage {
+			return true
+		}
+	}
+	return false
+}
+
+func buildModule(name string, moduleCfg interface{}, builders map[string]Builder) (Module, error) {
+	builder, ok := builders[name]
+	if !ok {
+		return nil, fmt.Errorf("hooks: module %s is not known", name)
+	}
+	cfgJSON, err := json.Marshal(moduleCfg)
+	if err != nil {
+		return nil, fmt.Errorf("hooks: failed to read the configuration of module %s: %v", name, err)
+	}
+	module, err := builder(cfgJSON)
+	if err != nil {
+		return nil, fmt.Errorf("hooks: failed to build module %s: %v", name, err)
+	}
+	return module, nil
+}
+
+// NewExecutor returns an executor which runs the plan for one auction.
+func (plan *ExecutionPlan) NewExecutor() *Executor {
+	if plan == nil {
+		return nil
+	}
+	return &Executor{plan: plan}
+}
@@ -0,0 +1,119 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockEntrypointHook struct{}
+
+func (hook mockEntrypointHook) HandleEntrypointHook(ctx context.Context, payload EntrypointPayload) (EntrypointPayload, HookResult, error) {
+	return payload, HookResult{}, nil
+}
+
+func TestNewExecutionPlan(t *testing.T) {
+	var builtConfigs []string
+	builders := map[string]Builder{
+		"acme": func(cfg json.RawMessage) (Module, error) {
+			builtConfigs = append(builtConfigs, string(cfg))
+			return Module{"entry": mockEntrypointHook{}}, nil
+		},
+		"broken": func(cfg json.RawMessage) (Module, error) {
+			return nil, errors.New("no license")
+		},
+	}
+
+	testCases := []struct {
+		description   string
+		cfg           config.Hooks
+		expectedSteps map[Stage][]string
+		expectedError string
+	}{
+		{
+			description: "Disabled",
+			cfg: config.Hooks{
+				ExecutionPlan: map[string][]config.HookStep{"entrypoint": {{Module: "acme", Hook: "entry", TimeoutMillis: 5}}},
+			},
+		},
+		{
+			description: "Valid",
+			cfg: config.Hooks{
+				Enabled:       true,
+				Modules:       map[string]interface{}{"acme": map[string]interface{}{"key": "value"}},
+				ExecutionPlan: map[string][]config.HookStep{"entrypoint": {{Module: "acme", Hook: "entry", TimeoutMillis: 5}}},
+			},
+			expectedSteps: map[Stage][]string{StageEntrypoint: {"acme.entry"}},
+		},
+		{
+			description: "Unknown Stage",
+			cfg: config.Hooks{
+				Enabled:       true,
+				ExecutionPlan: map[string][]config.HookStep{"exitpoint": {{Module: "acme", Hook: "entry", TimeoutMillis: 5}}},
+			},
+			expectedError: "hooks.execution_plan.exitpoint is not a known stage",
+		},
+		{
+			description: "Unknown Module",
+			cfg: config.Hooks{
+				Enabled:       true,
+				ExecutionPlan: map[string][]config.HookStep{"entrypoint": {{Module: "other", Hook: "entry", TimeoutMillis: 5}}},
+			},
+			expectedError: "hooks: module other is not known",
+		},
+		{
+			description: "Module Fails To Build",
+			cfg: config.Hooks{
+				Enabled:       true,
+				ExecutionPlan: map[string][]config.HookStep{"entrypoint": {{Module: "broken", Hook: "entry", TimeoutMillis: 5}}},
+			},
+			expectedError: "hooks: failed to build module broken: no license",
+		},
+		{
+			description: "Unknown Hook",
+			cfg: config.Hooks{
+				Enabled:       true,
+				ExecutionPlan: map[string][]config.HookStep{"entrypoint": {{Module: "acme", Hook: "exit", TimeoutMillis: 5}}},
+			},
+			expectedError: "hooks.execution_plan.entrypoint[0]: module acme has no hook exit",
+		},
+		{
+			description: "Hook Doesn't Implement Stage",
+			cfg: config.Hooks{
+				Enabled:       true,
+				ExecutionPlan: map[string][]config.HookStep{"auction_response": {{Module: "acme", Hook: "entry", TimeoutMillis: 5}}},
+			},
+			expectedError: "hooks.execution_plan.auction_response[0]: hook entry of module acme can't run at the auction_response stage",
+		},
+	}
+
+	for _, test := range testCases {
+		plan, err := NewExecutionPlan(test.cfg, builders)
+
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		if test.expectedSteps == nil {
+			assert.Nil(t, plan, test.description)
+			continue
+		}
+
+		steps := make(map[Stage][]string, len(plan.stages))
+		for stage, stageSteps := range plan.stages {
+			for _, s := range stageSteps {
+				steps[stage] = append(steps[stage], s.module+"."+s.code)
+			}
+		}
+		assert.Equal(t, test.expectedSteps, steps, test.description)
+	}
+
+	if assert.NotEmpty(t, builtConfigs) {
+		assert.JSONEq(t, `{"key":"value"}`, builtConfigs[0], "The module should get its own configuration")
+	}
+}
@@ -0,0 +1,160 @@
+package hooks
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Stage names a point of the auction where hooks can run.
+type Stage string
+
+// The stages of an auction, in the order they run.
+const (
+	// StageEntrypoint runs as soon as the HTTP request arrives, before its body is parsed.
+	StageEntrypoint Stage = "entrypoint"
+	// StageRawAuctionRequest runs on the body of the request, once the stored requests are merged into it.
+	StageRawAuctionRequest Stage = "raw_auction_request"
+	// StageProcessedAuctionRequest runs on the validated request, right before the auction is held.
+	StageProcessedAuctionRequest Stage = "processed_auction_request"
+	// StageBidderRequest runs once per bidder, on the request the bidder is about to get.
+	StageBidderRequest Stage = "bidder_request"
+	// StageRawBidderResponse runs once per bidder, on the bids the bidder returned.
+	StageRawBidderResponse Stage = "raw_bidder_response"
+	// StageAllProcessedBidResponses runs on the bids of every bidder, once all of them have answered.
+	StageAllProcessedBidResponses Stage = "all_processed_bid_responses"
+	// StageAuctionResponse runs on the response, right before it's sent.
+	StageAuctionResponse Stage = "auction_response"
+)
+
+// Stages lists every stage, in the order they run.
+var Stages = []Stage{
+	StageEntrypoint,
+	StageRawAuctionRequest,
+	StageProcessedAuctionRequest,
+	StageBidderRequest,
+	StageRawBidderResponse,
+	StageAllProcessedBidResponses,
+	StageAuctionResponse,
+}
+
+// canReject tells whether the hooks of a stage may reject what they're given.
+// Once every bid is in, the auction runs to the end.
+func (stage Stage) canReject() bool {
+	return stage != StageAllProcessedBidResponses && stage != StageAuctionResponse
+}
+
+// HookResult is what a hook reports about its run.
+type HookResult struct {
+	// Reject stops the processing of what the hook was given. Depending on the stage, that's the whole
+	// auction, one bidder's request or one bidder's response.
+	Reject bool
+	// NBR is the no-bid reason sent back when the whole auction is rejected.
+	NBR openrtb.NoBidReasonCode
+
+	Errors        []string
+	Warnings      []string
+	DebugMessages []string
+}
+
+// Hooks must not modify their payload in place, since a hook which runs out of time keeps running in the
+// background. They return the payload to use from then on instead, which is ignored if they time out,
+// fail or reject.
+
+// EntrypointPayload is the payload of the entrypoint hooks.
+type EntrypointPayload struct {
+	Request *http.Request
+	Body    []byte
+}
+
+// EntrypointHook runs at StageEntrypoint.
+type EntrypointHook interface {
+	HandleEntrypointHook(ctx context.Context, payload EntrypointPayload) (EntrypointPayload, HookResult, error)
+}
+
+// RawAuctionRequestPayload is the payload of the raw auction request hooks.
+type RawAuctionRequestPayload struct {
+	Body []byte
+}
+
+// RawAuctionRequestHook runs at StageRawAuctionRequest.
+type RawAuctionRequestHook interface {
+	HandleRawAuctionRequestHook(ctx context.Context, payload RawAuctionRequestPayload) (RawAuctionRequestPayload, HookResult, error)
+}
+
+// ProcessedAuctionRequestPayload is the payload of the processed auction request hooks.
+type ProcessedAuctionRequestPayload struct {
+	BidRequest *openrtb.BidRequest
+}
+
+// ProcessedAuctionRequestHook runs at StageProcessedAuctionRequest.
+type ProcessedAuctionRequestHook interface {
+	HandleProcessedAuctionRequestHook(ctx context.Context, payload ProcessedAuctionRequestPayload) (ProcessedAuctionRequestPayload, HookResult, error)
+}
+
+// BidderRequestPayload is the payload of the bidder request hooks.
+type BidderRequestPayload struct {
+	Bidder     openrtb_ext.BidderName
+	BidRequest *openrtb.BidRequest
+}
+
+// BidderRequestHook runs at StageBidderRequest.
+type BidderRequestHook interface {
+	HandleBidderRequestHook(ctx context.Context, payload BidderRequestPayload) (BidderRequestPayload, HookResult, error)
+}
+
+// RawBidderResponsePayload is the payload of the raw bidder response hooks.
+type RawBidderResponsePayload struct {
+	Bidder openrtb_ext.BidderName
+	Bids   []*adapters.TypedBid
+}
+
+// RawBidderResponseHook runs at StageRawBidderResponse.
+type RawBidderResponseHook interface {
+	HandleRawBidderResponseHook(ctx context.Context, payload RawBidderResponsePayload) (RawBidderResponsePayload, HookResult, error)
+}
+
+// AllProcessedBidResponsesPayload is the payload of the all processed bid responses hooks.
+type AllProcessedBidResponsesPayload struct {
+	Responses map[openrtb_ext.BidderName][]*adapters.TypedBid
+}
+
+// AllProcessedBidResponsesHook runs at StageAllProcessedBidResponses.
+type AllProcessedBidResponsesHook interface {
+	HandleAllProcessedBidResponsesHook(ctx context.Context, payload AllProcessedBidResponsesPayload) (AllProcessedBidResponsesPayload, HookResult, error)
+}
+
+// AuctionResponsePayload is the payload of the auction response hooks.
+type AuctionResponsePayload struct {
+	BidResponse *openrtb.BidResponse
+}
+
+// AuctionResponseHook runs at StageAuctionResponse.
+type AuctionResponseHook interface {
+	HandleAuctionResponseHook(ctx context.Context, payload AuctionResponsePayload) (AuctionResponsePayload, HookResult, error)
+}
+
+// implementsStage tells whether a hook can run at the stage.
+func implementsStage(hook interface{}, stage Stage) bool {
+	var ok bool
+	switch stage {
+	case StageEntrypoint:
+		_, ok = hook.(EntrypointHook)
+	case StageRawAuctionRequest:
+		_, ok = hook.(RawAuctionRequestHook)
+	case StageProcessedAuctionRequest:
+		_, ok = hook.(ProcessedAuctionRequestHook)
+	case StageBidderRequest:
+		_, ok = hook.(BidderRequestHook)
+	case StageRawBidderResponse:
+		_, ok = hook.(RawBidderResponseHook)
+	case StageAllProcessedBidResponses:
+		_, ok = hook.(AllProcessedBidResponsesHook)
+	case StageAuctionResponse:
+		_, ok = hook.(AuctionResponseHook)
+	}
+	return ok
+}
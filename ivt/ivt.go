@@ -0,0 +1,200 @@
+package ivt
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+// Detection is an invalid traffic check which a request failed, and the action taken on it.
+type Detection struct {
+	Check  metrics.InvalidTrafficCheck `json:"check"`
+	Action config.InvalidTrafficAction `json:"action"`
+}
+
+// Result is what the Filter found out about a request.
+type Result struct {
+	// Detections are the checks the request failed. They're empty if the request looks valid.
+	Detections []Detection
+	// SkippedBidders are the bidders which mustn't be called, because the request was flagged.
+	SkippedBidders map[string]struct{}
+}
+
+// Rejected returns true if the request failed a check whose action is reject.
+func (r Result) Rejected() bool {
+	for _, detection := range r.Detections {
+		if detection.Action == config.InvalidTrafficReject {
+			return true
+		}
+	}
+	return false
+}
+
+// Error returns the error to write back for a rejected request.
+func (r Result) Error() error {
+	checks := make([]string, 0, len(r.Detections))
+	for _, detection := range r.Detections {
+		if detection.Action == config.InvalidTrafficReject {
+			checks = append(checks, string(detection.Check))
+		}
+	}
+	return &errortypes.InvalidTraffic{
+		Message: fmt.Sprintf("request rejected as invalid traffic: failed %s", strings.Join(checks, ", ")),
+	}
+}
+
+// Filter runs the invalid traffic checks on the requests of the auction endpoints. It's threadsafe.
+type Filter struct {
+	botUserAgentAction config.InvalidTrafficAction
+	botUserAgents      []*regexp.Regexp
+	dataCenterIPAction config.InvalidTrafficAction
+	dataCenters        []*net.IPNet
+	invalidIPAction    config.InvalidTrafficAction
+	skippedBidders     map[string]struct{}
+}
+
+// NewFilter builds the filter of the config. It returns nil if the filter is disabled.
+func NewFilter(cfg config.InvalidTraffic) (*Filter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	filter := &Filter{
+		botUserAgentAction: cfg.BotUserAgentAction,
+		botUserAgents:      make([]*regexp.Regexp, 0, len(cfg.BotUserAgentPatterns)),
+		dataCenterIPAction: cfg.DataCenterIPAction,
+		invalidIPAction:    cfg.InvalidIPAction,
+		skippedBidders:     make(map[string]struct{}, len(cfg.FlaggedSkipBidders)),
+	}
+	for i, pattern := range cfg.BotUserAgentPatterns {
+		botUserAgent, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid_traffic.bot_user_agent_patterns[%d] is not a valid regular expression: %v", i, err)
+		}
+		filter.botUserAgents = append(filter.botUserAgents, botUserAgent)
+	}
+	if isEnabled(cfg.DataCenterIPAction) {
+		dataCenters, err := loadNetworks(cfg.DataCenterCIDRFile)
+		if err != nil {
+			return nil, err
+		}
+		filter.dataCenters = dataCenters
+	}
+	for _, bidder := range cfg.FlaggedSkipBidders {
+		filter.skippedBidders[bidder] = struct{}{}
+	}
+	return filter, nil
+}
+
+// loadNetworks reads a file with a CIDR block on each line. Empty lines and lines starting with # are ignored.
+func loadNetworks(path string) ([]*net.IPNet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var networks []*net.IPNet
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		_, network, err := net.ParseCIDR(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d is not a CIDR block: %s", path, lineNumber, line)
+		}
+		networks = append(networks, network)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return networks, nil
+}
+
+// Check runs the checks on the request. The filter may be nil, in which case the request passes.
+func (f *Filter) Check(req *openrtb.BidRequest) Result {
+	result := Result{}
+	if f == nil {
+		return result
+	}
+
+	var ua string
+	var ip net.IP
+	validIP := false
+	if req.Device != nil {
+		ua = req.Device.UA
+		ip, validIP = parseDeviceIP(req.Device)
+	}
+
+	if isEnabled(f.botUserAgentAction) && ua != "" && f.isBotUserAgent(ua) {
+		result.add(metrics.InvalidTrafficBotUserAgent, f.botUserAgentAction)
+	}
+	if isEnabled(f.dataCenterIPAction) && validIP && f.isDataCenterIP(ip) {
+		result.add(metrics.InvalidTrafficDataCenterIP, f.dataCenterIPAction)
+	}
+	if isEnabled(f.invalidIPAction) && !validIP {
+		result.add(metrics.InvalidTrafficInvalidIP, f.invalidIPAction)
+	}
+
+	if len(result.Detections) > 0 && !result.Rejected() && len(f.skippedBidders) > 0 {
+		result.SkippedBidders = f.skippedBidders
+	}
+	return result
+}
+
+func (r *Result) add(check metrics.InvalidTrafficCheck, action config.InvalidTrafficAction) {
+	r.Detections = append(r.Detections, Detection{Check: check, Action: action})
+}
+
+func (f *Filter) isBotUserAgent(ua string) bool {
+	for _, botUserAgent := range f.botUserAgents {
+		if botUserAgent.MatchString(ua) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) isDataCenterIP(ip net.IP) bool {
+	for _, dataCenter := range f.dataCenters {
+		if dataCenter.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDeviceIP returns the IP address of the device, and whether it's one a real device could have.
+// device.ip must be an IPv4 address and device.ipv6 an IPv6 one, and the first one which is set is used.
+func parseDeviceIP(device *openrtb.Device) (net.IP, bool) {
+	var ip net.IP
+	if device.IP != "" {
+		if ip = net.ParseIP(device.IP); ip == nil || ip.To4() == nil {
+			return nil, false
+		}
+	} else if device.IPv6 != "" {
+		if ip = net.ParseIP(device.IPv6); ip == nil || ip.To4() != nil {
+			return nil, false
+		}
+	} else {
+		return nil, false
+	}
+	if ip.IsUnspecified() || ip.IsLoopback() || ip.IsMulticast() {
+		return nil, false
+	}
+	return ip, true
+}
+
+func isEnabled(action config.InvalidTrafficAction) bool {
+	return action == config.InvalidTrafficFlag || action == config.InvalidTrafficReject
+}
@@ -0,0 +1,179 @@
+package ivt
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+const browserUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/84.0.4147.105 Safari/537.36"
+
+func TestCheck(t *testing.T) {
+	cidrFile := writeCIDRFile(t, "# AWS\n3.0.0.0/15\n\n2600:1f00::/24\n")
+	defer os.Remove(cidrFile)
+
+	filter, err := NewFilter(config.InvalidTraffic{
+		Enabled:              true,
+		BotUserAgentAction:   config.InvalidTrafficReject,
+		BotUserAgentPatterns: []string{`bot\b`, "crawl"},
+		DataCenterIPAction:   config.InvalidTrafficFlag,
+		DataCenterCIDRFile:   cidrFile,
+		InvalidIPAction:      config.InvalidTrafficFlag,
+		FlaggedSkipBidders:   []string{"appnexus"},
+	})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		description    string
+		device         *openrtb.Device
+		wantDetections []Detection
+		wantRejected   bool
+		wantSkipped    map[string]struct{}
+	}{
+		{
+			description: "Valid",
+			device:      &openrtb.Device{UA: browserUA, IP: "1.2.3.4"},
+		},
+		{
+			description:    "Bot user agent",
+			device:         &openrtb.Device{UA: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", IP: "1.2.3.4"},
+			wantDetections: []Detection{{Check: metrics.InvalidTrafficBotUserAgent, Action: config.InvalidTrafficReject}},
+			wantRejected:   true,
+		},
+		{
+			description:    "Bot user agent patterns are case insensitive",
+			device:         &openrtb.Device{UA: "Some-CRAWLER/1.0", IP: "1.2.3.4"},
+			wantDetections: []Detection{{Check: metrics.InvalidTrafficBotUserAgent, Action: config.InvalidTrafficReject}},
+			wantRejected:   true,
+		},
+		{
+			description:    "Data center IPv4",
+			device:         &openrtb.Device{UA: browserUA, IP: "3.1.2.3"},
+			wantDetections: []Detection{{Check: metrics.InvalidTrafficDataCenterIP, Action: config.InvalidTrafficFlag}},
+			wantSkipped:    map[string]struct{}{"appnexus": {}},
+		},
+		{
+			description:    "Data center IPv6",
+			device:         &openrtb.Device{UA: browserUA, IPv6: "2600:1f00::1"},
+			wantDetections: []Detection{{Check: metrics.InvalidTrafficDataCenterIP, Action: config.InvalidTrafficFlag}},
+			wantSkipped:    map[string]struct{}{"appnexus": {}},
+		},
+		{
+			description:    "No device",
+			wantDetections: []Detection{{Check: metrics.InvalidTrafficInvalidIP, Action: config.InvalidTrafficFlag}},
+			wantSkipped:    map[string]struct{}{"appnexus": {}},
+		},
+		{
+			description:    "Unparsable IP",
+			device:         &openrtb.Device{UA: browserUA, IP: "1.2.3"},
+			wantDetections: []Detection{{Check: metrics.InvalidTrafficInvalidIP, Action: config.InvalidTrafficFlag}},
+			wantSkipped:    map[string]struct{}{"appnexus": {}},
+		},
+		{
+			description:    "IPv6 address in device.ip",
+			device:         &openrtb.Device{UA: browserUA, IP: "2001:db8::1"},
+			wantDetections: []Detection{{Check: metrics.InvalidTrafficInvalidIP, Action: config.InvalidTrafficFlag}},
+			wantSkipped:    map[string]struct{}{"appnexus": {}},
+		},
+		{
+			description:    "Loopback IP",
+			device:         &openrtb.Device{UA: browserUA, IP: "127.0.0.1"},
+			wantDetections: []Detection{{Check: metrics.InvalidTrafficInvalidIP, Action: config.InvalidTrafficFlag}},
+			wantSkipped:    map[string]struct{}{"appnexus": {}},
+		},
+		{
+			description: "Bidders aren't skipped for a rejected request",
+			device:      &openrtb.Device{UA: "AhrefsBot/7.0", IP: "3.1.2.3"},
+			wantDetections: []Detection{
+				{Check: metrics.InvalidTrafficBotUserAgent, Action: config.InvalidTrafficReject},
+				{Check: metrics.InvalidTrafficDataCenterIP, Action: config.InvalidTrafficFlag},
+			},
+			wantRejected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		result := filter.Check(&openrtb.BidRequest{Device: test.device})
+		assert.Equal(t, test.wantDetections, result.Detections, test.description+":detections")
+		assert.Equal(t, test.wantRejected, result.Rejected(), test.description+":rejected")
+		assert.Equal(t, test.wantSkipped, result.SkippedBidders, test.description+":skipped")
+	}
+}
+
+func TestSkippedChecks(t *testing.T) {
+	filter, err := NewFilter(config.InvalidTraffic{
+		Enabled:              true,
+		BotUserAgentAction:   config.InvalidTrafficSkip,
+		BotUserAgentPatterns: []string{"bot"},
+		DataCenterIPAction:   config.InvalidTrafficSkip,
+		DataCenterCIDRFile:   "/does/not/exist",
+	})
+	assert.NoError(t, err, "The CIDR file shouldn't be loaded if the check is skipped")
+
+	result := filter.Check(&openrtb.BidRequest{Device: &openrtb.Device{UA: "bot"}})
+	assert.Empty(t, result.Detections)
+}
+
+func TestDisabled(t *testing.T) {
+	filter, err := NewFilter(config.InvalidTraffic{Enabled: false, DataCenterIPAction: config.InvalidTrafficFlag})
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+
+	result := filter.Check(&openrtb.BidRequest{})
+	assert.Empty(t, result.Detections)
+	assert.False(t, result.Rejected())
+}
+
+func TestError(t *testing.T) {
+	result := Result{Detections: []Detection{
+		{Check: metrics.InvalidTrafficBotUserAgent, Action: config.InvalidTrafficReject},
+		{Check: metrics.InvalidTrafficDataCenterIP, Action: config.InvalidTrafficFlag},
+		{Check: metrics.InvalidTrafficInvalidIP, Action: config.InvalidTrafficReject},
+	}}
+	err := result.Error()
+	assert.Equal(t, "request rejected as invalid traffic: failed bot_user_agent, invalid_ip", err.Error())
+	assert.Equal(t, errortypes.InvalidTrafficErrorCode, errortypes.ReadCode(err))
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := NewFilter(config.InvalidTraffic{
+		Enabled:              true,
+		BotUserAgentAction:   config.InvalidTrafficReject,
+		BotUserAgentPatterns: []string{"bot("},
+	})
+	assert.Error(t, err)
+
+	_, err = NewFilter(config.InvalidTraffic{
+		Enabled:            true,
+		DataCenterIPAction: config.InvalidTrafficFlag,
+		DataCenterCIDRFile: "/does/not/exist",
+	})
+	assert.Error(t, err)
+
+	cidrFile := writeCIDRFile(t, "3.0.0.0/15\n3.0.0.0\n")
+	defer os.Remove(cidrFile)
+	_, err = NewFilter(config.InvalidTraffic{
+		Enabled:            true,
+		DataCenterIPAction: config.InvalidTrafficFlag,
+		DataCenterCIDRFile: cidrFile,
+	})
+	assert.EqualError(t, err, cidrFile+":2 is not a CIDR block: 3.0.0.0")
+}
+
+func writeCIDRFile(t *testing.T, contents string) string {
+	file, err := ioutil.TempFile("", "datacenters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
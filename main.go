@@ -53,8 +53,7 @@ func loadConfig() (*config.Configuration, error) {
 
 func serve(revision string, cfg *config.Configuration) error {
 	fetchingInterval := time.Duration(cfg.CurrencyConverter.FetchIntervalSeconds) * time.Second
-	staleRatesThreshold := time.Duration(cfg.CurrencyConverter.StaleRatesSeconds) * time.Second
-	currencyConverter := currency.NewRateConverter(&http.Client{}, cfg.CurrencyConverter.FetchURL, staleRatesThreshold)
+	currencyConverter := newCurrencyConverter(cfg.CurrencyConverter)
 
 	currencyConverterTickerTask := task.NewTickerTask(fetchingInterval, currencyConverter)
 	currencyConverterTickerTask.Start()
@@ -67,8 +66,30 @@ func serve(revision string, cfg *config.Configuration) error {
 	pbc.InitPrebidCache(cfg.CacheURL.GetBaseURL())
 
 	corsRouter := router.SupportCORS(r)
-	server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(revision, currencyConverter, fetchingInterval), r.MetricsEngine)
+	server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(revision, currencyConverter, fetchingInterval, r.BidderCircuitBreakers), r.MetricsEngine)
 
 	r.Shutdown()
 	return nil
 }
+
+func newCurrencyConverter(cfg config.CurrencyConverter) *currency.RateConverter {
+	if len(cfg.Sources) == 0 {
+		staleRatesThreshold := time.Duration(cfg.StaleRatesSeconds) * time.Second
+		return currency.NewRateConverter(&http.Client{}, cfg.FetchURL, staleRatesThreshold)
+	}
+
+	sources := make([]currency.Source, 0, len(cfg.Sources))
+	for _, sourceCfg := range cfg.Sources {
+		source := currency.Source{StaleRatesThreshold: time.Duration(sourceCfg.StaleRatesSeconds) * time.Second}
+		switch sourceCfg.Type {
+		case config.CurrencyRateSourceJSON:
+			source.RateSource = currency.NewJSONRateSource(&http.Client{}, sourceCfg.URL)
+		case config.CurrencyRateSourceECB:
+			source.RateSource = currency.NewECBRateSource(&http.Client{}, sourceCfg.URL)
+		case config.CurrencyRateSourceFile:
+			source.RateSource = currency.NewFileRateSource(sourceCfg.Path)
+		}
+		sources = append(sources, source)
+	}
+	return currency.NewRateConverterFromSources(sources)
+}
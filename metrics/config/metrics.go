@@ -125,6 +125,27 @@ func (me *MultiMetricsEngine) RecordAdapterPanic(labels metrics.AdapterLabels) {
 	}
 }
 
+// RecordAdapterInvalidCreative across all engines
+func (me *MultiMetricsEngine) RecordAdapterInvalidCreative(adapter openrtb_ext.BidderName, validation metrics.CreativeValidation, rejected bool) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterInvalidCreative(adapter, validation, rejected)
+	}
+}
+
+// RecordAdapterBlockedBid across all engines
+func (me *MultiMetricsEngine) RecordAdapterBlockedBid(adapter openrtb_ext.BidderName, blocklist metrics.Blocklist) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterBlockedBid(adapter, blocklist)
+	}
+}
+
+// RecordAdapterNonBid across all engines
+func (me *MultiMetricsEngine) RecordAdapterNonBid(adapter openrtb_ext.BidderName, reason openrtb_ext.NonBidReason) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterNonBid(adapter, reason)
+	}
+}
+
 // RecordAdapterRequest across all engines
 func (me *MultiMetricsEngine) RecordAdapterRequest(labels metrics.AdapterLabels) {
 	for _, thisME := range *me {
@@ -244,6 +265,34 @@ func (me *MultiMetricsEngine) RecordRequestPrivacy(privacy metrics.PrivacyLabels
 	}
 }
 
+// RecordGeoLookup across all engines
+func (me *MultiMetricsEngine) RecordGeoLookup(result metrics.GeoLookupResult) {
+	for _, thisME := range *me {
+		thisME.RecordGeoLookup(result)
+	}
+}
+
+// RecordInvalidTraffic across all engines
+func (me *MultiMetricsEngine) RecordInvalidTraffic(check metrics.InvalidTrafficCheck, rejected bool) {
+	for _, thisME := range *me {
+		thisME.RecordInvalidTraffic(check, rejected)
+	}
+}
+
+// RecordVendorListFetch across all engines
+func (me *MultiMetricsEngine) RecordVendorListFetch(success bool) {
+	for _, thisME := range *me {
+		thisME.RecordVendorListFetch(success)
+	}
+}
+
+// RecordVendorListCacheSize across all engines
+func (me *MultiMetricsEngine) RecordVendorListCacheSize(size int) {
+	for _, thisME := range *me {
+		thisME.RecordVendorListCacheSize(size)
+	}
+}
+
 // DummyMetricsEngine is a Noop metrics engine in case no metrics are configured. (may also be useful for tests)
 type DummyMetricsEngine struct{}
 
@@ -283,6 +332,18 @@ func (me *DummyMetricsEngine) RecordStoredDataError(labels metrics.StoredDataLab
 func (me *DummyMetricsEngine) RecordAdapterPanic(labels metrics.AdapterLabels) {
 }
 
+// RecordAdapterInvalidCreative as a noop
+func (me *DummyMetricsEngine) RecordAdapterInvalidCreative(adapter openrtb_ext.BidderName, validation metrics.CreativeValidation, rejected bool) {
+}
+
+// RecordAdapterBlockedBid as a noop
+func (me *DummyMetricsEngine) RecordAdapterBlockedBid(adapter openrtb_ext.BidderName, blocklist metrics.Blocklist) {
+}
+
+// RecordAdapterNonBid as a noop
+func (me *DummyMetricsEngine) RecordAdapterNonBid(adapter openrtb_ext.BidderName, reason openrtb_ext.NonBidReason) {
+}
+
 // RecordAdapterRequest as a noop
 func (me *DummyMetricsEngine) RecordAdapterRequest(labels metrics.AdapterLabels) {
 }
@@ -350,3 +411,19 @@ func (me *DummyMetricsEngine) RecordTimeoutNotice(success bool) {
 // RecordRequestPrivacy as a noop
 func (me *DummyMetricsEngine) RecordRequestPrivacy(privacy metrics.PrivacyLabels) {
 }
+
+// RecordGeoLookup as a noop
+func (me *DummyMetricsEngine) RecordGeoLookup(result metrics.GeoLookupResult) {
+}
+
+// RecordInvalidTraffic as a noop
+func (me *DummyMetricsEngine) RecordInvalidTraffic(check metrics.InvalidTrafficCheck, rejected bool) {
+}
+
+// RecordVendorListFetch as a noop
+func (me *DummyMetricsEngine) RecordVendorListFetch(success bool) {
+}
+
+// RecordVendorListCacheSize as a noop
+func (me *DummyMetricsEngine) RecordVendorListCacheSize(size int) {
+}
@@ -35,15 +35,18 @@ type Metrics struct {
 
 	// Metrics for OpenRTB requests specifically. So we can track what % of RequestsMeter are OpenRTB
 	// and know when legacy requests have been abandoned.
-	RequestStatuses       map[RequestType]map[RequestStatus]metrics.Meter
-	AmpNoCookieMeter      metrics.Meter
-	CookieSyncMeter       metrics.Meter
-	CookieSyncGen         map[openrtb_ext.BidderName]metrics.Meter
-	CookieSyncGDPRPrevent map[openrtb_ext.BidderName]metrics.Meter
-	userSyncOptout        metrics.Meter
-	userSyncBadRequest    metrics.Meter
-	userSyncSet           map[openrtb_ext.BidderName]metrics.Meter
-	userSyncGDPRPrevent   map[openrtb_ext.BidderName]metrics.Meter
+	RequestStatuses         map[RequestType]map[RequestStatus]metrics.Meter
+	AmpNoCookieMeter        metrics.Meter
+	CookieSyncMeter         metrics.Meter
+	CookieSyncGen           map[openrtb_ext.BidderName]metrics.Meter
+	CookieSyncGDPRPrevent   map[openrtb_ext.BidderName]metrics.Meter
+	userSyncOptout          metrics.Meter
+	userSyncBadRequest      metrics.Meter
+	userSyncSet             map[openrtb_ext.BidderName]metrics.Meter
+	userSyncGDPRPrevent     map[openrtb_ext.BidderName]metrics.Meter
+	userSyncCOPPAPrevent    map[openrtb_ext.BidderName]metrics.Meter
+	userSyncCCPAPrevent     map[openrtb_ext.BidderName]metrics.Meter
+	userSyncActivityPrevent map[openrtb_ext.BidderName]metrics.Meter
 
 	// Media types found in the "imp" JSON object
 	ImpsTypeBanner metrics.Meter
@@ -62,6 +65,15 @@ type Metrics struct {
 	PrivacyLMTRequest        metrics.Meter
 	PrivacyTCFRequestVersion map[TCFVersionValue]metrics.Meter
 
+	GeoLookups map[GeoLookupResult]metrics.Meter
+
+	InvalidTraffic map[InvalidTrafficCheck]*InvalidTrafficMetrics
+
+	// GDPR vendor list metrics
+	VendorListFetchSuccess metrics.Meter
+	VendorListFetchFailure metrics.Meter
+	VendorListCacheSize    metrics.Gauge
+
 	AdapterMetrics map[openrtb_ext.BidderName]*AdapterMetrics
 	// Don't export accountMetrics because we need helper functions here to insure its properly populated dynamically
 	accountMetrics        map[string]*accountMetrics
@@ -83,12 +95,25 @@ type AdapterMetrics struct {
 	PriceHistogram    metrics.Histogram
 	BidsReceivedMeter metrics.Meter
 	PanicMeter        metrics.Meter
+	NonBidMeters      map[openrtb_ext.NonBidReason]metrics.Meter
+	BlockedBidMeters  map[Blocklist]metrics.Meter
+	InvalidCreatives  map[CreativeValidation]*InvalidCreativeMetrics
 	MarkupMetrics     map[openrtb_ext.BidType]*MarkupDeliveryMetrics
 	ConnCreated       metrics.Counter
 	ConnReused        metrics.Counter
 	ConnWaitTime      metrics.Timer
 }
 
+type InvalidTrafficMetrics struct {
+	FlaggedMeter  metrics.Meter
+	RejectedMeter metrics.Meter
+}
+
+type InvalidCreativeMetrics struct {
+	WarnedMeter   metrics.Meter
+	RejectedMeter metrics.Meter
+}
+
 type MarkupDeliveryMetrics struct {
 	AdmMeter  metrics.Meter
 	NurlMeter metrics.Meter
@@ -145,6 +170,9 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderNa
 		userSyncBadRequest:             blankMeter,
 		userSyncSet:                    make(map[openrtb_ext.BidderName]metrics.Meter),
 		userSyncGDPRPrevent:            make(map[openrtb_ext.BidderName]metrics.Meter),
+		userSyncCOPPAPrevent:           make(map[openrtb_ext.BidderName]metrics.Meter),
+		userSyncCCPAPrevent:            make(map[openrtb_ext.BidderName]metrics.Meter),
+		userSyncActivityPrevent:        make(map[openrtb_ext.BidderName]metrics.Meter),
 
 		ImpsTypeBanner: blankMeter,
 		ImpsTypeVideo:  blankMeter,
@@ -160,6 +188,14 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderNa
 		PrivacyLMTRequest:        blankMeter,
 		PrivacyTCFRequestVersion: make(map[TCFVersionValue]metrics.Meter, len(TCFVersions())),
 
+		GeoLookups: make(map[GeoLookupResult]metrics.Meter, len(GeoLookupResults())),
+
+		InvalidTraffic: make(map[InvalidTrafficCheck]*InvalidTrafficMetrics, len(InvalidTrafficChecks())),
+
+		VendorListFetchSuccess: blankMeter,
+		VendorListFetchFailure: blankMeter,
+		VendorListCacheSize:    &metrics.NilGauge{},
+
 		AdapterMetrics:  make(map[openrtb_ext.BidderName]*AdapterMetrics, len(exchanges)),
 		accountMetrics:  make(map[string]*accountMetrics),
 		MetricsDisabled: disabledMetrics,
@@ -188,6 +224,17 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderNa
 		newMetrics.PrivacyTCFRequestVersion[v] = blankMeter
 	}
 
+	for _, result := range GeoLookupResults() {
+		newMetrics.GeoLookups[result] = blankMeter
+	}
+
+	for _, check := range InvalidTrafficChecks() {
+		newMetrics.InvalidTraffic[check] = &InvalidTrafficMetrics{
+			FlaggedMeter:  blankMeter,
+			RejectedMeter: blankMeter,
+		}
+	}
+
 	for _, dt := range StoredDataTypes() {
 		newMetrics.StoredDataFetchTimer[dt] = make(map[StoredDataFetchType]metrics.Timer)
 		newMetrics.StoredDataErrorMeter[dt] = make(map[StoredDataError]metrics.Meter)
@@ -253,6 +300,9 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 		newMetrics.CookieSyncGDPRPrevent[a] = metrics.GetOrRegisterMeter(fmt.Sprintf("cookie_sync.%s.gdpr_prevent", string(a)), registry)
 		newMetrics.userSyncSet[a] = metrics.GetOrRegisterMeter(fmt.Sprintf("usersync.%s.sets", string(a)), registry)
 		newMetrics.userSyncGDPRPrevent[a] = metrics.GetOrRegisterMeter(fmt.Sprintf("usersync.%s.gdpr_prevent", string(a)), registry)
+		newMetrics.userSyncCOPPAPrevent[a] = metrics.GetOrRegisterMeter(fmt.Sprintf("usersync.%s.coppa_prevent", string(a)), registry)
+		newMetrics.userSyncCCPAPrevent[a] = metrics.GetOrRegisterMeter(fmt.Sprintf("usersync.%s.ccpa_prevent", string(a)), registry)
+		newMetrics.userSyncActivityPrevent[a] = metrics.GetOrRegisterMeter(fmt.Sprintf("usersync.%s.activity_prevent", string(a)), registry)
 		registerAdapterMetrics(registry, "adapter", string(a), newMetrics.AdapterMetrics[a])
 	}
 	for typ, statusMap := range newMetrics.RequestStatuses {
@@ -272,6 +322,9 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 
 	newMetrics.userSyncSet[unknownBidder] = metrics.GetOrRegisterMeter("usersync.unknown.sets", registry)
 	newMetrics.userSyncGDPRPrevent[unknownBidder] = metrics.GetOrRegisterMeter("usersync.unknown.gdpr_prevent", registry)
+	newMetrics.userSyncCOPPAPrevent[unknownBidder] = metrics.GetOrRegisterMeter("usersync.unknown.coppa_prevent", registry)
+	newMetrics.userSyncCCPAPrevent[unknownBidder] = metrics.GetOrRegisterMeter("usersync.unknown.ccpa_prevent", registry)
+	newMetrics.userSyncActivityPrevent[unknownBidder] = metrics.GetOrRegisterMeter("usersync.unknown.activity_prevent", registry)
 
 	newMetrics.TimeoutNotificationSuccess = metrics.GetOrRegisterMeter("timeout_notification.ok", registry)
 	newMetrics.TimeoutNotificationFailure = metrics.GetOrRegisterMeter("timeout_notification.failed", registry)
@@ -284,6 +337,21 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 		newMetrics.PrivacyTCFRequestVersion[version] = metrics.GetOrRegisterMeter(fmt.Sprintf("privacy.request.tcf.%s", string(version)), registry)
 	}
 
+	for _, result := range GeoLookupResults() {
+		newMetrics.GeoLookups[result] = metrics.GetOrRegisterMeter(fmt.Sprintf("geo_lookups.%s", string(result)), registry)
+	}
+
+	for _, check := range InvalidTrafficChecks() {
+		newMetrics.InvalidTraffic[check] = &InvalidTrafficMetrics{
+			FlaggedMeter:  metrics.GetOrRegisterMeter(fmt.Sprintf("invalid_traffic.%s.flagged", string(check)), registry),
+			RejectedMeter: metrics.GetOrRegisterMeter(fmt.Sprintf("invalid_traffic.%s.rejected", string(check)), registry),
+		}
+	}
+
+	newMetrics.VendorListFetchSuccess = metrics.GetOrRegisterMeter("gdpr.vendorlist.fetch.ok", registry)
+	newMetrics.VendorListFetchFailure = metrics.GetOrRegisterMeter("gdpr.vendorlist.fetch.failed", registry)
+	newMetrics.VendorListCacheSize = metrics.GetOrRegisterGauge("gdpr.vendorlist.cache_size", registry)
+
 	return newMetrics
 }
 
@@ -299,6 +367,9 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 		PriceHistogram:    &metrics.NilHistogram{},
 		BidsReceivedMeter: blankMeter,
 		PanicMeter:        blankMeter,
+		NonBidMeters:      make(map[openrtb_ext.NonBidReason]metrics.Meter),
+		BlockedBidMeters:  make(map[Blocklist]metrics.Meter),
+		InvalidCreatives:  make(map[CreativeValidation]*InvalidCreativeMetrics),
 		MarkupMetrics:     makeBlankBidMarkupMetrics(),
 	}
 	if !disabledMetrics.AdapterConnectionMetrics {
@@ -309,6 +380,18 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 	for _, err := range AdapterErrors() {
 		newAdapter.ErrorMeters[err] = blankMeter
 	}
+	for _, reason := range openrtb_ext.NonBidReasons() {
+		newAdapter.NonBidMeters[reason] = blankMeter
+	}
+	for _, blocklist := range Blocklists() {
+		newAdapter.BlockedBidMeters[blocklist] = blankMeter
+	}
+	for _, validation := range CreativeValidations() {
+		newAdapter.InvalidCreatives[validation] = &InvalidCreativeMetrics{
+			WarnedMeter:   blankMeter,
+			RejectedMeter: blankMeter,
+		}
+	}
 	return newAdapter
 }
 
@@ -350,6 +433,18 @@ func registerAdapterMetrics(registry metrics.Registry, adapterOrAccount string,
 		am.BidsReceivedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.bids_received", adapterOrAccount, exchange), registry)
 	}
 	am.PanicMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.requests.panic", adapterOrAccount, exchange), registry)
+	for reason := range am.NonBidMeters {
+		am.NonBidMeters[reason] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.nonbids.%s", adapterOrAccount, exchange, reason), registry)
+	}
+	for blocklist := range am.BlockedBidMeters {
+		am.BlockedBidMeters[blocklist] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.blocked_bids.%s", adapterOrAccount, exchange, blocklist), registry)
+	}
+	for validation := range am.InvalidCreatives {
+		am.InvalidCreatives[validation] = &InvalidCreativeMetrics{
+			WarnedMeter:   metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.invalid_creatives.%s.warned", adapterOrAccount, exchange, validation), registry),
+			RejectedMeter: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.invalid_creatives.%s.rejected", adapterOrAccount, exchange, validation), registry),
+		}
+	}
 }
 
 func makeDeliveryMetrics(registry metrics.Registry, prefix string, bidType openrtb_ext.BidType) *MarkupDeliveryMetrics {
@@ -485,6 +580,46 @@ func (me *Metrics) RecordAdapterPanic(labels AdapterLabels) {
 	am.PanicMeter.Mark(1)
 }
 
+// RecordAdapterBlockedBid implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterBlockedBid(adapter openrtb_ext.BidderName, blocklist Blocklist) {
+	am, ok := me.AdapterMetrics[adapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter metrics on %s: adapter metrics not found", string(adapter))
+		return
+	}
+	if meter, ok := am.BlockedBidMeters[blocklist]; ok {
+		meter.Mark(1)
+	}
+}
+
+// RecordAdapterInvalidCreative implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterInvalidCreative(adapter openrtb_ext.BidderName, validation CreativeValidation, rejected bool) {
+	am, ok := me.AdapterMetrics[adapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter metrics on %s: adapter metrics not found", string(adapter))
+		return
+	}
+	if validationMetrics, ok := am.InvalidCreatives[validation]; ok {
+		if rejected {
+			validationMetrics.RejectedMeter.Mark(1)
+		} else {
+			validationMetrics.WarnedMeter.Mark(1)
+		}
+	}
+}
+
+// RecordAdapterNonBid implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterNonBid(adapter openrtb_ext.BidderName, reason openrtb_ext.NonBidReason) {
+	am, ok := me.AdapterMetrics[adapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter metrics on %s: adapter metrics not found", string(adapter))
+		return
+	}
+	if meter, ok := am.NonBidMeters[reason]; ok {
+		meter.Mark(1)
+	}
+}
+
 // RecordAdapterRequest implements a part of the MetricsEngine interface
 func (me *Metrics) RecordAdapterRequest(labels AdapterLabels) {
 	am, ok := me.AdapterMetrics[labels.Adapter]
@@ -631,6 +766,12 @@ func (me *Metrics) RecordUserIDSet(userLabels UserLabels) {
 		doMark(userLabels.Bidder, me.userSyncSet)
 	case RequestActionGDPR:
 		doMark(userLabels.Bidder, me.userSyncGDPRPrevent)
+	case RequestActionCOPPA:
+		doMark(userLabels.Bidder, me.userSyncCOPPAPrevent)
+	case RequestActionCCPA:
+		doMark(userLabels.Bidder, me.userSyncCCPAPrevent)
+	case RequestActionActivity:
+		doMark(userLabels.Bidder, me.userSyncActivityPrevent)
 	}
 }
 
@@ -704,6 +845,34 @@ func (me *Metrics) RecordRequestPrivacy(privacy PrivacyLabels) {
 	return
 }
 
+func (me *Metrics) RecordGeoLookup(result GeoLookupResult) {
+	me.GeoLookups[result].Mark(1)
+}
+
+func (me *Metrics) RecordInvalidTraffic(check InvalidTrafficCheck, rejected bool) {
+	checkMetrics, ok := me.InvalidTraffic[check]
+	if !ok {
+		return
+	}
+	if rejected {
+		checkMetrics.RejectedMeter.Mark(1)
+	} else {
+		checkMetrics.FlaggedMeter.Mark(1)
+	}
+}
+
+func (me *Metrics) RecordVendorListFetch(success bool) {
+	if success {
+		me.VendorListFetchSuccess.Mark(1)
+	} else {
+		me.VendorListFetchFailure.Mark(1)
+	}
+}
+
+func (me *Metrics) RecordVendorListCacheSize(size int) {
+	me.VendorListCacheSize.Update(int64(size))
+}
+
 func doMark(bidder openrtb_ext.BidderName, meters map[openrtb_ext.BidderName]metrics.Meter) {
 	met, ok := meters[bidder]
 	if ok {
@@ -26,6 +26,11 @@ func TestNewMetrics(t *testing.T) {
 	ensureContains(t, registry, "usersync.appnexus.gdpr_prevent", m.userSyncGDPRPrevent["appnexus"])
 	ensureContains(t, registry, "usersync.rubicon.gdpr_prevent", m.userSyncGDPRPrevent["rubicon"])
 	ensureContains(t, registry, "usersync.unknown.gdpr_prevent", m.userSyncGDPRPrevent["unknown"])
+	ensureContains(t, registry, "usersync.appnexus.coppa_prevent", m.userSyncCOPPAPrevent["appnexus"])
+	ensureContains(t, registry, "usersync.unknown.coppa_prevent", m.userSyncCOPPAPrevent["unknown"])
+	ensureContains(t, registry, "usersync.appnexus.ccpa_prevent", m.userSyncCCPAPrevent["appnexus"])
+	ensureContains(t, registry, "usersync.unknown.ccpa_prevent", m.userSyncCCPAPrevent["unknown"])
+	ensureContains(t, registry, "usersync.appnexus.activity_prevent", m.userSyncActivityPrevent["appnexus"])
 	ensureContains(t, registry, "prebid_cache_request_time.ok", m.PrebidCacheRequestTimerSuccess)
 	ensureContains(t, registry, "prebid_cache_request_time.err", m.PrebidCacheRequestTimerError)
 
@@ -63,6 +68,13 @@ func TestNewMetrics(t *testing.T) {
 	ensureContains(t, registry, "privacy.request.tcf.v1", m.PrivacyTCFRequestVersion[TCFVersionV1])
 	ensureContains(t, registry, "privacy.request.tcf.v2", m.PrivacyTCFRequestVersion[TCFVersionV2])
 	ensureContains(t, registry, "privacy.request.tcf.err", m.PrivacyTCFRequestVersion[TCFVersionErr])
+	ensureContains(t, registry, "geo_lookups.eea", m.GeoLookups[GeoLookupEEA])
+	ensureContains(t, registry, "geo_lookups.error", m.GeoLookups[GeoLookupError])
+	ensureContains(t, registry, "invalid_traffic.bot_user_agent.flagged", m.InvalidTraffic[InvalidTrafficBotUserAgent].FlaggedMeter)
+	ensureContains(t, registry, "invalid_traffic.invalid_ip.rejected", m.InvalidTraffic[InvalidTrafficInvalidIP].RejectedMeter)
+	ensureContains(t, registry, "gdpr.vendorlist.fetch.ok", m.VendorListFetchSuccess)
+	ensureContains(t, registry, "gdpr.vendorlist.fetch.failed", m.VendorListFetchFailure)
+	ensureContains(t, registry, "gdpr.vendorlist.cache_size", m.VendorListCacheSize)
 }
 
 func TestRecordBidType(t *testing.T) {
@@ -92,6 +104,61 @@ func TestRecordGDPRRejection(t *testing.T) {
 	VerifyMetrics(t, "GDPR sync rejects", m.userSyncGDPRPrevent[openrtb_ext.BidderAppnexus].Count(), 1)
 }
 
+func TestRecordCOPPARejection(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+	m.RecordUserIDSet(UserLabels{
+		Action: RequestActionCOPPA,
+		Bidder: openrtb_ext.BidderAppnexus,
+	})
+	VerifyMetrics(t, "COPPA sync rejects", m.userSyncCOPPAPrevent[openrtb_ext.BidderAppnexus].Count(), 1)
+}
+
+func TestRecordCCPARejection(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+	m.RecordUserIDSet(UserLabels{
+		Action: RequestActionCCPA,
+		Bidder: openrtb_ext.BidderAppnexus,
+	})
+	VerifyMetrics(t, "CCPA sync rejects", m.userSyncCCPAPrevent[openrtb_ext.BidderAppnexus].Count(), 1)
+}
+
+func TestRecordAdapterNonBid(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterNonBid(openrtb_ext.BidderAppnexus, openrtb_ext.NonBidResponseRejectedBelowFloor)
+	m.RecordAdapterNonBid(openrtb_ext.BidderAppnexus, openrtb_ext.NonBidResponseRejectedBelowFloor)
+	m.RecordAdapterNonBid(openrtb_ext.BidderRubicon, openrtb_ext.NonBidErrorTimeout)
+
+	VerifyMetrics(t, "Appnexus below floor nonbids", m.AdapterMetrics[openrtb_ext.BidderAppnexus].NonBidMeters[openrtb_ext.NonBidResponseRejectedBelowFloor].Count(), 2)
+	VerifyMetrics(t, "Appnexus timeout nonbids", m.AdapterMetrics[openrtb_ext.BidderAppnexus].NonBidMeters[openrtb_ext.NonBidErrorTimeout].Count(), 0)
+}
+
+func TestRecordAdapterBlockedBid(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterBlockedBid(openrtb_ext.BidderAppnexus, BlocklistAdvertisers)
+
+	VerifyMetrics(t, "Appnexus badv blocked bids", m.AdapterMetrics[openrtb_ext.BidderAppnexus].BlockedBidMeters[BlocklistAdvertisers].Count(), 1)
+	VerifyMetrics(t, "Appnexus bcat blocked bids", m.AdapterMetrics[openrtb_ext.BidderAppnexus].BlockedBidMeters[BlocklistCategories].Count(), 0)
+}
+
+func TestRecordAdapterInvalidCreative(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterInvalidCreative(openrtb_ext.BidderAppnexus, CreativeValidationBannerSize, true)
+	m.RecordAdapterInvalidCreative(openrtb_ext.BidderAppnexus, CreativeValidationSecureMarkup, false)
+
+	invalidCreatives := m.AdapterMetrics[openrtb_ext.BidderAppnexus].InvalidCreatives
+	VerifyMetrics(t, "Appnexus rejected banner sizes", invalidCreatives[CreativeValidationBannerSize].RejectedMeter.Count(), 1)
+	VerifyMetrics(t, "Appnexus warned banner sizes", invalidCreatives[CreativeValidationBannerSize].WarnedMeter.Count(), 0)
+	VerifyMetrics(t, "Appnexus warned secure markup", invalidCreatives[CreativeValidationSecureMarkup].WarnedMeter.Count(), 1)
+}
+
 func ensureContains(t *testing.T, registry metrics.Registry, name string, metric interface{}) {
 	t.Helper()
 	if inRegistry := registry.Get(name); inRegistry == nil {
@@ -110,6 +177,11 @@ func ensureContainsAdapterMetrics(t *testing.T, registry metrics.Registry, name
 	ensureContains(t, registry, name+".requests.badserverresponse", adapterMetrics.ErrorMeters[AdapterErrorBadServerResponse])
 	ensureContains(t, registry, name+".requests.timeout", adapterMetrics.ErrorMeters[AdapterErrorTimeout])
 	ensureContains(t, registry, name+".requests.unknown_error", adapterMetrics.ErrorMeters[AdapterErrorUnknown])
+	ensureContains(t, registry, name+".nonbids.timeout", adapterMetrics.NonBidMeters[openrtb_ext.NonBidErrorTimeout])
+	ensureContains(t, registry, name+".nonbids.rejected_below_floor", adapterMetrics.NonBidMeters[openrtb_ext.NonBidResponseRejectedBelowFloor])
+	ensureContains(t, registry, name+".blocked_bids.badv", adapterMetrics.BlockedBidMeters[BlocklistAdvertisers])
+	ensureContains(t, registry, name+".invalid_creatives.banner_size.rejected", adapterMetrics.InvalidCreatives[CreativeValidationBannerSize].RejectedMeter)
+	ensureContains(t, registry, name+".invalid_creatives.secure_markup.warned", adapterMetrics.InvalidCreatives[CreativeValidationSecureMarkup].WarnedMeter)
 
 	ensureContains(t, registry, name+".request_time", adapterMetrics.RequestTimer)
 	ensureContains(t, registry, name+".prices", adapterMetrics.PriceHistogram)
@@ -570,6 +642,47 @@ func TestRecordRequestPrivacy(t *testing.T) {
 	assert.Equal(t, m.PrivacyTCFRequestVersion[TCFVersionV2].Count(), int64(1), "TCF V2")
 }
 
+func TestRecordGeoLookup(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordGeoLookup(GeoLookupEEA)
+	m.RecordGeoLookup(GeoLookupEEA)
+	m.RecordGeoLookup(GeoLookupNotFound)
+
+	assert.Equal(t, int64(2), m.GeoLookups[GeoLookupEEA].Count(), "EEA")
+	assert.Equal(t, int64(0), m.GeoLookups[GeoLookupNonEEA].Count(), "Non EEA")
+	assert.Equal(t, int64(1), m.GeoLookups[GeoLookupNotFound].Count(), "Not Found")
+}
+
+func TestRecordInvalidTraffic(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordInvalidTraffic(InvalidTrafficBotUserAgent, true)
+	m.RecordInvalidTraffic(InvalidTrafficBotUserAgent, true)
+	m.RecordInvalidTraffic(InvalidTrafficDataCenterIP, false)
+
+	assert.Equal(t, int64(2), m.InvalidTraffic[InvalidTrafficBotUserAgent].RejectedMeter.Count(), "Bot UA rejected")
+	assert.Equal(t, int64(0), m.InvalidTraffic[InvalidTrafficBotUserAgent].FlaggedMeter.Count(), "Bot UA flagged")
+	assert.Equal(t, int64(1), m.InvalidTraffic[InvalidTrafficDataCenterIP].FlaggedMeter.Count(), "Data center IP flagged")
+	assert.Equal(t, int64(0), m.InvalidTraffic[InvalidTrafficInvalidIP].FlaggedMeter.Count(), "Invalid IP flagged")
+}
+
+func TestRecordVendorList(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordVendorListFetch(true)
+	m.RecordVendorListFetch(false)
+	m.RecordVendorListFetch(false)
+	m.RecordVendorListCacheSize(3)
+
+	assert.Equal(t, int64(1), m.VendorListFetchSuccess.Count(), "Fetch succeeded")
+	assert.Equal(t, int64(2), m.VendorListFetchFailure.Count(), "Fetch failed")
+	assert.Equal(t, int64(3), m.VendorListCacheSize.Value(), "Cache size")
+}
+
 func ensureContainsBidTypeMetrics(t *testing.T, registry metrics.Registry, prefix string, mdm map[openrtb_ext.BidType]*MarkupDeliveryMetrics) {
 	ensureContains(t, registry, prefix+".banner.adm_bids_received", mdm[openrtb_ext.BidTypeBanner].AdmMeter)
 	ensureContains(t, registry, prefix+".banner.nurl_bids_received", mdm[openrtb_ext.BidTypeBanner].NurlMeter)
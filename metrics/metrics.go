@@ -129,6 +129,18 @@ type AdapterError string
 // CacheResult : Cache hit/miss
 type CacheResult string
 
+// Blocklist : The request blocklist a bid was rejected by
+type Blocklist string
+
+// CreativeValidation : The check of the creative a bid failed
+type CreativeValidation string
+
+// GeoLookupResult : Where the IP address of a device was found to be
+type GeoLookupResult string
+
+// InvalidTrafficCheck : The invalid traffic check a request failed
+type InvalidTrafficCheck string
+
 // PublisherUnknown : Default value for Labels.PubID
 const PublisherUnknown = "unknown"
 
@@ -200,12 +212,13 @@ func CookieTypes() []CookieFlag {
 
 // Request/return status
 const (
-	RequestStatusOK           RequestStatus = "ok"
-	RequestStatusBadInput     RequestStatus = "badinput"
-	RequestStatusErr          RequestStatus = "err"
-	RequestStatusNetworkErr   RequestStatus = "networkerr"
-	RequestStatusBlacklisted  RequestStatus = "blacklistedacctorapp"
-	RequestStatusQueueTimeout RequestStatus = "queuetimeout"
+	RequestStatusOK             RequestStatus = "ok"
+	RequestStatusBadInput       RequestStatus = "badinput"
+	RequestStatusErr            RequestStatus = "err"
+	RequestStatusNetworkErr     RequestStatus = "networkerr"
+	RequestStatusBlacklisted    RequestStatus = "blacklistedacctorapp"
+	RequestStatusQueueTimeout   RequestStatus = "queuetimeout"
+	RequestStatusInvalidTraffic RequestStatus = "invalidtraffic"
 )
 
 func RequestStatuses() []RequestStatus {
@@ -216,6 +229,7 @@ func RequestStatuses() []RequestStatus {
 		RequestStatusNetworkErr,
 		RequestStatusBlacklisted,
 		RequestStatusQueueTimeout,
+		RequestStatusInvalidTraffic,
 	}
 }
 
@@ -238,6 +252,7 @@ const (
 	AdapterErrorBadServerResponse   AdapterError = "badserverresponse"
 	AdapterErrorTimeout             AdapterError = "timeout"
 	AdapterErrorFailedToRequestBids AdapterError = "failedtorequestbid"
+	AdapterErrorCircuitOpen         AdapterError = "circuit_open"
 	AdapterErrorUnknown             AdapterError = "unknown_error"
 )
 
@@ -247,10 +262,71 @@ func AdapterErrors() []AdapterError {
 		AdapterErrorBadServerResponse,
 		AdapterErrorTimeout,
 		AdapterErrorFailedToRequestBids,
+		AdapterErrorCircuitOpen,
 		AdapterErrorUnknown,
 	}
 }
 
+// The blocklists bids are checked against
+const (
+	BlocklistAdvertisers Blocklist = "badv"
+	BlocklistCategories  Blocklist = "bcat"
+	BlocklistAttributes  Blocklist = "battr"
+)
+
+func Blocklists() []Blocklist {
+	return []Blocklist{
+		BlocklistAdvertisers,
+		BlocklistCategories,
+		BlocklistAttributes,
+	}
+}
+
+// The checks run on the creatives of the bids
+const (
+	CreativeValidationBannerSize   CreativeValidation = "banner_size"
+	CreativeValidationSecureMarkup CreativeValidation = "secure_markup"
+)
+
+func CreativeValidations() []CreativeValidation {
+	return []CreativeValidation{
+		CreativeValidationBannerSize,
+		CreativeValidationSecureMarkup,
+	}
+}
+
+// The results of looking up where a device is from its IP address
+const (
+	GeoLookupEEA      GeoLookupResult = "eea"
+	GeoLookupNonEEA   GeoLookupResult = "non_eea"
+	GeoLookupNotFound GeoLookupResult = "not_found"
+	GeoLookupError    GeoLookupResult = "error"
+)
+
+func GeoLookupResults() []GeoLookupResult {
+	return []GeoLookupResult{
+		GeoLookupEEA,
+		GeoLookupNonEEA,
+		GeoLookupNotFound,
+		GeoLookupError,
+	}
+}
+
+// The checks which find invalid traffic
+const (
+	InvalidTrafficBotUserAgent InvalidTrafficCheck = "bot_user_agent"
+	InvalidTrafficDataCenterIP InvalidTrafficCheck = "datacenter_ip"
+	InvalidTrafficInvalidIP    InvalidTrafficCheck = "invalid_ip"
+)
+
+func InvalidTrafficChecks() []InvalidTrafficCheck {
+	return []InvalidTrafficCheck{
+		InvalidTrafficBotUserAgent,
+		InvalidTrafficDataCenterIP,
+		InvalidTrafficInvalidIP,
+	}
+}
+
 const (
 	// CacheHit represents a cache hit i.e the key was found in cache
 	CacheHit CacheResult = "hit"
@@ -278,10 +354,13 @@ type RequestAction string
 
 // /setuid action labels
 const (
-	RequestActionSet    RequestAction = "set"
-	RequestActionOptOut RequestAction = "opt_out"
-	RequestActionGDPR   RequestAction = "gdpr"
-	RequestActionErr    RequestAction = "err"
+	RequestActionSet      RequestAction = "set"
+	RequestActionOptOut   RequestAction = "opt_out"
+	RequestActionGDPR     RequestAction = "gdpr"
+	RequestActionCOPPA    RequestAction = "coppa"
+	RequestActionCCPA     RequestAction = "ccpa"
+	RequestActionActivity RequestAction = "activity"
+	RequestActionErr      RequestAction = "err"
 )
 
 // RequestActions returns possible setuid action labels
@@ -290,6 +369,9 @@ func RequestActions() []RequestAction {
 		RequestActionSet,
 		RequestActionOptOut,
 		RequestActionGDPR,
+		RequestActionCOPPA,
+		RequestActionCCPA,
+		RequestActionActivity,
 		RequestActionErr,
 	}
 }
@@ -341,6 +423,13 @@ type MetricsEngine interface {
 	RecordDNSTime(dnsLookupTime time.Duration)
 	RecordTLSHandshakeTime(tlsHandshakeTime time.Duration)
 	RecordAdapterPanic(labels AdapterLabels)
+	// RecordAdapterBlockedBid records a bid of the adapter which was rejected by one of the request blocklists.
+	RecordAdapterBlockedBid(adapter openrtb_ext.BidderName, blocklist Blocklist)
+	// RecordAdapterInvalidCreative records a bid of the adapter whose creative failed a validation, and whether
+	// the bid was rejected for it or only warned about.
+	RecordAdapterInvalidCreative(adapter openrtb_ext.BidderName, validation CreativeValidation, rejected bool)
+	// RecordAdapterNonBid records a bid the adapter didn't make, or which was rejected, along with the reason why.
+	RecordAdapterNonBid(adapter openrtb_ext.BidderName, reason openrtb_ext.NonBidReason)
 	// This records whether or not a bid of a particular type uses `adm` or `nurl`.
 	// Since the legacy endpoints don't have a bid type, it can only count bids from OpenRTB and AMP.
 	RecordAdapterBidReceived(labels AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool)
@@ -358,4 +447,13 @@ type MetricsEngine interface {
 	RecordRequestQueueTime(success bool, requestType RequestType, length time.Duration)
 	RecordTimeoutNotice(sucess bool)
 	RecordRequestPrivacy(privacy PrivacyLabels)
+	// RecordGeoLookup records where the IP address of a device was found to be.
+	RecordGeoLookup(result GeoLookupResult)
+	// RecordInvalidTraffic records a request which failed an invalid traffic check, and whether it was
+	// rejected for it or only flagged.
+	RecordInvalidTraffic(check InvalidTrafficCheck, rejected bool)
+	// RecordVendorListFetch records an attempt to download a GDPR vendor list, and whether it succeeded.
+	RecordVendorListFetch(success bool)
+	// RecordVendorListCacheSize records how many GDPR vendor lists are cached.
+	RecordVendorListCacheSize(size int)
 }
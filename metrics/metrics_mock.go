@@ -57,6 +57,21 @@ func (me *MetricsEngineMock) RecordAdapterPanic(labels AdapterLabels) {
 	me.Called(labels)
 }
 
+// RecordAdapterBlockedBid mock
+func (me *MetricsEngineMock) RecordAdapterBlockedBid(adapter openrtb_ext.BidderName, blocklist Blocklist) {
+	me.Called(adapter, blocklist)
+}
+
+// RecordAdapterInvalidCreative mock
+func (me *MetricsEngineMock) RecordAdapterInvalidCreative(adapter openrtb_ext.BidderName, validation CreativeValidation, rejected bool) {
+	me.Called(adapter, validation, rejected)
+}
+
+// RecordAdapterNonBid mock
+func (me *MetricsEngineMock) RecordAdapterNonBid(adapter openrtb_ext.BidderName, reason openrtb_ext.NonBidReason) {
+	me.Called(adapter, reason)
+}
+
 // RecordAdapterRequest mock
 func (me *MetricsEngineMock) RecordAdapterRequest(labels AdapterLabels) {
 	me.Called(labels)
@@ -140,3 +155,23 @@ func (me *MetricsEngineMock) RecordTimeoutNotice(success bool) {
 func (me *MetricsEngineMock) RecordRequestPrivacy(privacy PrivacyLabels) {
 	me.Called(privacy)
 }
+
+// RecordGeoLookup mock
+func (me *MetricsEngineMock) RecordGeoLookup(result GeoLookupResult) {
+	me.Called(result)
+}
+
+// RecordInvalidTraffic mock
+func (me *MetricsEngineMock) RecordInvalidTraffic(check InvalidTrafficCheck, rejected bool) {
+	me.Called(check, rejected)
+}
+
+// RecordVendorListFetch mock
+func (me *MetricsEngineMock) RecordVendorListFetch(success bool) {
+	me.Called(success)
+}
+
+// RecordVendorListCacheSize mock
+func (me *MetricsEngineMock) RecordVendorListCacheSize(size int) {
+	me.Called(size)
+}
@@ -40,6 +40,10 @@ type Metrics struct {
 	storedVideoFetchTimer        *prometheus.HistogramVec
 	storedVideoErrors            *prometheus.CounterVec
 	timeoutNotifications         *prometheus.CounterVec
+	geoLookups                   *prometheus.CounterVec
+	invalidTraffic               *prometheus.CounterVec
+	vendorListFetches            *prometheus.CounterVec
+	vendorListCacheSize          prometheus.Gauge
 	dnsLookupTimer               prometheus.Histogram
 	tlsHandhakeTimer             prometheus.Histogram
 	privacyCCPA                  *prometheus.CounterVec
@@ -52,6 +56,9 @@ type Metrics struct {
 	adapterCookieSync         *prometheus.CounterVec
 	adapterErrors             *prometheus.CounterVec
 	adapterPanics             *prometheus.CounterVec
+	adapterNonBids            *prometheus.CounterVec
+	adapterBlockedBids        *prometheus.CounterVec
+	adapterInvalidCreatives   *prometheus.CounterVec
 	adapterPrices             *prometheus.HistogramVec
 	adapterRequests           *prometheus.CounterVec
 	adapterRequestsTimer      *prometheus.HistogramVec
@@ -81,6 +88,12 @@ const (
 	isNativeLabel        = "native"
 	isVideoLabel         = "video"
 	markupDeliveryLabel  = "delivery"
+	nonBidReasonLabel    = "nonbid_reason"
+	blocklistLabel       = "blocklist"
+	validationLabel      = "validation"
+	geoLookupResultLabel = "result"
+	invalidTrafficLabel  = "check"
+	rejectedLabel        = "rejected"
 	optOutLabel          = "opt_out"
 	privacyBlockedLabel  = "privacy_blocked"
 	requestStatusLabel   = "request_status"
@@ -253,6 +266,25 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of timeout notifications triggered, and if they were successfully sent.",
 		[]string{successLabel})
 
+	metrics.geoLookups = newCounter(cfg, metrics.Registry,
+		"geo_lookups",
+		"Count of lookups of where devices are from their IP addresses, by result.",
+		[]string{geoLookupResultLabel})
+
+	metrics.invalidTraffic = newCounter(cfg, metrics.Registry,
+		"invalid_traffic",
+		"Count of requests which failed an invalid traffic check, by check and whether they were rejected.",
+		[]string{invalidTrafficLabel, rejectedLabel})
+
+	metrics.vendorListFetches = newCounter(cfg, metrics.Registry,
+		"gdpr_vendorlist_fetches",
+		"Count of GDPR vendor list downloads, and if they were successful.",
+		[]string{successLabel})
+
+	metrics.vendorListCacheSize = newGauge(cfg, metrics.Registry,
+		"gdpr_vendorlist_cache_size",
+		"Number of GDPR vendor lists cached.")
+
 	metrics.dnsLookupTimer = newHistogram(cfg, metrics.Registry,
 		"dns_lookup_time",
 		"Seconds to resolve DNS",
@@ -303,6 +335,24 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of panics labeled by adapter.",
 		[]string{adapterLabel})
 
+	// Not preloaded, since most adapters never see most of the reasons.
+	metrics.adapterNonBids = newCounter(cfg, metrics.Registry,
+		"adapter_nonbids",
+		"Count of bids which were not made or were rejected, labeled by adapter and reason.",
+		[]string{adapterLabel, nonBidReasonLabel})
+
+	// Not preloaded, since most adapters respect the blocklists.
+	metrics.adapterBlockedBids = newCounter(cfg, metrics.Registry,
+		"adapter_blocked_bids",
+		"Count of bids rejected by a request blocklist, labeled by adapter and blocklist.",
+		[]string{adapterLabel, blocklistLabel})
+
+	// Not preloaded, since validations are off by default.
+	metrics.adapterInvalidCreatives = newCounter(cfg, metrics.Registry,
+		"adapter_invalid_creatives",
+		"Count of bids whose creative failed a validation, labeled by adapter, validation and whether the bid was rejected.",
+		[]string{adapterLabel, validationLabel, rejectedLabel})
+
 	metrics.adapterPrices = newHistogramVec(cfg, metrics.Registry,
 		"adapter_prices",
 		"Monetary value of the bids labeled by adapter.",
@@ -383,6 +433,18 @@ func newCounterWithoutLabels(cfg config.PrometheusMetrics, registry *prometheus.
 	return counter
 }
 
+func newGauge(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string) prometheus.Gauge {
+	opts := prometheus.GaugeOpts{
+		Namespace: cfg.Namespace,
+		Subsystem: cfg.Subsystem,
+		Name:      name,
+		Help:      help,
+	}
+	gauge := prometheus.NewGauge(opts)
+	registry.MustRegister(gauge)
+	return gauge
+}
+
 func newHistogramVec(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
 	opts := prometheus.HistogramOpts{
 		Namespace: cfg.Namespace,
@@ -570,6 +632,28 @@ func (m *Metrics) RecordAdapterPanic(labels metrics.AdapterLabels) {
 	}).Inc()
 }
 
+func (m *Metrics) RecordAdapterBlockedBid(adapter openrtb_ext.BidderName, blocklist metrics.Blocklist) {
+	m.adapterBlockedBids.With(prometheus.Labels{
+		adapterLabel:   string(adapter),
+		blocklistLabel: string(blocklist),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterInvalidCreative(adapter openrtb_ext.BidderName, validation metrics.CreativeValidation, rejected bool) {
+	m.adapterInvalidCreatives.With(prometheus.Labels{
+		adapterLabel:    string(adapter),
+		validationLabel: string(validation),
+		rejectedLabel:   strconv.FormatBool(rejected),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterNonBid(adapter openrtb_ext.BidderName, reason openrtb_ext.NonBidReason) {
+	m.adapterNonBids.With(prometheus.Labels{
+		adapterLabel:      string(adapter),
+		nonBidReasonLabel: reason.String(),
+	}).Inc()
+}
+
 func (m *Metrics) RecordAdapterBidReceived(labels metrics.AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool) {
 	markupDelivery := markupDeliveryNurl
 	if hasAdm {
@@ -664,6 +748,35 @@ func (m *Metrics) RecordTimeoutNotice(success bool) {
 	}
 }
 
+func (m *Metrics) RecordGeoLookup(result metrics.GeoLookupResult) {
+	m.geoLookups.With(prometheus.Labels{
+		geoLookupResultLabel: string(result),
+	}).Inc()
+}
+
+func (m *Metrics) RecordInvalidTraffic(check metrics.InvalidTrafficCheck, rejected bool) {
+	m.invalidTraffic.With(prometheus.Labels{
+		invalidTrafficLabel: string(check),
+		rejectedLabel:       strconv.FormatBool(rejected),
+	}).Inc()
+}
+
+func (m *Metrics) RecordVendorListFetch(success bool) {
+	if success {
+		m.vendorListFetches.With(prometheus.Labels{
+			successLabel: requestSuccessful,
+		}).Inc()
+	} else {
+		m.vendorListFetches.With(prometheus.Labels{
+			successLabel: requestFailed,
+		}).Inc()
+	}
+}
+
+func (m *Metrics) RecordVendorListCacheSize(size int) {
+	m.vendorListCacheSize.Set(float64(size))
+}
+
 func (m *Metrics) RecordRequestPrivacy(privacy metrics.PrivacyLabels) {
 	if privacy.CCPAProvided {
 		m.privacyCCPA.With(prometheus.Labels{
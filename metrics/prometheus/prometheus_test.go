@@ -62,7 +62,7 @@ func TestMetricCountGatekeeping(t *testing.T) {
 	// Verify Per-Adapter Cardinality
 	// - This assertion provides a warning for newly added adapter metrics. Threre are 40+ adapters which makes the
 	//   cost of new per-adapter metrics rather expensive. Thought should be given when adding new per-adapter metrics.
-	assert.True(t, perAdapterCardinalityCount <= 27, "Per-Adapter Cardinality count equals %d \n", perAdapterCardinalityCount)
+	assert.True(t, perAdapterCardinalityCount <= 29, "Per-Adapter Cardinality count equals %d \n", perAdapterCardinalityCount)
 }
 
 func TestConnectionMetrics(t *testing.T) {
@@ -990,6 +990,52 @@ func TestAdapterPanicMetric(t *testing.T) {
 		})
 }
 
+func TestAdapterNonBidMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterNonBid(openrtb_ext.BidderName(adapterName), openrtb_ext.NonBidResponseRejectedBelowFloor)
+
+	expectedCount := float64(1)
+	assertCounterVecValue(t, "", "adapterNonBids", m.adapterNonBids,
+		expectedCount,
+		prometheus.Labels{
+			adapterLabel:      adapterName,
+			nonBidReasonLabel: "rejected_below_floor",
+		})
+}
+
+func TestAdapterBlockedBidMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterBlockedBid(openrtb_ext.BidderName(adapterName), metrics.BlocklistCategories)
+
+	expectedCount := float64(1)
+	assertCounterVecValue(t, "", "adapterBlockedBids", m.adapterBlockedBids,
+		expectedCount,
+		prometheus.Labels{
+			adapterLabel:   adapterName,
+			blocklistLabel: "bcat",
+		})
+}
+
+func TestAdapterInvalidCreativeMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterInvalidCreative(openrtb_ext.BidderName(adapterName), metrics.CreativeValidationSecureMarkup, true)
+
+	expectedCount := float64(1)
+	assertCounterVecValue(t, "", "adapterInvalidCreatives", m.adapterInvalidCreatives,
+		expectedCount,
+		prometheus.Labels{
+			adapterLabel:    adapterName,
+			validationLabel: "secure_markup",
+			rejectedLabel:   "true",
+		})
+}
+
 func TestStoredReqCacheResultMetric(t *testing.T) {
 	m := createMetricsForTesting()
 
@@ -1152,6 +1198,70 @@ func TestTimeoutNotifications(t *testing.T) {
 
 }
 
+func TestRecordGeoLookup(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordGeoLookup(metrics.GeoLookupEEA)
+	m.RecordGeoLookup(metrics.GeoLookupEEA)
+	m.RecordGeoLookup(metrics.GeoLookupError)
+
+	assertCounterVecValue(t, "", "geo_lookups:eea", m.geoLookups,
+		float64(2),
+		prometheus.Labels{
+			geoLookupResultLabel: string(metrics.GeoLookupEEA),
+		})
+	assertCounterVecValue(t, "", "geo_lookups:error", m.geoLookups,
+		float64(1),
+		prometheus.Labels{
+			geoLookupResultLabel: string(metrics.GeoLookupError),
+		})
+}
+
+func TestRecordInvalidTraffic(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordInvalidTraffic(metrics.InvalidTrafficBotUserAgent, true)
+	m.RecordInvalidTraffic(metrics.InvalidTrafficDataCenterIP, false)
+	m.RecordInvalidTraffic(metrics.InvalidTrafficDataCenterIP, false)
+
+	assertCounterVecValue(t, "", "invalid_traffic:bot_user_agent:rejected", m.invalidTraffic,
+		float64(1),
+		prometheus.Labels{
+			invalidTrafficLabel: string(metrics.InvalidTrafficBotUserAgent),
+			rejectedLabel:       "true",
+		})
+	assertCounterVecValue(t, "", "invalid_traffic:datacenter_ip:flagged", m.invalidTraffic,
+		float64(2),
+		prometheus.Labels{
+			invalidTrafficLabel: string(metrics.InvalidTrafficDataCenterIP),
+			rejectedLabel:       "false",
+		})
+}
+
+func TestRecordVendorList(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordVendorListFetch(true)
+	m.RecordVendorListFetch(false)
+	m.RecordVendorListFetch(false)
+	m.RecordVendorListCacheSize(3)
+
+	assertCounterVecValue(t, "", "gdpr_vendorlist_fetches:ok", m.vendorListFetches,
+		float64(1),
+		prometheus.Labels{
+			successLabel: requestSuccessful,
+		})
+	assertCounterVecValue(t, "", "gdpr_vendorlist_fetches:failed", m.vendorListFetches,
+		float64(2),
+		prometheus.Labels{
+			successLabel: requestFailed,
+		})
+
+	cacheSize := dto.Metric{}
+	m.vendorListCacheSize.Write(&cacheSize)
+	assert.Equal(t, float64(3), cacheSize.GetGauge().GetValue(), "gdpr_vendorlist_cache_size")
+}
+
 func TestRecordDNSTime(t *testing.T) {
 	type testIn struct {
 		dnsLookupDuration time.Duration
@@ -23,6 +23,9 @@ type ExtBidPrebid struct {
 	Type              BidType             `json:"type"`
 	Video             *ExtBidPrebidVideo  `json:"video,omitempty"`
 	Events            *ExtBidPrebidEvents `json:"events,omitempty"`
+	// ClearingPrice is the price the bid won at in a second-price auction. OriginalPrice is the price it was made at.
+	ClearingPrice float64 `json:"clearingprice,omitempty"`
+	OriginalPrice float64 `json:"originalprice,omitempty"`
 }
 
 // ExtBidPrebidCache defines the contract for  bidresponse.seatbid.bid[i].ext.prebid.cache
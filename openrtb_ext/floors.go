@@ -0,0 +1,91 @@
+package openrtb_ext
+
+// Price floor schema fields supported in bidrequest.ext.prebid.floors.data.schema.fields
+const (
+	FloorFieldMediaType  string = "mediaType"
+	FloorFieldSize       string = "size"
+	FloorFieldDomain     string = "domain"
+	FloorFieldBundle     string = "bundle"
+	FloorFieldAdUnitCode string = "adUnitCode"
+)
+
+// FloorWildcard matches any value of a schema field in a floor rule.
+const FloorWildcard string = "*"
+
+// FloorFields returns the schema fields which may be used to build price floor rules.
+func FloorFields() []string {
+	return []string{
+		FloorFieldMediaType,
+		FloorFieldSize,
+		FloorFieldDomain,
+		FloorFieldBundle,
+		FloorFieldAdUnitCode,
+	}
+}
+
+// PriceFloorRules defines the contract for bidrequest.ext.prebid.floors
+type PriceFloorRules struct {
+	Enabled     *bool                  `json:"enabled,omitempty"`
+	FloorMin    float64                `json:"floormin,omitempty"`
+	FloorMinCur string                 `json:"floormincur,omitempty"`
+	Enforcement *PriceFloorEnforcement `json:"enforcement,omitempty"`
+	Data        *PriceFloorData        `json:"data,omitempty"`
+}
+
+// PriceFloorEnforcement defines the contract for bidrequest.ext.prebid.floors.enforcement
+type PriceFloorEnforcement struct {
+	// EnforcePBS controls whether Prebid Server drops bids below the floor. Defaults to true.
+	EnforcePBS *bool `json:"enforcepbs,omitempty"`
+	// FloorDeals controls whether deal bids are subject to floor enforcement. Defaults to false.
+	FloorDeals bool `json:"floordeals,omitempty"`
+}
+
+// PriceFloorData defines the contract for bidrequest.ext.prebid.floors.data
+type PriceFloorData struct {
+	Currency string             `json:"currency,omitempty"`
+	Schema   PriceFloorSchema   `json:"schema"`
+	Values   map[string]float64 `json:"values,omitempty"`
+	Default  float64            `json:"default,omitempty"`
+}
+
+// PriceFloorSchema defines the contract for bidrequest.ext.prebid.floors.data.schema
+type PriceFloorSchema struct {
+	Fields    []string `json:"fields"`
+	Delimiter string   `json:"delimiter,omitempty"`
+}
+
+// GetEnabled returns whether the request asked for price floors. Floors are on unless explicitly disabled.
+func (f *PriceFloorRules) GetEnabled() bool {
+	if f.Enabled != nil {
+		return *f.Enabled
+	}
+	return true
+}
+
+// GetEnforcePBS returns whether bids below the floor should be dropped by Prebid Server.
+func (f *PriceFloorRules) GetEnforcePBS() bool {
+	if f.Enforcement != nil && f.Enforcement.EnforcePBS != nil {
+		return *f.Enforcement.EnforcePBS
+	}
+	return true
+}
+
+// GetFloorDeals returns whether deal bids must also meet the floor.
+func (f *PriceFloorRules) GetFloorDeals() bool {
+	return f.Enforcement != nil && f.Enforcement.FloorDeals
+}
+
+// ExtResponsePrebidFloors defines the contract for bidresponse.ext.prebid.floors
+type ExtResponsePrebidFloors struct {
+	// Enforced is true if bids below the floor were removed from the auction.
+	Enforced bool `json:"enforced"`
+	// Imps holds the floor applied to each imp, keyed by imp.id
+	Imps map[string]ExtResponsePrebidFloor `json:"imps,omitempty"`
+}
+
+// ExtResponsePrebidFloor defines the contract for bidresponse.ext.prebid.floors.imps.{impid}
+type ExtResponsePrebidFloor struct {
+	FloorValue    float64 `json:"floorvalue"`
+	FloorCurrency string  `json:"floorcurrency"`
+	FloorRule     string  `json:"floorrule,omitempty"`
+}
@@ -9,6 +9,12 @@ type ExtImpPrebid struct {
 	// StoredRequest specifies which stored impression to use, if any.
 	StoredRequest *ExtStoredRequest `json:"storedrequest"`
 
+	// StoredAuctionResponse specifies a stored auction response to use in place of the bidders' responses for this imp.
+	StoredAuctionResponse *ExtStoredAuctionResponse `json:"storedauctionresponse,omitempty"`
+
+	// StoredBidResponse specifies the stored bidder responses to use in place of the HTTP calls to those bidders for this imp.
+	StoredBidResponse []ExtStoredBidResponse `json:"storedbidresponse,omitempty"`
+
 	// IsRewardedInventory is a signal intended for video impressions. Must be 0 or 1.
 	IsRewardedInventory int8 `json:"is_rewarded_inventory"`
 
@@ -20,3 +26,20 @@ type ExtImpPrebid struct {
 type ExtStoredRequest struct {
 	ID string `json:"id"`
 }
+
+// ExtStoredAuctionResponse defines the contract for bidrequest.imp[i].ext.prebid.storedauctionresponse
+//
+// The stored data is the list of seatbids the auction returns for the imp, in the format of bidresponse.seatbid.
+// Stored responses are only supported by the /openrtb2/auction endpoint.
+type ExtStoredAuctionResponse struct {
+	ID string `json:"id"`
+}
+
+// ExtStoredBidResponse defines the contract for bidrequest.imp[i].ext.prebid.storedbidresponse[i]
+//
+// The stored data is the body of an HTTP response from the bidder, which is given to the bidder's adapter as is.
+// Stored responses are only supported by the /openrtb2/auction endpoint.
+type ExtStoredBidResponse struct {
+	ID     string `json:"id"`
+	Bidder string `json:"bidder"`
+}
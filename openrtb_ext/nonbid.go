@@ -0,0 +1,92 @@
+package openrtb_ext
+
+// NonBidReason is the status code which tells why a bidder made no bid for an imp, or why its bid was rejected.
+type NonBidReason int
+
+// The codes follow the Prebid seat non-bid status codes.
+const (
+	// NonBidErrorTimeout means the bidder didn't answer in time.
+	NonBidErrorTimeout NonBidReason = 101
+	// NonBidErrorInvalidBidResponse means the bidder answered in a currency which can't be used for the auction.
+	NonBidErrorInvalidBidResponse NonBidReason = 102
+	// NonBidResponseRejectedGeneral means the bid failed validation.
+	NonBidResponseRejectedGeneral NonBidReason = 300
+	// NonBidResponseRejectedBelowFloor means the bid price was below the floor of the imp.
+	NonBidResponseRejectedBelowFloor NonBidReason = 301
+	// NonBidResponseRejectedCategoryMappingInvalid means the bid was dropped while mapping or deduplicating categories.
+	NonBidResponseRejectedCategoryMappingInvalid NonBidReason = 303
+	// NonBidResponseRejectedCreativeSizeNotAllowed means the size of the banner creative matches none of the imp's sizes.
+	NonBidResponseRejectedCreativeSizeNotAllowed NonBidReason = 351
+	// NonBidResponseRejectedCreativeNotSecure means the creative loads something over http on a secure imp.
+	NonBidResponseRejectedCreativeNotSecure NonBidReason = 352
+)
+
+// NonBidReasons returns all the reasons a bid may be missing or rejected.
+func NonBidReasons() []NonBidReason {
+	return []NonBidReason{
+		NonBidErrorTimeout,
+		NonBidErrorInvalidBidResponse,
+		NonBidResponseRejectedGeneral,
+		NonBidResponseRejectedBelowFloor,
+		NonBidResponseRejectedCategoryMappingInvalid,
+		NonBidResponseRejectedCreativeSizeNotAllowed,
+		NonBidResponseRejectedCreativeNotSecure,
+	}
+}
+
+// String returns the name of the reason, as used by the metrics.
+func (reason NonBidReason) String() string {
+	switch reason {
+	case NonBidErrorTimeout:
+		return "timeout"
+	case NonBidErrorInvalidBidResponse:
+		return "invalid_bid_response"
+	case NonBidResponseRejectedGeneral:
+		return "rejected_general"
+	case NonBidResponseRejectedBelowFloor:
+		return "rejected_below_floor"
+	case NonBidResponseRejectedCategoryMappingInvalid:
+		return "rejected_category_mapping_invalid"
+	case NonBidResponseRejectedCreativeSizeNotAllowed:
+		return "rejected_creative_size_not_allowed"
+	case NonBidResponseRejectedCreativeNotSecure:
+		return "rejected_creative_not_secure"
+	}
+	return "unknown"
+}
+
+// ExtSeatNonBid defines the contract for bidresponse.ext.seatnonbid[i]
+type ExtSeatNonBid struct {
+	Seat   string      `json:"seat"`
+	NonBid []ExtNonBid `json:"nonbid"`
+}
+
+// ExtNonBid defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j]
+//
+// Ext is only set when the bidder made a bid which was rejected.
+type ExtNonBid struct {
+	ImpID      string        `json:"impid"`
+	StatusCode NonBidReason  `json:"statuscode"`
+	Ext        *ExtNonBidExt `json:"ext,omitempty"`
+}
+
+// ExtNonBidExt defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j].ext
+type ExtNonBidExt struct {
+	Prebid ExtNonBidPrebid `json:"prebid"`
+}
+
+// ExtNonBidPrebid defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j].ext.prebid
+type ExtNonBidPrebid struct {
+	Bid ExtNonBidBid `json:"bid"`
+}
+
+// ExtNonBidBid defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j].ext.prebid.bid
+type ExtNonBidBid struct {
+	ID      string   `json:"id"`
+	Price   float64  `json:"price"`
+	ADomain []string `json:"adomain,omitempty"`
+	CrID    string   `json:"crid,omitempty"`
+	DealID  string   `json:"dealid,omitempty"`
+	W       uint64   `json:"w,omitempty"`
+	H       uint64   `json:"h,omitempty"`
+}
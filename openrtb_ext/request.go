@@ -3,6 +3,7 @@ package openrtb_ext
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 )
 
 // FirstPartyDataContextExtKey defines the field name within bidrequest.ext reserved
@@ -18,11 +19,14 @@ type ExtRequest struct {
 // ExtRequestPrebid defines the contract for bidrequest.ext.prebid
 type ExtRequestPrebid struct {
 	Aliases              map[string]string         `json:"aliases,omitempty"`
-	BidAdjustmentFactors map[string]float64        `json:"bidadjustmentfactors,omitempty"`
+	BidAdjustmentFactors *ExtBidAdjustmentFactors  `json:"bidadjustmentfactors,omitempty"`
 	Cache                *ExtRequestPrebidCache    `json:"cache,omitempty"`
+	CurrencyConversions  *ExtRequestCurrency       `json:"currency,omitempty"`
 	Data                 *ExtRequestPrebidData     `json:"data,omitempty"`
 	Debug                bool                      `json:"debug,omitempty"`
 	Events               json.RawMessage           `json:"events,omitempty"`
+	Floors               *PriceFloorRules          `json:"floors,omitempty"`
+	MultiBid             []*ExtMultiBid            `json:"multibid,omitempty"`
 	SChains              []*ExtRequestPrebidSChain `json:"schains,omitempty"`
 	StoredRequest        *ExtStoredRequest         `json:"storedrequest,omitempty"`
 	SupportDeals         bool                      `json:"supportdeals,omitempty"`
@@ -59,6 +63,168 @@ type ExtRequestPrebidSChainSChainNode struct {
 	Ext    json.RawMessage `json:"ext,omitempty"`
 }
 
+// MaxBidsPerBidder is the highest number of bids a bidder can keep on an imp through bidrequest.ext.prebid.multibid
+const MaxBidsPerBidder int = 9
+
+// ExtMultiBid defines the contract for bidrequest.ext.prebid.multibid[i]
+type ExtMultiBid struct {
+	// Bidder names a single bidder. TargetBidderCodePrefix may only be used with it.
+	Bidder string `json:"bidder,omitempty"`
+	// Bidders names several bidders which share the same MaxBids.
+	Bidders []string `json:"bidders,omitempty"`
+	MaxBids *int     `json:"maxbids,omitempty"`
+	// TargetBidderCodePrefix is used in place of the bidder name in the targeting keys of the bidder's extra bids.
+	// The second bid is keyed with the prefix followed by 2, the third with the prefix followed by 3, and so on.
+	// Extra bids don't get targeting keys if it's empty.
+	TargetBidderCodePrefix string `json:"targetbiddercodeprefix,omitempty"`
+}
+
+// BidAdjustmentWildcard stands for any bidder, or any deal, in bidrequest.ext.prebid.bidadjustmentfactors
+const BidAdjustmentWildcard = "*"
+
+// ExtBidAdjustmentFactors defines the contract for bidrequest.ext.prebid.bidadjustmentfactors
+//
+// The factors keyed by bidder, such as {"appnexus": 0.9}, apply to all of the bidder's bids. The ones under
+// "mediatypes" apply to the bids of one media type, and may be set per deal, as in
+// {"mediatypes": {"video": {"appnexus": {"deal-1": 1.1, "*": 0.8}}}}. A number in place of the deals,
+// such as {"mediatypes": {"banner": {"appnexus": 0.8}}}, applies to all of the bidder's bids of that type.
+//
+// The type is also used for the account defaults, which hosts set with bidders and mediatypes keys in their config.
+type ExtBidAdjustmentFactors struct {
+	Bidders    map[string]float64                              `mapstructure:"bidders"`
+	MediaTypes map[BidType]map[string]ExtDealAdjustmentFactors `mapstructure:"mediatypes"`
+}
+
+// ExtDealAdjustmentFactors maps deal IDs to bid adjustment factors. The "*" factor applies to the bids
+// of the deals which aren't listed, and to the bids without a deal.
+type ExtDealAdjustmentFactors map[string]float64
+
+const bidAdjustmentMediaTypesKey = "mediatypes"
+
+// UnmarshalJSON reads the factors of the bidders, along with the ones by media type.
+func (factors *ExtBidAdjustmentFactors) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	*factors = ExtBidAdjustmentFactors{}
+	for key, value := range fields {
+		if key == bidAdjustmentMediaTypesKey {
+			if err := json.Unmarshal(value, &factors.MediaTypes); err != nil {
+				return err
+			}
+			continue
+		}
+		var factor float64
+		if err := json.Unmarshal(value, &factor); err != nil {
+			return fmt.Errorf("bidadjustmentfactors.%s must be a number", key)
+		}
+		if factors.Bidders == nil {
+			factors.Bidders = make(map[string]float64, len(fields))
+		}
+		factors.Bidders[key] = factor
+	}
+	return nil
+}
+
+// MarshalJSON writes the factors in the same form UnmarshalJSON reads them.
+func (factors ExtBidAdjustmentFactors) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(factors.Bidders)+1)
+	for bidder, factor := range factors.Bidders {
+		fields[bidder] = factor
+	}
+	if len(factors.MediaTypes) > 0 {
+		fields[bidAdjustmentMediaTypesKey] = factors.MediaTypes
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON reads either the factors by deal, or a single factor for all deals.
+func (factors *ExtDealAdjustmentFactors) UnmarshalJSON(b []byte) error {
+	var factor float64
+	if err := json.Unmarshal(b, &factor); err == nil {
+		*factors = ExtDealAdjustmentFactors{BidAdjustmentWildcard: factor}
+		return nil
+	}
+	var dealFactors map[string]float64
+	if err := json.Unmarshal(b, &dealFactors); err != nil {
+		return err
+	}
+	*factors = dealFactors
+	return nil
+}
+
+// Factor returns the adjustment of the bidder's bid. The factors of the bidder come before the wildcard ones,
+// so a bidder's factor for all media types still beats a wildcard one for the media type. For each of them,
+// the factors for the media type come first, and those of the deal before the wildcard deal. Bids which no
+// factor applies to are left as they are. A nil ExtBidAdjustmentFactors adjusts no bid.
+func (factors *ExtBidAdjustmentFactors) Factor(bidder string, bidType BidType, dealID string) float64 {
+	if factors == nil {
+		return 1.0
+	}
+
+	mediaTypeFactors := factors.MediaTypes[bidType]
+	for _, key := range []string{bidder, BidAdjustmentWildcard} {
+		dealFactors := mediaTypeFactors[key]
+		if factor, ok := dealFactors[dealID]; ok && dealID != "" {
+			return factor
+		}
+		if factor, ok := dealFactors[BidAdjustmentWildcard]; ok {
+			return factor
+		}
+		if factor, ok := factors.Bidders[key]; ok {
+			return factor
+		}
+	}
+	return 1.0
+}
+
+// Merge returns the factors, overridden by the given ones wherever both set a factor for the same bidder,
+// media type and deal. Either may be nil.
+func (factors *ExtBidAdjustmentFactors) Merge(overrides *ExtBidAdjustmentFactors) *ExtBidAdjustmentFactors {
+	if factors == nil {
+		return overrides
+	}
+	if overrides == nil {
+		return factors
+	}
+
+	merged := &ExtBidAdjustmentFactors{
+		Bidders:    make(map[string]float64, len(factors.Bidders)+len(overrides.Bidders)),
+		MediaTypes: make(map[BidType]map[string]ExtDealAdjustmentFactors, len(factors.MediaTypes)+len(overrides.MediaTypes)),
+	}
+	for _, source := range []*ExtBidAdjustmentFactors{factors, overrides} {
+		for bidder, factor := range source.Bidders {
+			merged.Bidders[bidder] = factor
+		}
+		for bidType, bidderFactors := range source.MediaTypes {
+			if merged.MediaTypes[bidType] == nil {
+				merged.MediaTypes[bidType] = make(map[string]ExtDealAdjustmentFactors, len(bidderFactors))
+			}
+			for bidder, dealFactors := range bidderFactors {
+				if merged.MediaTypes[bidType][bidder] == nil {
+					merged.MediaTypes[bidType][bidder] = make(ExtDealAdjustmentFactors, len(dealFactors))
+				}
+				for dealID, factor := range dealFactors {
+					merged.MediaTypes[bidType][bidder][dealID] = factor
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// ExtRequestCurrency defines the contract for bidrequest.ext.prebid.currency
+type ExtRequestCurrency struct {
+	// ConversionRates has the same shape as the conversions of the server's currency file. They take priority
+	// over the server's rates.
+	ConversionRates map[string]map[string]float64 `json:"rates"`
+	// UsePBSRates tells whether the server's rates are used for the conversions the request has no rate for.
+	// It defaults to true.
+	UsePBSRates *bool `json:"usepbsrates"`
+}
+
 // SourceExt defines the contract for bidrequest.source.ext
 type SourceExt struct {
 	SChain ExtRequestPrebidSChainSChain `json:"schain"`
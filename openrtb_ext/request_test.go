@@ -101,6 +101,88 @@ func TestCacheNothing(t *testing.T) {
 	assert.Error(t, json.Unmarshal([]byte(`{}`), &bids))
 }
 
+func TestBidAdjustmentFactorsJSON(t *testing.T) {
+	var factors ExtBidAdjustmentFactors
+	assert.NoError(t, json.Unmarshal([]byte(`{"appnexus":0.9,"mediatypes":{"banner":{"rubicon":0.8},"video":{"*":{"deal-1":1.1,"*":0.7}}}}`), &factors))
+	assert.Equal(t, ExtBidAdjustmentFactors{
+		Bidders: map[string]float64{"appnexus": 0.9},
+		MediaTypes: map[BidType]map[string]ExtDealAdjustmentFactors{
+			BidTypeBanner: {"rubicon": {"*": 0.8}},
+			BidTypeVideo:  {"*": {"deal-1": 1.1, "*": 0.7}},
+		},
+	}, factors)
+
+	roundTrip, err := json.Marshal(factors)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"appnexus":0.9,"mediatypes":{"banner":{"rubicon":{"*":0.8}},"video":{"*":{"deal-1":1.1,"*":0.7}}}}`, string(roundTrip))
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"appnexus":"high"}`), &factors), "bidadjustmentfactors.appnexus must be a number")
+}
+
+func TestBidAdjustmentFactor(t *testing.T) {
+	factors := &ExtBidAdjustmentFactors{
+		Bidders: map[string]float64{"appnexus": 0.9, "*": 0.95},
+		MediaTypes: map[BidType]map[string]ExtDealAdjustmentFactors{
+			BidTypeVideo: {
+				"appnexus": {"deal-1": 1.1},
+				"*":        {"deal-1": 1.2, "*": 0.7},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description string
+		bidder      string
+		bidType     BidType
+		dealID      string
+		expected    float64
+	}{
+		{"Bidder", "appnexus", BidTypeBanner, "", 0.9},
+		{"Any bidder", "rubicon", BidTypeBanner, "", 0.95},
+		{"Bidder's deal", "appnexus", BidTypeVideo, "deal-1", 1.1},
+		{"Any bidder's deal", "rubicon", BidTypeVideo, "deal-1", 1.2},
+		{"Any deal", "rubicon", BidTypeVideo, "deal-2", 0.7},
+		{"No deal", "rubicon", BidTypeVideo, "", 0.7},
+		{"Bidder before any bidder's media type", "appnexus", BidTypeVideo, "deal-2", 0.9},
+	}
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, factors.Factor(test.bidder, test.bidType, test.dealID), test.description)
+	}
+
+	var noFactors *ExtBidAdjustmentFactors
+	assert.Equal(t, 1.0, noFactors.Factor("appnexus", BidTypeBanner, ""))
+}
+
+func TestBidAdjustmentFactorsMerge(t *testing.T) {
+	accountFactors := &ExtBidAdjustmentFactors{
+		Bidders:    map[string]float64{"appnexus": 0.9, "rubicon": 0.8},
+		MediaTypes: map[BidType]map[string]ExtDealAdjustmentFactors{BidTypeVideo: {"appnexus": {"*": 0.7, "deal-1": 1.1}}},
+	}
+	requestFactors := &ExtBidAdjustmentFactors{
+		Bidders:    map[string]float64{"appnexus": 0.5},
+		MediaTypes: map[BidType]map[string]ExtDealAdjustmentFactors{BidTypeVideo: {"appnexus": {"*": 0.6}}},
+	}
+
+	assert.Equal(t, &ExtBidAdjustmentFactors{
+		Bidders:    map[string]float64{"appnexus": 0.5, "rubicon": 0.8},
+		MediaTypes: map[BidType]map[string]ExtDealAdjustmentFactors{BidTypeVideo: {"appnexus": {"*": 0.6, "deal-1": 1.1}}},
+	}, accountFactors.Merge(requestFactors))
+	assert.Equal(t, 0.9, accountFactors.Factor("appnexus", BidTypeBanner, ""), "The account's factors should be left as they are")
+
+	// A wildcard default of the account mustn't override a legacy factor of the request for the bidder
+	accountWildcard := &ExtBidAdjustmentFactors{
+		MediaTypes: map[BidType]map[string]ExtDealAdjustmentFactors{BidTypeBanner: {"*": {"*": 0.8}}},
+	}
+	legacyRequest := &ExtBidAdjustmentFactors{Bidders: map[string]float64{"appnexus": 0.5}}
+	merged := accountWildcard.Merge(legacyRequest)
+	assert.Equal(t, 0.5, merged.Factor("appnexus", BidTypeBanner, ""), "The request's factor for the bidder should be used")
+	assert.Equal(t, 0.8, merged.Factor("rubicon", BidTypeBanner, ""), "The account's wildcard should apply to other bidders")
+
+	var noFactors *ExtBidAdjustmentFactors
+	assert.Equal(t, requestFactors, noFactors.Merge(requestFactors))
+	assert.Equal(t, accountFactors, accountFactors.Merge(nil))
+}
+
 type granularityTestData struct {
 	json   []byte
 	target PriceGranularity
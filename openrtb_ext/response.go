@@ -19,6 +19,8 @@ type ExtBidResponse struct {
 	Usersync map[BidderName]*ExtResponseSyncData `json:"usersync,omitempty"`
 	// Prebid defines the contract for bidresponse.ext.prebid
 	Prebid *ExtResponsePrebid `json:"prebid,omitempty"`
+	// SeatNonBid defines the contract for bidresponse.ext.seatnonbid
+	SeatNonBid []ExtSeatNonBid `json:"seatnonbid,omitempty"`
 }
 
 // ExtResponseDebug defines the contract for bidresponse.ext.debug
@@ -38,7 +40,36 @@ type ExtResponseSyncData struct {
 
 // ExtResponsePrebid defines the contract for bidresponse.ext.prebid
 type ExtResponsePrebid struct {
-	AuctionTimestamp int64 `json:"auctiontimestamp,omitempty"`
+	AuctionTimestamp int64                    `json:"auctiontimestamp,omitempty"`
+	Floors           *ExtResponsePrebidFloors `json:"floors,omitempty"`
+	Modules          *ExtModules              `json:"modules,omitempty"`
+}
+
+// ExtModules defines the contract for bidresponse.ext.prebid.modules
+//
+// It reports what the hooks of the modules did during the auction, and is only included in debug mode.
+type ExtModules struct {
+	Trace []ExtModulesStage `json:"trace,omitempty"`
+}
+
+// ExtModulesStage defines the contract for bidresponse.ext.prebid.modules.trace[i]
+type ExtModulesStage struct {
+	Stage string `json:"stage"`
+	// Entity is the bidder the stage ran for. It's only set for the stages which run once per bidder.
+	Entity              string                  `json:"entity,omitempty"`
+	ExecutionTimeMillis int                     `json:"executiontimemillis"`
+	Outcomes            []ExtModulesHookOutcome `json:"outcomes"`
+}
+
+// ExtModulesHookOutcome defines the contract for bidresponse.ext.prebid.modules.trace[i].outcomes[j]
+type ExtModulesHookOutcome struct {
+	Module              string   `json:"module"`
+	Hook                string   `json:"hook"`
+	Status              string   `json:"status"`
+	ExecutionTimeMillis int      `json:"executiontimemillis"`
+	Errors              []string `json:"errors,omitempty"`
+	Warnings            []string `json:"warnings,omitempty"`
+	DebugMessages       []string `json:"debugmessages,omitempty"`
 }
 
 // ExtUserSync defines the contract for bidresponse.ext.usersync.{bidder}.syncs[i]
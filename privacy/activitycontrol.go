@@ -0,0 +1,95 @@
+package privacy
+
+import (
+	"strings"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// Activity enumerates the privacy sensitive activities which activity controls allow or deny.
+type Activity int
+
+const (
+	// ActivitySyncUser is a user sync in /cookie_sync or /setuid.
+	ActivitySyncUser Activity = iota
+
+	// ActivityTransmitUFPD is sending the user's first party data to a bidder.
+	ActivityTransmitUFPD
+
+	// ActivityTransmitPreciseGeo is sending the precise geo and full IP of the device to a bidder.
+	ActivityTransmitPreciseGeo
+
+	// ActivityTransmitEIDs is sending the user's extended IDs to a bidder.
+	ActivityTransmitEIDs
+)
+
+// ActivityScope describes who an activity is for, and the request it comes from.
+type ActivityScope struct {
+	Bidder string
+	// GDPR is true when GDPR applies to the request
+	GDPR bool
+	// Country is the ISO 3166-1 alpha-3 code of the country the request comes from, if it's known
+	Country string
+}
+
+// ActivityControl decides whether activities are allowed, going by the rules of the host and the account.
+type ActivityControl struct {
+	activities map[Activity]config.Activity
+}
+
+// NewActivityControl returns the activity controls of an account, which the host's defaults are already
+// merged into.
+func NewActivityControl(cfg *config.AccountActivities) ActivityControl {
+	if cfg == nil {
+		return ActivityControl{}
+	}
+	return ActivityControl{
+		activities: map[Activity]config.Activity{
+			ActivitySyncUser:           cfg.SyncUser,
+			ActivityTransmitUFPD:       cfg.TransmitUFPD,
+			ActivityTransmitPreciseGeo: cfg.TransmitPreciseGeo,
+			ActivityTransmitEIDs:       cfg.TransmitEIDs,
+		},
+	}
+}
+
+// Allow returns true unless the rules of the activity deny it for the scope.
+func (c ActivityControl) Allow(activity Activity, scope ActivityScope) bool {
+	cfg, ok := c.activities[activity]
+	if !ok {
+		return true
+	}
+
+	for _, rule := range cfg.Rules {
+		if conditionMatches(rule.Condition, scope) {
+			return rule.Allow
+		}
+	}
+
+	if cfg.Default != nil {
+		return *cfg.Default
+	}
+	return true
+}
+
+func conditionMatches(condition config.ActivityCondition, scope ActivityScope) bool {
+	if condition.GDPR != nil && *condition.GDPR != scope.GDPR {
+		return false
+	}
+	if len(condition.Bidders) > 0 && !containsFold(condition.Bidders, scope.Bidder) {
+		return false
+	}
+	if len(condition.Countries) > 0 && !containsFold(condition.Countries, scope.Country) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
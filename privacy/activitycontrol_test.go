@@ -0,0 +1,110 @@
+package privacy
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityControlAllow(t *testing.T) {
+	allow := true
+	deny := false
+	gdprApplies := true
+
+	testCases := []struct {
+		description string
+		activity    config.Activity
+		scope       ActivityScope
+		expected    bool
+	}{
+		{
+			description: "No Rules - Allowed",
+			activity:    config.Activity{},
+			scope:       ActivityScope{Bidder: "appnexus"},
+			expected:    true,
+		},
+		{
+			description: "No Rules - Default Deny",
+			activity:    config.Activity{Default: &deny},
+			scope:       ActivityScope{Bidder: "appnexus"},
+			expected:    false,
+		},
+		{
+			description: "Bidder Rule Matches",
+			activity: config.Activity{Rules: []config.ActivityRule{
+				{Allow: false, Condition: config.ActivityCondition{Bidders: []string{"appnexus"}}},
+			}},
+			scope:    ActivityScope{Bidder: "APPNEXUS"},
+			expected: false,
+		},
+		{
+			description: "Bidder Rule Doesn't Match - Falls Back To Default",
+			activity: config.Activity{Default: &allow, Rules: []config.ActivityRule{
+				{Allow: false, Condition: config.ActivityCondition{Bidders: []string{"appnexus"}}},
+			}},
+			scope:    ActivityScope{Bidder: "rubicon"},
+			expected: true,
+		},
+		{
+			description: "First Matching Rule Wins",
+			activity: config.Activity{Default: &deny, Rules: []config.ActivityRule{
+				{Allow: true, Condition: config.ActivityCondition{Bidders: []string{"appnexus"}}},
+				{Allow: false},
+			}},
+			scope:    ActivityScope{Bidder: "appnexus"},
+			expected: true,
+		},
+		{
+			description: "GDPR Condition Matches",
+			activity: config.Activity{Rules: []config.ActivityRule{
+				{Allow: false, Condition: config.ActivityCondition{GDPR: &gdprApplies}},
+			}},
+			scope:    ActivityScope{Bidder: "appnexus", GDPR: true},
+			expected: false,
+		},
+		{
+			description: "GDPR Condition Doesn't Match",
+			activity: config.Activity{Rules: []config.ActivityRule{
+				{Allow: false, Condition: config.ActivityCondition{GDPR: &gdprApplies}},
+			}},
+			scope:    ActivityScope{Bidder: "appnexus", GDPR: false},
+			expected: true,
+		},
+		{
+			description: "All Parts Of The Condition Must Match",
+			activity: config.Activity{Rules: []config.ActivityRule{
+				{Allow: false, Condition: config.ActivityCondition{Bidders: []string{"appnexus"}, Countries: []string{"USA"}}},
+			}},
+			scope:    ActivityScope{Bidder: "appnexus", Country: "CAN"},
+			expected: true,
+		},
+		{
+			description: "Country Condition Matches",
+			activity: config.Activity{Rules: []config.ActivityRule{
+				{Allow: false, Condition: config.ActivityCondition{Countries: []string{"USA"}}},
+			}},
+			scope:    ActivityScope{Bidder: "appnexus", Country: "usa"},
+			expected: false,
+		},
+		{
+			description: "Country Condition - Unknown Country",
+			activity: config.Activity{Rules: []config.ActivityRule{
+				{Allow: false, Condition: config.ActivityCondition{Countries: []string{"USA"}}},
+			}},
+			scope:    ActivityScope{Bidder: "appnexus"},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		control := NewActivityControl(&config.AccountActivities{TransmitEIDs: test.activity})
+		assert.Equal(t, test.expected, control.Allow(ActivityTransmitEIDs, test.scope), test.description)
+		assert.True(t, control.Allow(ActivitySyncUser, test.scope), test.description+":other activity")
+	}
+}
+
+func TestActivityControlNil(t *testing.T) {
+	control := NewActivityControl(nil)
+	assert.True(t, control.Allow(ActivitySyncUser, ActivityScope{Bidder: "appnexus"}))
+}
@@ -1,6 +1,8 @@
 package ccpa
 
 import (
+	"encoding/json"
+
 	"github.com/mxmCherry/openrtb"
 )
 
@@ -23,3 +25,44 @@ func (c ConsentWriter) Write(req *openrtb.BidRequest) error {
 
 	return nil
 }
+
+// GPCWriter implements the PolicyWriter interface for the Global Privacy Control signal.
+type GPCWriter struct {
+	GPC bool
+}
+
+// Write mutates an OpenRTB bid request with the Global Privacy Control signal. A false signal removes it.
+func (g GPCWriter) Write(req *openrtb.BidRequest) error {
+	if req == nil {
+		return nil
+	}
+
+	extMap := make(map[string]interface{})
+	if req.Regs != nil && len(req.Regs.Ext) > 0 {
+		if err := json.Unmarshal(req.Regs.Ext, &extMap); err != nil {
+			return err
+		}
+	} else if !g.GPC {
+		return nil
+	}
+
+	delete(extMap, "gpc")
+	if g.GPC {
+		extMap["gpc"] = gpcEnabled
+	}
+
+	regs := openrtb.Regs{}
+	if req.Regs != nil {
+		regs = *req.Regs
+	}
+	regs.Ext = nil
+	if len(extMap) > 0 {
+		ext, err := json.Marshal(extMap)
+		if err != nil {
+			return err
+		}
+		regs.Ext = ext
+	}
+	req.Regs = &regs
+	return nil
+}
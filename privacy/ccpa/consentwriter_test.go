@@ -49,3 +49,58 @@ func TestConsentWriter(t *testing.T) {
 		assert.Equal(t, test.expected, test.request, test.description)
 	}
 }
+
+func TestGPCWriter(t *testing.T) {
+	testCases := []struct {
+		description   string
+		writer        GPCWriter
+		request       *openrtb.BidRequest
+		expected      *openrtb.BidRequest
+		expectedError bool
+	}{
+		{
+			description: "Nil Request",
+			writer:      GPCWriter{GPC: true},
+			request:     nil,
+			expected:    nil,
+		},
+		{
+			description: "Success",
+			writer:      GPCWriter{GPC: true},
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"us_privacy":"1NNN"}`)},
+			},
+			expected: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpc":"1","us_privacy":"1NNN"}`)},
+			},
+		},
+		{
+			description: "Remove",
+			writer:      GPCWriter{GPC: false},
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{COPPA: 1, Ext: json.RawMessage(`{"gpc":"1"}`)},
+			},
+			expected: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{COPPA: 1},
+			},
+		},
+		{
+			description: "Error With Regs.Ext - Does Not Mutate",
+			writer:      GPCWriter{GPC: true},
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`malformed}`)},
+			},
+			expectedError: true,
+			expected: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`malformed}`)},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		err := test.writer.Write(test.request)
+
+		assertError(t, test.expectedError, err, test.description)
+		assert.Equal(t, test.expected, test.request, test.description)
+	}
+}
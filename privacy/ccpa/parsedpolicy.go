@@ -34,6 +34,7 @@ func ValidateConsent(consent string) bool {
 type ParsedPolicy struct {
 	consentSpecified      bool
 	consentOptOutSale     bool
+	gpcOptOutSale         bool
 	noSaleForAllBidders   bool
 	noSaleSpecificBidders map[string]struct{}
 }
@@ -54,6 +55,7 @@ func (p Policy) Parse(validBidders map[string]struct{}) (ParsedPolicy, error) {
 	return ParsedPolicy{
 		consentSpecified:      p.Consent != "",
 		consentOptOutSale:     consentOptOut,
+		gpcOptOutSale:         p.GPC,
 		noSaleForAllBidders:   noSaleForAllBidders,
 		noSaleSpecificBidders: noSaleSpecificBidders,
 	}, nil
@@ -117,9 +119,10 @@ func parseNoSaleBidders(noSaleBidders []string, validBidders map[string]struct{}
 	return
 }
 
-// CanEnforce returns true when consent is specifically provided by the publisher, as opposed to an empty string.
+// CanEnforce returns true when consent is specifically provided by the publisher, as opposed to an empty string,
+// or the user's browser sends the Global Privacy Control signal.
 func (p ParsedPolicy) CanEnforce() bool {
-	return p.consentSpecified
+	return p.consentSpecified || p.gpcOptOutSale
 }
 
 func (p ParsedPolicy) isNoSaleForBidder(bidder string) bool {
@@ -131,7 +134,8 @@ func (p ParsedPolicy) isNoSaleForBidder(bidder string) bool {
 	return exists
 }
 
-// ShouldEnforce returns true when the opt-out signal is explicitly detected.
+// ShouldEnforce returns true when the opt-out signal is explicitly detected, either in the consent string or
+// as the Global Privacy Control signal.
 func (p ParsedPolicy) ShouldEnforce(bidder string) bool {
-	return !p.isNoSaleForBidder(bidder) && p.consentOptOutSale
+	return !p.isNoSaleForBidder(bidder) && (p.consentOptOutSale || p.gpcOptOutSale)
 }
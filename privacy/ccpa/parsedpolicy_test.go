@@ -49,6 +49,7 @@ func TestParse(t *testing.T) {
 		description    string
 		consent        string
 		noSaleBidders  []string
+		gpc            bool
 		expectedPolicy ParsedPolicy
 		expectedError  string
 	}{
@@ -77,10 +78,23 @@ func TestParse(t *testing.T) {
 				noSaleSpecificBidders: map[string]struct{}{"a": {}},
 			},
 		},
+		{
+			description:   "Success - GPC",
+			consent:       "",
+			noSaleBidders: []string{},
+			gpc:           true,
+			expectedPolicy: ParsedPolicy{
+				consentSpecified:      false,
+				consentOptOutSale:     false,
+				gpcOptOutSale:         true,
+				noSaleForAllBidders:   false,
+				noSaleSpecificBidders: map[string]struct{}{},
+			},
+		},
 	}
 
 	for _, test := range testCases {
-		policy := Policy{test.consent, test.noSaleBidders}
+		policy := Policy{Consent: test.consent, NoSaleBidders: test.noSaleBidders, GPC: test.gpc}
 
 		result, err := policy.Parse(validBidders)
 
@@ -292,6 +306,16 @@ func TestCanEnforce(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			description: "GPC",
+			policy: ParsedPolicy{
+				consentSpecified:      false,
+				gpcOptOutSale:         true,
+				noSaleForAllBidders:   false,
+				noSaleSpecificBidders: map[string]struct{}{},
+			},
+			expected: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -373,6 +397,28 @@ func TestShouldEnforce(t *testing.T) {
 			bidder:   "a",
 			expected: true,
 		},
+		{
+			description: "Enforced - GPC",
+			policy: ParsedPolicy{
+				consentSpecified:      false,
+				gpcOptOutSale:         true,
+				noSaleForAllBidders:   false,
+				noSaleSpecificBidders: map[string]struct{}{},
+			},
+			bidder:   "a",
+			expected: true,
+		},
+		{
+			description: "Not Enforced - GPC With All Bidders No Sale",
+			policy: ParsedPolicy{
+				consentSpecified:      false,
+				gpcOptOutSale:         true,
+				noSaleForAllBidders:   true,
+				noSaleSpecificBidders: map[string]struct{}{},
+			},
+			bidder:   "a",
+			expected: false,
+		},
 	}
 
 	for _, test := range testCases {
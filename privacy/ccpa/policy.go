@@ -9,16 +9,21 @@ import (
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
+const gpcEnabled = "1"
+
 // Policy represents the CCPA regulatory information from an OpenRTB bid request.
 type Policy struct {
 	Consent       string
 	NoSaleBidders []string
+	// GPC is true when the user's browser sends the Global Privacy Control signal.
+	GPC bool
 }
 
 // ReadFromRequest extracts the CCPA regulatory information from an OpenRTB bid request.
 func ReadFromRequest(req *openrtb.BidRequest) (Policy, error) {
 	var consent string
 	var noSaleBidders []string
+	var gpc bool
 
 	if req == nil {
 		return Policy{}, nil
@@ -31,6 +36,7 @@ func ReadFromRequest(req *openrtb.BidRequest) (Policy, error) {
 			return Policy{}, fmt.Errorf("error reading request.regs.ext: %s", err)
 		}
 		consent = ext.USPrivacy
+		gpc = ext.GPC == gpcEnabled
 	}
 
 	// Read no sale bidders from request.ext.prebid
@@ -42,7 +48,7 @@ func ReadFromRequest(req *openrtb.BidRequest) (Policy, error) {
 		noSaleBidders = ext.Prebid.NoSale
 	}
 
-	return Policy{consent, noSaleBidders}, nil
+	return Policy{Consent: consent, NoSaleBidders: noSaleBidders, GPC: gpc}, nil
 }
 
 // Write mutates an OpenRTB bid request with the CCPA regulatory information.
@@ -26,6 +26,23 @@ func TestReadFromRequest(t *testing.T) {
 				NoSaleBidders: []string{"a", "b"},
 			},
 		},
+		{
+			description: "Success - GPC",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"us_privacy":"ABC","gpc":"1"}`)},
+			},
+			expectedPolicy: Policy{
+				Consent: "ABC",
+				GPC:     true,
+			},
+		},
+		{
+			description: "GPC Not Set To 1",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpc":"0"}`)},
+			},
+			expectedPolicy: Policy{},
+		},
 		{
 			description: "Nil Request",
 			request:     nil,
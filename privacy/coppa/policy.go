@@ -0,0 +1,31 @@
+package coppa
+
+import (
+	"github.com/mxmCherry/openrtb"
+)
+
+const coppaApplies = 1
+
+// Policy represents the COPPA (Children's Online Privacy Protection Act) policy for an OpenRTB bid request.
+type Policy struct {
+	Enforce bool
+}
+
+// ReadFromRequest extracts the COPPA (Children's Online Privacy Protection Act) policy from an OpenRTB bid request.
+func ReadFromRequest(req *openrtb.BidRequest) (policy Policy) {
+	if req != nil && req.Regs != nil {
+		policy.Enforce = req.Regs.COPPA == coppaApplies
+	}
+	return
+}
+
+// CanEnforce returns true when the COPPA (Children's Online Privacy Protection Act) flag is set by the publisher.
+func (p Policy) CanEnforce() bool {
+	return p.Enforce
+}
+
+// ShouldEnforce returns true when the COPPA (Children's Online Privacy Protection Act) policy is in effect. It
+// applies to all bidders alike.
+func (p Policy) ShouldEnforce(bidder string) bool {
+	return p.Enforce
+}
@@ -0,0 +1,49 @@
+package coppa
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFromRequest(t *testing.T) {
+	testCases := []struct {
+		description    string
+		request        *openrtb.BidRequest
+		expectedPolicy Policy
+	}{
+		{
+			description:    "Nil Request",
+			request:        nil,
+			expectedPolicy: Policy{Enforce: false},
+		},
+		{
+			description:    "Nil Regs",
+			request:        &openrtb.BidRequest{Regs: nil},
+			expectedPolicy: Policy{Enforce: false},
+		},
+		{
+			description:    "Disabled",
+			request:        &openrtb.BidRequest{Regs: &openrtb.Regs{COPPA: 0}},
+			expectedPolicy: Policy{Enforce: false},
+		},
+		{
+			description:    "Enabled",
+			request:        &openrtb.BidRequest{Regs: &openrtb.Regs{COPPA: 1}},
+			expectedPolicy: Policy{Enforce: true},
+		},
+	}
+
+	for _, test := range testCases {
+		p := ReadFromRequest(test.request)
+		assert.Equal(t, test.expectedPolicy, p, test.description)
+	}
+}
+
+func TestShouldEnforce(t *testing.T) {
+	assert.True(t, Policy{Enforce: true}.CanEnforce())
+	assert.True(t, Policy{Enforce: true}.ShouldEnforce("anyBidder"))
+	assert.False(t, Policy{Enforce: false}.CanEnforce())
+	assert.False(t, Policy{Enforce: false}.ShouldEnforce("anyBidder"))
+}
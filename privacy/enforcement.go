@@ -2,6 +2,12 @@ package privacy
 
 import (
 	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+)
+
+var (
+	defaultIPMask      = config.IPMask{IPv4PrefixLength: 24, IPv6PrefixLength: 112}
+	defaultCOPPAIPMask = config.IPMask{IPv4PrefixLength: 24, IPv6PrefixLength: 96}
 )
 
 // Enforcement represents the privacy policies to enforce for an OpenRTB bid request.
@@ -10,12 +16,26 @@ type Enforcement struct {
 	COPPA   bool
 	GDPRGeo bool
 	GDPRID  bool
+	GPP     bool
 	LMT     bool
+
+	// EIDs, PreciseGeo and UFPD are set when activity controls deny sending the user's extended IDs, the
+	// precise geo of the device or the user's first party data.
+	EIDs       bool
+	PreciseGeo bool
+	UFPD       bool
+
+	// IPMasks are how much of the IP address of the device each policy keeps. The masks which aren't set
+	// use the defaults.
+	IPMasks config.AccountIPMasks
+	// IPHashKey is the host's secret key for the IP masks which hash. If it's empty, the masks zero the
+	// bits they remove instead.
+	IPHashKey []byte
 }
 
 // Any returns true if at least one privacy policy requires enforcement.
 func (e Enforcement) Any() bool {
-	return e.CCPA || e.COPPA || e.GDPRGeo || e.GDPRID || e.LMT
+	return e.CCPA || e.COPPA || e.GDPRGeo || e.GDPRID || e.GPP || e.LMT || e.EIDs || e.PreciseGeo || e.UFPD
 }
 
 // Apply cleans personally identifiable information from an OpenRTB bid request.
@@ -27,11 +47,14 @@ func (e Enforcement) apply(bidRequest *openrtb.BidRequest, scrubber Scrubber) {
 	if bidRequest != nil && e.Any() {
 		bidRequest.Device = scrubber.ScrubDevice(bidRequest.Device, e.getDeviceIDScrubStrategy(), e.getIPv4ScrubStrategy(), e.getIPv6ScrubStrategy(), e.getGeoScrubStrategy())
 		bidRequest.User = scrubber.ScrubUser(bidRequest.User, e.getUserScrubStrategy(), e.getGeoScrubStrategy())
+		if e.UFPD || e.EIDs {
+			bidRequest.User = scrubber.ScrubUserFPD(bidRequest.User, e.UFPD, e.EIDs)
+		}
 	}
 }
 
 func (e Enforcement) getDeviceIDScrubStrategy() ScrubStrategyDeviceID {
-	if e.COPPA || e.GDPRID || e.CCPA || e.LMT {
+	if e.COPPA || e.GDPRID || e.CCPA || e.GPP || e.LMT {
 		return ScrubStrategyDeviceIDAll
 	}
 
@@ -39,23 +62,74 @@ func (e Enforcement) getDeviceIDScrubStrategy() ScrubStrategyDeviceID {
 }
 
 func (e Enforcement) getIPv4ScrubStrategy() ScrubStrategyIPV4 {
-	if e.COPPA || e.GDPRGeo || e.CCPA || e.LMT {
-		return ScrubStrategyIPV4Lowest8
+	strategy := ScrubStrategyIPV4None
+	hash := false
+	for i, mask := range e.getIPMasks() {
+		maskBits := 32 - mask.IPv4PrefixLength
+		if i == 0 || maskBits > strategy.MaskBits {
+			strategy.MaskBits = maskBits
+			hash = mask.Hash
+		} else if maskBits == strategy.MaskBits {
+			hash = hash && mask.Hash
+		}
+	}
+	if hash {
+		strategy.HashKey = e.IPHashKey
 	}
 
-	return ScrubStrategyIPV4None
+	return strategy
 }
 
 func (e Enforcement) getIPv6ScrubStrategy() ScrubStrategyIPV6 {
-	if e.COPPA {
-		return ScrubStrategyIPV6Lowest32
+	strategy := ScrubStrategyIPV6None
+	hash := false
+	for i, mask := range e.getIPMasks() {
+		maskBits := 128 - mask.IPv6PrefixLength
+		if i == 0 || maskBits > strategy.MaskBits {
+			strategy.MaskBits = maskBits
+			hash = mask.Hash
+		} else if maskBits == strategy.MaskBits {
+			hash = hash && mask.Hash
+		}
 	}
+	if hash {
+		strategy.HashKey = e.IPHashKey
+	}
+
+	return strategy
+}
+
+// getIPMasks returns the IP masks of the policies which require the IP address of the device be anonymized.
+func (e Enforcement) getIPMasks() []config.IPMask {
+	var masks []config.IPMask
 
-	if e.GDPRGeo || e.CCPA || e.LMT {
-		return ScrubStrategyIPV6Lowest16
+	if e.COPPA {
+		masks = append(masks, ipMaskWithDefaults(e.IPMasks.COPPA, defaultCOPPAIPMask))
+	}
+	if e.GDPRGeo {
+		masks = append(masks, ipMaskWithDefaults(e.IPMasks.GDPR, defaultIPMask))
+	}
+	if e.CCPA || e.GPP {
+		masks = append(masks, ipMaskWithDefaults(e.IPMasks.CCPA, defaultIPMask))
+	}
+	if e.LMT {
+		masks = append(masks, ipMaskWithDefaults(e.IPMasks.LMT, defaultIPMask))
+	}
+	if e.PreciseGeo {
+		masks = append(masks, ipMaskWithDefaults(e.IPMasks.PreciseGeo, defaultIPMask))
 	}
 
-	return ScrubStrategyIPV6None
+	return masks
+}
+
+func ipMaskWithDefaults(mask config.IPMask, defaults config.IPMask) config.IPMask {
+	if mask.IPv4PrefixLength == 0 {
+		mask.IPv4PrefixLength = defaults.IPv4PrefixLength
+	}
+	if mask.IPv6PrefixLength == 0 {
+		mask.IPv6PrefixLength = defaults.IPv6PrefixLength
+	}
+	return mask
 }
 
 func (e Enforcement) getGeoScrubStrategy() ScrubStrategyGeo {
@@ -63,7 +137,7 @@ func (e Enforcement) getGeoScrubStrategy() ScrubStrategyGeo {
 		return ScrubStrategyGeoFull
 	}
 
-	if e.GDPRGeo || e.CCPA || e.LMT {
+	if e.GDPRGeo || e.CCPA || e.GPP || e.LMT || e.PreciseGeo {
 		return ScrubStrategyGeoReducedPrecision
 	}
 
@@ -75,7 +149,7 @@ func (e Enforcement) getUserScrubStrategy() ScrubStrategyUser {
 		return ScrubStrategyUserIDAndDemographic
 	}
 
-	if e.CCPA || e.LMT {
+	if e.CCPA || e.GPP || e.LMT {
 		return ScrubStrategyUserID
 	}
 
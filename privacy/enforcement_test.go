@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,6 +22,7 @@ func TestAny(t *testing.T) {
 				COPPA:   false,
 				GDPRGeo: false,
 				GDPRID:  false,
+				GPP:     false,
 				LMT:     false,
 			},
 			expected: false,
@@ -32,6 +34,7 @@ func TestAny(t *testing.T) {
 				COPPA:   true,
 				GDPRGeo: true,
 				GDPRID:  true,
+				GPP:     true,
 				LMT:     true,
 			},
 			expected: true,
@@ -162,6 +165,18 @@ func TestApply(t *testing.T) {
 			expectedUser:       ScrubStrategyUserNone,
 			expectedUserGeo:    ScrubStrategyGeoReducedPrecision,
 		},
+		{
+			description: "GPP Only",
+			enforcement: Enforcement{
+				GPP: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDAll,
+			expectedDeviceIPv4: ScrubStrategyIPV4Lowest8,
+			expectedDeviceIPv6: ScrubStrategyIPV6Lowest16,
+			expectedDeviceGeo:  ScrubStrategyGeoReducedPrecision,
+			expectedUser:       ScrubStrategyUserID,
+			expectedUserGeo:    ScrubStrategyGeoReducedPrecision,
+		},
 		{
 			description: "LMT Only",
 			enforcement: Enforcement{
@@ -216,6 +231,150 @@ func TestApply(t *testing.T) {
 	}
 }
 
+func TestApplyActivities(t *testing.T) {
+	testCases := []struct {
+		description        string
+		enforcement        Enforcement
+		expectedDeviceIPv4 ScrubStrategyIPV4
+		expectedDeviceIPv6 ScrubStrategyIPV6
+		expectedGeo        ScrubStrategyGeo
+		expectedFPD        bool
+		expectedEIDs       bool
+	}{
+		{
+			description:        "Precise Geo Denied",
+			enforcement:        Enforcement{PreciseGeo: true},
+			expectedDeviceIPv4: ScrubStrategyIPV4Lowest8,
+			expectedDeviceIPv6: ScrubStrategyIPV6Lowest16,
+			expectedGeo:        ScrubStrategyGeoReducedPrecision,
+		},
+		{
+			description:        "UFPD And EIDs Denied",
+			enforcement:        Enforcement{UFPD: true, EIDs: true},
+			expectedDeviceIPv4: ScrubStrategyIPV4None,
+			expectedDeviceIPv6: ScrubStrategyIPV6None,
+			expectedGeo:        ScrubStrategyGeoNone,
+			expectedFPD:        true,
+			expectedEIDs:       true,
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb.BidRequest{
+			Device: &openrtb.Device{},
+			User:   &openrtb.User{},
+		}
+		replacedDevice := &openrtb.Device{}
+		replacedUser := &openrtb.User{}
+		scrubbedUser := &openrtb.User{}
+
+		m := &mockScrubber{}
+		m.On("ScrubDevice", req.Device, ScrubStrategyDeviceIDNone, test.expectedDeviceIPv4, test.expectedDeviceIPv6, test.expectedGeo).Return(replacedDevice).Once()
+		m.On("ScrubUser", req.User, ScrubStrategyUserNone, test.expectedGeo).Return(replacedUser).Once()
+		if test.expectedFPD || test.expectedEIDs {
+			m.On("ScrubUserFPD", replacedUser, test.expectedFPD, test.expectedEIDs).Return(scrubbedUser).Once()
+		} else {
+			scrubbedUser = replacedUser
+		}
+
+		test.enforcement.apply(req, m)
+
+		m.AssertExpectations(t)
+		assert.Same(t, replacedDevice, req.Device, test.description+":device")
+		assert.Same(t, scrubbedUser, req.User, test.description+":user")
+	}
+}
+
+func TestIPScrubStrategies(t *testing.T) {
+	ipMasks := config.AccountIPMasks{
+		GDPR:  config.IPMask{IPv4PrefixLength: 16, IPv6PrefixLength: 48},
+		CCPA:  config.IPMask{IPv4PrefixLength: 24, IPv6PrefixLength: 64, Hash: true},
+		COPPA: config.IPMask{IPv4PrefixLength: 16, IPv6PrefixLength: 48, Hash: true},
+
+		PreciseGeo: config.IPMask{IPv4PrefixLength: 20, IPv6PrefixLength: 56},
+	}
+	hashKey := []byte("secret")
+
+	testCases := []struct {
+		description  string
+		enforcement  Enforcement
+		expectedIPv4 ScrubStrategyIPV4
+		expectedIPv6 ScrubStrategyIPV6
+	}{
+		{
+			description:  "None",
+			enforcement:  Enforcement{IPMasks: ipMasks},
+			expectedIPv4: ScrubStrategyIPV4None,
+			expectedIPv6: ScrubStrategyIPV6None,
+		},
+		{
+			description:  "GDPR",
+			enforcement:  Enforcement{GDPRGeo: true, IPMasks: ipMasks},
+			expectedIPv4: ScrubStrategyIPV4{MaskBits: 16},
+			expectedIPv6: ScrubStrategyIPV6{MaskBits: 80},
+		},
+		{
+			description:  "CCPA - Hashed",
+			enforcement:  Enforcement{CCPA: true, IPMasks: ipMasks, IPHashKey: hashKey},
+			expectedIPv4: ScrubStrategyIPV4{MaskBits: 8, HashKey: hashKey},
+			expectedIPv6: ScrubStrategyIPV6{MaskBits: 64, HashKey: hashKey},
+		},
+		{
+			description:  "CCPA - Hash Without A Key Zeroes The Bits",
+			enforcement:  Enforcement{CCPA: true, IPMasks: ipMasks},
+			expectedIPv4: ScrubStrategyIPV4{MaskBits: 8},
+			expectedIPv6: ScrubStrategyIPV6{MaskBits: 64},
+		},
+		{
+			description:  "GPP - Uses CCPA Mask",
+			enforcement:  Enforcement{GPP: true, IPMasks: ipMasks, IPHashKey: hashKey},
+			expectedIPv4: ScrubStrategyIPV4{MaskBits: 8, HashKey: hashKey},
+			expectedIPv6: ScrubStrategyIPV6{MaskBits: 64, HashKey: hashKey},
+		},
+		{
+			description:  "Precise Geo",
+			enforcement:  Enforcement{PreciseGeo: true, IPMasks: ipMasks},
+			expectedIPv4: ScrubStrategyIPV4{MaskBits: 12},
+			expectedIPv6: ScrubStrategyIPV6{MaskBits: 72},
+		},
+		{
+			description:  "Precise Geo - Not Set Uses Default",
+			enforcement:  Enforcement{PreciseGeo: true},
+			expectedIPv4: ScrubStrategyIPV4Lowest8,
+			expectedIPv6: ScrubStrategyIPV6Lowest16,
+		},
+		{
+			description:  "LMT - Not Set Uses Default",
+			enforcement:  Enforcement{LMT: true, IPMasks: ipMasks},
+			expectedIPv4: ScrubStrategyIPV4Lowest8,
+			expectedIPv6: ScrubStrategyIPV6Lowest16,
+		},
+		{
+			description:  "GDPR & CCPA - Mask Which Keeps The Fewest Bits Wins",
+			enforcement:  Enforcement{GDPRGeo: true, CCPA: true, IPMasks: ipMasks},
+			expectedIPv4: ScrubStrategyIPV4{MaskBits: 16},
+			expectedIPv6: ScrubStrategyIPV6{MaskBits: 80},
+		},
+		{
+			description:  "GDPR & COPPA - Same Length Hashes Only If Both Do",
+			enforcement:  Enforcement{GDPRGeo: true, COPPA: true, IPMasks: ipMasks, IPHashKey: hashKey},
+			expectedIPv4: ScrubStrategyIPV4{MaskBits: 16},
+			expectedIPv6: ScrubStrategyIPV6{MaskBits: 80},
+		},
+		{
+			description:  "COPPA - Not Set Uses Default",
+			enforcement:  Enforcement{COPPA: true},
+			expectedIPv4: ScrubStrategyIPV4Lowest8,
+			expectedIPv6: ScrubStrategyIPV6Lowest32,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedIPv4, test.enforcement.getIPv4ScrubStrategy(), test.description+":ipv4")
+		assert.Equal(t, test.expectedIPv6, test.enforcement.getIPv6ScrubStrategy(), test.description+":ipv6")
+	}
+}
+
 func TestApplyNoneApplicable(t *testing.T) {
 	req := &openrtb.BidRequest{}
 
@@ -257,3 +416,8 @@ func (m *mockScrubber) ScrubUser(user *openrtb.User, strategy ScrubStrategyUser,
 	args := m.Called(user, strategy, geo)
 	return args.Get(0).(*openrtb.User)
 }
+
+func (m *mockScrubber) ScrubUserFPD(user *openrtb.User, fpd bool, eids bool) *openrtb.User {
+	args := m.Called(user, fpd, eids)
+	return args.Get(0).(*openrtb.User)
+}
@@ -0,0 +1,215 @@
+package gpp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SectionID identifies a section of a GPP string, see: https://github.com/InteractiveAdvertisingBureau/Global-Privacy-Platform/blob/main/Sections/Section%20Information.md
+type SectionID int8
+
+const (
+	SectionTCFEU2 SectionID = 2
+	SectionUSPV1  SectionID = 6
+	SectionUSNat  SectionID = 7
+	SectionUSCA   SectionID = 8
+	SectionUSVA   SectionID = 9
+	SectionUSCO   SectionID = 10
+	SectionUSUT   SectionID = 11
+	SectionUSCT   SectionID = 12
+)
+
+const (
+	headerType = 3
+	maxBits    = 4096
+)
+
+// usOptOutFields are the bit offsets of the opt out fields of the US national and state sections, from the
+// start of their core segment. Each of them is 2 bits long, and 1 means the user opted out.
+var usOptOutFields = map[SectionID][]int{
+	// SaleOptOut, SharingOptOut, TargetedAdvertisingOptOut
+	SectionUSNat: {18, 20, 22},
+	// SaleOptOut, SharingOptOut
+	SectionUSCA: {12, 14},
+	// SaleOptOut, TargetedAdvertisingOptOut
+	SectionUSVA: {12, 14},
+	SectionUSCO: {12, 14},
+	SectionUSUT: {14, 16},
+	SectionUSCT: {12, 14},
+}
+
+const usOptedOut = 1
+
+// consent is a decoded GPP string.
+type consent struct {
+	// sections are the encoded sections of the string, by ID.
+	sections map[SectionID]string
+}
+
+// parseConsent decodes the header of a GPP string, and splits the sections it lists.
+func parseConsent(gpp string) (consent, error) {
+	parts := strings.Split(gpp, "~")
+	header, err := newBitReader(parts[0])
+	if err != nil {
+		return consent{}, fmt.Errorf("has an invalid header: %v", err)
+	}
+	if t, err := header.readInt(6); err != nil || t != headerType {
+		return consent{}, errors.New("has an invalid header: wrong type")
+	}
+	if _, err := header.readInt(6); err != nil {
+		return consent{}, fmt.Errorf("has an invalid header: %v", err)
+	}
+	ids, err := header.readFibonacciRange()
+	if err != nil {
+		return consent{}, fmt.Errorf("has an invalid header: %v", err)
+	}
+	if len(ids) != len(parts)-1 {
+		return consent{}, fmt.Errorf("lists %d sections in its header, but has %d", len(ids), len(parts)-1)
+	}
+
+	c := consent{sections: make(map[SectionID]string, len(ids))}
+	for i, id := range ids {
+		if parts[i+1] == "" {
+			return consent{}, fmt.Errorf("has an empty section %d", id)
+		}
+		c.sections[id] = parts[i+1]
+	}
+	return c, nil
+}
+
+// usOptOut returns true if the US national or state section opts the user out of the sale or sharing of their
+// data, or of targeted advertising.
+func usOptOut(id SectionID, section string) (bool, error) {
+	fields, ok := usOptOutFields[id]
+	if !ok {
+		return false, nil
+	}
+	// The optional subsections, such as the GPC one, follow the core segment.
+	core, err := newBitReader(strings.SplitN(section, ".", 2)[0])
+	if err != nil {
+		return false, fmt.Errorf("has an invalid section %d: %v", id, err)
+	}
+	for _, offset := range fields {
+		core.pos = offset
+		value, err := core.readInt(2)
+		if err != nil {
+			return false, fmt.Errorf("has an invalid section %d: %v", id, err)
+		}
+		if value == usOptedOut {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// bitReader reads the fields of a base64url encoded bit string.
+type bitReader struct {
+	bits []bool
+	pos  int
+}
+
+const base64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+func newBitReader(encoded string) (*bitReader, error) {
+	if encoded == "" {
+		return nil, errors.New("empty")
+	}
+	if len(encoded)*6 > maxBits {
+		return nil, errors.New("too long")
+	}
+	bits := make([]bool, 0, len(encoded)*6)
+	for i := 0; i < len(encoded); i++ {
+		value := strings.IndexByte(base64URLAlphabet, encoded[i])
+		if value < 0 {
+			return nil, fmt.Errorf("invalid character %q", encoded[i])
+		}
+		for bit := 5; bit >= 0; bit-- {
+			bits = append(bits, value&(1<<uint(bit)) != 0)
+		}
+	}
+	return &bitReader{bits: bits}, nil
+}
+
+func (r *bitReader) readBit() (bool, error) {
+	if r.pos >= len(r.bits) {
+		return false, errors.New("too short")
+	}
+	bit := r.bits[r.pos]
+	r.pos++
+	return bit, nil
+}
+
+func (r *bitReader) readInt(length int) (int, error) {
+	value := 0
+	for i := 0; i < length; i++ {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		value <<= 1
+		if bit {
+			value |= 1
+		}
+	}
+	return value, nil
+}
+
+// readFibonacci reads a Fibonacci coded integer, which ends with two 1 bits in a row.
+func (r *bitReader) readFibonacci() (int, error) {
+	value := 0
+	previous := false
+	for prev, fib := 1, 1; ; prev, fib = fib, prev+fib {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		if bit && previous {
+			return value, nil
+		}
+		if bit {
+			value += fib
+		}
+		previous = bit
+		if value > maxBits {
+			return 0, errors.New("integer out of range")
+		}
+	}
+}
+
+// readFibonacciRange reads a list of IDs as a count followed by the Fibonacci coded entries. Each entry is an ID or
+// a range of IDs, written as offsets from the previous ID.
+func (r *bitReader) readFibonacciRange() ([]SectionID, error) {
+	count, err := r.readInt(12)
+	if err != nil {
+		return nil, err
+	}
+	var ids []SectionID
+	last := 0
+	for i := 0; i < count; i++ {
+		isRange, err := r.readBit()
+		if err != nil {
+			return nil, err
+		}
+		offset, err := r.readFibonacci()
+		if err != nil {
+			return nil, err
+		}
+		start := last + offset
+		end := start
+		if isRange {
+			if offset, err = r.readFibonacci(); err != nil {
+				return nil, err
+			}
+			end = start + offset
+		}
+		if end > 127 {
+			return nil, errors.New("section id out of range")
+		}
+		for id := start; id <= end; id++ {
+			ids = append(ids, SectionID(id))
+		}
+		last = end
+	}
+	return ids, nil
+}
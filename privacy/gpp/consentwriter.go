@@ -0,0 +1,118 @@
+package gpp
+
+import (
+	"encoding/json"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/gdpr"
+)
+
+// ConsentWriter implements the PolicyWriter interface for GPP.
+type ConsentWriter struct {
+	Consent string
+	SIDs    []SectionID
+}
+
+// Write mutates an OpenRTB bid request with the GPP string and the sections which apply. An empty
+// string removes them.
+func (c ConsentWriter) Write(req *openrtb.BidRequest) error {
+	if req == nil {
+		return nil
+	}
+
+	extMap := make(map[string]interface{})
+	if req.Regs != nil && len(req.Regs.Ext) > 0 {
+		if err := json.Unmarshal(req.Regs.Ext, &extMap); err != nil {
+			return err
+		}
+	} else if c.Consent == "" {
+		return nil
+	}
+
+	delete(extMap, "gpp")
+	delete(extMap, "gpp_sid")
+	if c.Consent != "" {
+		extMap["gpp"] = c.Consent
+		if len(c.SIDs) > 0 {
+			extMap["gpp_sid"] = c.SIDs
+		}
+	}
+
+	regs := openrtb.Regs{}
+	if req.Regs != nil {
+		regs = *req.Regs
+	}
+	regs.Ext = nil
+	if len(extMap) > 0 {
+		ext, err := json.Marshal(extMap)
+		if err != nil {
+			return err
+		}
+		regs.Ext = ext
+	}
+	req.Regs = &regs
+	return nil
+}
+
+// WriteDerivedPolicies fills in the GDPR signal, the TCF consent string and the US Privacy string which the
+// GPP string derives, unless the request already has them. The rest of Prebid Server then enforces them as
+// if the request had set them.
+func (p ParsedPolicy) WriteDerivedPolicies(req *openrtb.BidRequest) error {
+	if req == nil {
+		return nil
+	}
+
+	var regsExt openrtb_ext.ExtRegs
+	if req.Regs != nil && len(req.Regs.Ext) > 0 {
+		if err := json.Unmarshal(req.Regs.Ext, &regsExt); err != nil {
+			return err
+		}
+	}
+	var userExt openrtb_ext.ExtUser
+	if req.User != nil && len(req.User.Ext) > 0 {
+		if err := json.Unmarshal(req.User.Ext, &userExt); err != nil {
+			return err
+		}
+	}
+
+	if signal := p.GDPRSignal(); signal != nil && regsExt.GDPR == nil {
+		if err := writeGDPRSignal(req, *signal); err != nil {
+			return err
+		}
+	}
+	if consent := p.TCFConsent(); consent != "" && userExt.Consent == "" {
+		if err := (gdpr.ConsentWriter{Consent: consent}).Write(req); err != nil {
+			return err
+		}
+	}
+	if usPrivacy := p.USPrivacy(); usPrivacy != "" && regsExt.USPrivacy == "" {
+		if err := (ccpa.ConsentWriter{Consent: usPrivacy}).Write(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGDPRSignal(req *openrtb.BidRequest, signal int8) error {
+	extMap := make(map[string]interface{})
+	if req.Regs != nil && len(req.Regs.Ext) > 0 {
+		if err := json.Unmarshal(req.Regs.Ext, &extMap); err != nil {
+			return err
+		}
+	}
+	extMap["gdpr"] = signal
+
+	ext, err := json.Marshal(extMap)
+	if err != nil {
+		return err
+	}
+	regs := openrtb.Regs{}
+	if req.Regs != nil {
+		regs = *req.Regs
+	}
+	regs.Ext = ext
+	req.Regs = &regs
+	return nil
+}
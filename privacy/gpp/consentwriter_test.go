@@ -0,0 +1,121 @@
+package gpp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsentWriter(t *testing.T) {
+	testCases := []struct {
+		description   string
+		writer        ConsentWriter
+		request       *openrtb.BidRequest
+		expected      *openrtb.BidRequest
+		expectedError bool
+	}{
+		{
+			description: "Nil Request",
+			writer:      ConsentWriter{Consent: "anyConsent"},
+			request:     nil,
+			expected:    nil,
+		},
+		{
+			description: "Success",
+			writer:      ConsentWriter{Consent: "anyConsent", SIDs: []SectionID{SectionUSNat}},
+			request:     &openrtb.BidRequest{},
+			expected: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"anyConsent","gpp_sid":[7]}`)},
+			},
+		},
+		{
+			description: "Remove",
+			writer:      ConsentWriter{},
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{COPPA: 1, Ext: json.RawMessage(`{"gpp":"anyConsent","gpp_sid":[7]}`)},
+			},
+			expected: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{COPPA: 1},
+			},
+		},
+		{
+			description: "Error With Regs.Ext - Does Not Mutate",
+			writer:      ConsentWriter{Consent: "anyConsent"},
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`malformed}`)},
+			},
+			expectedError: true,
+			expected: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`malformed}`)},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		err := test.writer.Write(test.request)
+
+		if test.expectedError {
+			assert.Error(t, err, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+		assert.Equal(t, test.expected, test.request, test.description)
+	}
+}
+
+func TestWriteDerivedPolicies(t *testing.T) {
+	testCases := []struct {
+		description string
+		policy      Policy
+		request     *openrtb.BidRequest
+		expected    *openrtb.BidRequest
+	}{
+		{
+			description: "TCF EU section",
+			policy:      Policy{Consent: "DBACNY~" + tcfConsent + "~1YYN", SIDs: []SectionID{SectionTCFEU2}},
+			request:     &openrtb.BidRequest{},
+			expected: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+				User: &openrtb.User{Ext: json.RawMessage(`{"consent":"` + tcfConsent + `"}`)},
+			},
+		},
+		{
+			description: "USP section",
+			policy:      Policy{Consent: "DBACNY~" + tcfConsent + "~1YYN", SIDs: []SectionID{SectionUSPV1}},
+			request:     &openrtb.BidRequest{},
+			expected: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gdpr":0,"us_privacy":"1YYN"}`)},
+			},
+		},
+		{
+			description: "Request values win",
+			policy:      Policy{Consent: "DBACNY~" + tcfConsent + "~1YYN", SIDs: []SectionID{SectionTCFEU2, SectionUSPV1}},
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gdpr":0,"us_privacy":"1NNN"}`)},
+				User: &openrtb.User{Ext: json.RawMessage(`{"consent":"otherConsent"}`)},
+			},
+			expected: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gdpr":0,"us_privacy":"1NNN"}`)},
+				User: &openrtb.User{Ext: json.RawMessage(`{"consent":"otherConsent"}`)},
+			},
+		},
+		{
+			description: "No applicable sections",
+			policy:      Policy{Consent: "DBACNY~" + tcfConsent + "~1YYN"},
+			request:     &openrtb.BidRequest{},
+			expected:    &openrtb.BidRequest{},
+		},
+	}
+
+	for _, test := range testCases {
+		parsed, err := test.policy.Parse()
+		assert.NoError(t, err, test.description)
+
+		err = parsed.WriteDerivedPolicies(test.request)
+
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expected, test.request, test.description)
+	}
+}
@@ -0,0 +1,115 @@
+package gpp
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/errortypes"
+)
+
+// ValidateConsent returns true if the consent string is empty or a valid GPP string.
+func ValidateConsent(consent string) bool {
+	if consent == "" {
+		return true
+	}
+	_, err := parseConsent(consent)
+	return err == nil
+}
+
+// ParsedPolicy represents parsed and validated GPP regulatory information. Use this struct
+// to make enforcement decisions.
+type ParsedPolicy struct {
+	sidsSpecified bool
+	// applicable are the sections of the string which apply to the request.
+	applicable            map[SectionID]string
+	usOptOut              bool
+	noSaleForAllBidders   bool
+	noSaleSpecificBidders map[string]struct{}
+}
+
+const allBiddersMarker = "*"
+
+// Parse returns a parsed and validated ParsedPolicy intended for use in enforcement decisions.
+func (p Policy) Parse() (ParsedPolicy, error) {
+	parsed := ParsedPolicy{
+		sidsSpecified:         len(p.SIDs) > 0,
+		applicable:            make(map[SectionID]string),
+		noSaleSpecificBidders: make(map[string]struct{}, len(p.NoSaleBidders)),
+	}
+	for _, bidder := range p.NoSaleBidders {
+		if bidder == allBiddersMarker {
+			parsed.noSaleForAllBidders = true
+		} else {
+			parsed.noSaleSpecificBidders[bidder] = struct{}{}
+		}
+	}
+	if p.Consent == "" {
+		return parsed, nil
+	}
+
+	c, err := parseConsent(p.Consent)
+	if err != nil {
+		return ParsedPolicy{}, &errortypes.InvalidPrivacyConsent{Message: fmt.Sprintf("request.regs.ext.gpp %s", err.Error())}
+	}
+	for _, sid := range p.SIDs {
+		section, ok := c.sections[sid]
+		if !ok {
+			continue
+		}
+		optOut, err := usOptOut(sid, section)
+		if err != nil {
+			return ParsedPolicy{}, &errortypes.InvalidPrivacyConsent{Message: fmt.Sprintf("request.regs.ext.gpp %s", err.Error())}
+		}
+		parsed.applicable[sid] = section
+		parsed.usOptOut = parsed.usOptOut || optOut
+	}
+	return parsed, nil
+}
+
+// GDPRSignal returns whether GDPR applies to the request, going by whether the TCF EU section applies.
+// It returns nil if the request doesn't list the sections which apply.
+func (p ParsedPolicy) GDPRSignal() *int8 {
+	if !p.sidsSpecified {
+		return nil
+	}
+	signal := int8(0)
+	if _, ok := p.applicable[SectionTCFEU2]; ok {
+		signal = 1
+	}
+	return &signal
+}
+
+// TCFConsent returns the TCF consent string of the TCF EU section, if it applies.
+func (p ParsedPolicy) TCFConsent() string {
+	return p.applicable[SectionTCFEU2]
+}
+
+// USPrivacy returns the US Privacy string of the USP section, if it applies.
+func (p ParsedPolicy) USPrivacy() string {
+	return p.applicable[SectionUSPV1]
+}
+
+// CanEnforce returns true when a US national or state section applies to the request.
+func (p ParsedPolicy) CanEnforce() bool {
+	for sid := range p.applicable {
+		if _, ok := usOptOutFields[sid]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p ParsedPolicy) isNoSaleForBidder(bidder string) bool {
+	if p.noSaleForAllBidders {
+		return true
+	}
+
+	_, exists := p.noSaleSpecificBidders[bidder]
+	return exists
+}
+
+// ShouldEnforce returns true when a US national or state section which applies to the request opts
+// the user out of the sale or sharing of their data, or of targeted advertising, unless the bidder
+// is one of the request's nosale bidders.
+func (p ParsedPolicy) ShouldEnforce(bidder string) bool {
+	return !p.isNoSaleForBidder(bidder) && p.usOptOut
+}
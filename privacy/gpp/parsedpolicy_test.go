@@ -0,0 +1,137 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/stretchr/testify/assert"
+)
+
+const tcfConsent = "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+
+func TestParse(t *testing.T) {
+	gdprApplies := int8(1)
+	gdprDoesNotApply := int8(0)
+
+	testCases := []struct {
+		description     string
+		policy          Policy
+		expectedError   string
+		expectedGDPR    *int8
+		expectedTCF     string
+		expectedUSP     string
+		expectedEnforce bool
+		expectedOptOut  bool
+	}{
+		{
+			description: "Empty",
+			policy:      Policy{},
+		},
+		{
+			description:  "TCF EU section applies",
+			policy:       Policy{Consent: "DBABMA~" + tcfConsent, SIDs: []SectionID{SectionTCFEU2}},
+			expectedGDPR: &gdprApplies,
+			expectedTCF:  tcfConsent,
+		},
+		{
+			description: "Sections apply only if the request says so",
+			policy:      Policy{Consent: "DBACNY~" + tcfConsent + "~1YYN"},
+		},
+		{
+			description:  "USP section applies, TCF EU section doesn't",
+			policy:       Policy{Consent: "DBACNY~" + tcfConsent + "~1YYN", SIDs: []SectionID{SectionUSPV1}},
+			expectedGDPR: &gdprDoesNotApply,
+			expectedUSP:  "1YYN",
+		},
+		{
+			description:     "US national section without opt out",
+			policy:          Policy{Consent: "DBABLA~BVQqAAAAAgA.QA", SIDs: []SectionID{SectionUSNat}},
+			expectedGDPR:    &gdprDoesNotApply,
+			expectedEnforce: true,
+		},
+		{
+			description:     "US national section with a sale opt out",
+			policy:          Policy{Consent: "DBABLA~BVQaAAAAAgA.QA", SIDs: []SectionID{SectionUSNat}},
+			expectedGDPR:    &gdprDoesNotApply,
+			expectedEnforce: true,
+			expectedOptOut:  true,
+		},
+		{
+			description:     "US national section with a sale opt out, bidder is a nosale bidder",
+			policy:          Policy{Consent: "DBABLA~BVQaAAAAAgA.QA", SIDs: []SectionID{SectionUSNat}, NoSaleBidders: []string{"appnexus"}},
+			expectedGDPR:    &gdprDoesNotApply,
+			expectedEnforce: true,
+		},
+		{
+			description:     "US national section with a sale opt out, all bidders are nosale bidders",
+			policy:          Policy{Consent: "DBABLA~BVQaAAAAAgA.QA", SIDs: []SectionID{SectionUSNat}, NoSaleBidders: []string{"*"}},
+			expectedGDPR:    &gdprDoesNotApply,
+			expectedEnforce: true,
+		},
+		{
+			description:     "US national section with a sale opt out, other nosale bidders",
+			policy:          Policy{Consent: "DBABLA~BVQaAAAAAgA.QA", SIDs: []SectionID{SectionUSNat}, NoSaleBidders: []string{"rubicon"}},
+			expectedGDPR:    &gdprDoesNotApply,
+			expectedEnforce: true,
+			expectedOptOut:  true,
+		},
+		{
+			description:     "California section with a sale opt out",
+			policy:          Policy{Consent: "DBAENbY~" + tcfConsent + "~1YNN~BVQqAAAAAgA~BVYAAA", SIDs: []SectionID{SectionUSCA, SectionUSPV1}},
+			expectedGDPR:    &gdprDoesNotApply,
+			expectedUSP:     "1YNN",
+			expectedEnforce: true,
+			expectedOptOut:  true,
+		},
+		{
+			description:     "California section without opt out",
+			policy:          Policy{Consent: "DBABBg~BVoAAA", SIDs: []SectionID{SectionUSCA}},
+			expectedGDPR:    &gdprDoesNotApply,
+			expectedEnforce: true,
+		},
+		{
+			description:   "Not a GPP string",
+			policy:        Policy{Consent: tcfConsent},
+			expectedError: "request.regs.ext.gpp has an invalid header: wrong type",
+		},
+		{
+			description:   "Missing sections",
+			policy:        Policy{Consent: "DBACNY~" + tcfConsent},
+			expectedError: "request.regs.ext.gpp lists 2 sections in its header, but has 1",
+		},
+		{
+			description:   "Invalid character",
+			policy:        Policy{Consent: "DBA*M~" + tcfConsent},
+			expectedError: "request.regs.ext.gpp has an invalid header: invalid character '*'",
+		},
+		{
+			description:   "Truncated US section",
+			policy:        Policy{Consent: "DBABLA~BVQ", SIDs: []SectionID{SectionUSNat}},
+			expectedError: "request.regs.ext.gpp has an invalid section 7: too short",
+		},
+	}
+
+	for _, test := range testCases {
+		parsed, err := test.policy.Parse()
+
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+			assert.IsType(t, &errortypes.InvalidPrivacyConsent{}, err, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedGDPR, parsed.GDPRSignal(), test.description+":gdpr")
+		assert.Equal(t, test.expectedTCF, parsed.TCFConsent(), test.description+":tcf")
+		assert.Equal(t, test.expectedUSP, parsed.USPrivacy(), test.description+":usp")
+		assert.Equal(t, test.expectedEnforce, parsed.CanEnforce(), test.description+":canEnforce")
+		assert.Equal(t, test.expectedOptOut, parsed.ShouldEnforce("appnexus"), test.description+":shouldEnforce")
+	}
+}
+
+func TestParseRangeHeader(t *testing.T) {
+	// A single entry for the range of sections 7 to 8
+	c, err := parseConsent("DBABrw~BVQqAAAAAgA~BVoAAA")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[SectionID]string{SectionUSNat: "BVQqAAAAAgA", SectionUSCA: "BVoAAA"}, c.sections)
+}
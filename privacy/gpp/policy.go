@@ -0,0 +1,100 @@
+package gpp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Policy represents the GPP regulatory information from an OpenRTB bid request.
+type Policy struct {
+	Consent string
+	// SIDs are the sections of the GPP string which apply to the request.
+	SIDs []SectionID
+	// NoSaleBidders are the bidders the opt outs don't apply to, as listed in request.ext.prebid.nosale.
+	// They're validated along with the CCPA policy, which shares them.
+	NoSaleBidders []string
+}
+
+// ReadFromRequest extracts the GPP regulatory information from an OpenRTB bid request.
+func ReadFromRequest(req *openrtb.BidRequest) (Policy, error) {
+	if req == nil || req.Regs == nil || len(req.Regs.Ext) == 0 {
+		return Policy{}, nil
+	}
+
+	var ext openrtb_ext.ExtRegs
+	if err := json.Unmarshal(req.Regs.Ext, &ext); err != nil {
+		return Policy{}, fmt.Errorf("error reading request.regs.ext: %s", err)
+	}
+
+	policy := Policy{Consent: ext.GPP}
+	for _, sid := range ext.GPPSID {
+		policy.SIDs = append(policy.SIDs, SectionID(sid))
+	}
+
+	// Read no sale bidders from request.ext.prebid
+	if len(req.Ext) > 0 {
+		var reqExt openrtb_ext.ExtRequest
+		if err := json.Unmarshal(req.Ext, &reqExt); err != nil {
+			return Policy{}, fmt.Errorf("error reading request.ext.prebid: %s", err)
+		}
+		policy.NoSaleBidders = reqExt.Prebid.NoSale
+	}
+	return policy, nil
+}
+
+// ParseSIDs parses a comma separated list of section IDs, as the AMP, /cookie_sync and /setuid endpoints take them.
+func ParseSIDs(sids string) ([]SectionID, error) {
+	if sids == "" {
+		return nil, nil
+	}
+	parts := strings.Split(sids, ",")
+	parsed := make([]SectionID, 0, len(parts))
+	for _, part := range parts {
+		sid, err := strconv.ParseInt(strings.TrimSpace(part), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("gpp_sid must be a comma separated list of section ids. Got %s", sids)
+		}
+		parsed = append(parsed, SectionID(sid))
+	}
+	return parsed, nil
+}
+
+// FormatSIDs writes the section IDs in the format of ParseSIDs.
+func FormatSIDs(sids []SectionID) string {
+	parts := make([]string, len(sids))
+	for i, sid := range sids {
+		parts[i] = strconv.Itoa(int(sid))
+	}
+	return strings.Join(parts, ",")
+}
+
+// MoveToRegsExt moves the OpenRTB 2.6 regs.gpp and regs.gpp_sid fields of a bid request to regs.ext, which is
+// where the rest of Prebid Server reads them. Values already in regs.ext win.
+func MoveToRegsExt(requestJSON []byte) ([]byte, error) {
+	for _, field := range []string{"gpp", "gpp_sid"} {
+		value, dataType, _, err := jsonparser.Get(requestJSON, "regs", field)
+		if dataType == jsonparser.NotExist {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("request.regs.%s is invalid: %v", field, err)
+		}
+		if dataType == jsonparser.String {
+			// jsonparser strips the quotes, but leaves the string escaped.
+			value = []byte(`"` + string(value) + `"`)
+		}
+		if _, extType, _, _ := jsonparser.Get(requestJSON, "regs", "ext", field); extType == jsonparser.NotExist {
+			if requestJSON, err = jsonparser.Set(requestJSON, value, "regs", "ext", field); err != nil {
+				return nil, err
+			}
+		}
+		requestJSON = jsonparser.Delete(requestJSON, "regs", field)
+	}
+	return requestJSON, nil
+}
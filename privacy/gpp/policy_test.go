@@ -0,0 +1,115 @@
+package gpp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFromRequest(t *testing.T) {
+	testCases := []struct {
+		description    string
+		request        *openrtb.BidRequest
+		expectedPolicy Policy
+		expectedError  bool
+	}{
+		{
+			description:    "Nil Request",
+			request:        nil,
+			expectedPolicy: Policy{},
+		},
+		{
+			description:    "Nil Regs",
+			request:        &openrtb.BidRequest{},
+			expectedPolicy: Policy{},
+		},
+		{
+			description: "Success",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABMA~` + tcfConsent + `","gpp_sid":[2,6]}`)},
+			},
+			expectedPolicy: Policy{Consent: "DBABMA~" + tcfConsent, SIDs: []SectionID{SectionTCFEU2, SectionUSPV1}},
+		},
+		{
+			description: "Success with nosale bidders",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABLA~BVQaAAAAAgA.QA","gpp_sid":[7]}`)},
+				Ext:  json.RawMessage(`{"prebid":{"nosale":["appnexus"]}}`),
+			},
+			expectedPolicy: Policy{Consent: "DBABLA~BVQaAAAAAgA.QA", SIDs: []SectionID{SectionUSNat}, NoSaleBidders: []string{"appnexus"}},
+		},
+		{
+			description: "Malformed Ext",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`{"gpp":"DBABLA~BVQaAAAAAgA.QA","gpp_sid":[7]}`)},
+				Ext:  json.RawMessage(`malformed`),
+			},
+			expectedError: true,
+		},
+		{
+			description: "Malformed Regs.Ext",
+			request: &openrtb.BidRequest{
+				Regs: &openrtb.Regs{Ext: json.RawMessage(`malformed`)},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		policy, err := ReadFromRequest(test.request)
+
+		if test.expectedError {
+			assert.Error(t, err, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, test.expectedPolicy, policy, test.description)
+		}
+	}
+}
+
+func TestParseSIDs(t *testing.T) {
+	sids, err := ParseSIDs("2, 6")
+	assert.NoError(t, err)
+	assert.Equal(t, []SectionID{SectionTCFEU2, SectionUSPV1}, sids)
+	assert.Equal(t, "2,6", FormatSIDs(sids))
+
+	sids, err = ParseSIDs("")
+	assert.NoError(t, err)
+	assert.Nil(t, sids)
+
+	_, err = ParseSIDs("2,a")
+	assert.EqualError(t, err, "gpp_sid must be a comma separated list of section ids. Got 2,a")
+}
+
+func TestMoveToRegsExt(t *testing.T) {
+	testCases := []struct {
+		description string
+		request     string
+		expected    string
+	}{
+		{
+			description: "No GPP",
+			request:     `{"id":"1","regs":{"coppa":1}}`,
+			expected:    `{"id":"1","regs":{"coppa":1}}`,
+		},
+		{
+			description: "GPP moved",
+			request:     `{"id":"1","regs":{"gpp":"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA","gpp_sid":[2]}}`,
+			expected:    `{"id":"1","regs":{"ext":{"gpp":"DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA","gpp_sid":[2]}}}`,
+		},
+		{
+			description: "Regs.Ext wins",
+			request:     `{"regs":{"gpp_sid":[2],"ext":{"gpp_sid":[6]}}}`,
+			expected:    `{"regs":{"ext":{"gpp_sid":[6]}}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := MoveToRegsExt([]byte(test.request))
+
+		assert.NoError(t, err, test.description)
+		assert.JSONEq(t, test.expected, string(result), test.description)
+	}
+}
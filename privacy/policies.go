@@ -2,13 +2,17 @@ package privacy
 
 import (
 	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/coppa"
 	"github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/privacy/lmt"
 )
 
 // Policies represents the privacy regulations for an OpenRTB bid request.
 type Policies struct {
-	CCPA ccpa.Policy
-	GDPR gdpr.Policy
-	LMT  lmt.Policy
+	CCPA  ccpa.Policy
+	COPPA coppa.Policy
+	GDPR  gdpr.Policy
+	GPP   gpp.Policy
+	LMT   lmt.Policy
 }
@@ -1,35 +1,49 @@
 package privacy
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/json"
-	"strings"
+	"net"
 
 	"github.com/mxmCherry/openrtb"
 )
 
 // ScrubStrategyIPV4 defines the approach to scrub PII from an IPV4 address.
-type ScrubStrategyIPV4 int
+type ScrubStrategyIPV4 struct {
+	// MaskBits is the number of lowest bits of the address which are removed.
+	MaskBits int
+	// HashKey, if set, replaces the removed bits with bits of an HMAC of the whole address keyed with it,
+	// rather than zeroes.
+	HashKey []byte
+}
 
-const (
+var (
 	// ScrubStrategyIPV4None does not remove any part of an IPV4 address.
-	ScrubStrategyIPV4None ScrubStrategyIPV4 = iota
+	ScrubStrategyIPV4None = ScrubStrategyIPV4{}
 
 	// ScrubStrategyIPV4Lowest8 zeroes out the last 8 bits of an IPV4 address.
-	ScrubStrategyIPV4Lowest8
+	ScrubStrategyIPV4Lowest8 = ScrubStrategyIPV4{MaskBits: 8}
 )
 
 // ScrubStrategyIPV6 defines the approach to scrub PII from an IPV6 address.
-type ScrubStrategyIPV6 int
+type ScrubStrategyIPV6 struct {
+	// MaskBits is the number of lowest bits of the address which are removed.
+	MaskBits int
+	// HashKey, if set, replaces the removed bits with bits of an HMAC of the whole address keyed with it,
+	// rather than zeroes.
+	HashKey []byte
+}
 
-const (
+var (
 	// ScrubStrategyIPV6None does not remove any part of an IPV6 address.
-	ScrubStrategyIPV6None ScrubStrategyIPV6 = iota
+	ScrubStrategyIPV6None = ScrubStrategyIPV6{}
 
 	// ScrubStrategyIPV6Lowest16 zeroes out the last 16 bits of an IPV6 address.
-	ScrubStrategyIPV6Lowest16
+	ScrubStrategyIPV6Lowest16 = ScrubStrategyIPV6{MaskBits: 16}
 
 	// ScrubStrategyIPV6Lowest32 zeroes out the last 32 bits of an IPV6 address.
-	ScrubStrategyIPV6Lowest32
+	ScrubStrategyIPV6Lowest32 = ScrubStrategyIPV6{MaskBits: 32}
 )
 
 // ScrubStrategyGeo defines the approach to scrub PII from geographical data.
@@ -75,6 +89,7 @@ const (
 type Scrubber interface {
 	ScrubDevice(device *openrtb.Device, id ScrubStrategyDeviceID, ipv4 ScrubStrategyIPV4, ipv6 ScrubStrategyIPV6, geo ScrubStrategyGeo) *openrtb.Device
 	ScrubUser(user *openrtb.User, strategy ScrubStrategyUser, geo ScrubStrategyGeo) *openrtb.User
+	ScrubUserFPD(user *openrtb.User, fpd bool, eids bool) *openrtb.User
 }
 
 type scrubber struct{}
@@ -102,16 +117,12 @@ func (scrubber) ScrubDevice(device *openrtb.Device, id ScrubStrategyDeviceID, ip
 		deviceCopy.MACSHA1 = ""
 	}
 
-	switch ipv4 {
-	case ScrubStrategyIPV4Lowest8:
-		deviceCopy.IP = scrubIPV4Lowest8(device.IP)
+	if ipv4.MaskBits > 0 {
+		deviceCopy.IP = scrubIP(device.IP, net.IPv4len*8, ipv4.MaskBits, ipv4.HashKey)
 	}
 
-	switch ipv6 {
-	case ScrubStrategyIPV6Lowest16:
-		deviceCopy.IPv6 = scrubIPV6Lowest16Bits(device.IPv6)
-	case ScrubStrategyIPV6Lowest32:
-		deviceCopy.IPv6 = scrubIPV6Lowest32Bits(device.IPv6)
+	if ipv6.MaskBits > 0 {
+		deviceCopy.IPv6 = scrubIP(device.IPv6, net.IPv6len*8, ipv6.MaskBits, ipv6.HashKey)
 	}
 
 	switch geo {
@@ -154,44 +165,61 @@ func (scrubber) ScrubUser(user *openrtb.User, strategy ScrubStrategyUser, geo Sc
 	return &userCopy
 }
 
-func scrubIPV4Lowest8(ip string) string {
-	i := strings.LastIndex(ip, ".")
-	if i == -1 {
-		return ""
+// ScrubUserFPD removes the first party data of the user if fpd is true, and the extended IDs of the user
+// if eids is true.
+func (scrubber) ScrubUserFPD(user *openrtb.User, fpd bool, eids bool) *openrtb.User {
+	if user == nil {
+		return nil
 	}
 
-	return ip[0:i] + ".0"
-}
-
-func scrubIPV6Lowest16Bits(ip string) string {
-	ip = removeLowestIPV6Segment(ip)
+	userCopy := *user
 
-	if ip != "" {
-		ip += ":0"
+	if fpd {
+		userCopy.Yob = 0
+		userCopy.Gender = ""
+		userCopy.Keywords = ""
+		userCopy.CustomData = ""
+		userCopy.Data = nil
+		userCopy.Ext = scrubUserExtData(userCopy.Ext)
 	}
 
-	return ip
-}
-
-func scrubIPV6Lowest32Bits(ip string) string {
-	ip = removeLowestIPV6Segment(ip)
-	ip = removeLowestIPV6Segment(ip)
-
-	if ip != "" {
-		ip += ":0:0"
+	if eids {
+		userCopy.Ext = scrubUserExtIDs(userCopy.Ext)
 	}
 
-	return ip
+	return &userCopy
 }
 
-func removeLowestIPV6Segment(ip string) string {
-	i := strings.LastIndex(ip, ":")
-
-	if i == -1 {
+// scrubIP removes the lowest maskBits bits of an IP address which is addressBits long. It returns an empty
+// string if the IP address is malformed, or isn't of the expected length.
+//
+// The hash is keyed with a secret of the host. Without it, the removed bits could be found again by hashing
+// each of their possible values.
+func scrubIP(ip string, addressBits, maskBits int, hashKey []byte) string {
+	parsed := net.ParseIP(ip)
+	if addressBits == net.IPv4len*8 {
+		parsed = parsed.To4()
+	}
+	if parsed == nil {
 		return ""
 	}
 
-	return ip[0:i]
+	if maskBits > addressBits {
+		maskBits = addressBits
+	}
+	mask := net.CIDRMask(addressBits-maskBits, addressBits)
+	scrubbed := parsed.Mask(mask)
+
+	if len(hashKey) > 0 {
+		mac := hmac.New(sha256.New, hashKey)
+		mac.Write(parsed)
+		sum := mac.Sum(nil)
+		for i := range scrubbed {
+			scrubbed[i] |= sum[i] &^ mask[i]
+		}
+	}
+
+	return scrubbed.String()
 }
 
 func scrubGeoFull(geo *openrtb.Geo) *openrtb.Geo {
@@ -238,3 +266,26 @@ func scrubUserExtIDs(userExt json.RawMessage) json.RawMessage {
 
 	return userExt
 }
+
+func scrubUserExtData(userExt json.RawMessage) json.RawMessage {
+	if len(userExt) == 0 {
+		return userExt
+	}
+
+	var userExtParsed map[string]json.RawMessage
+	err := json.Unmarshal(userExt, &userExtParsed)
+	if err != nil {
+		return userExt
+	}
+
+	if _, hasData := userExtParsed["data"]; hasData {
+		delete(userExtParsed, "data")
+
+		result, err := json.Marshal(userExtParsed)
+		if err == nil {
+			return result
+		}
+	}
+
+	return userExt
+}
@@ -55,7 +55,7 @@ func TestScrubDevice(t *testing.T) {
 				MACMD5:   "",
 				IFA:      "",
 				IP:       "1.2.3.0",
-				IPv6:     "2001:0db8:0000:0000:0000:ff00:0:0",
+				IPv6:     "2001:db8::ff00:0:0",
 				Geo:      &openrtb.Geo{},
 			},
 			id:   ScrubStrategyDeviceIDAll,
@@ -112,7 +112,7 @@ func TestScrubDevice(t *testing.T) {
 				MACMD5:   "anyMACMD5",
 				IFA:      "anyIFA",
 				IP:       "1.2.3.4",
-				IPv6:     "2001:0db8:0000:0000:0000:ff00:0042:0",
+				IPv6:     "2001:db8::ff00:42:0",
 				Geo:      device.Geo,
 			},
 			id:   ScrubStrategyDeviceIDNone,
@@ -131,7 +131,7 @@ func TestScrubDevice(t *testing.T) {
 				MACMD5:   "anyMACMD5",
 				IFA:      "anyIFA",
 				IP:       "1.2.3.4",
-				IPv6:     "2001:0db8:0000:0000:0000:ff00:0:0",
+				IPv6:     "2001:db8::ff00:0:0",
 				Geo:      device.Geo,
 			},
 			id:   ScrubStrategyDeviceIDNone,
@@ -384,120 +384,211 @@ func TestScrubUserNil(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+func TestScrubUserFPD(t *testing.T) {
+	user := &openrtb.User{
+		ID:         "anyID",
+		BuyerUID:   "anyBuyerUID",
+		Yob:        42,
+		Gender:     "anyGender",
+		Keywords:   "anyKeywords",
+		CustomData: "anyCustomData",
+		Data:       []openrtb.Data{{ID: "anyData"}},
+		Ext:        json.RawMessage(`{"data":{"any":42},"eids":[{"source":"anySource"}],"consent":"anyConsent"}`),
+	}
+
+	testCases := []struct {
+		description string
+		fpd         bool
+		eids        bool
+		expected    *openrtb.User
+	}{
+		{
+			description: "First Party Data",
+			fpd:         true,
+			expected: &openrtb.User{
+				ID:       "anyID",
+				BuyerUID: "anyBuyerUID",
+				Ext:      json.RawMessage(`{"consent":"anyConsent","eids":[{"source":"anySource"}]}`),
+			},
+		},
+		{
+			description: "Extended IDs",
+			eids:        true,
+			expected: &openrtb.User{
+				ID:         "anyID",
+				BuyerUID:   "anyBuyerUID",
+				Yob:        42,
+				Gender:     "anyGender",
+				Keywords:   "anyKeywords",
+				CustomData: "anyCustomData",
+				Data:       []openrtb.Data{{ID: "anyData"}},
+				Ext:        json.RawMessage(`{"consent":"anyConsent","data":{"any":42}}`),
+			},
+		},
+		{
+			description: "Both",
+			fpd:         true,
+			eids:        true,
+			expected: &openrtb.User{
+				ID:       "anyID",
+				BuyerUID: "anyBuyerUID",
+				Ext:      json.RawMessage(`{"consent":"anyConsent"}`),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		result := NewScrubber().ScrubUserFPD(user, test.fpd, test.eids)
+		assert.Equal(t, test.expected, result, test.description)
+	}
+	assert.Nil(t, NewScrubber().ScrubUserFPD(nil, true, true))
+}
+
 func TestScrubIPV4(t *testing.T) {
 	testCases := []struct {
 		IP          string
+		maskBits    int
+		hashKey     []byte
 		cleanedIP   string
 		description string
 	}{
 		{
 			IP:          "0.0.0.0",
+			maskBits:    8,
 			cleanedIP:   "0.0.0.0",
 			description: "Shouldn't do anything for a 0.0.0.0 IP address",
 		},
 		{
 			IP:          "192.127.111.134",
+			maskBits:    8,
 			cleanedIP:   "192.127.111.0",
 			description: "Should remove the lowest 8 bits",
 		},
 		{
 			IP:          "192.127.111.0",
+			maskBits:    8,
 			cleanedIP:   "192.127.111.0",
 			description: "Shouldn't change anything if the lowest 8 bits are already 0",
 		},
 		{
-			IP:          "not an ip",
-			cleanedIP:   "",
-			description: "Should return an empty string for a bad IP",
+			IP:          "192.127.111.134",
+			maskBits:    16,
+			cleanedIP:   "192.127.0.0",
+			description: "Should remove the lowest 16 bits",
 		},
 		{
-			IP:          "",
-			cleanedIP:   "",
-			description: "Should return an empty string for a bad IP",
+			IP:          "192.127.111.134",
+			maskBits:    12,
+			cleanedIP:   "192.127.96.0",
+			description: "Should remove bits which don't end on a byte",
 		},
-	}
-
-	for _, test := range testCases {
-		result := scrubIPV4Lowest8(test.IP)
-		assert.Equal(t, test.cleanedIP, result, test.description)
-	}
-}
-
-func TestScrubIPV6Lowest16Bits(t *testing.T) {
-	testCases := []struct {
-		IP          string
-		cleanedIP   string
-		description string
-	}{
 		{
-			IP:          "0:0:0:0",
-			cleanedIP:   "0:0:0:0",
-			description: "Shouldn't do anything for a 0:0:0:0 IP address",
+			IP:          "192.127.111.134",
+			maskBits:    40,
+			cleanedIP:   "0.0.0.0",
+			description: "Should remove the whole address if the mask is longer than it",
 		},
 		{
-			IP:          "2001:0db8:0000:0000:0000:ff00:0042:8329",
-			cleanedIP:   "2001:0db8:0000:0000:0000:ff00:0042:0",
-			description: "Should remove lowest 16 bits",
+			IP:          "192.127.111.134",
+			maskBits:    8,
+			hashKey:     []byte("secret"),
+			cleanedIP:   "192.127.111.237",
+			description: "Should replace the lowest 8 bits with a keyed hash",
 		},
 		{
-			IP:          "2001:0db8:0000:0000:0000:ff00:0042:0",
-			cleanedIP:   "2001:0db8:0000:0000:0000:ff00:0042:0",
-			description: "Shouldn't do anything if the lowest 16 bits are already 0",
+			IP:          "192.127.111.134",
+			maskBits:    8,
+			hashKey:     []byte("another secret"),
+			cleanedIP:   "192.127.111.223",
+			description: "Should replace the lowest 8 bits with a hash which depends on the key",
+		},
+		{
+			IP:          "2001:0db8:0000:0000:0000:ff00:0042:8329",
+			maskBits:    8,
+			cleanedIP:   "",
+			description: "Should return an empty string for an IPV6 address",
 		},
 		{
 			IP:          "not an ip",
+			maskBits:    8,
 			cleanedIP:   "",
 			description: "Should return an empty string for a bad IP",
 		},
 		{
 			IP:          "",
+			maskBits:    8,
 			cleanedIP:   "",
 			description: "Should return an empty string for a bad IP",
 		},
 	}
 
 	for _, test := range testCases {
-		result := scrubIPV6Lowest16Bits(test.IP)
+		result := scrubIP(test.IP, 32, test.maskBits, test.hashKey)
 		assert.Equal(t, test.cleanedIP, result, test.description)
 	}
 }
 
-func TestScrubIPV6Lowest32Bits(t *testing.T) {
+func TestScrubIPV6(t *testing.T) {
 	testCases := []struct {
 		IP          string
+		maskBits    int
+		hashKey     []byte
 		cleanedIP   string
 		description string
 	}{
 		{
-			IP:          "0:0:0:0",
-			cleanedIP:   "0:0:0:0",
-			description: "Shouldn't do anything for a 0:0:0:0 IP address",
+			IP:          "::",
+			maskBits:    16,
+			cleanedIP:   "::",
+			description: "Shouldn't do anything for a :: IP address",
+		},
+		{
+			IP:          "2001:0db8:0000:0000:0000:ff00:0042:8329",
+			maskBits:    16,
+			cleanedIP:   "2001:db8::ff00:42:0",
+			description: "Should remove lowest 16 bits",
+		},
+		{
+			IP:          "2001:0db8:0000:0000:0000:ff00:0042:0",
+			maskBits:    16,
+			cleanedIP:   "2001:db8::ff00:42:0",
+			description: "Shouldn't do anything if the lowest 16 bits are already 0",
 		},
 		{
 			IP:          "2001:0db8:0000:0000:0000:ff00:0042:8329",
-			cleanedIP:   "2001:0db8:0000:0000:0000:ff00:0:0",
+			maskBits:    32,
+			cleanedIP:   "2001:db8::ff00:0:0",
 			description: "Should remove lowest 32 bits",
 		},
 		{
-			IP:          "2001:0db8:0000:0000:0000:ff00:0:0",
-			cleanedIP:   "2001:0db8:0000:0000:0000:ff00:0:0",
-			description: "Shouldn't do anything if the lowest 32 bits are already 0",
+			IP:          "2001:0db8:1234:5678:0000:ff00:0042:8329",
+			maskBits:    80,
+			cleanedIP:   "2001:db8:1234::",
+			description: "Should remove lowest 80 bits",
+		},
+		{
+			IP:          "2001:0db8:0000:0000:0000:ff00:0042:8329",
+			maskBits:    16,
+			hashKey:     []byte("secret"),
+			cleanedIP:   "2001:db8::ff00:42:98d5",
+			description: "Should replace the lowest 16 bits with a keyed hash",
 		},
-
 		{
 			IP:          "not an ip",
+			maskBits:    16,
 			cleanedIP:   "",
 			description: "Should return an empty string for a bad IP",
 		},
 		{
 			IP:          "",
+			maskBits:    16,
 			cleanedIP:   "",
 			description: "Should return an empty string for a bad IP",
 		},
 	}
 
 	for _, test := range testCases {
-		result := scrubIPV6Lowest32Bits(test.IP)
+		result := scrubIP(test.IP, 128, test.maskBits, test.hashKey)
 		assert.Equal(t, test.cleanedIP, result, test.description)
 	}
 }
@@ -5,11 +5,12 @@ import (
 	"net/http/pprof"
 	"time"
 
+	"github.com/prebid/prebid-server/circuitbreaker"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/endpoints"
 )
 
-func Admin(revision string, rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration) *http.ServeMux {
+func Admin(revision string, rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration, bidderCircuitBreakers *circuitbreaker.Breakers) *http.ServeMux {
 	// Add endpoints to the admin server
 	// Making sure to add pprof routes
 	mux := http.NewServeMux()
@@ -22,5 +23,6 @@ func Admin(revision string, rateConverter *currency.RateConverter, rateConverter
 	// Register prebid-server defined admin handlers
 	mux.HandleFunc("/currency/rates", endpoints.NewCurrencyRatesEndpoint(rateConverter, rateConverterFetchingInterval))
 	mux.HandleFunc("/version", endpoints.NewVersionEndpoint(revision))
+	mux.HandleFunc("/bidders/circuit_breakers", endpoints.NewCircuitBreakersEndpoint(bidderCircuitBreakers))
 	return mux
 }
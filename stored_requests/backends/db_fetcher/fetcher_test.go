@@ -173,6 +173,52 @@ func TestRowErrors(t *testing.T) {
 	assertMapLength(t, 0, data)
 }
 
+// TestFetchResponses makes sure we interpret DB responses properly when fetching stored responses.
+func TestFetchResponses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mockQuery := "SELECT id, responseData FROM resp_table WHERE id IN (?, ?)"
+	mockReturn := sqlmock.NewRows([]string{"id", "responseData"}).
+		AddRow("resp-id", `{"resp":true}`)
+	mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(mockQuery))).WithArgs("resp-id", "resp-id-2").WillReturnRows(mockReturn)
+
+	fetcher := &dbFetcher{
+		db:                 db,
+		queryMaker:         successfulQueryMaker(""),
+		responseQueryMaker: successfulResponseQueryMaker(mockQuery),
+	}
+	storedResponses, errs := fetcher.FetchResponses(context.Background(), []string{"resp-id", "resp-id-2"})
+
+	assertMockExpectations(t, mock)
+	assertErrorCount(t, 1, errs)
+	assertMapLength(t, 1, storedResponses)
+	assertHasData(t, storedResponses, "resp-id", `{"resp":true}`)
+}
+
+// TestFetchResponsesWithoutQuery makes sure stored responses are reported missing if there's no query for them.
+func TestFetchResponsesWithoutQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	fetcher := &dbFetcher{
+		db:                 db,
+		queryMaker:         successfulQueryMaker(""),
+		responseQueryMaker: successfulResponseQueryMaker(""),
+	}
+	storedResponses, errs := fetcher.FetchResponses(context.Background(), []string{"resp-id"})
+
+	assertMockExpectations(t, mock)
+	assertErrorCount(t, 1, errs)
+	assertMapLength(t, 0, storedResponses)
+}
+
 func newFetcher(t *testing.T, rows *sqlmock.Rows, query string, args ...driver.Value) (sqlmock.Sqlmock, *dbFetcher) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -227,3 +273,9 @@ func successfulQueryMaker(response string) func(int, int) string {
 		return response
 	}
 }
+
+func successfulResponseQueryMaker(response string) func(int) string {
+	return func(numIDs int) string {
+		return response
+	}
+}
@@ -0,0 +1,123 @@
+package file_fetcher
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/events"
+)
+
+// NewFileFetcherWithEvents is like NewFileFetcher, but it also returns an EventProducer which rereads
+// the directory every time it's Run.
+//
+// The EventProducer keeps the Fetcher up to date with the files, and emits Saves and Invalidations
+// for the Stored Requests, Stored Imps and Accounts which changed so that any caches in front of
+// the Fetcher are updated too. Files which aren't valid JSON are logged and ignored, which leaves
+// the last good version of them in place until they're fixed.
+func NewFileFetcherWithEvents(directory string) (stored_requests.AllFetcher, *FileEventProducer, error) {
+	storedData, err := collectStoredData(directory, FileSystem{make(map[string]FileSystem), make(map[string]json.RawMessage)}, nil)
+	fetcher := &eagerFetcher{FileSystem: storedData}
+	return fetcher, &FileEventProducer{
+		directory:     directory,
+		fetcher:       fetcher,
+		saves:         make(chan events.Save, 1),
+		invalidations: make(chan events.Invalidation, 1),
+	}, err
+}
+
+// FileEventProducer reloads the directory of a Fetcher made by NewFileFetcherWithEvents.
+type FileEventProducer struct {
+	directory     string
+	fetcher       *eagerFetcher
+	saves         chan events.Save
+	invalidations chan events.Invalidation
+}
+
+func (e *FileEventProducer) Saves() <-chan events.Save {
+	return e.saves
+}
+
+func (e *FileEventProducer) Invalidations() <-chan events.Invalidation {
+	return e.invalidations
+}
+
+// Run rereads the directory and sends events for the data which changed since the last Run.
+// If the directory can't be read, the Fetcher keeps the data it already had.
+//
+// The events channels aren't buffered much, so something must be listening to them.
+func (e *FileEventProducer) Run() error {
+	save, invalidation, err := e.reload()
+	if err != nil {
+		return err
+	}
+
+	if len(save.Requests) > 0 || len(save.Imps) > 0 || len(save.Accounts) > 0 {
+		e.saves <- save
+	}
+	if len(invalidation.Requests) > 0 || len(invalidation.Imps) > 0 || len(invalidation.Accounts) > 0 {
+		e.invalidations <- invalidation
+	}
+	return nil
+}
+
+// Reload rereads the directory like Run, but it doesn't send any events.
+// It's meant for Fetchers which have no cache in front of them, so nothing listens to the events.
+func (e *FileEventProducer) Reload() error {
+	_, _, err := e.reload()
+	return err
+}
+
+func (e *FileEventProducer) reload() (save events.Save, invalidation events.Invalidation, err error) {
+	latest, err := collectStoredData(e.directory, FileSystem{make(map[string]FileSystem), make(map[string]json.RawMessage)}, nil)
+	if err != nil {
+		glog.Warningf("Failed to reload stored data from %s: %v", e.directory, err)
+		return
+	}
+
+	e.fetcher.mutex.Lock()
+	previous := e.fetcher.FileSystem.Directories
+	save.Requests, invalidation.Requests = reloadDirectory(latest, previous, "stored_requests")
+	save.Imps, invalidation.Imps = reloadDirectory(latest, previous, "stored_imps")
+	save.Accounts, invalidation.Accounts = reloadDirectory(latest, previous, "accounts")
+	e.fetcher.FileSystem = latest
+	e.fetcher.Categories = nil
+	e.fetcher.mutex.Unlock()
+	return
+}
+
+// reloadDirectory compares the files in a subdirectory of latest with the ones it had before.
+// It returns the files which were added or changed, and the IDs of the ones which were removed.
+//
+// Malformed files are replaced in latest by their previous version, or dropped if there isn't one.
+func reloadDirectory(latest FileSystem, previous map[string]FileSystem, name string) (saves map[string]json.RawMessage, invalidations []string) {
+	oldFiles := previous[name].Files
+	files := latest.Directories[name].Files
+
+	for id, data := range files {
+		oldData, hadFile := oldFiles[id]
+		if !json.Valid(data) {
+			glog.Warningf("Stored data in %s/%s.json is not valid JSON. It will be ignored until it's fixed.", name, id)
+			if hadFile {
+				files[id] = oldData
+			} else {
+				delete(files, id)
+			}
+			continue
+		}
+		if !hadFile || !bytes.Equal(data, oldData) {
+			if saves == nil {
+				saves = make(map[string]json.RawMessage)
+			}
+			saves[id] = data
+		}
+	}
+
+	for id := range oldFiles {
+		if _, ok := files[id]; !ok {
+			invalidations = append(invalidations, id)
+		}
+	}
+	return
+}
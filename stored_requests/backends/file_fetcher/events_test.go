@@ -0,0 +1,169 @@
+package file_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/stored_requests/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileEventProducer(t *testing.T) {
+	directory, err := ioutil.TempDir("", "stored_requests")
+	if err != nil {
+		t.Fatalf("Failed to create a temp directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	writeFile(t, directory, "stored_requests", "req-1", `{"id":"req-1"}`)
+	writeFile(t, directory, "stored_requests", "req-2", `{"id":"req-2"}`)
+	writeFile(t, directory, "stored_imps", "imp-1", `{"id":"imp-1"}`)
+	writeFile(t, directory, "accounts", "account-1", `{"id":"account-1"}`)
+
+	fetcher, fileEvents, err := NewFileFetcherWithEvents(directory)
+	assert.NoError(t, err, "Failed to create test fetcher")
+
+	// Nothing changed since the fetcher loaded the files
+	assert.NoError(t, fileEvents.Run())
+	assertNoEvents(t, fileEvents)
+
+	writeFile(t, directory, "stored_requests", "req-1", `{"id":"req-1","changed":true}`)
+	writeFile(t, directory, "stored_imps", "imp-2", `{"id":"imp-2"}`)
+	assert.NoError(t, os.Remove(filepath.Join(directory, "stored_requests", "req-2.json")))
+	assert.NoError(t, os.Remove(filepath.Join(directory, "accounts", "account-1.json")))
+
+	assert.NoError(t, fileEvents.Run())
+	save, invalidation := readEvents(t, fileEvents)
+	assert.Equal(t, map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1","changed":true}`)}, save.Requests)
+	assert.Equal(t, map[string]json.RawMessage{"imp-2": json.RawMessage(`{"id":"imp-2"}`)}, save.Imps)
+	assert.Nil(t, save.Accounts)
+	assert.Equal(t, []string{"req-2"}, invalidation.Requests)
+	assert.Nil(t, invalidation.Imps)
+	assert.Equal(t, []string{"account-1"}, invalidation.Accounts)
+
+	storedReqs, storedImps, errs := fetcher.FetchRequests(context.Background(), []string{"req-1", "req-2"}, []string{"imp-1", "imp-2"})
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "req-2", DataType: "Request"}}, errs)
+	assert.JSONEq(t, `{"id":"req-1","changed":true}`, string(storedReqs["req-1"]))
+	assert.JSONEq(t, `{"id":"imp-1"}`, string(storedImps["imp-1"]))
+	assert.JSONEq(t, `{"id":"imp-2"}`, string(storedImps["imp-2"]))
+
+	_, errs = fetcher.FetchAccount(context.Background(), "account-1")
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "account-1", DataType: "Account"}}, errs)
+}
+
+func TestFileEventProducerMalformedFiles(t *testing.T) {
+	directory, err := ioutil.TempDir("", "stored_requests")
+	if err != nil {
+		t.Fatalf("Failed to create a temp directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	writeFile(t, directory, "stored_requests", "req-1", `{"id":"req-1"}`)
+
+	fetcher, fileEvents, err := NewFileFetcherWithEvents(directory)
+	assert.NoError(t, err, "Failed to create test fetcher")
+
+	writeFile(t, directory, "stored_requests", "req-1", `{"id":`)
+	writeFile(t, directory, "stored_requests", "req-2", `{"id":`)
+
+	assert.NoError(t, fileEvents.Run())
+	assertNoEvents(t, fileEvents)
+
+	storedReqs, _, errs := fetcher.FetchRequests(context.Background(), []string{"req-1", "req-2"}, nil)
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "req-2", DataType: "Request"}}, errs)
+	assert.JSONEq(t, `{"id":"req-1"}`, string(storedReqs["req-1"]), "The last good version should be kept")
+
+	// Once the file is fixed, it should be picked up again
+	writeFile(t, directory, "stored_requests", "req-1", `{"id":"req-1","fixed":true}`)
+
+	assert.NoError(t, fileEvents.Run())
+	save, _ := readEvents(t, fileEvents)
+	assert.Equal(t, map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1","fixed":true}`)}, save.Requests)
+}
+
+func TestFileEventProducerReload(t *testing.T) {
+	directory, err := ioutil.TempDir("", "stored_requests")
+	if err != nil {
+		t.Fatalf("Failed to create a temp directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	writeFile(t, directory, "stored_requests", "req-1", `{"id":"req-1"}`)
+
+	fetcher, fileEvents, err := NewFileFetcherWithEvents(directory)
+	assert.NoError(t, err, "Failed to create test fetcher")
+
+	writeFile(t, directory, "stored_requests", "req-1", `{"id":"req-1","changed":true}`)
+	writeFile(t, directory, "stored_requests", "req-2", `{"id":"req-2"}`)
+
+	// Nothing listens to the events, so Reload mustn't block on them
+	assert.NoError(t, fileEvents.Reload())
+	assert.NoError(t, fileEvents.Reload())
+	assertNoEvents(t, fileEvents)
+
+	storedReqs, _, errs := fetcher.FetchRequests(context.Background(), []string{"req-1", "req-2"}, nil)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"id":"req-1","changed":true}`, string(storedReqs["req-1"]))
+	assert.JSONEq(t, `{"id":"req-2"}`, string(storedReqs["req-2"]))
+}
+
+func TestFileEventProducerMissingDirectory(t *testing.T) {
+	directory, err := ioutil.TempDir("", "stored_requests")
+	if err != nil {
+		t.Fatalf("Failed to create a temp directory: %v", err)
+	}
+	defer os.RemoveAll(directory)
+
+	writeFile(t, directory, "stored_requests", "req-1", `{"id":"req-1"}`)
+
+	fetcher, fileEvents, err := NewFileFetcherWithEvents(directory)
+	assert.NoError(t, err, "Failed to create test fetcher")
+
+	assert.NoError(t, os.RemoveAll(directory))
+	assert.Error(t, fileEvents.Run())
+	assertNoEvents(t, fileEvents)
+
+	_, _, errs := fetcher.FetchRequests(context.Background(), []string{"req-1"}, nil)
+	assert.Empty(t, errs, "The fetcher should keep its data if the directory can't be read")
+}
+
+func writeFile(t *testing.T, directory string, subdirectory string, id string, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(directory, subdirectory), 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", subdirectory, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(directory, subdirectory, id+".json"), []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write %s/%s.json: %v", subdirectory, id, err)
+	}
+}
+
+func readEvents(t *testing.T, fileEvents *FileEventProducer) (save events.Save, invalidation events.Invalidation) {
+	t.Helper()
+	// Read data from the channels with timeouts to avoid test suite deadlock
+	select {
+	case save = <-fileEvents.Saves():
+	case <-time.After(20 * time.Millisecond):
+	}
+	select {
+	case invalidation = <-fileEvents.Invalidations():
+	case <-time.After(20 * time.Millisecond):
+	}
+	return
+}
+
+func assertNoEvents(t *testing.T, fileEvents *FileEventProducer) {
+	t.Helper()
+	select {
+	case save := <-fileEvents.Saves():
+		t.Errorf("Unexpected save: %v", save)
+	case invalidation := <-fileEvents.Invalidations():
+		t.Errorf("Unexpected invalidation: %v", invalidation)
+	default:
+	}
+}
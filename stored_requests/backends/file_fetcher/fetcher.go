@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"sync"
 
 	"github.com/prebid/prebid-server/stored_requests"
 )
@@ -17,15 +18,20 @@ import (
 // For example, when asked to fetch the request with ID == "23", it will return the data from "directory/23.json".
 func NewFileFetcher(directory string) (stored_requests.AllFetcher, error) {
 	storedData, err := collectStoredData(directory, FileSystem{make(map[string]FileSystem), make(map[string]json.RawMessage)}, nil)
-	return &eagerFetcher{storedData, nil}, err
+	return &eagerFetcher{FileSystem: storedData}, err
 }
 
 type eagerFetcher struct {
 	FileSystem FileSystem
 	Categories map[string]map[string]stored_requests.Category
+	// mutex guards the fields above, which a FileEventProducer replaces when the files change
+	mutex sync.RWMutex
 }
 
 func (fetcher *eagerFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	fetcher.mutex.RLock()
+	defer fetcher.mutex.RUnlock()
+
 	storedRequests := fetcher.FileSystem.Directories["stored_requests"].Files
 	storedImpressions := fetcher.FileSystem.Directories["stored_imps"].Files
 	errs := appendErrors("Request", requestIDs, storedRequests, nil)
@@ -33,11 +39,24 @@ func (fetcher *eagerFetcher) FetchRequests(ctx context.Context, requestIDs []str
 	return storedRequests, storedImpressions, errs
 }
 
+// FetchResponses fetches the stored responses from the "stored_responses" directory
+func (fetcher *eagerFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	fetcher.mutex.RLock()
+	defer fetcher.mutex.RUnlock()
+
+	storedResponses := fetcher.FileSystem.Directories["stored_responses"].Files
+	errs := appendErrors("Response", ids, storedResponses, nil)
+	return storedResponses, errs
+}
+
 // FetchAccount fetches the host account configuration for a publisher
 func (fetcher *eagerFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
 	if len(accountID) == 0 {
 		return nil, []error{fmt.Errorf("Cannot look up an empty accountID")}
 	}
+	fetcher.mutex.RLock()
+	defer fetcher.mutex.RUnlock()
+
 	accountJSON, ok := fetcher.FileSystem.Directories["accounts"].Files[accountID]
 	if !ok {
 		return nil, []error{stored_requests.NotFoundError{
@@ -49,6 +68,10 @@ func (fetcher *eagerFetcher) FetchAccount(ctx context.Context, accountID string)
 }
 
 func (fetcher *eagerFetcher) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
+	// The categories are parsed lazily, so this needs to hold the write lock
+	fetcher.mutex.Lock()
+	defer fetcher.mutex.Unlock()
+
 	fileName := primaryAdServer
 
 	if len(publisherId) != 0 {
@@ -38,6 +38,16 @@ func TestAccountFetcher(t *testing.T) {
 	assert.Equal(t, stored_requests.NotFoundError{"nonexistent", "Account"}, errs[0])
 }
 
+func TestResponseFetcher(t *testing.T) {
+	fetcher, err := NewFileFetcher("./test")
+	assert.NoError(t, err, "Failed to create test fetcher")
+
+	storedResponses, errs := fetcher.FetchResponses(context.Background(), []string{"some-response", "nonexistent"})
+	assertErrorCount(t, 1, errs)
+	assert.Equal(t, stored_requests.NotFoundError{ID: "nonexistent", DataType: "Response"}, errs[0])
+	assert.JSONEq(t, `[{"seat": "appnexus", "bid": [{"id": "bid1", "impid": "some-imp", "price": 0.5}]}]`, string(storedResponses["some-response"]))
+}
+
 func TestInvalidDirectory(t *testing.T) {
 	_, err := NewFileFetcher("./nonexistant-directory")
 	if err == nil {
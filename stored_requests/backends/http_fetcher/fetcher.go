@@ -26,6 +26,9 @@ import (
 // Accounts
 // GET {endpoint}?account-ids=["acc1","acc2"]
 //
+// Stored responses
+// GET {endpoint}?response-ids=["resp1","resp2"]
+//
 // The above endpoints should return a payload like:
 //
 // {
@@ -46,6 +49,13 @@ import (
 //     "acc2": { ... config data for acc2 ... },
 //   },
 // }
+// or
+// {
+//   "responses": {
+//     "resp1": { ... stored data for resp1 ... },
+//     "resp2": { ... stored data for resp2 ... },
+//   },
+// }
 //
 //
 func NewFetcher(client *http.Client, endpoint string) *HttpFetcher {
@@ -161,6 +171,65 @@ func (fetcher *HttpFetcher) FetchAccount(ctx context.Context, accountID string)
 	return accountJSON, nil
 }
 
+// FetchResponses retrieves stored auction and bid responses
+//
+// Request format is similar to the one for accounts:
+// GET {endpoint}?response-ids=["response1","response2",...]
+//
+// The endpoint is expected to respond with a JSON map with responseID -> json.RawMessage
+// {
+//   "responses": {
+//     "response1": { ... response json ... }
+//   }
+// }
+func (fetcher *HttpFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fetcher.Endpoint+"response-ids=[\""+strings.Join(ids, "\",\"")+"\"]", nil)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: build request failed with %v`, ids, err),
+		}
+	}
+	httpResp, err := ctxhttp.Do(ctx, fetcher.client, httpReq)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: %v`, ids, err),
+		}
+	}
+	defer httpResp.Body.Close()
+	respBytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: error reading response: %v`, ids, err),
+		}
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: unexpected response status %d`, ids, httpResp.StatusCode),
+		}
+	}
+	var responseData storedResponsesContract
+	if err = json.Unmarshal(respBytes, &responseData); err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: failed to parse response: %v`, ids, err),
+		}
+	}
+	// Missing responses are reported just like the ones the endpoint returned as null
+	var errs []error
+	for _, id := range ids {
+		if data, ok := responseData.Responses[id]; !ok || bytes.Equal(data, []byte("null")) {
+			delete(responseData.Responses, id)
+			errs = append(errs, stored_requests.NotFoundError{
+				ID:       id,
+				DataType: "Response",
+			})
+		}
+	}
+	return responseData.Responses, errs
+}
+
 func (fetcher *HttpFetcher) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
 	if fetcher.Categories == nil {
 		fetcher.Categories = make(map[string]map[string]stored_requests.Category)
@@ -270,3 +339,7 @@ type responseContract struct {
 type accountsResponseContract struct {
 	Accounts map[string]json.RawMessage `json:"accounts"`
 }
+
+type storedResponsesContract struct {
+	Responses map[string]json.RawMessage `json:"responses"`
+}
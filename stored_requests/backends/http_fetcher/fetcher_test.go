@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -159,6 +160,45 @@ func TestFetchAccountNoIDProvided(t *testing.T) {
 	assert.Nil(t, account, "Fetching account with empty id should return nil")
 }
 
+func TestFetchResponses(t *testing.T) {
+	fetcher, close := newTestResponseFetcher(t, []string{"resp-1", "resp-2"}, jsonifyID)
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1", "resp-2"})
+	assert.Empty(t, errs, "Unexpected error fetching known stored responses")
+	assertMapKeys(t, respData, "resp-1", "resp-2")
+}
+
+func TestFetchResponsesMissingValues(t *testing.T) {
+	fetcher, close := newTestResponseFetcher(t, []string{"resp-1", "resp-2"}, jsonifyToNull)
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1", "resp-2"})
+	assert.Empty(t, respData, "Fetching unknown stored responses should return no responses")
+	assert.ElementsMatch(t, []error{
+		stored_requests.NotFoundError{ID: "resp-1", DataType: "Response"},
+		stored_requests.NotFoundError{ID: "resp-2", DataType: "Response"},
+	}, errs)
+}
+
+func TestFetchResponsesNoIDsProvided(t *testing.T) {
+	fetcher, close := newTestResponseFetcher(t, nil, jsonifyID)
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), nil)
+	assert.Empty(t, errs, "Fetching no stored responses shouldn't return errors")
+	assert.Nil(t, respData, "Fetching no stored responses should return a nil map")
+}
+
+func TestFetchResponsesBadJSON(t *testing.T) {
+	fetcher, close := newFetcherBadJSON()
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1"})
+	assert.Len(t, errs, 1, "Fetching stored responses with broken json should have returned an error")
+	assert.Nil(t, respData, "Fetching stored responses with broken json should return a nil map")
+}
+
 func TestErrResponse(t *testing.T) {
 	fetcher, close := newFetcherBrokenBackend()
 	defer close()
@@ -304,6 +344,29 @@ func newAccountHandler(t *testing.T, expectAccIDs []string, jsonifier func(strin
 	}
 }
 
+func newTestResponseFetcher(t *testing.T, expectRespIDs []string, jsonifier func(string) json.RawMessage) (fetcher *HttpFetcher, closer func()) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		gotRespIDs := richSplit(r.URL.Query().Get("response-ids"))
+		assertMatches(t, gotRespIDs, expectRespIDs)
+
+		respIDResponse := make(map[string]json.RawMessage, len(gotRespIDs))
+		for _, respID := range gotRespIDs {
+			if respID != "" {
+				respIDResponse[respID] = jsonifier(respID)
+			}
+		}
+
+		if respBytes, err := json.Marshal(storedResponsesContract{Responses: respIDResponse}); err != nil {
+			t.Errorf("failed to marshal storedResponsesContract in test:  %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+		} else {
+			w.Write(respBytes)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	return NewFetcher(server.Client(), server.URL), server.Close
+}
+
 func assertMatches(t *testing.T, queryVals []string, expected []string) {
 	t.Helper()
 
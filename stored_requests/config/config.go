@@ -21,6 +21,7 @@ import (
 	"github.com/prebid/prebid-server/stored_requests/events"
 	apiEvents "github.com/prebid/prebid-server/stored_requests/events/api"
 	httpEvents "github.com/prebid/prebid-server/stored_requests/events/http"
+	mysqlEvents "github.com/prebid/prebid-server/stored_requests/events/mysql"
 	postgresEvents "github.com/prebid/prebid-server/stored_requests/events/postgres"
 	"github.com/prebid/prebid-server/util/task"
 )
@@ -64,19 +65,58 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 			glog.Fatal("Multiple database connection settings found in config, only a single database connection is currently supported.")
 		}
 	}
+	if cfg.MySQL.ConnectionInfo.Database != "" {
+		conn := cfg.MySQL.ConnectionInfo.ConnString()
+
+		if dbc.conn == "" {
+			glog.Infof("Connecting to MySQL for Stored %s. DB=%s, host=%s, port=%d, user=%s",
+				cfg.DataType(),
+				cfg.MySQL.ConnectionInfo.Database,
+				cfg.MySQL.ConnectionInfo.Host,
+				cfg.MySQL.ConnectionInfo.Port,
+				cfg.MySQL.ConnectionInfo.Username)
+			db := newMySQLDB(cfg.DataType(), cfg.MySQL.ConnectionInfo)
+			dbc.conn = conn
+			dbc.db = db
+		}
+
+		// Error out if config is trying to use multiple database connections for different stored requests (not supported yet)
+		if conn != dbc.conn {
+			glog.Fatal("Multiple database connection settings found in config, only a single database connection is currently supported.")
+		}
+	}
 
 	eventProducers := newEventProducers(cfg, client, dbc.db, metricsEngine, router)
-	fetcher = newFetcher(cfg, client, dbc.db)
+	fetcher, fileEvents := newFetcher(cfg, client, dbc.db)
+
+	// The file reloads only send events if there's a cache listening to them
+	var fileRunner task.Runner
+	if fileEvents != nil {
+		fileRunner = fileReloader{fileEvents}
+	}
 
 	var shutdown1 func()
 
 	if cfg.InMemoryCache.Type != "" {
+		if fileEvents != nil {
+			eventProducers = append(eventProducers, fileEvents)
+			fileRunner = fileEvents
+		}
 		cache := newCache(cfg)
 		fetcher = stored_requests.WithCache(fetcher, cache, metricsEngine)
 		shutdown1 = addListeners(cache, eventProducers)
 	}
 
+	var fileEventTickerTask *task.TickerTask
+	if fileRunner != nil {
+		fileEventTickerTask = task.NewTickerTask(time.Duration(cfg.Files.RefreshRate)*time.Second, fileRunner)
+		fileEventTickerTask.Start()
+	}
+
 	shutdown = func() {
+		if fileEventTickerTask != nil {
+			fileEventTickerTask.Stop()
+		}
 		if shutdown1 != nil {
 			shutdown1()
 		}
@@ -156,16 +196,32 @@ func addListeners(cache stored_requests.Cache, eventProducers []events.EventProd
 	}
 }
 
-func newFetcher(cfg *config.StoredRequests, client *http.Client, db *sql.DB) (fetcher stored_requests.AllFetcher) {
+// fileReloader rereads the files of a Fetcher without sending any events.
+type fileReloader struct {
+	producer *file_fetcher.FileEventProducer
+}
+
+func (r fileReloader) Run() error {
+	return r.producer.Reload()
+}
+
+// newFetcher returns the Fetcher for the config. If the files it loads should be reloaded when they change,
+// it also returns the EventProducer which reloads them. It's up to the caller to run it.
+func newFetcher(cfg *config.StoredRequests, client *http.Client, db *sql.DB) (fetcher stored_requests.AllFetcher, fileEvents *file_fetcher.FileEventProducer) {
 	idList := make(stored_requests.MultiFetcher, 0, 3)
 
 	if cfg.Files.Enabled {
-		fFetcher := newFilesystem(cfg.DataType(), cfg.Files.Path)
+		var fFetcher stored_requests.AllFetcher
+		fFetcher, fileEvents = newFilesystem(cfg.DataType(), cfg.Files)
 		idList = append(idList, fFetcher)
 	}
 	if cfg.Postgres.FetcherQueries.QueryTemplate != "" {
 		glog.Infof("Loading Stored %s data via Postgres.\nQuery: %s", cfg.DataType(), cfg.Postgres.FetcherQueries.QueryTemplate)
-		idList = append(idList, db_fetcher.NewFetcher(db, cfg.Postgres.FetcherQueries.MakeQuery))
+		idList = append(idList, db_fetcher.NewFetcher(db, cfg.Postgres.FetcherQueries.MakeQuery, cfg.Postgres.FetcherQueries.MakeResponsesQuery))
+	}
+	if cfg.MySQL.FetcherQueries.QueryTemplate != "" {
+		glog.Infof("Loading Stored %s data via MySQL.\nQuery: %s", cfg.DataType(), cfg.MySQL.FetcherQueries.QueryTemplate)
+		idList = append(idList, db_fetcher.NewFetcher(db, cfg.MySQL.FetcherQueries.MakeQuery, cfg.MySQL.FetcherQueries.MakeResponsesQuery))
 	}
 	if cfg.HTTP.Endpoint != "" {
 		glog.Infof("Loading Stored %s data via HTTP. endpoint=%s", cfg.DataType(), cfg.HTTP.Endpoint)
@@ -213,6 +269,22 @@ func newEventProducers(cfg *config.StoredRequests, client *http.Client, db *sql.
 		pgEventTickerTask.Start()
 		eventProducers = append(eventProducers, pgEventProducer)
 	}
+	if cfg.MySQL.CacheInitialization.Query != "" {
+		mysqlEventCfg := postgresEvents.PostgresEventProducerConfig{
+			DB:                 db,
+			RequestType:        cfg.DataType(),
+			CacheInitQuery:     cfg.MySQL.CacheInitialization.Query,
+			CacheInitTimeout:   time.Duration(cfg.MySQL.CacheInitialization.Timeout) * time.Millisecond,
+			CacheUpdateQuery:   cfg.MySQL.PollUpdates.Query,
+			CacheUpdateTimeout: time.Duration(cfg.MySQL.PollUpdates.Timeout) * time.Millisecond,
+			MetricsEngine:      metricsEngine,
+		}
+		mysqlEventProducer := mysqlEvents.NewMySQLEventProducer(mysqlEventCfg)
+		fetchInterval := time.Duration(cfg.MySQL.PollUpdates.RefreshRate) * time.Second
+		mysqlEventTickerTask := task.NewTickerTask(fetchInterval, mysqlEventProducer)
+		mysqlEventTickerTask.Start()
+		eventProducers = append(eventProducers, mysqlEventProducer)
+	}
 	return
 }
 
@@ -230,13 +302,22 @@ func newHttpEvents(client *http.Client, timeout time.Duration, refreshRate time.
 	return httpEvents.NewHTTPEvents(client, endpoint, ctxProducer, refreshRate)
 }
 
-func newFilesystem(dataType config.DataType, configPath string) stored_requests.AllFetcher {
-	glog.Infof("Loading Stored %s data from filesystem at path %s", dataType, configPath)
-	fetcher, err := file_fetcher.NewFileFetcher(configPath)
+func newFilesystem(dataType config.DataType, cfg config.FileFetcherConfig) (stored_requests.AllFetcher, *file_fetcher.FileEventProducer) {
+	glog.Infof("Loading Stored %s data from filesystem at path %s", dataType, cfg.Path)
+	if cfg.RefreshRate <= 0 {
+		fetcher, err := file_fetcher.NewFileFetcher(cfg.Path)
+		if err != nil {
+			glog.Fatalf("Failed to create a %s FileFetcher: %v", dataType, err)
+		}
+		return fetcher, nil
+	}
+
+	glog.Infof("Reloading Stored %s data from filesystem every %d seconds", dataType, cfg.RefreshRate)
+	fetcher, fileEventProducer, err := file_fetcher.NewFileFetcherWithEvents(cfg.Path)
 	if err != nil {
 		glog.Fatalf("Failed to create a %s FileFetcher: %v", dataType, err)
 	}
-	return fetcher
+	return fetcher, fileEventProducer
 }
 
 func newPostgresDB(dataType config.DataType, cfg config.PostgresConnection) *sql.DB {
@@ -252,6 +333,19 @@ func newPostgresDB(dataType config.DataType, cfg config.PostgresConnection) *sql
 	return db
 }
 
+func newMySQLDB(dataType config.DataType, cfg config.MySQLConnection) *sql.DB {
+	db, err := sql.Open("mysql", cfg.ConnString())
+	if err != nil {
+		glog.Fatalf("Failed to open %s mysql connection: %v", dataType, err)
+	}
+
+	if err := db.Ping(); err != nil {
+		glog.Fatalf("Failed to ping %s mysql: %v", dataType, err)
+	}
+
+	return db
+}
+
 // consolidate returns a single Fetcher from an array of fetchers of any size.
 func consolidate(dataType config.DataType, fetchers []stored_requests.AllFetcher) stored_requests.AllFetcher {
 	if len(fetchers) == 0 {
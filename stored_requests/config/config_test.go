@@ -41,7 +41,7 @@ func isMemoryCacheType(cache stored_requests.CacheJSON) bool {
 }
 
 func TestNewEmptyFetcher(t *testing.T) {
-	fetcher := newFetcher(&config.StoredRequests{}, nil, nil)
+	fetcher, _ := newFetcher(&config.StoredRequests{}, nil, nil)
 	if fetcher == nil {
 		t.Errorf("The fetcher should be non-nil, even with an empty config.")
 	}
@@ -51,7 +51,7 @@ func TestNewEmptyFetcher(t *testing.T) {
 }
 
 func TestNewHTTPFetcher(t *testing.T) {
-	fetcher := newFetcher(&config.StoredRequests{
+	fetcher, _ := newFetcher(&config.StoredRequests{
 		HTTP: config.HTTPFetcherConfig{
 			Endpoint: "stored-requests.prebid.com",
 		},
@@ -65,6 +65,27 @@ func TestNewHTTPFetcher(t *testing.T) {
 	}
 }
 
+func TestNewFileFetcher(t *testing.T) {
+	fetcher, fileEvents := newFetcher(&config.StoredRequests{
+		Files: config.FileFetcherConfig{
+			Enabled: true,
+			Path:    "../backends/file_fetcher/test",
+		},
+	}, nil, nil)
+	assert.NotNil(t, fetcher)
+	assert.Nil(t, fileEvents, "The files shouldn't be reloaded without a refresh rate")
+
+	fetcher, fileEvents = newFetcher(&config.StoredRequests{
+		Files: config.FileFetcherConfig{
+			Enabled:     true,
+			Path:        "../backends/file_fetcher/test",
+			RefreshRate: 60,
+		},
+	}, nil, nil)
+	assert.NotNil(t, fetcher)
+	assert.NotNil(t, fileEvents, "The files should be reloaded when there's a refresh rate")
+}
+
 func TestNewHTTPEvents(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -150,6 +171,38 @@ func TestNewPostgresEventProducers(t *testing.T) {
 	metricsMock.AssertExpectations(t)
 }
 
+func TestNewMySQLEventProducers(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.Mock.On("RecordStoredDataFetchTime", mock.Anything, mock.Anything).Return()
+	metricsMock.Mock.On("RecordStoredDataError", mock.Anything).Return()
+
+	cfg := &config.StoredRequests{
+		MySQL: config.MySQLConfig{
+			CacheInitialization: config.MySQLCacheInitializer{
+				Timeout: 50,
+				Query:   "SELECT id, requestData, type FROM stored_data",
+			},
+			PollUpdates: config.MySQLUpdatePolling{
+				RefreshRate: 20,
+				Timeout:     50,
+				Query:       "SELECT id, requestData, type FROM stored_data WHERE last_updated > ?",
+			},
+		},
+	}
+	client := &http.Client{}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	mock.ExpectQuery("^" + regexp.QuoteMeta(cfg.MySQL.CacheInitialization.Query) + "$").WillReturnError(errors.New("Query failed"))
+
+	evProducers := newEventProducers(cfg, client, db, metricsMock, nil)
+	assertProducerLength(t, evProducers, 1)
+
+	assertExpectationsMet(t, mock)
+	metricsMock.AssertExpectations(t)
+}
+
 func TestNewEventsAPI(t *testing.T) {
 	router := httprouter.New()
 	newEventsAPI(router, "/test-endpoint")
@@ -0,0 +1,30 @@
+package mysql
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/stored_requests/events/postgres"
+)
+
+// NewMySQLEventProducer makes an EventProducer which polls a MySQL database for Stored data.
+// It works like the Postgres one, except for the wildcards of the update query.
+func NewMySQLEventProducer(cfg postgres.PostgresEventProducerConfig) *postgres.PostgresEventProducer {
+	if cfg.DB == nil {
+		glog.Fatalf("The MySQL Stored %s Loader needs a database connection to work.", cfg.RequestType)
+	}
+
+	cfg.UpdateQueryArgs = updateQueryArgs
+	return postgres.NewPostgresEventProducer(cfg)
+}
+
+// updateQueryArgs binds the time of the last update to every wildcard in the update query.
+// Unlike Postgres' $1, MySQL's ? placeholders can only be used once.
+func updateQueryArgs(query string, lastUpdate time.Time) []interface{} {
+	args := make([]interface{}, strings.Count(query, "?"))
+	for i := range args {
+		args[i] = lastUpdate
+	}
+	return args
+}
@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/stored_requests/events/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+const fakeQuery = "SELECT id, requestData, type FROM stored_data"
+
+const fakeUpdateQuery = "SELECT id, requestData, 'request' AS type FROM stored_requests WHERE last_updated > ? UNION ALL SELECT id, impData, 'imp' AS type FROM stored_imps WHERE last_updated > ?"
+
+func TestUpdateQueryArgs(t *testing.T) {
+	lastUpdate := time.Date(2020, time.July, 1, 12, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, []interface{}{lastUpdate, lastUpdate}, updateQueryArgs(fakeUpdateQuery, lastUpdate))
+	assert.Empty(t, updateQueryArgs(fakeQuery, lastUpdate))
+}
+
+func TestFetchDeltaBindsEveryWildcard(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	dbMock.ExpectQuery("^" + regexp.QuoteMeta(fakeQuery) + "$").WillReturnRows(sqlmock.NewRows([]string{"id", "data", "dataType"}))
+	dbMock.ExpectQuery("^"+regexp.QuoteMeta(fakeUpdateQuery)+"$").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data", "dataType"}))
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.Mock.On("RecordStoredDataFetchTime", mock.Anything, mock.Anything).Return()
+
+	eventProducer := NewMySQLEventProducer(postgres.PostgresEventProducerConfig{
+		DB:                 db,
+		RequestType:        config.RequestDataType,
+		CacheInitQuery:     fakeQuery,
+		CacheInitTimeout:   100 * time.Millisecond,
+		CacheUpdateQuery:   fakeUpdateQuery,
+		CacheUpdateTimeout: 100 * time.Millisecond,
+		MetricsEngine:      metricsMock,
+	})
+
+	assert.NoError(t, eventProducer.Run(), "Fetch all")
+	assert.NoError(t, eventProducer.Run(), "Fetch delta")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
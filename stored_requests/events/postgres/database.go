@@ -35,6 +35,9 @@ type PostgresEventProducerConfig struct {
 	CacheUpdateQuery   string
 	CacheUpdateTimeout time.Duration
 	MetricsEngine      metrics.MetricsEngine
+	// UpdateQueryArgs binds the time of the last update to the wildcards of the CacheUpdateQuery.
+	// If it's nil, the time is bound once, to the $1 of Postgres.
+	UpdateQueryArgs func(query string, lastUpdate time.Time) []interface{}
 }
 
 type PostgresEventProducer struct {
@@ -76,8 +79,7 @@ func (e *PostgresEventProducer) Invalidations() <-chan events.Invalidation {
 }
 
 func (e *PostgresEventProducer) fetchAll() (fetchErr error) {
-	timeout := e.cfg.CacheInitTimeout * time.Millisecond
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.CacheInitTimeout)
 	defer cancel()
 
 	startTime := e.time.Now().UTC()
@@ -113,12 +115,11 @@ func (e *PostgresEventProducer) fetchAll() (fetchErr error) {
 }
 
 func (e *PostgresEventProducer) fetchDelta() (fetchErr error) {
-	timeout := e.cfg.CacheUpdateTimeout * time.Millisecond
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.CacheUpdateTimeout)
 	defer cancel()
 
 	startTime := e.time.Now().UTC()
-	rows, err := e.cfg.DB.QueryContext(ctx, e.cfg.CacheUpdateQuery, e.lastUpdate)
+	rows, err := e.cfg.DB.QueryContext(ctx, e.cfg.CacheUpdateQuery, e.updateQueryArgs()...)
 	elapsedTime := time.Since(startTime)
 	e.recordFetchTime(elapsedTime, metrics.FetchDelta)
 
@@ -149,6 +150,13 @@ func (e *PostgresEventProducer) fetchDelta() (fetchErr error) {
 	return nil
 }
 
+func (e *PostgresEventProducer) updateQueryArgs() []interface{} {
+	if e.cfg.UpdateQueryArgs != nil {
+		return e.cfg.UpdateQueryArgs(e.cfg.CacheUpdateQuery, e.lastUpdate)
+	}
+	return []interface{}{e.lastUpdate}
+}
+
 func (e *PostgresEventProducer) recordFetchTime(elapsedTime time.Duration, fetchType metrics.StoredDataFetchType) {
 	e.cfg.MetricsEngine.RecordStoredDataFetchTime(
 		metrics.StoredDataLabels{
@@ -224,6 +224,31 @@ func TestFetchAllErrors(t *testing.T) {
 	}
 }
 
+func TestFetchAllTimeout(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	dbMock.ExpectQuery(fakeQueryRegex()).WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"id", "data", "dataType"}))
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.Mock.On("RecordStoredDataFetchTime", mock.Anything, mock.Anything).Return()
+	metricsMock.Mock.On("RecordStoredDataError", mock.Anything).Return()
+
+	eventProducer := NewPostgresEventProducer(PostgresEventProducerConfig{
+		DB:               db,
+		RequestType:      config.RequestDataType,
+		CacheInitTimeout: 10 * time.Millisecond,
+		CacheInitQuery:   fakeQuery,
+		MetricsEngine:    metricsMock,
+	})
+
+	start := time.Now()
+	assert.Error(t, eventProducer.Run())
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "The query should be cancelled once the timeout is up")
+	assert.True(t, eventProducer.lastUpdate.IsZero())
+}
+
 func TestFetchDeltaSuccess(t *testing.T) {
 	tests := []struct {
 		description         string
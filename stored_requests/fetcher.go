@@ -23,6 +23,13 @@ type Fetcher interface {
 	//
 	// The returned objects can only be read from. They may not be written to.
 	FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error)
+
+	// FetchResponses fetches the stored responses for the given IDs. These are the stored auction
+	// and bidder responses referenced by imp.ext.prebid.storedauctionresponse and imp.ext.prebid.storedbidresponse.
+	//
+	// The returned map will have a key for every ID in the ids list, unless errors exist.
+	// The returned objects can only be read from. They may not be written to.
+	FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error)
 }
 
 type AccountFetcher interface {
@@ -191,6 +198,11 @@ func (f *fetcherWithCache) FetchRequests(ctx context.Context, requestIDs []strin
 	return
 }
 
+// FetchResponses always delegates to the backing Fetcher. Stored responses are meant for testing, so they aren't cached.
+func (f *fetcherWithCache) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return f.fetcher.FetchResponses(ctx, ids)
+}
+
 func (f *fetcherWithCache) FetchAccount(ctx context.Context, accountID string) (account json.RawMessage, errs []error) {
 	accountData := f.cache.Accounts.Get(ctx, []string{accountID})
 	// TODO: add metrics
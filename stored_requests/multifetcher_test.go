@@ -176,3 +176,26 @@ func TestMultiFetcherAccountNotFound(t *testing.T) {
 	assert.Nil(t, account)
 	assert.EqualError(t, errs[0], NotFoundError{"MISSING", "Account"}.Error())
 }
+
+func TestMultiFetcherResponses(t *testing.T) {
+	f1 := &mockFetcher{}
+	f2 := &mockFetcher{}
+	fetcher := &MultiFetcher{f1, f2}
+	ctx := context.Background()
+
+	f1.On("FetchResponses", ctx, []string{"ONE", "TWO", "MISSING"}).Once().Return(
+		map[string]json.RawMessage{"ONE": json.RawMessage(`[]`)},
+		[]error{NotFoundError{"TWO", "Response"}, NotFoundError{"MISSING", "Response"}},
+	)
+	f2.On("FetchResponses", ctx, []string{"TWO", "MISSING"}).Once().Return(
+		map[string]json.RawMessage{"TWO": json.RawMessage(`{}`)},
+		[]error{NotFoundError{"MISSING", "Response"}},
+	)
+
+	responses, errs := fetcher.FetchResponses(ctx, []string{"ONE", "TWO", "MISSING"})
+
+	f1.AssertExpectations(t)
+	f2.AssertExpectations(t)
+	assert.Equal(t, []error{NotFoundError{"MISSING", "Response"}}, errs)
+	assert.Equal(t, map[string]json.RawMessage{"ONE": json.RawMessage(`[]`), "TWO": json.RawMessage(`{}`)}, responses)
+}